@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func (c *cli) newConfigCommand() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the configuration",
+	}
+
+	printCmd := &cobra.Command{
+		Use:   "print",
+		Short: "Print the effective configuration with secrets masked",
+		Long: `Print the effective configuration with secrets masked.
+
+Every variable is printed as KEY=value, with the value read from the environment and .env
+or its default. Passwords, keys and tokens are masked.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := c.config()
+			if err != nil {
+				return err
+			}
+			for _, entry := range cfg.Entries() {
+				if _, err := fmt.Fprintf(cmd.OutOrStdout(), "%s=%s\n", entry.Key, entry.Value); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+
+	configCmd.AddCommand(printCmd)
+	return configCmd
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alxhtp/monogo/internal/generator"
+	"github.com/spf13/cobra"
+)
+
+func (c *cli) newGenerateCommand() *cobra.Command {
+	generateCmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate code",
+	}
+
+	var fields, plural, root string
+	resourceCmd := &cobra.Command{
+		Use:   "resource <Name>",
+		Short: "Scaffold a resource end to end",
+		Long: `Scaffold a resource end to end, in the layout of the user resource.
+
+The entity, its migration, the DTOs, the repository, serializer and usecase with their
+implementations, the handler with its swagger annotations, the router and test skeletons are
+written, and the router is registered in ` + generator.RoutesFile + `. Nothing is written
+when a file of the resource exists.
+
+Fields are comma separated name:type[:option...], the types are string, text, int, int64,
+float, bool, time and uuid, the options required and unique, e.g.
+
+  monogo generate resource BlogPost --fields "title:string:required,body:text,published:bool,slug:string:required:unique"
+
+Then run make swagger and the migrations.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			parsed, err := generator.ParseFields(fields)
+			if err != nil {
+				return fmt.Errorf("--fields: %w", err)
+			}
+			resource, err := generator.NewResource(args[0], plural, parsed)
+			if err != nil {
+				return err
+			}
+
+			paths, err := generator.Generate(root, resource, time.Now())
+			for _, path := range paths {
+				fmt.Fprintln(cmd.OutOrStdout(), path)
+			}
+			return err
+		},
+	}
+	resourceCmd.Flags().StringVar(&fields, "fields", "", "fields of the resource, name:type[:option...] comma separated")
+	resourceCmd.Flags().StringVar(&plural, "plural", "", "plural of the name, naming the table and the routes, derived from the name by default")
+	resourceCmd.Flags().StringVar(&root, "root", ".", "root of the repository")
+	_ = resourceCmd.MarkFlagRequired("fields")
+
+	generateCmd.AddCommand(resourceCmd)
+	return generateCmd
+}
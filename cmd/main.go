@@ -7,10 +7,6 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
-
-	"github.com/alxhtp/monogo/config"
-	_ "github.com/alxhtp/monogo/docs"
-	restserver "github.com/alxhtp/monogo/internal/server/rest"
 )
 
 // Package main provides the API server
@@ -35,29 +31,17 @@ import (
 // @in                         header
 // @name                       Authorization
 // @description                Authentication token (Bearer token)
+//
+// @securityDefinitions.basic BasicAuth
+// @description               Admin endpoints basic authentication
 func main() {
-	if err := run(); err != nil {
-		slog.Error(fmt.Sprintf("application error: %v", err))
-		os.Exit(1)
-	}
-}
-
-func run() error {
 	// Handle interrupt (ctrl-c) and termination
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer stop()
+	err := newRootCommand().ExecuteContext(ctx)
+	stop()
 
-	// Load configuration
-	cfg, err := config.Load()
 	if err != nil {
-		return fmt.Errorf("load config: %v", err)
-	}
-
-	// Initialize and start server
-	srv := restserver.NewRestServer(ctx, cfg)
-	if err := srv.Start(); err != nil {
-		return fmt.Errorf("server start: %v", err)
+		slog.Error(fmt.Sprintf("application error: %v", err))
+		os.Exit(1)
 	}
-
-	return nil
 }
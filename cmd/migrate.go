@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/alxhtp/monogo/migration"
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
+	"github.com/spf13/cobra"
+)
+
+func (c *cli) newMigrateCommand() *cobra.Command {
+	migrate := &cobra.Command{
+		Use:   "migrate",
+		Short: "Manage the embedded database migrations",
+	}
+
+	var upLimit int
+	up := &cobra.Command{
+		Use:   "up",
+		Short: "Apply pending migrations, every one by default",
+		Long: `Apply pending migrations, every one by default.
+In the schema-per-tenant mode the schemas of the tenants are migrated too, unless --limit is set.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return c.withSQLDB(cmd.Context(), func(ctx context.Context, db *sql.DB) error {
+				applied, err := migration.Up(ctx, db, upLimit)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "applied %d migrations\n", applied)
+				if upLimit > 0 || !c.cfg.TenantEnabled || c.cfg.TenantMode == databasehelper.TenantModeRow {
+					return nil
+				}
+				return c.migrateTenants(ctx, cmd.OutOrStdout())
+			})
+		},
+	}
+	up.Flags().IntVar(&upLimit, "limit", 0, "maximum number of migrations, 0 for every one")
+
+	var downLimit int
+	down := &cobra.Command{
+		Use:   "down",
+		Short: "Roll back the latest migrations, one by default",
+		Long: `Roll back the latest migrations, one by default.
+In the schema-per-tenant mode the schemas of the tenants are rolled back too, by as many migrations each.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return c.withSQLDB(cmd.Context(), func(ctx context.Context, db *sql.DB) error {
+				rolledBack, err := migration.Down(ctx, db, downLimit)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "rolled back %d migrations\n", rolledBack)
+
+				schemas, err := c.tenantSchemas(ctx)
+				if err != nil {
+					return err
+				}
+				for _, schema := range schemas {
+					rolledBack, err := migration.DownSchema(ctx, db, schema, downLimit)
+					if err != nil {
+						return err
+					}
+					fmt.Fprintf(cmd.OutOrStdout(), "rolled back %d migrations of %s\n", rolledBack, schema)
+				}
+				return nil
+			})
+		},
+	}
+	down.Flags().IntVar(&downLimit, "limit", 1, "maximum number of migrations, 0 for every one")
+
+	status := &cobra.Command{
+		Use:   "status",
+		Short: "List the migrations and when they were applied",
+		Long: `List the migrations and when they were applied.
+In the schema-per-tenant mode the migrations of the schemas of the tenants are listed too.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return c.withSQLDB(cmd.Context(), func(ctx context.Context, db *sql.DB) error {
+				statuses, err := migration.GetStatus(ctx, db)
+				if err != nil {
+					return err
+				}
+				w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "SCHEMA\tMIGRATION\tAPPLIED")
+				writeStatuses(w, migration.SharedSchema, statuses)
+
+				schemas, err := c.tenantSchemas(ctx)
+				if err != nil {
+					return err
+				}
+				for _, schema := range schemas {
+					statuses, err := migration.GetSchemaStatus(ctx, db, schema)
+					if err != nil {
+						return err
+					}
+					writeStatuses(w, schema, statuses)
+				}
+				return w.Flush()
+			})
+		},
+	}
+
+	redo := &cobra.Command{
+		Use:   "redo",
+		Short: "Roll back the latest migration and apply it again",
+		Long: `Roll back the latest migration and apply it again.
+In the schema-per-tenant mode the latest migration of the schemas of the tenants is redone too.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return c.withSQLDB(cmd.Context(), func(ctx context.Context, db *sql.DB) error {
+				if err := migration.Redo(ctx, db); err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), "redone the latest migration")
+
+				schemas, err := c.tenantSchemas(ctx)
+				if err != nil {
+					return err
+				}
+				for _, schema := range schemas {
+					if err := migration.RedoSchema(ctx, db, schema); err != nil {
+						return err
+					}
+					fmt.Fprintf(cmd.OutOrStdout(), "redone the latest migration of %s\n", schema)
+				}
+				return nil
+			})
+		},
+	}
+
+	var dir string
+	create := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Write an empty migration into migration/files",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := migration.Create(dir, args[0], time.Now())
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), path)
+			return nil
+		},
+	}
+	create.Flags().StringVar(&dir, "dir", migration.Dir, "directory of the migration files")
+
+	migrate.AddCommand(up, down, status, redo, create)
+	return migrate
+}
+
+// migrateTenants applies the pending migrations to the schema of every tenant, provisioning an existing tenant upgrades it
+func (c *cli) migrateTenants(ctx context.Context, out io.Writer) error {
+	// the pool of withSQLDB is still open, NewGormDB returns it
+	db, err := c.openDB(ctx)
+	if err != nil {
+		return err
+	}
+
+	tenants := newTenantRepository(db, c.cfg.TenantMode)
+	all, err := tenants.GetAll(ctx)
+	if err != nil {
+		return fmt.Errorf("list tenants: %w", err)
+	}
+	for _, tenant := range all {
+		if _, _, err := tenants.Provision(ctx, tenant.ID); err != nil {
+			return fmt.Errorf("migrate tenant %s: %w", tenant.ID, err)
+		}
+	}
+	fmt.Fprintf(out, "migrated %d tenant schemas\n", len(all))
+	return nil
+}
+
+// tenantSchemas returns the schemas of every tenant in the schema-per-tenant mode, none otherwise
+func (c *cli) tenantSchemas(ctx context.Context) ([]string, error) {
+	if !c.cfg.TenantEnabled || c.cfg.TenantMode == databasehelper.TenantModeRow {
+		return nil, nil
+	}
+
+	// the pool of withSQLDB is still open, NewGormDB returns it
+	db, err := c.openDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tenants, err := newTenantRepository(db, c.cfg.TenantMode).GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list tenants: %w", err)
+	}
+	schemas := make([]string, len(tenants))
+	for i, tenant := range tenants {
+		schemas[i] = databasehelper.TenantSchema(tenant.ID)
+	}
+	return schemas, nil
+}
+
+// writeStatuses writes a row per migration of schema
+func writeStatuses(w io.Writer, schema string, statuses []migration.Status) {
+	for _, status := range statuses {
+		applied := "no"
+		if status.AppliedAt != nil {
+			applied = status.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", schema, status.ID, applied)
+	}
+}
+
+// withSQLDB runs fn against the database of DatabaseConfig, the connection is closed once fn returns
+func (c *cli) withSQLDB(ctx context.Context, fn func(ctx context.Context, db *sql.DB) error) error {
+	db, err := c.openDB(ctx)
+	if err != nil {
+		return err
+	}
+	defer databasehelper.CloseGormDB(db)
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return fn(ctx, sqlDB)
+}
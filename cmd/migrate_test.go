@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alxhtp/monogo/config"
+)
+
+func TestMigrateCommandValidatesArguments(t *testing.T) {
+	for _, args := range [][]string{
+		{"migrate", "up", "extra"},
+		{"migrate", "down", "extra"},
+		{"migrate", "status", "extra"},
+		{"migrate", "redo", "extra"},
+		{"migrate", "create"},
+		{"migrate", "create", "add-users-phone", "extra"},
+		{"migrate", "up", "--limit", "all"},
+	} {
+		if _, err := execute(t, &config.Config{}, args...); err == nil {
+			t.Errorf("%s: got no error", strings.Join(args, " "))
+		}
+	}
+}
+
+func TestMigrateCreateWritesIntoDir(t *testing.T) {
+	dir := t.TempDir()
+
+	out, err := execute(t, &config.Config{}, "migrate", "create", "add users phone", "--dir", dir)
+	if err != nil {
+		t.Fatalf("migrate create: %v", err)
+	}
+	path := strings.TrimSpace(out)
+	if filepath.Dir(path) != dir || !strings.HasSuffix(path, "-add-users-phone.sql") {
+		t.Fatalf("created %q, want a migration of %s", path, dir)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("migration is not written: %v", err)
+	}
+}
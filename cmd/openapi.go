@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	docsv1 "github.com/alxhtp/monogo/docs/v1"
+	docsv2 "github.com/alxhtp/monogo/docs/v2"
+	"github.com/alxhtp/monogo/internal/server/rest/router"
+	"github.com/spf13/cobra"
+	"github.com/swaggo/swag"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	openAPIFormatJSON = "json"
+	openAPIFormatYAML = "yaml"
+)
+
+func (c *cli) newOpenAPICommand() *cobra.Command {
+	openapi := &cobra.Command{
+		Use:   "openapi",
+		Short: "Work with the OpenAPI documents of the REST API",
+	}
+
+	var version, format, output string
+	export := &cobra.Command{
+		Use:   "export",
+		Short: "Write the OpenAPI document of an API version",
+		Long: `Write the OpenAPI document of an API version, as served under /swagger/<version>/doc.json.
+The document is written to standard output unless --output is given.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := c.config()
+			if err != nil {
+				return err
+			}
+
+			specs := map[string]*swag.Spec{router.V1: docsv1.SwaggerInfov1, router.V2: docsv2.SwaggerInfov2}
+			spec, ok := specs[version]
+			if !ok {
+				return fmt.Errorf("unknown API version %q, expected %s or %s", version, router.V1, router.V2)
+			}
+			// the same schemes as the served documents
+			spec.Schemes = []string{cfg.Scheme}
+
+			document, err := formatOpenAPI(spec.ReadDoc(), format)
+			if err != nil {
+				return err
+			}
+
+			if output == "" {
+				_, err = io.WriteString(cmd.OutOrStdout(), document)
+				return err
+			}
+			return os.WriteFile(output, []byte(document), 0o644)
+		},
+	}
+	export.Flags().StringVar(&version, "version", router.V1, "API version of the document, v1 or v2")
+	export.Flags().StringVar(&format, "format", openAPIFormatJSON, "format of the document, json or yaml")
+	export.Flags().StringVarP(&output, "output", "o", "", "file the document is written to")
+
+	openapi.AddCommand(export)
+	return openapi
+}
+
+// formatOpenAPI re-encodes the JSON document doc in format, keeping the order of its keys
+func formatOpenAPI(doc, format string) (string, error) {
+	switch format {
+	case openAPIFormatJSON:
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, []byte(doc), "", "    "); err != nil {
+			return "", fmt.Errorf("invalid OpenAPI document: %w", err)
+		}
+		buf.WriteByte('\n')
+		return buf.String(), nil
+	case openAPIFormatYAML:
+		// a yaml.Node keeps the key order, a map would sort the keys
+		var node yaml.Node
+		if err := yaml.Unmarshal([]byte(doc), &node); err != nil {
+			return "", fmt.Errorf("invalid OpenAPI document: %w", err)
+		}
+		resetStyle(&node)
+
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(&node); err != nil {
+			return "", err
+		}
+		if err := encoder.Close(); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+	return "", fmt.Errorf("unknown format %q, expected %s or %s", format, openAPIFormatJSON, openAPIFormatYAML)
+}
+
+// resetStyle drops the flow and quoted styles a JSON document is parsed with, so it is written as block YAML.
+// The encoder still quotes the strings that would otherwise read as another type.
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
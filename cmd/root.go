@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alxhtp/monogo/config"
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+// cli holds the configuration shared by every command, it is loaded on first use
+// so help and commands that do not read it work without an environment
+type cli struct {
+	cfg *config.Config
+}
+
+func newRootCommand() *cobra.Command {
+	return (&cli{}).newRootCommand()
+}
+
+// newRootCommand returns the commands of c, tests give c the configuration the commands read
+func (c *cli) newRootCommand() *cobra.Command {
+	root := &cobra.Command{
+		Use:   "monogo",
+		Short: "Monogo API server and operations commands",
+		Long: `Monogo API server and operations commands.
+
+Every command reads the configuration of the environment, see .env.example.
+Without a command the API server is started, the same as monogo serve.`,
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return c.serve(cmd.Context())
+		},
+	}
+
+	root.AddCommand(
+		c.newServeCommand(),
+		c.newMigrateCommand(),
+		c.newSeedCommand(),
+		c.newUserCommand(),
+		c.newConfigCommand(),
+		c.newOpenAPICommand(),
+		c.newGenerateCommand(),
+	)
+
+	return root
+}
+
+// config loads the configuration with config.Load, once
+func (c *cli) config() (*config.Config, error) {
+	if c.cfg != nil {
+		return c.cfg, nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("load config: %v", err)
+	}
+	c.cfg = cfg
+	return cfg, nil
+}
+
+// openDB connects to the database of DatabaseConfig without retrying, a command fails right away when it is down
+func (c *cli) openDB(ctx context.Context) (*gorm.DB, error) {
+	cfg, err := c.config()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := databasehelper.NewGormDB(ctx, &cfg.DatabaseConfig)
+	if err != nil {
+		return nil, fmt.Errorf("connect: %w", err)
+	}
+	return db, nil
+}
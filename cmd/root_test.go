@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/alxhtp/monogo/config"
+)
+
+// execute runs the command line args against cfg and returns what it printed.
+// None of the cases reach a database, their arguments are rejected before it is opened.
+func execute(t *testing.T, cfg *config.Config, args ...string) (string, error) {
+	t.Helper()
+
+	var out bytes.Buffer
+	root := (&cli{cfg: cfg}).newRootCommand()
+	root.SetOut(&out)
+	root.SetErr(&out)
+	root.SetArgs(args)
+	err := root.ExecuteContext(context.Background())
+	return out.String(), err
+}
+
+func TestConfigPrintMasksSecrets(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.DBPassword = "hunter2"
+	cfg.SecretKey = "jwt-secret"
+
+	out, err := execute(t, cfg, "config", "print")
+	if err != nil {
+		t.Fatalf("config print: %v", err)
+	}
+	if !strings.Contains(out, "DB_PASSWORD=****\n") || !strings.Contains(out, "JWT_SECRET_KEY=****\n") {
+		t.Fatalf("secrets are not masked:\n%s", out)
+	}
+	if strings.Contains(out, "hunter2") || strings.Contains(out, "jwt-secret") {
+		t.Fatalf("a secret is printed:\n%s", out)
+	}
+
+	if _, err := execute(t, cfg, "config", "print", "extra"); err == nil {
+		t.Fatal("config print accepted an argument")
+	}
+}
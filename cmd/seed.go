@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alxhtp/monogo/internal/seed"
+	userusecase "github.com/alxhtp/monogo/internal/usecase/user"
+	"github.com/spf13/cobra"
+)
+
+// defaultSeedDir holds a seed set per environment, e.g. fixtures/development
+const defaultSeedDir = "fixtures"
+
+func (c *cli) newSeedCommand() *cobra.Command {
+	var dir, environment, tenant string
+	var files []string
+
+	seedCmd := &cobra.Command{
+		Use:   "seed",
+		Short: "Seed the fixtures of an environment",
+		Long: `Seed the fixtures of an environment.
+
+The seed set of an environment is the YAML and JSON files of fixtures/<environment>, seeded
+in name order, the environment defaults to APP_ENVIRONMENT. A fixture file maps kinds to
+fixture lists, e.g. users to create user request bodies, and holds generate blocks creating
+fixtures with fake data. A fixture named with ref is referenced by the fixtures after it as
+${kind.ref.field}, e.g. ${users.alice.id}.
+
+Seeding is idempotent, an entity that exists is kept as is and is not created again.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := c.config()
+			if err != nil {
+				return err
+			}
+			if environment == "" {
+				environment = cfg.Environment
+			}
+			if len(files) == 0 {
+				if files, err = seed.SetFiles(dir, environment); err != nil {
+					return err
+				}
+			}
+
+			return c.withUserUsecase(cmd.Context(), tenant, func(ctx context.Context, usecase userusecase.UserUsecase) error {
+				results, err := seed.NewSeeder(seed.Users(usecase)).Run(ctx, files)
+				for _, result := range results {
+					fmt.Fprintf(cmd.OutOrStdout(), "%s: %d created, %d existing\n", result.Kind, result.Created, result.Existing)
+				}
+				return err
+			})
+		},
+	}
+	seedCmd.Flags().StringVar(&dir, "dir", defaultSeedDir, "directory of the seed sets")
+	seedCmd.Flags().StringVar(&environment, "env", "", "environment of the seed set, APP_ENVIRONMENT by default")
+	seedCmd.Flags().StringSliceVarP(&files, "file", "f", nil, "fixture files seeded instead of the seed set, in order")
+	seedCmd.Flags().StringVar(&tenant, "tenant", "", "tenant the fixtures are seeded for, required while multi-tenancy is enabled")
+
+	return seedCmd
+}
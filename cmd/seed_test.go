@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alxhtp/monogo/config"
+)
+
+func TestSeedCommandValidatesArguments(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Environment = "development"
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "empty"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "empty", "README.md"), []byte("no fixtures"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "argument", args: []string{"seed", "users.yaml"}, wantErr: `unknown command "users.yaml"`},
+		{name: "environment without seed set", args: []string{"seed", "--dir", dir}, wantErr: `no seed set for environment "development"`},
+		{name: "seed set without fixture files", args: []string{"seed", "--dir", dir, "--env", "empty"}, wantErr: "holds no fixture files"},
+		{name: "tenant without multi-tenancy", args: []string{"seed", "--file", "users.yaml", "--tenant", "acme"}, wantErr: "--tenant is only supported while TENANT_ENABLED is true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := execute(t, cfg, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
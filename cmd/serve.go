@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	grpcserver "github.com/alxhtp/monogo/internal/server/grpc"
+	restserver "github.com/alxhtp/monogo/internal/server/rest"
+	"github.com/alxhtp/monogo/pkg/lifecycle"
+	"github.com/alxhtp/monogo/pkg/tracing"
+	"github.com/spf13/cobra"
+)
+
+func (c *cli) newServeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Run the REST, gRPC and GraphQL servers until interrupted",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return c.serve(cmd.Context())
+		},
+	}
+}
+
+// serve runs the servers until ctx is done, then drains them
+func (c *cli) serve(ctx context.Context) error {
+	cfg, err := c.config()
+	if err != nil {
+		return err
+	}
+
+	// Components are started in order and stopped in reverse order within the drain timeout
+	manager := lifecycle.NewManager(time.Duration(cfg.ShutdownTimeout) * time.Second)
+
+	// Initialize tracing, spans are flushed once every other component stopped
+	shutdownTracing, err := tracing.Setup(ctx, &cfg.TracingConfig, cfg.AppName)
+	if err != nil {
+		return fmt.Errorf("setup tracing: %v", err)
+	}
+	manager.Append(lifecycle.Hook{Name: "tracing", OnStop: shutdownTracing})
+
+	// Initialize server
+	srv, err := restserver.NewRestServer(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("server init: %v", err)
+	}
+	if err := srv.Register(manager); err != nil {
+		return fmt.Errorf("server init: %v", err)
+	}
+
+	// gRPC shares the user usecase of the REST server, it is stopped first
+	if cfg.GrpcEnabled {
+		grpcSrv, err := grpcserver.NewGrpcServer(cfg, srv.UserUsecase(), srv.TenantUsecase())
+		if err != nil {
+			return fmt.Errorf("grpc server init: %v", err)
+		}
+		grpcSrv.Register(manager)
+	}
+
+	// Run until interrupted, then drain
+	if err := manager.Run(ctx); err != nil {
+		return fmt.Errorf("server: %v", err)
+	}
+
+	return nil
+}
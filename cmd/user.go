@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	outboxrepository "github.com/alxhtp/monogo/internal/repository/outbox/implementation"
+	tenantrepositoryinterface "github.com/alxhtp/monogo/internal/repository/tenant"
+	tenantrepository "github.com/alxhtp/monogo/internal/repository/tenant/implementation"
+	tenantrepositoryrow "github.com/alxhtp/monogo/internal/repository/tenant/row"
+	transactionrepository "github.com/alxhtp/monogo/internal/repository/transaction/implementation"
+	userrepository "github.com/alxhtp/monogo/internal/repository/user/implementation"
+	userserializer "github.com/alxhtp/monogo/internal/serializer/user/implementation"
+	userusecase "github.com/alxhtp/monogo/internal/usecase/user"
+	userusecaseimplementation "github.com/alxhtp/monogo/internal/usecase/user/implementation"
+	"github.com/alxhtp/monogo/pkg/constant"
+	"github.com/alxhtp/monogo/pkg/dto"
+	dtov2 "github.com/alxhtp/monogo/pkg/dto/v2"
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+func (c *cli) newUserCommand() *cobra.Command {
+	var tenant string
+	var asJSON bool
+
+	user := &cobra.Command{
+		Use:   "user",
+		Short: "Manage users without going through the API",
+		Long: `Manage users without going through the API.
+
+Changes are made through the user usecase, so they are validated, audited as the cli actor
+and published as domain events like the changes made through the API. The servers cache
+user reads, they see a change once their cache entries expire after CACHE_TTL.`,
+	}
+	user.PersistentFlags().StringVar(&tenant, "tenant", "", "tenant the users belong to, required while multi-tenancy is enabled")
+	user.PersistentFlags().BoolVar(&asJSON, "json", false, "print the response envelopes as JSON")
+
+	var create dto.ReqCreateUser
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a user",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return c.withUserUsecase(cmd.Context(), tenant, func(ctx context.Context, usecase userusecase.UserUsecase) error {
+				res := usecase.CreateUser(ctx, &create)
+				if !res.Success {
+					return fmt.Errorf("create user: %w", &res.BaseRes)
+				}
+				return printUsers(cmd.OutOrStdout(), asJSON, res, res.Data)
+			})
+		},
+	}
+	createCmd.Flags().StringVar(&create.Name, "name", "", "name of the user")
+	createCmd.Flags().StringVar(&create.Email, "email", "", "email of the user")
+	createCmd.Flags().StringVar(&create.Metadata.Sex, "sex", "", "sex of the user, male or female")
+	createCmd.Flags().StringVar(&create.Metadata.Address, "address", "", "address of the user")
+	createCmd.Flags().StringVar(&create.Metadata.Phone, "phone", "", "phone number of the user in the E.164 format")
+
+	banCmd := &cobra.Command{
+		Use:   "ban <id|email>",
+		Short: "Ban a user",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.withUserUsecase(cmd.Context(), tenant, func(ctx context.Context, usecase userusecase.UserUsecase) error {
+				id, err := resolveUserID(ctx, usecase, args[0])
+				if err != nil {
+					return err
+				}
+				status := int(constant.UserStatusBanned)
+				res := usecase.UpdateUser(ctx, id, &dto.ReqUpdateUser{Status: &status})
+				if !res.Success {
+					return fmt.Errorf("ban user: %w", &res.BaseRes)
+				}
+				return printUsers(cmd.OutOrStdout(), asJSON, res, res.Data)
+			})
+		},
+	}
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete <id|email>",
+		Short: "Delete a user",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.withUserUsecase(cmd.Context(), tenant, func(ctx context.Context, usecase userusecase.UserUsecase) error {
+				id, err := resolveUserID(ctx, usecase, args[0])
+				if err != nil {
+					return err
+				}
+				res := usecase.DeleteUser(ctx, id)
+				if !res.Success {
+					return fmt.Errorf("delete user: %w", &res)
+				}
+				if asJSON {
+					return printJSON(cmd.OutOrStdout(), res)
+				}
+				_, err = fmt.Fprintf(cmd.OutOrStdout(), "deleted user %s\n", id)
+				return err
+			})
+		},
+	}
+
+	var filter dto.ReqGetUser
+	var name, email string
+	var status, offset, limit int
+	var includeDeleted bool
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List users by filter",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			// only the flags given narrow the list, like the query parameters of the API
+			flags := cmd.Flags()
+			if flags.Changed("name") {
+				filter.Name = &name
+			}
+			if flags.Changed("email") {
+				filter.Email = &email
+			}
+			if flags.Changed("status") {
+				filter.Status = &status
+			}
+			if flags.Changed("include-deleted") {
+				filter.IncludeDeleted = &includeDeleted
+			}
+			filter.Offset = &offset
+			filter.Limit = &limit
+
+			return c.withUserUsecase(cmd.Context(), tenant, func(ctx context.Context, usecase userusecase.UserUsecase) error {
+				res := usecase.GetUsersByFilter(ctx, &filter)
+				if !res.Success {
+					return fmt.Errorf("list users: %w", &res.BaseRes)
+				}
+				users := make([]*dto.ResUser, len(res.Data))
+				for i := range res.Data {
+					users[i] = &res.Data[i]
+				}
+				return printUsers(cmd.OutOrStdout(), asJSON, res, users...)
+			})
+		},
+	}
+	listCmd.Flags().StringVar(&name, "name", "", "only users with this name")
+	listCmd.Flags().StringVar(&email, "email", "", "only the user with this email")
+	listCmd.Flags().IntVar(&status, "status", 0, "only users with this status, 0 inactive, 1 active, 2 banned")
+	listCmd.Flags().BoolVar(&includeDeleted, "include-deleted", false, "list deleted users too")
+	listCmd.Flags().IntVar(&offset, "offset", 0, "number of users to skip")
+	listCmd.Flags().IntVar(&limit, "limit", 100, "maximum number of users")
+
+	user.AddCommand(createCmd, banCmd, deleteCmd, listCmd)
+	return user
+}
+
+// withUserUsecase runs fn with the user usecase over the database of DatabaseConfig and a context of the cli actor.
+// While multi-tenancy is enabled fn runs for tenant, which must have been registered.
+// The usecase reads the database directly, without the cache of the servers, and publishes no events to subscribers.
+func (c *cli) withUserUsecase(ctx context.Context, tenant string, fn func(ctx context.Context, usecase userusecase.UserUsecase) error) error {
+	cfg, err := c.config()
+	if err != nil {
+		return err
+	}
+	if !cfg.TenantEnabled && tenant != "" {
+		return errors.New("--tenant is only supported while TENANT_ENABLED is true")
+	}
+	if cfg.TenantEnabled && tenant == "" {
+		return errors.New("--tenant is required while TENANT_ENABLED is true")
+	}
+
+	db, err := c.openDB(ctx)
+	if err != nil {
+		return err
+	}
+	defer databasehelper.CloseGormDB(db)
+
+	ctx = contexthelper.WithActor(ctx, contexthelper.ActorCLI)
+	if cfg.TenantEnabled {
+		if err := databasehelper.EnableTenancy(ctx, db, &cfg.DatabaseConfig, &cfg.TenantConfig); err != nil {
+			return fmt.Errorf("enable multi-tenancy: %w", err)
+		}
+		if _, err := newTenantRepository(db, cfg.TenantMode).GetByID(ctx, tenant); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("tenant %q is not registered", tenant)
+			}
+			return fmt.Errorf("tenant %q: %w", tenant, err)
+		}
+		ctx = contexthelper.WithTenant(ctx, tenant)
+	}
+
+	usecase := userusecaseimplementation.NewUserUsecase(
+		userrepository.NewUserRepository(db),
+		outboxrepository.NewOutboxRepository(db),
+		transactionrepository.NewTransactionRepository(db),
+		userserializer.NewUserSerializer(),
+		nil,
+		cfg.UserImportConfig,
+		cfg.UserEventsConfig,
+	)
+	return fn(ctx, usecase)
+}
+
+// newTenantRepository returns the tenant registry of mode
+func newTenantRepository(db *gorm.DB, mode string) tenantrepositoryinterface.TenantRepository {
+	if mode == databasehelper.TenantModeRow {
+		return tenantrepositoryrow.NewTenantRepository(db)
+	}
+	return tenantrepository.NewTenantRepository(db)
+}
+
+// resolveUserID returns the id of the user named by ref, an id or an email
+func resolveUserID(ctx context.Context, usecase userusecase.UserUsecase, ref string) (uuid.UUID, error) {
+	if id, err := uuid.Parse(ref); err == nil {
+		return id, nil
+	}
+
+	res := usecase.GetUsersByFilter(ctx, &dto.ReqGetUser{Email: &ref})
+	if !res.Success {
+		return uuid.Nil, fmt.Errorf("find user: %w", &res.BaseRes)
+	}
+	if len(res.Data) == 0 {
+		return uuid.Nil, fmt.Errorf("no user with id or email %q", ref)
+	}
+	return res.Data[0].ID, nil
+}
+
+// printUsers prints the response envelope res as JSON, or users as a table
+func printUsers(w io.Writer, asJSON bool, res any, users ...*dto.ResUser) error {
+	if asJSON {
+		return printJSON(w, res)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tEMAIL\tSTATUS\tSEX\tPHONE")
+	for _, user := range users {
+		if user == nil {
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			user.ID, user.Name, user.Email, dtov2.UserStatusLabel(user.Status), user.Metadata.Sex, user.Metadata.Phone)
+	}
+	return tw.Flush()
+}
+
+func printJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alxhtp/monogo/config"
+)
+
+func TestUserCommandValidatesArguments(t *testing.T) {
+	single := &config.Config{}
+	tenants := &config.Config{}
+	tenants.TenantEnabled = true
+
+	tests := []struct {
+		name    string
+		cfg     *config.Config
+		args    []string
+		wantErr string
+	}{
+		{name: "create with an argument", cfg: single, args: []string{"user", "create", "alice"}, wantErr: "unknown command"},
+		{name: "ban without a user", cfg: single, args: []string{"user", "ban"}, wantErr: "accepts 1 arg(s), received 0"},
+		{name: "ban with two users", cfg: single, args: []string{"user", "ban", "alice", "bob"}, wantErr: "accepts 1 arg(s), received 2"},
+		{name: "delete without a user", cfg: single, args: []string{"user", "delete"}, wantErr: "accepts 1 arg(s), received 0"},
+		{name: "list with an argument", cfg: single, args: []string{"user", "list", "alice"}, wantErr: "unknown command"},
+		{name: "unknown flag", cfg: single, args: []string{"user", "list", "--role", "admin"}, wantErr: "unknown flag: --role"},
+		{name: "invalid flag value", cfg: single, args: []string{"user", "list", "--status", "banned"}, wantErr: "invalid argument"},
+		{name: "tenant without multi-tenancy", cfg: single, args: []string{"user", "list", "--tenant", "acme"}, wantErr: "--tenant is only supported while TENANT_ENABLED is true"},
+		{name: "multi-tenancy without tenant", cfg: tenants, args: []string{"user", "delete", "alice@example.com"}, wantErr: "--tenant is required while TENANT_ENABLED is true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := execute(t, tt.cfg, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
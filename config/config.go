@@ -7,15 +7,32 @@ import (
 	"github.com/kelseyhightower/envconfig"
 )
 
-// Config holds all configuration for the application
+// Config holds all configuration for the application.
+// Fields tagged secret are masked when the configuration is printed, see Entries.
 type Config struct {
 	AppConfig
 	SwaggerAuth
+	AdminAuth
 	DatabaseConfig
+	MigrationConfig
 	LogConfig
 	CORSConfig
 	JWTConfig
 	RateLimitConfig
+	UserImportConfig
+	OutboxConfig
+	WebhookConfig
+	UserEventsConfig
+	MetricsConfig
+	TracingConfig
+	HealthConfig
+	TLSConfig
+	GrpcConfig
+	GraphqlConfig
+	APIVersionConfig
+	IdempotencyConfig
+	CacheConfig
+	TenantConfig
 }
 
 // AppConfig holds application-specific configuration
@@ -30,6 +47,12 @@ type AppConfig struct {
 	WriteTimeout int    `envconfig:"APP_WRITE_TIMEOUT" default:"120"`
 	IdleTimeout  int    `envconfig:"APP_IDLE_TIMEOUT" default:"60"`
 	BodyLimit    int    `envconfig:"APP_BODY_LIMIT" default:"4"`
+
+	// ShutdownTimeout bounds draining in-flight requests and stopping every component, in seconds
+	ShutdownTimeout int `envconfig:"APP_SHUTDOWN_TIMEOUT" default:"30"`
+	// ShutdownDelay keeps serving after readiness turns down so load balancers stop routing first, in seconds.
+	// It is part of the shutdown timeout.
+	ShutdownDelay int `envconfig:"APP_SHUTDOWN_DELAY" default:"5"`
 }
 
 // DatabaseConfig holds database configuration
@@ -38,11 +61,30 @@ type DatabaseConfig struct {
 	DBPort          int    `envconfig:"DB_PORT" default:"5432"`
 	DBName          string `envconfig:"DB_NAME" default:"app"`
 	DBUser          string `envconfig:"DB_USER" default:"user"`
-	DBPassword      string `envconfig:"DB_PASSWORD" default:"password"`
+	DBPassword      string `envconfig:"DB_PASSWORD" default:"password" secret:"true"`
 	DBSSLMode       string `envconfig:"DB_SSL_MODE" default:"disable"`
 	MaxIdleConns    int    `envconfig:"DB_MAX_IDLE_CONNS" default:"10"`
 	MaxOpenConns    int    `envconfig:"DB_MAX_OPEN_CONNS" default:"100"`
 	ConnMaxLifetime string `envconfig:"DB_CONN_MAX_LIFETIME" default:"1h"`
+
+	// Startup connection retry, 0 attempts retries until shutdown
+	ConnectMaxAttempts int    `envconfig:"DB_CONNECT_MAX_ATTEMPTS" default:"0"`
+	ConnectBackoff     string `envconfig:"DB_CONNECT_BACKOFF" default:"1s"`
+	ConnectMaxBackoff  string `envconfig:"DB_CONNECT_MAX_BACKOFF" default:"30s"`
+
+	// Read replicas, comma separated postgres:// DSNs. Reads outside of a transaction go to a healthy replica,
+	// a caller reads from the primary for DB_REPLICA_STICKY_WINDOW after its writes.
+	ReplicaDSNs          []string `envconfig:"DB_REPLICA_DSNS" secret:"true"`
+	ReplicaStickyWindow  string   `envconfig:"DB_REPLICA_STICKY_WINDOW" default:"5s"`
+	ReplicaMaxLag        string   `envconfig:"DB_REPLICA_MAX_LAG" default:"10s"`
+	ReplicaCheckInterval string   `envconfig:"DB_REPLICA_CHECK_INTERVAL" default:"5s"`
+}
+
+// MigrationConfig holds the embedded migrations. With MIGRATION_AUTO the server applies the pending migrations on startup,
+// otherwise it refuses to start while migrations are pending unless MIGRATION_CHECK is disabled.
+type MigrationConfig struct {
+	MigrationAuto  bool `envconfig:"MIGRATION_AUTO" default:"false"`
+	MigrationCheck bool `envconfig:"MIGRATION_CHECK" default:"true"`
 }
 
 // LogConfig holds logging configuration
@@ -56,14 +98,14 @@ type LogConfig struct {
 type CORSConfig struct {
 	AllowedOrigins   []string `envconfig:"CORS_ALLOWED_ORIGINS" default:"*"`
 	AllowedMethods   []string `envconfig:"CORS_ALLOWED_METHODS" default:"GET,POST,PUT,DELETE,OPTIONS"`
-	AllowedHeaders   []string `envconfig:"CORS_ALLOWED_HEADERS" default:"Origin,Content-Type,Accept,Authorization"`
+	AllowedHeaders   []string `envconfig:"CORS_ALLOWED_HEADERS" default:"Origin,Content-Type,Accept,Authorization,Idempotency-Key"`
 	AllowCredentials bool     `envconfig:"CORS_ALLOW_CREDENTIALS" default:"true"`
 	MaxAge           int      `envconfig:"CORS_MAX_AGE" default:"300"`
 }
 
 // JWTConfig holds JWT configuration
 type JWTConfig struct {
-	SecretKey                string `envconfig:"JWT_SECRET_KEY" required:"true"`
+	SecretKey                string `envconfig:"JWT_SECRET_KEY" required:"true" secret:"true"`
 	AccessTokenExpiryInHours int    `envconfig:"JWT_ACCESS_TOKEN_EXPIRY_IN_HOURS" default:"1"`
 	RefreshTokenExpiryInDays int    `envconfig:"JWT_REFRESH_TOKEN_EXPIRY_IN_DAYS" default:"7"`
 	TokenIssuer              string `envconfig:"JWT_TOKEN_ISSUER" default:"upskill-tb"`
@@ -75,10 +117,164 @@ type RateLimitConfig struct {
 	MaxRequests int `envconfig:"RATE_LIMIT_MAX_REQUESTS" default:"100"`
 }
 
+// UserImportConfig holds CSV user import configuration
+type UserImportConfig struct {
+	ImportMaxRows        int    `envconfig:"USER_IMPORT_MAX_ROWS" default:"100000"`
+	ImportAsyncThreshold int    `envconfig:"USER_IMPORT_ASYNC_THRESHOLD" default:"500"`
+	ImportJobRetention   string `envconfig:"USER_IMPORT_JOB_RETENTION" default:"24h"`
+}
+
+// OutboxConfig holds domain events outbox relay configuration
+type OutboxConfig struct {
+	OutboxPublishers     []string `envconfig:"OUTBOX_PUBLISHERS" default:"log,webhook-subscriptions,pgnotify"`
+	OutboxPollInterval   string   `envconfig:"OUTBOX_POLL_INTERVAL" default:"1s"`
+	OutboxBatchSize      int      `envconfig:"OUTBOX_BATCH_SIZE" default:"100"`
+	OutboxMaxBackoff     string   `envconfig:"OUTBOX_MAX_BACKOFF" default:"5m"`
+	OutboxClaimTimeout   string   `envconfig:"OUTBOX_CLAIM_TIMEOUT" default:"1m"` // a batch not recorded by then is published again
+	OutboxWebhookURL     string   `envconfig:"OUTBOX_WEBHOOK_URL"`
+	OutboxWebhookTimeout string   `envconfig:"OUTBOX_WEBHOOK_TIMEOUT" default:"10s"`
+	OutboxNotifyChannel  string   `envconfig:"OUTBOX_NOTIFY_CHANNEL" default:"monogo_events"`
+}
+
+// WebhookConfig holds outgoing webhook deliveries configuration
+type WebhookConfig struct {
+	WebhookMaxAttempts  int    `envconfig:"WEBHOOK_MAX_ATTEMPTS" default:"8"`
+	WebhookTimeout      string `envconfig:"WEBHOOK_TIMEOUT" default:"10s"`
+	WebhookPollInterval string `envconfig:"WEBHOOK_POLL_INTERVAL" default:"1s"`
+	WebhookBatchSize    int    `envconfig:"WEBHOOK_BATCH_SIZE" default:"50"`
+	WebhookBaseBackoff  string `envconfig:"WEBHOOK_BASE_BACKOFF" default:"10s"`
+	WebhookMaxBackoff   string `envconfig:"WEBHOOK_MAX_BACKOFF" default:"1h"`
+
+	// WebhookAllowPrivateNetworks lets deliveries reach loopback, private and link-local addresses, for local development
+	WebhookAllowPrivateNetworks bool `envconfig:"WEBHOOK_ALLOW_PRIVATE_NETWORKS" default:"false"`
+
+	// WebhookMaxRedeliveries bounds how many times a delivery is queued again through the redeliver endpoint
+	WebhookMaxRedeliveries int `envconfig:"WEBHOOK_MAX_REDELIVERIES" default:"3"`
+}
+
+// UserEventsConfig holds user events stream configuration.
+// The stream is fed by the pgnotify outbox publisher.
+type UserEventsConfig struct {
+	UserEventsHeartbeatInterval string `envconfig:"USER_EVENTS_HEARTBEAT_INTERVAL" default:"15s"`
+	UserEventsReplayLimit       int    `envconfig:"USER_EVENTS_REPLAY_LIMIT" default:"1000"`
+	UserEventsBufferSize        int    `envconfig:"USER_EVENTS_BUFFER_SIZE" default:"256"`
+}
+
+// MetricsConfig holds prometheus metrics configuration.
+// Metrics are served on the API port unless METRICS_PORT is set.
+type MetricsConfig struct {
+	MetricsEnabled bool   `envconfig:"METRICS_ENABLED" default:"true"`
+	MetricsPath    string `envconfig:"METRICS_PATH" default:"/metrics"`
+	MetricsPort    int    `envconfig:"METRICS_PORT" default:"0"`
+}
+
+// TracingConfig holds OpenTelemetry tracing configuration, exporter is one of none, stdout, otlp
+type TracingConfig struct {
+	TracingExporter     string            `envconfig:"TRACING_EXPORTER" default:"none"`
+	TracingServiceName  string            `envconfig:"TRACING_SERVICE_NAME"` // defaults to APP_NAME
+	TracingSampleRatio  float64           `envconfig:"TRACING_SAMPLE_RATIO" default:"1"`
+	TracingOTLPEndpoint string            `envconfig:"TRACING_OTLP_ENDPOINT" default:"localhost:4318"`
+	TracingOTLPInsecure bool              `envconfig:"TRACING_OTLP_INSECURE" default:"true"`
+	TracingOTLPHeaders  map[string]string `envconfig:"TRACING_OTLP_HEADERS" secret:"true"`
+}
+
+// HealthConfig holds readiness checks configuration, the disk check is disabled when HEALTH_DISK_MIN_FREE_MB is 0
+type HealthConfig struct {
+	HealthCheckTimeout     string `envconfig:"HEALTH_CHECK_TIMEOUT" default:"2s"`
+	HealthMigrationTable   string `envconfig:"HEALTH_MIGRATION_TABLE" default:"migrations"`
+	HealthMigrationVersion string `envconfig:"HEALTH_MIGRATION_VERSION"`
+	HealthDiskPath         string `envconfig:"HEALTH_DISK_PATH" default:"/"`
+	HealthDiskMinFreeMB    uint64 `envconfig:"HEALTH_DISK_MIN_FREE_MB" default:"100"`
+}
+
+// TLSConfig holds HTTPS configuration, used when APP_SCHEME is https.
+// Client certificates are verified against TLS_CLIENT_CA_FILE when it is set, TLS_CLIENT_AUTH is one of none, optional, require.
+type TLSConfig struct {
+	TLSCertFile       string `envconfig:"TLS_CERT_FILE"`
+	TLSKeyFile        string `envconfig:"TLS_KEY_FILE"`
+	TLSClientCAFile   string `envconfig:"TLS_CLIENT_CA_FILE"`
+	TLSClientAuth     string `envconfig:"TLS_CLIENT_AUTH" default:"require"`
+	TLSMinVersion     string `envconfig:"TLS_MIN_VERSION" default:"1.2"`
+	TLSReloadInterval string `envconfig:"TLS_RELOAD_INTERVAL" default:"30s"`
+	TLSRedirectPort   int    `envconfig:"TLS_REDIRECT_PORT" default:"0"` // plain HTTP port redirecting to HTTPS, 0 disables it
+}
+
+// GrpcConfig holds gRPC server configuration.
+// GRPC_AUTH_TOKENS maps caller names to bearer tokens, e.g. billing:token1,crm:token2, it is required when gRPC is enabled.
+// GRPC_TOKEN_TENANTS maps caller names to the tenants they may use, e.g. billing:acme|globex,crm:*,
+// every caller needs one when multi-tenancy is enabled.
+type GrpcConfig struct {
+	GrpcEnabled      bool              `envconfig:"GRPC_ENABLED" default:"false"`
+	GrpcPort         int               `envconfig:"GRPC_PORT" default:"9090"`
+	GrpcReflection   bool              `envconfig:"GRPC_REFLECTION" default:"false"`
+	GrpcAuthTokens   map[string]string `envconfig:"GRPC_AUTH_TOKENS" secret:"true"`
+	GrpcTokenTenants map[string]string `envconfig:"GRPC_TOKEN_TENANTS"`
+}
+
+// GraphqlConfig holds the GraphQL endpoint configuration, a zero limit disables the check.
+// The playground is protected by the swagger credentials.
+type GraphqlConfig struct {
+	GraphqlEnabled       bool `envconfig:"GRAPHQL_ENABLED" default:"true"`
+	GraphqlPlayground    bool `envconfig:"GRAPHQL_PLAYGROUND" default:"true"`
+	GraphqlMaxDepth      int  `envconfig:"GRAPHQL_MAX_DEPTH" default:"6"`
+	GraphqlMaxComplexity int  `envconfig:"GRAPHQL_MAX_COMPLEXITY" default:"10000"`
+	GraphqlMaxBatchSize  int  `envconfig:"GRAPHQL_MAX_BATCH_SIZE" default:"10"`
+}
+
+// APIVersionConfig holds the deprecation schedule of REST API versions as version:YYYY-MM-DD pairs,
+// e.g. API_DEPRECATED_VERSIONS=v1:2026-10-01 and API_SUNSET_VERSIONS=v1:2027-04-01.
+type APIVersionConfig struct {
+	APIDeprecatedVersions map[string]string `envconfig:"API_DEPRECATED_VERSIONS"`
+	APISunsetVersions     map[string]string `envconfig:"API_SUNSET_VERSIONS"`
+}
+
+// IdempotencyConfig holds the Idempotency-Key handling of POST endpoints.
+// IDEMPOTENCY_LOCK_TIMEOUT bounds how long an in-flight request holds its key, IDEMPOTENCY_LOCK_WAIT how long a retry waits for it.
+type IdempotencyConfig struct {
+	IdempotencyEnabled         bool   `envconfig:"IDEMPOTENCY_ENABLED" default:"true"`
+	IdempotencyTTL             string `envconfig:"IDEMPOTENCY_TTL" default:"24h"`
+	IdempotencyLockTimeout     string `envconfig:"IDEMPOTENCY_LOCK_TIMEOUT" default:"1m"`
+	IdempotencyLockWait        string `envconfig:"IDEMPOTENCY_LOCK_WAIT" default:"5s"`
+	IdempotencyCleanupInterval string `envconfig:"IDEMPOTENCY_CLEANUP_INTERVAL" default:"1h"`
+}
+
+// CacheConfig holds the cache of user reads. The cache is in-process, with several instances
+// a write is only visible to the other instances once their entries expire after CACHE_TTL.
+type CacheConfig struct {
+	CacheEnabled    bool   `envconfig:"CACHE_ENABLED" default:"true"`
+	CacheTTL        string `envconfig:"CACHE_TTL" default:"1m"`
+	CacheMaxEntries int    `envconfig:"CACHE_MAX_ENTRIES" default:"10000"`
+}
+
+// TenantConfig holds multi-tenancy. TENANT_MODE schema gives each tenant its own tenant_<id> schema,
+// row keeps every tenant in the shared schema isolated by row level security on tenant_id.
+// The tenant is resolved per request by TENANT_RESOLVERS: jwt claim signed with JWT_SECRET_KEY, certificate organization,
+// with the header and the subdomain of TENANT_BASE_DOMAIN as hints that must match it.
+// TENANT_MAX_POOLS caps the tenant pools of the schema mode, the least recently used pool is closed beyond it.
+type TenantConfig struct {
+	TenantEnabled      bool     `envconfig:"TENANT_ENABLED" default:"false"`
+	TenantMode         string   `envconfig:"TENANT_MODE" default:"schema"`
+	TenantResolvers    []string `envconfig:"TENANT_RESOLVERS" default:"jwt"`
+	TenantHeader       string   `envconfig:"TENANT_HEADER" default:"X-Tenant-ID"`
+	TenantBaseDomain   string   `envconfig:"TENANT_BASE_DOMAIN"`
+	TenantJWTClaim     string   `envconfig:"TENANT_JWT_CLAIM" default:"tenant"`
+	TenantCacheTTL     string   `envconfig:"TENANT_CACHE_TTL" default:"1m"`
+	TenantMaxOpenConns int      `envconfig:"TENANT_MAX_OPEN_CONNS" default:"10"`
+	TenantMaxIdleConns int      `envconfig:"TENANT_MAX_IDLE_CONNS" default:"2"`
+	TenantMaxPools     int      `envconfig:"TENANT_MAX_POOLS" default:"100"`
+}
+
 // SwaggerAuth holds swagger authentication configuration
 type SwaggerAuth struct {
 	SwaggerUsername string `envconfig:"SWAGGER_USERNAME" required:"true"`
-	SwaggerPassword string `envconfig:"SWAGGER_PASSWORD" required:"true"`
+	SwaggerPassword string `envconfig:"SWAGGER_PASSWORD" required:"true" secret:"true"`
+}
+
+// AdminAuth holds admin endpoints basic authentication configuration.
+// Admin endpoints are not registered while the credentials are empty.
+type AdminAuth struct {
+	AdminUsername string `envconfig:"ADMIN_USERNAME"`
+	AdminPassword string `envconfig:"ADMIN_PASSWORD" secret:"true"`
 }
 
 // Load loads configuration
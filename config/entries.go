@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// maskedValue replaces a secret value when the configuration is printed
+const maskedValue = "****"
+
+// Entry is a configuration variable with its effective value
+type Entry struct {
+	Key   string
+	Value string
+}
+
+// Entries returns every configuration variable in declaration order, formatted the way envconfig reads it.
+// Values of secret fields are masked, only the password of a secret URL is.
+func (c *Config) Entries() []Entry {
+	var entries []Entry
+	collectEntries(reflect.ValueOf(c).Elem(), &entries)
+	return entries
+}
+
+func collectEntries(v reflect.Value, entries *[]Entry) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			collectEntries(v.Field(i), entries)
+			continue
+		}
+
+		key := field.Tag.Get("envconfig")
+		if key == "" {
+			continue
+		}
+		secret := field.Tag.Get("secret") == "true"
+		*entries = append(*entries, Entry{Key: key, Value: formatValue(v.Field(i), secret)})
+	}
+}
+
+func formatValue(v reflect.Value, secret bool) string {
+	switch v.Kind() {
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = formatValue(v.Index(i), secret)
+		}
+		return strings.Join(items, ",")
+	case reflect.Map:
+		// the keys of a secret map name its values, e.g. the callers of GRPC_AUTH_TOKENS, only the values are masked
+		items := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			items = append(items, fmt.Sprint(iter.Key().Interface())+":"+formatValue(iter.Value(), secret))
+		}
+		sort.Strings(items)
+		return strings.Join(items, ",")
+	}
+
+	value := fmt.Sprint(v.Interface())
+	if !secret || value == "" {
+		return value
+	}
+	if u, err := url.Parse(value); err == nil && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			return u.Redacted()
+		}
+	}
+	return maskedValue
+}
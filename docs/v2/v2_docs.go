@@ -1,9 +1,9 @@
-// Package docs Code generated by swaggo/swag. DO NOT EDIT
-package docs
+// Package docsv2 Code generated by swaggo/swag. DO NOT EDIT
+package docsv2
 
 import "github.com/swaggo/swag"
 
-const docTemplate = `{
+const docTemplatev2 = `{
     "schemes": {{ marshal .Schemes }},
     "swagger": "2.0",
     "info": {
@@ -57,7 +57,12 @@ const docTemplate = `{
                         "in": "query"
                     },
                     {
-                        "type": "integer",
+                        "enum": [
+                            "inactive",
+                            "active",
+                            "banned"
+                        ],
+                        "type": "string",
                         "description": "Status",
                         "name": "status",
                         "in": "query"
@@ -115,7 +120,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/github_com_alxhtp_monogo_pkg_dto.ResUserList"
+                            "$ref": "#/definitions/dtov2.ResUserList"
                         }
                     }
                 }
@@ -139,15 +144,21 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/github_com_alxhtp_monogo_pkg_dto.ReqCreateUser"
+                            "$ref": "#/definitions/dtov2.ReqCreateUser"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key replaying the stored response when the request is retried",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/github_com_alxhtp_monogo_pkg_dto.ResUserSingle"
+                            "$ref": "#/definitions/dtov2.ResUserSingle"
                         }
                     }
                 }
@@ -179,7 +190,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/github_com_alxhtp_monogo_pkg_dto.ResUserSingle"
+                            "$ref": "#/definitions/dtov2.ResUserSingle"
                         }
                     }
                 }
@@ -210,7 +221,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/github_com_alxhtp_monogo_pkg_dto.ReqUpdateUser"
+                            "$ref": "#/definitions/dtov2.ReqUpdateUser"
                         }
                     }
                 ],
@@ -218,7 +229,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/github_com_alxhtp_monogo_pkg_dto.ResUserSingle"
+                            "$ref": "#/definitions/dtov2.ResUserSingle"
                         }
                     }
                 }
@@ -248,7 +259,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/github_com_alxhtp_monogo_pkg_dto_base.BaseRes"
+                            "$ref": "#/definitions/dtobase.BaseRes"
                         }
                     }
                 }
@@ -256,42 +267,112 @@ const docTemplate = `{
         }
     },
     "definitions": {
-        "github_com_alxhtp_monogo_pkg_dto.ReqCreateUser": {
+        "dto.UserMetadata": {
             "type": "object",
             "required": [
-                "email",
-                "name"
+                "address",
+                "phone",
+                "sex"
             ],
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "sex": {
+                    "type": "string",
+                    "enum": [
+                        "male",
+                        "female"
+                    ]
+                }
+            }
+        },
+        "dtobase.BasePagination": {
+            "type": "object",
+            "required": [
+                "count",
+                "limit",
+                "offset",
+                "order_by"
+            ],
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "order_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtobase.BaseRes": {
+            "type": "object",
+            "required": [
+                "code",
+                "message",
+                "success"
+            ],
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "stacktrace": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "dtov2.ReqCreateUser": {
+            "type": "object",
             "properties": {
                 "email": {
                     "type": "string"
                 },
                 "metadata": {
-                    "$ref": "#/definitions/github_com_alxhtp_monogo_pkg_dto.UserMetadata"
+                    "$ref": "#/definitions/dto.UserMetadata"
                 },
                 "name": {
                     "type": "string"
                 }
             }
         },
-        "github_com_alxhtp_monogo_pkg_dto.ReqUpdateUser": {
+        "dtov2.ReqUpdateUser": {
             "type": "object",
             "properties": {
                 "email": {
                     "type": "string"
                 },
                 "metadata": {
-                    "$ref": "#/definitions/github_com_alxhtp_monogo_pkg_dto.UserMetadata"
+                    "$ref": "#/definitions/dto.UserMetadata"
                 },
                 "name": {
                     "type": "string"
                 },
                 "status": {
-                    "type": "integer"
+                    "type": "string",
+                    "enum": [
+                        "inactive",
+                        "active",
+                        "banned"
+                    ]
                 }
             }
         },
-        "github_com_alxhtp_monogo_pkg_dto.ResUser": {
+        "dtov2.ResUser": {
             "type": "object",
             "properties": {
                 "email": {
@@ -301,17 +382,22 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "metadata": {
-                    "$ref": "#/definitions/github_com_alxhtp_monogo_pkg_dto.UserMetadata"
+                    "$ref": "#/definitions/dto.UserMetadata"
                 },
                 "name": {
                     "type": "string"
                 },
                 "status": {
-                    "type": "integer"
+                    "type": "string",
+                    "enum": [
+                        "inactive",
+                        "active",
+                        "banned"
+                    ]
                 }
             }
         },
-        "github_com_alxhtp_monogo_pkg_dto.ResUserList": {
+        "dtov2.ResUserList": {
             "type": "object",
             "required": [
                 "code",
@@ -325,14 +411,14 @@ const docTemplate = `{
                 "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/github_com_alxhtp_monogo_pkg_dto.ResUser"
+                        "$ref": "#/definitions/dtov2.ResUser"
                     }
                 },
                 "message": {
                     "type": "string"
                 },
                 "page": {
-                    "$ref": "#/definitions/github_com_alxhtp_monogo_pkg_dto_base.BasePagination"
+                    "$ref": "#/definitions/dtobase.BasePagination"
                 },
                 "stacktrace": {
                     "type": "string"
@@ -342,7 +428,7 @@ const docTemplate = `{
                 }
             }
         },
-        "github_com_alxhtp_monogo_pkg_dto.ResUserSingle": {
+        "dtov2.ResUserSingle": {
             "type": "object",
             "required": [
                 "code",
@@ -354,76 +440,7 @@ const docTemplate = `{
                     "type": "integer"
                 },
                 "data": {
-                    "$ref": "#/definitions/github_com_alxhtp_monogo_pkg_dto.ResUser"
-                },
-                "message": {
-                    "type": "string"
-                },
-                "stacktrace": {
-                    "type": "string"
-                },
-                "success": {
-                    "type": "boolean"
-                }
-            }
-        },
-        "github_com_alxhtp_monogo_pkg_dto.UserMetadata": {
-            "type": "object",
-            "required": [
-                "address",
-                "phone",
-                "sex"
-            ],
-            "properties": {
-                "address": {
-                    "type": "string",
-                    "maxLength": 255
-                },
-                "phone": {
-                    "type": "string"
-                },
-                "sex": {
-                    "type": "string",
-                    "enum": [
-                        "male",
-                        "female"
-                    ]
-                }
-            }
-        },
-        "github_com_alxhtp_monogo_pkg_dto_base.BasePagination": {
-            "type": "object",
-            "required": [
-                "count",
-                "limit",
-                "offset",
-                "order_by"
-            ],
-            "properties": {
-                "count": {
-                    "type": "integer"
-                },
-                "limit": {
-                    "type": "integer"
-                },
-                "offset": {
-                    "type": "integer"
-                },
-                "order_by": {
-                    "type": "string"
-                }
-            }
-        },
-        "github_com_alxhtp_monogo_pkg_dto_base.BaseRes": {
-            "type": "object",
-            "required": [
-                "code",
-                "message",
-                "success"
-            ],
-            "properties": {
-                "code": {
-                    "type": "integer"
+                    "$ref": "#/definitions/dtov2.ResUser"
                 },
                 "message": {
                     "type": "string"
@@ -437,34 +454,26 @@ const docTemplate = `{
             }
         }
     },
-    "securityDefinitions": {
-        "Authorization": {
-            "description": "Authentication token (Bearer token)",
-            "type": "apiKey",
-            "name": "Authorization",
-            "in": "header"
-        }
-    },
     "externalDocs": {
         "description": "OpenAPI",
         "url": "https://swagger.io/resources/open-api/"
     }
 }`
 
-// SwaggerInfo holds exported Swagger Info so clients can modify it
-var SwaggerInfo = &swag.Spec{
-	Version:          "1.0",
+// SwaggerInfov2 holds exported Swagger Info so clients can modify it
+var SwaggerInfov2 = &swag.Spec{
+	Version:          "2.0",
 	Host:             "",
-	BasePath:         "/v1",
+	BasePath:         "/v2",
 	Schemes:          []string{},
 	Title:            "Monogo API",
-	Description:      "Monogo API Collection",
-	InfoInstanceName: "swagger",
-	SwaggerTemplate:  docTemplate,
+	Description:      "Monogo API Collection, v2 exposes the user status by name",
+	InfoInstanceName: "v2",
+	SwaggerTemplate:  docTemplatev2,
 	LeftDelim:        "{{",
 	RightDelim:       "}}",
 }
 
 func init() {
-	swag.Register(SwaggerInfo.InstanceName(), SwaggerInfo)
+	swag.Register(SwaggerInfov2.InstanceName(), SwaggerInfov2)
 }
@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"time"
+
+	entitybase "github.com/alxhtp/monogo/internal/entity/base"
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
+)
+
+// AuditLog is written by the entitybase.Base hooks, it does not embed Base to avoid auditing itself
+type AuditLog struct {
+	ID         int64                                                              `gorm:"column:id;primaryKey;autoIncrement"`
+	Actor      string                                                             `gorm:"column:actor;type:varchar(255);not null"`
+	RequestID  string                                                             `gorm:"column:request_id;type:varchar(255)"`
+	EntityType string                                                             `gorm:"column:entity_type;type:varchar(255);not null"`
+	EntityID   string                                                             `gorm:"column:entity_id;type:varchar(255);not null"`
+	Operation  string                                                             `gorm:"column:operation;type:varchar(32);not null"`
+	Before     databasehelper.GormJsonType[map[string]any]                        `gorm:"column:before;type:jsonb"`
+	After      databasehelper.GormJsonType[map[string]any]                        `gorm:"column:after;type:jsonb"`
+	Changes    databasehelper.GormJsonType[map[string]databasehelper.AuditChange] `gorm:"column:changes;type:jsonb"`
+	CreatedAt  time.Time                                                          `gorm:"column:created_at;type:timestamptz;default:now()"`
+}
+
+type AuditLogFilter struct {
+	EntityType       *string
+	EntityIDs        []string
+	Actor            *string
+	RequestID        *string
+	Operation        *string
+	PaginationFilter entitybase.BasePaginationFilter
+}
+
+func (a *AuditLog) TableName() string {
+	return databasehelper.AuditTableName
+}
+
+func (a *AuditLog) OrderMap() map[string]bool {
+	return map[string]bool{
+		"created_at": true,
+		"id":         true,
+	}
+}
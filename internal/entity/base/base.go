@@ -13,22 +13,49 @@ type Base struct {
 	BaseTime
 }
 
+// Identified is an entity with a uuid primary key, every entity embedding Base
+type Identified interface {
+	GetID() uuid.UUID
+}
+
+func (b *Base) GetID() uuid.UUID {
+	return b.ID
+}
+
 func (b *Base) BeforeCreate(tx *gorm.DB) error {
 	databasehelper.PrepareCreation(tx)
 
 	return nil
 }
 
+func (b *Base) AfterCreate(tx *gorm.DB) error {
+	return databasehelper.RecordCreation(tx, b.ID)
+}
+
 func (b *Base) BeforeUpdate(tx *gorm.DB) error {
 	databasehelper.PrepareUpdate(tx)
 
-	return nil
+	return databasehelper.SnapshotBeforeChange(tx)
+}
+
+func (b *Base) AfterUpdate(tx *gorm.DB) error {
+	return databasehelper.RecordUpdate(tx)
 }
 
 func (b *Base) BeforeDelete(tx *gorm.DB) error {
 	databasehelper.PrepareDeletion(tx)
 
-	return nil
+	return databasehelper.SnapshotBeforeChange(tx)
+}
+
+func (b *Base) AfterDelete(tx *gorm.DB) error {
+	return databasehelper.RecordDeletion(tx)
+}
+
+// BaseTenant marks a tenant-owned entity, TenantID is empty for rows that belong to no tenant.
+// It is set on creation in the row level tenancy mode, see databasehelper.PrepareCreation.
+type BaseTenant struct {
+	TenantID string `gorm:"column:tenant_id;type:varchar(48);not null"`
 }
 
 type BaseTime struct {
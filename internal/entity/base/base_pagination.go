@@ -102,10 +102,7 @@ func PaginateEntityQuery(
 		paginationResult.Offset = *filter.Offset
 	}
 
-	limit := defaultLimit
-	if filter.Limit != nil && *filter.Limit > 0 && *filter.Limit <= maxLimit {
-		limit = *filter.Limit
-	}
+	limit := PageLimit(filter)
 	db = db.Limit(limit)
 	paginationResult.Limit = limit
 
@@ -117,6 +114,14 @@ func PaginateEntityQuery(
 	return db.Order(baseTableName + defaultGormQuerySort)
 }
 
+// PageLimit returns the limit of a page of filter, the default limit when filter has none or one above the max limit
+func PageLimit(filter *BasePaginationFilter) int {
+	if filter != nil && filter.Limit != nil && *filter.Limit > 0 && *filter.Limit <= maxLimit {
+		return *filter.Limit
+	}
+	return defaultLimit
+}
+
 // OrderEntityQuery implement order query param into order query db statements
 func OrderEntityQuery(db *gorm.DB, orderByQueryParam string, orderMap map[string]bool) *gorm.DB {
 	orderStatements := OrderQueryTranslator(orderByQueryParam, orderMap)
@@ -133,6 +138,28 @@ func OrderEntityQuery(db *gorm.DB, orderByQueryParam string, orderMap map[string
 func OrderQueryTranslator(orderByQueryParam string, orderMap map[string]bool) []string {
 	out := make([]string, 0)
 
+	for _, column := range OrderQueryColumns(orderByQueryParam, orderMap) {
+		direction := "asc"
+		if column.Desc {
+			direction = "desc"
+		}
+		out = append(out, fmt.Sprintf(`"%s" %s`, column.Column, direction))
+	}
+
+	return out
+}
+
+// OrderColumn is a column of an order query param, e.g. -name
+type OrderColumn struct {
+	Column string
+	Desc   bool
+}
+
+// OrderQueryColumns parses the comma separated words of an order query param, a word is a column of orderMap
+// optionally prefixed by + or -, the words of other columns are skipped
+func OrderQueryColumns(orderByQueryParam string, orderMap map[string]bool) []OrderColumn {
+	out := make([]OrderColumn, 0)
+
 	if orderMap == nil || len(orderByQueryParam) == 0 {
 		return out
 	}
@@ -141,8 +168,8 @@ func OrderQueryTranslator(orderByQueryParam string, orderMap map[string]bool) []
 
 	for i := range words {
 		var (
-			word      = strings.TrimSpace(words[i])
-			direction = "asc"
+			word = strings.TrimSpace(words[i])
+			desc bool
 		)
 
 		if len(word) == 0 {
@@ -153,13 +180,13 @@ func OrderQueryTranslator(orderByQueryParam string, orderMap map[string]bool) []
 			word = word[1:]
 		}
 
-		if word[0:1] == "-" {
-			direction = "desc"
+		if len(word) > 0 && word[0:1] == "-" {
+			desc = true
 			word = word[1:]
 		}
 
 		if enableOrder, ok := orderMap[word]; ok && enableOrder {
-			out = append(out, fmt.Sprintf(`"%s" %s`, word, direction))
+			out = append(out, OrderColumn{Column: word, Desc: desc})
 		}
 	}
 
@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+// IdempotencyKey is a request identified by the Idempotency-Key header of its caller.
+// It is in flight until StatusCode is set, the stored response is then replayed until ExpiresAt.
+// It does not embed entitybase.Base, idempotency keys are not audited.
+type IdempotencyKey struct {
+	Caller       string     `gorm:"column:caller;type:varchar(255);primaryKey"`
+	Key          string     `gorm:"column:key;type:varchar(255);primaryKey"`
+	Method       string     `gorm:"column:method;type:varchar(16);not null"`
+	Path         string     `gorm:"column:path;type:text;not null"`
+	RequestHash  string     `gorm:"column:request_hash;type:varchar(64);not null"`
+	StatusCode   *int       `gorm:"column:status_code;type:int"`
+	ContentType  string     `gorm:"column:content_type;type:varchar(255)"`
+	ResponseBody []byte     `gorm:"column:response_body;type:bytea"`
+	LockedUntil  time.Time  `gorm:"column:locked_until;type:timestamptz;not null"`
+	ExpiresAt    time.Time  `gorm:"column:expires_at;type:timestamptz;not null"`
+	CompletedAt  *time.Time `gorm:"column:completed_at;type:timestamptz"`
+	CreatedAt    time.Time  `gorm:"column:created_at;type:timestamptz;default:now()"`
+}
+
+func (i *IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
+)
+
+// OutboxMessage is a domain event waiting to be relayed to publishers.
+// It does not embed entitybase.Base, outbox rows are not audited.
+type OutboxMessage struct {
+	ID            int64                                        `gorm:"column:id;primaryKey;autoIncrement"`
+	AggregateType string                                       `gorm:"column:aggregate_type;type:varchar(255);not null"`
+	AggregateID   string                                       `gorm:"column:aggregate_id;type:varchar(255);not null"`
+	EventType     string                                       `gorm:"column:event_type;type:varchar(255);not null"`
+	Payload       databasehelper.GormJsonType[json.RawMessage] `gorm:"column:payload;type:jsonb;not null"`
+	Actor         string                                       `gorm:"column:actor;type:varchar(255)"`
+	RequestID     string                                       `gorm:"column:request_id;type:varchar(255)"`
+	Attempts      int                                          `gorm:"column:attempts;type:int;not null;default:0"`
+	LastError     string                                       `gorm:"column:last_error;type:text"`
+	NextAttemptAt time.Time                                    `gorm:"column:next_attempt_at;type:timestamptz;default:now()"`
+	ClaimedAt     *time.Time                                   `gorm:"column:claimed_at;type:timestamptz"` // last time a relay took it for publishing
+	PublishedAt   *time.Time                                   `gorm:"column:published_at;type:timestamptz"`
+	CreatedAt     time.Time                                    `gorm:"column:created_at;type:timestamptz;default:now()"`
+}
+
+func (o *OutboxMessage) TableName() string {
+	return "outbox"
+}
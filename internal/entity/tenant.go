@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+// Tenant is a customer. In the schema-per-tenant mode its data lives in its own schema and tenants have no table,
+// the tenant schemas are the registry so a tenant exists exactly when its schema does.
+// In the row level mode its data lives in the shared schema and tenants are registered in the tenants table.
+type Tenant struct {
+	ID     string
+	Schema string
+	// Migrations is the number of migrations applied to the schema
+	Migrations int
+	// MigratedAt is when the last migration was applied, nil before the first one
+	MigratedAt *time.Time
+}
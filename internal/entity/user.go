@@ -1,6 +1,10 @@
 package entity
 
 import (
+	"regexp"
+	"slices"
+	"strings"
+
 	entitybase "github.com/alxhtp/monogo/internal/entity/base"
 	"github.com/alxhtp/monogo/pkg/constant"
 	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
@@ -9,6 +13,7 @@ import (
 
 type User struct {
 	entitybase.Base
+	entitybase.BaseTenant
 	Name     string                                    `gorm:"column:name;type:varchar(255);not null"`
 	Email    string                                    `gorm:"column:email;type:varchar(255);not null;unique"`
 	Status   constant.UserStatus                       `gorm:"column:status;type:int;not null;default:0"`
@@ -33,7 +38,7 @@ type UserFilter struct {
 }
 
 func (u *User) TableName() string {
-	return "monogo.users"
+	return "users"
 }
 
 func (u *User) OrderMap() map[string]bool {
@@ -44,3 +49,74 @@ func (u *User) OrderMap() map[string]bool {
 
 	return out
 }
+
+func (u *User) AuditEntityType() string {
+	return "user"
+}
+
+// Match reports whether user satisfies the filter, with the same semantics as the repository query.
+// Pagination is ignored.
+func (f *UserFilter) Match(user *User) bool {
+	if user == nil {
+		return false
+	}
+
+	if f.IDs != nil && !slices.Contains(f.IDs, user.ID) {
+		return false
+	}
+
+	if f.Name != nil && !containsFold(user.Name, *f.Name) {
+		return false
+	}
+
+	if f.Email != nil && user.Email != *f.Email {
+		return false
+	}
+
+	if f.Status != nil && user.Status != *f.Status {
+		return false
+	}
+
+	if f.Sex != nil && user.Metadata.Item.Sex != *f.Sex {
+		return false
+	}
+
+	if f.Address != nil && !containsFold(user.Metadata.Item.Address, *f.Address) {
+		return false
+	}
+
+	if f.Phone != nil && user.Metadata.Item.Phone != *f.Phone {
+		return false
+	}
+
+	return true
+}
+
+// containsFold mirrors ILIKE '%substr%', as in postgres % and _ of substr are wildcards and \ escapes them
+func containsFold(s, substr string) bool {
+	var expr strings.Builder
+	expr.WriteString(`(?is)^.*`)
+	escaped := false
+	for _, r := range substr {
+		switch {
+		case escaped:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			expr.WriteString(".*")
+		case r == '_':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	if escaped {
+		// the backslash escapes the closing %
+		expr.WriteString("%$")
+	} else {
+		expr.WriteString(".*$")
+	}
+	return regexp.MustCompile(expr.String()).MatchString(s)
+}
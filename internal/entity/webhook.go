@@ -0,0 +1,102 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	entitybase "github.com/alxhtp/monogo/internal/entity/base"
+	"github.com/alxhtp/monogo/pkg/constant"
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
+	"github.com/google/uuid"
+)
+
+// WebhookEventAll subscribes a webhook to every event type
+const WebhookEventAll = "*"
+
+type Webhook struct {
+	entitybase.Base
+	entitybase.BaseTenant
+	Name   string                                `gorm:"column:name;type:varchar(255);not null"`
+	URL    string                                `gorm:"column:url;type:text;not null"`
+	Events databasehelper.GormJsonType[[]string] `gorm:"column:events;type:jsonb;not null"`
+	Secret string                                `gorm:"column:secret;type:varchar(255);not null"`
+	Active bool                                  `gorm:"column:active;type:boolean;not null;default:true"`
+}
+
+type WebhookFilter struct {
+	IDs              []uuid.UUID
+	Name             *string
+	Active           *bool
+	EventType        *string
+	PaginationFilter entitybase.BasePaginationFilter
+}
+
+func (w *Webhook) TableName() string {
+	return "webhooks"
+}
+
+func (w *Webhook) AuditEntityType() string {
+	return "webhook"
+}
+
+func (w *Webhook) AuditRedactedColumns() []string {
+	return []string{"secret"}
+}
+
+func (w *Webhook) OrderMap() map[string]bool {
+	out := entitybase.GenerateBaseOrderMap()
+
+	out["name"] = true
+
+	return out
+}
+
+// Subscribed reports whether the webhook wants events of eventType
+func (w *Webhook) Subscribed(eventType string) bool {
+	for _, subscribed := range w.Events.Item {
+		if subscribed == WebhookEventAll || subscribed == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is a single event delivery to a webhook, it is not audited
+type WebhookDelivery struct {
+	ID             uuid.UUID                                    `gorm:"column:id;primaryKey;type:uuid;default:uuid_generate_v4()"`
+	WebhookID      uuid.UUID                                    `gorm:"column:webhook_id;type:uuid;not null"`
+	EventID        int64                                        `gorm:"column:event_id;type:bigint;not null"`
+	EventType      string                                       `gorm:"column:event_type;type:varchar(255);not null"`
+	Payload        databasehelper.GormJsonType[json.RawMessage] `gorm:"column:payload;type:jsonb;not null"`
+	Status         constant.WebhookDeliveryStatus               `gorm:"column:status;type:varchar(32);not null"`
+	Attempts       int                                          `gorm:"column:attempts;type:int;not null;default:0"`
+	Redeliveries   int                                          `gorm:"column:redeliveries;type:int;not null;default:0"`
+	NextAttemptAt  time.Time                                    `gorm:"column:next_attempt_at;type:timestamptz"`
+	LastStatusCode *int                                         `gorm:"column:last_status_code;type:int"`
+	LastError      string                                       `gorm:"column:last_error;type:text"`
+	LastResponse   string                                       `gorm:"column:last_response;type:text"`
+	DeliveredAt    *time.Time                                   `gorm:"column:delivered_at;type:timestamptz"`
+	CreatedAt      time.Time                                    `gorm:"column:created_at;type:timestamptz;default:now()"`
+	UpdatedAt      time.Time                                    `gorm:"column:updated_at;type:timestamptz;default:now()"`
+}
+
+type WebhookDeliveryFilter struct {
+	IDs              []uuid.UUID
+	WebhookID        *uuid.UUID
+	EventType        *string
+	Status           *constant.WebhookDeliveryStatus
+	PaginationFilter entitybase.BasePaginationFilter
+}
+
+func (d *WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+func (d *WebhookDelivery) OrderMap() map[string]bool {
+	return map[string]bool{
+		"created_at":      true,
+		"updated_at":      true,
+		"next_attempt_at": true,
+		"event_id":        true,
+	}
+}
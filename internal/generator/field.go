@@ -0,0 +1,236 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// fieldType is a field type of --fields and how each layer represents it
+type fieldType struct {
+	goType   string
+	sqlType  string
+	gormType string
+	// sqlDefault is the default of an optional column, empty when an optional column is nullable
+	sqlDefault string
+	// swaggerType is the type of the query parameter filtering the field, empty when the field is not filterable
+	swaggerType string
+	// like filters the field with ILIKE instead of equality
+	like bool
+	// ordered fields are listed in the order map
+	ordered bool
+	// boxed types are pointers in the create request, so that required tells an absent value from the zero value
+	boxed bool
+	// maxLength is the max validation of strings, 0 for no limit
+	maxLength int
+	// sample is a Go expression of a valid value, used by the test skeletons
+	sample string
+}
+
+var fieldTypes = map[string]fieldType{
+	"string": {goType: "string", sqlType: "VARCHAR(255)", gormType: "varchar(255)", sqlDefault: "''", swaggerType: "string", like: true, ordered: true, maxLength: 255, sample: `"Example"`},
+	"text":   {goType: "string", sqlType: "TEXT", gormType: "text", sqlDefault: "''", swaggerType: "string", like: true, sample: `"Example"`},
+	"int":    {goType: "int", sqlType: "INTEGER", gormType: "int", sqlDefault: "0", swaggerType: "int", ordered: true, boxed: true, sample: "1"},
+	"int64":  {goType: "int64", sqlType: "BIGINT", gormType: "bigint", sqlDefault: "0", swaggerType: "int", ordered: true, boxed: true, sample: "1"},
+	"float":  {goType: "float64", sqlType: "DOUBLE PRECISION", gormType: "double precision", sqlDefault: "0", swaggerType: "number", ordered: true, boxed: true, sample: "1.5"},
+	"bool":   {goType: "bool", sqlType: "BOOLEAN", gormType: "boolean", sqlDefault: "false", swaggerType: "bool", boxed: true, sample: "true"},
+	"time":   {goType: "time.Time", sqlType: "TIMESTAMPTZ", gormType: "timestamptz", ordered: true, sample: "time.Now().UTC().Truncate(time.Second)"},
+	"uuid":   {goType: "uuid.UUID", sqlType: "UUID", gormType: "uuid", swaggerType: "string", sample: "uuid.New()"},
+}
+
+// reservedColumns are the columns of entitybase.Base and entitybase.BaseTenant
+var reservedColumns = []string{"id", "tenant_id", "created_at", "updated_at", "deleted_at"}
+
+// field options of --fields
+const (
+	optionRequired = "required"
+	optionUnique   = "unique"
+)
+
+// Field is a field of the generated resource
+type Field struct {
+	Name     name
+	Type     string
+	Required bool
+	Unique   bool
+
+	fieldType fieldType
+}
+
+// ParseFields parses the --fields flag, comma separated name:type[:option...] fields, e.g.
+// "title:string:required,price:float,published:bool". The types are string, text, int, int64, float,
+// bool, time and uuid, the options required and unique.
+func ParseFields(spec string) ([]Field, error) {
+	var fields []Field
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		segments := strings.Split(part, ":")
+		if len(segments) < 2 {
+			return nil, fmt.Errorf("field %q: expected name:type[:option...]", part)
+		}
+
+		fieldName, err := parseName(segments[0])
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", part, err)
+		}
+		if slices.Contains(reservedColumns, fieldName.Snake) {
+			return nil, fmt.Errorf("field %q: %s is a column of every entity", part, fieldName.Snake)
+		}
+		if seen[fieldName.Snake] {
+			return nil, fmt.Errorf("field %q: %s is declared twice", part, fieldName.Snake)
+		}
+		seen[fieldName.Snake] = true
+
+		typ, ok := fieldTypes[segments[1]]
+		if !ok {
+			return nil, fmt.Errorf("field %q: unknown type %q, expected one of %s", part, segments[1], strings.Join(typeNames(), ", "))
+		}
+
+		field := Field{Name: fieldName, Type: segments[1], fieldType: typ}
+		for _, option := range segments[2:] {
+			switch option {
+			case optionRequired:
+				field.Required = true
+			case optionUnique:
+				field.Unique = true
+			default:
+				return nil, fmt.Errorf("field %q: unknown option %q, expected %s or %s", part, option, optionRequired, optionUnique)
+			}
+		}
+		fields = append(fields, field)
+	}
+
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("at least one field is required")
+	}
+	return fields, nil
+}
+
+func typeNames() []string {
+	names := make([]string, 0, len(fieldTypes))
+	for typeName := range fieldTypes {
+		names = append(names, typeName)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Nullable reports whether the column is nullable, optional times and uuids have no zero value worth storing
+func (f Field) Nullable() bool {
+	return !f.Required && f.fieldType.sqlDefault == ""
+}
+
+// GoType is the type of the field in the entity and the response
+func (f Field) GoType() string {
+	if f.Nullable() {
+		return "*" + f.fieldType.goType
+	}
+	return f.fieldType.goType
+}
+
+// BaseGoType is the type of the field without the pointer of nullable fields
+func (f Field) BaseGoType() string {
+	return f.fieldType.goType
+}
+
+// CreateGoType is the type of the field in the create request
+func (f Field) CreateGoType() string {
+	if f.fieldType.boxed || f.Nullable() {
+		return "*" + f.fieldType.goType
+	}
+	return f.fieldType.goType
+}
+
+// CreateDereference reports whether the create request holds a pointer the entity does not
+func (f Field) CreateDereference() bool {
+	return f.fieldType.boxed
+}
+
+func (f Field) GormTag() string {
+	tag := "column:" + f.Name.Snake + ";type:" + f.fieldType.gormType
+	if !f.Nullable() {
+		tag += ";not null"
+	}
+	if f.Unique {
+		tag += ";unique"
+	}
+	return tag
+}
+
+func (f Field) SQLColumn() string {
+	column := fmt.Sprintf("%q %s", f.Name.Snake, f.fieldType.sqlType)
+	switch {
+	case f.Required:
+		column += " NOT NULL"
+	case f.Nullable():
+		column += " NULL"
+	default:
+		column += " NOT NULL DEFAULT " + f.fieldType.sqlDefault
+	}
+	return column
+}
+
+func (f Field) CreateValidateTag() string {
+	var rules []string
+	switch {
+	case f.Required:
+		rules = append(rules, "required")
+	case f.fieldType.maxLength > 0:
+		rules = append(rules, "omitempty")
+	}
+	if f.fieldType.maxLength > 0 {
+		rules = append(rules, fmt.Sprintf("max=%d", f.fieldType.maxLength))
+	}
+	return validateTag(rules)
+}
+
+func (f Field) UpdateValidateTag() string {
+	if f.fieldType.maxLength == 0 {
+		return ""
+	}
+	return validateTag([]string{"omitempty", fmt.Sprintf("max=%d", f.fieldType.maxLength)})
+}
+
+func validateTag(rules []string) string {
+	if len(rules) == 0 {
+		return ""
+	}
+	return ` validate:"` + strings.Join(rules, ",") + `"`
+}
+
+func (f Field) Filterable() bool {
+	return f.fieldType.swaggerType != ""
+}
+
+// FilterQueryType is the type of the filter in the query request, uuids are parsed by the serializer
+func (f Field) FilterQueryType() string {
+	if f.Type == "uuid" {
+		return "*string"
+	}
+	return "*" + f.fieldType.goType
+}
+
+func (f Field) SwaggerType() string {
+	return f.fieldType.swaggerType
+}
+
+func (f Field) Like() bool {
+	return f.fieldType.like
+}
+
+func (f Field) Ordered() bool {
+	return f.fieldType.ordered
+}
+
+// Sample is a Go expression of a valid value of the entity field, empty for nullable fields
+func (f Field) Sample() string {
+	if f.Nullable() {
+		return ""
+	}
+	return f.fieldType.sample
+}
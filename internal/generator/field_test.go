@@ -0,0 +1,85 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    []string
+		wantErr string
+	}{
+		{name: "every type", spec: "title:string,body:text,views:int,size:int64,price:float,published:bool,published_at:time,author_id:uuid",
+			want: []string{"title:string", "body:text", "views:int", "size:int64", "price:float", "published:bool", "published_at:time", "author_id:uuid"}},
+		{name: "options", spec: "slug:string:required:unique,title:string:unique:required,body:text", want: []string{"slug:string:required:unique", "title:string:required:unique", "body:text"}},
+		{name: "spaces and empty parts", spec: " title:string , ,Body:text,", want: []string{"title:string", "body:text"}},
+		{name: "names in any case", spec: "PublishedAt:time,author-id:uuid", want: []string{"published_at:time", "author_id:uuid"}},
+		{name: "empty", spec: " , ", wantErr: "at least one field"},
+		{name: "missing type", spec: "title", wantErr: "expected name:type"},
+		{name: "unknown type", spec: "title:varchar", wantErr: `unknown type "varchar"`},
+		{name: "unknown option", spec: "title:string:indexed", wantErr: `unknown option "indexed"`},
+		{name: "invalid name", spec: "ti.tle:string", wantErr: "letters, digits"},
+		{name: "reserved column", spec: "CreatedAt:time", wantErr: "created_at is a column of every entity"},
+		{name: "tenant column", spec: "tenant_id:uuid", wantErr: "tenant_id is a column of every entity"},
+		{name: "declared twice", spec: "title:string,Title:text", wantErr: "title is declared twice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, err := ParseFields(tt.spec)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+
+			got := make([]string, len(fields))
+			for i, f := range fields {
+				got[i] = f.Name.Snake + ":" + f.Type
+				if f.Required {
+					got[i] += ":" + optionRequired
+				}
+				if f.Unique {
+					got[i] += ":" + optionUnique
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldColumns(t *testing.T) {
+	tests := []struct {
+		spec     string
+		goType   string
+		column   string
+		validate string
+	}{
+		{spec: "title:string:required", goType: "string", column: `"title" VARCHAR(255) NOT NULL`, validate: ` validate:"required,max=255"`},
+		{spec: "title:string", goType: "string", column: `"title" VARCHAR(255) NOT NULL DEFAULT ''`, validate: ` validate:"omitempty,max=255"`},
+		{spec: "views:int", goType: "int", column: `"views" INTEGER NOT NULL DEFAULT 0`},
+		{spec: "published_at:time", goType: "*time.Time", column: `"published_at" TIMESTAMPTZ NULL`},
+		{spec: "published_at:time:required", goType: "time.Time", column: `"published_at" TIMESTAMPTZ NOT NULL`, validate: ` validate:"required"`},
+		{spec: "author_id:uuid", goType: "*uuid.UUID", column: `"author_id" UUID NULL`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			fields, err := ParseFields(tt.spec)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			f := fields[0]
+			if f.GoType() != tt.goType || f.SQLColumn() != tt.column || f.CreateValidateTag() != tt.validate {
+				t.Fatalf("got %s, %s, %q, want %s, %s, %q", f.GoType(), f.SQLColumn(), f.CreateValidateTag(), tt.goType, tt.column, tt.validate)
+			}
+		})
+	}
+}
@@ -0,0 +1,276 @@
+// Package generator scaffolds a resource end to end, from its entity and migration to its REST routes,
+// in the layout of the user resource and on top of the generic repository and CRUD usecase.
+package generator
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/alxhtp/monogo/migration"
+)
+
+// RoutesFile registers the routers of the resources, the generated router is registered above RoutesMarker
+const (
+	RoutesFile   = "internal/server/rest/server.go"
+	RoutesMarker = "// routers of generated resources are registered above"
+)
+
+// reservedNames would shadow the packages the generated code imports
+var reservedNames = []string{"entity", "dto", "handler", "router", "generic", "base", "db", "filter", "output", "err"}
+
+//go:embed templates
+var templates embed.FS
+
+// Resource is the resource to generate
+type Resource struct {
+	Name   name
+	Plural name
+	Fields []Field
+	// Module is the module path of the repository
+	Module string
+	// Schema is the schema the migrations are written for
+	Schema string
+}
+
+// file is a generated file, path is relative to the repository root
+type file struct {
+	template string
+	path     string
+}
+
+// NewResource returns the resource resourceName, e.g. BlogPost. plural names its table and routes,
+// it is derived from resourceName when empty.
+func NewResource(resourceName, plural string, fields []Field) (*Resource, error) {
+	n, err := parseName(resourceName)
+	if err != nil {
+		return nil, fmt.Errorf("resource name %q: %w", resourceName, err)
+	}
+	if token.IsKeyword(n.Camel) || slices.Contains(reservedNames, n.Camel) {
+		return nil, fmt.Errorf("resource name %q is reserved", resourceName)
+	}
+
+	pluralName := n.plural()
+	if plural != "" {
+		if pluralName, err = parseName(plural); err != nil {
+			return nil, fmt.Errorf("plural %q: %w", plural, err)
+		}
+	}
+	if pluralName.Pascal == n.Pascal {
+		return nil, fmt.Errorf("plural %q is the resource name", plural)
+	}
+
+	return &Resource{Name: n, Plural: pluralName, Fields: fields, Schema: migration.SharedSchema}, nil
+}
+
+// Generate writes the files of r into the repository at root and registers its router, it returns the paths written.
+// Nothing is written when a file of r already exists.
+func Generate(root string, r *Resource, now time.Time) ([]string, error) {
+	module, err := modulePath(root)
+	if err != nil {
+		return nil, err
+	}
+	r.Module = module
+
+	files, err := r.files(now)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered := make(map[string][]byte, len(files))
+	for _, f := range files {
+		if _, err := os.Stat(filepath.Join(root, f.path)); err == nil {
+			return nil, fmt.Errorf("%s already exists", f.path)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+
+		content, err := r.render(f)
+		if err != nil {
+			return nil, err
+		}
+		rendered[f.path] = content
+	}
+
+	routes, err := r.registerRouter(root)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(files)+1)
+	for _, f := range files {
+		path := filepath.Join(root, f.path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return paths, err
+		}
+		if err := os.WriteFile(path, rendered[f.path], 0o644); err != nil {
+			return paths, err
+		}
+		paths = append(paths, f.path)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, RoutesFile), routes, 0o644); err != nil {
+		return paths, err
+	}
+	return append(paths, RoutesFile), nil
+}
+
+func (r *Resource) files(now time.Time) ([]file, error) {
+	migrationFile, err := migration.FileName("create-table-"+r.Plural.Kebab, now)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg, snake := r.Name.Package, r.Name.Snake
+	return []file{
+		{template: "entity.go.tmpl", path: filepath.Join("internal/entity", snake+".go")},
+		{template: "migration.sql.tmpl", path: filepath.Join(migration.Dir, migrationFile)},
+		{template: "migration_row.sql.tmpl", path: filepath.Join(migration.RowDir, migrationFile)},
+		{template: "dto.go.tmpl", path: filepath.Join("pkg/dto", snake+".go")},
+		{template: "repository.go.tmpl", path: filepath.Join("internal/repository", pkg, snake+"_repository.go")},
+		{template: "repository_implementation.go.tmpl", path: filepath.Join("internal/repository", pkg, "implementation", snake+"_repository.go")},
+		{template: "repository_test.go.tmpl", path: filepath.Join("internal/repository", pkg, "implementation", snake+"_repository_test.go")},
+		{template: "serializer.go.tmpl", path: filepath.Join("internal/serializer", pkg, snake+"_serializer.go")},
+		{template: "serializer_implementation.go.tmpl", path: filepath.Join("internal/serializer", pkg, "implementation", snake+"_serializer.go")},
+		{template: "usecase.go.tmpl", path: filepath.Join("internal/usecase", pkg, snake+"_usecase.go")},
+		{template: "usecase_implementation.go.tmpl", path: filepath.Join("internal/usecase", pkg, "implementation", snake+"_usecase.go")},
+		{template: "usecase_test.go.tmpl", path: filepath.Join("internal/usecase", pkg, "implementation", snake+"_usecase_test.go")},
+		{template: "handler.go.tmpl", path: filepath.Join("internal/handler", snake+"_handler.go")},
+		{template: "router.go.tmpl", path: filepath.Join("internal/server/rest/router", snake+"_router.go")},
+	}, nil
+}
+
+// render executes the template of f, Go files are formatted
+func (r *Resource) render(f file) ([]byte, error) {
+	tmpl, err := template.ParseFS(templates, "templates/"+f.template)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, r); err != nil {
+		return nil, fmt.Errorf("render %s: %w", f.path, err)
+	}
+	if filepath.Ext(f.path) != ".go" {
+		return buf.Bytes(), nil
+	}
+
+	content, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format %s: %w", f.path, err)
+	}
+	return content, nil
+}
+
+// registerRouter returns RoutesFile with the router of r registered above RoutesMarker
+func (r *Resource) registerRouter(root string) ([]byte, error) {
+	content, err := os.ReadFile(filepath.Join(root, RoutesFile))
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.SplitAfter(string(content), "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != RoutesMarker {
+			continue
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, "\t "))]
+		registration := indent + "router." + r.Name.Pascal + "Router(s.deps)\n"
+		return []byte(strings.Join(slices.Insert(lines, i, registration), "")), nil
+	}
+	return nil, fmt.Errorf("%s: %q not found, register router.%sRouter by hand", RoutesFile, RoutesMarker, r.Name.Pascal)
+}
+
+// modulePath reads the module path of the go.mod at root
+func modulePath(root string) (string, error) {
+	goMod, err := os.Open(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return "", fmt.Errorf("%s is not the repository root: %w", root, err)
+	}
+	defer goMod.Close()
+
+	scanner := bufio.NewScanner(goMod)
+	for scanner.Scan() {
+		if module, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); ok {
+			return strings.Trim(strings.TrimSpace(module), `"`), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", errors.New("go.mod declares no module")
+}
+
+// Receiver is the receiver of the entity methods, e.g. b for BlogPost
+func (r *Resource) Receiver() string {
+	return r.Name.Camel[:1]
+}
+
+func (r *Resource) Table() string {
+	return r.Plural.Snake
+}
+
+// Uses reports whether a field of r has the type typ
+func (r *Resource) Uses(typ string) bool {
+	return slices.ContainsFunc(r.Fields, func(f Field) bool { return f.Type == typ })
+}
+
+// FilterUses reports whether a filterable field of r has the type typ
+func (r *Resource) FilterUses(typ string) bool {
+	return slices.ContainsFunc(r.FilterFields(), func(f Field) bool { return f.Type == typ })
+}
+
+func (r *Resource) FilterFields() []Field {
+	return r.fieldsWhere(Field.Filterable)
+}
+
+func (r *Resource) OrderFields() []Field {
+	return r.fieldsWhere(Field.Ordered)
+}
+
+func (r *Resource) UniqueFields() []Field {
+	return r.fieldsWhere(func(f Field) bool { return f.Unique })
+}
+
+func (r *Resource) RequiredFields() []Field {
+	return r.fieldsWhere(func(f Field) bool { return f.Required })
+}
+
+// SampleFields are the fields the test skeletons set, the nullable fields are left empty
+func (r *Resource) SampleFields() []Field {
+	return r.fieldsWhere(func(f Field) bool { return f.Sample() != "" })
+}
+
+// SamplesUse reports whether a sample field of r has the type typ
+func (r *Resource) SamplesUse(typ string) bool {
+	return slices.ContainsFunc(r.SampleFields(), func(f Field) bool { return f.Type == typ })
+}
+
+func (r *Resource) fieldsWhere(keep func(Field) bool) []Field {
+	var fields []Field
+	for _, f := range r.Fields {
+		if keep(f) {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// UpdateField is the field the test skeletons update, nil when every field is nullable
+func (r *Resource) UpdateField() *Field {
+	fields := r.SampleFields()
+	if len(fields) == 0 {
+		return nil
+	}
+	return &fields[0]
+}
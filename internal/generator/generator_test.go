@@ -0,0 +1,246 @@
+package generator
+
+import (
+	"bytes"
+	"flag"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the generated resource")
+
+// repositoryRoot is the root of the repository, relative to the directory of the package
+const repositoryRoot = "../.."
+
+func newTestResource(t *testing.T) *Resource {
+	t.Helper()
+
+	fields, err := ParseFields("title:string:required,slug:string:required:unique,body:text,views:int,rating:float,published:bool,published_at:time,author_id:uuid")
+	if err != nil {
+		t.Fatalf("parse fields: %v", err)
+	}
+	resource, err := NewResource("BlogPost", "", fields)
+	if err != nil {
+		t.Fatalf("new resource: %v", err)
+	}
+	return resource
+}
+
+// writeFile writes content at path relative to root
+func writeFile(t *testing.T, root, path, content string) {
+	t.Helper()
+
+	path = filepath.Join(root, path)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestRegisterRouter(t *testing.T) {
+	tests := []struct {
+		name    string
+		routes  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "marker present",
+			routes: "func (s *server) routers() {\n\trouter.UserRouter(s.deps)\n\t" + RoutesMarker + "\n}\n",
+			want:   "func (s *server) routers() {\n\trouter.UserRouter(s.deps)\n\trouter.BlogPostRouter(s.deps)\n\t" + RoutesMarker + "\n}\n",
+		},
+		{
+			name:   "marker indented with spaces",
+			routes: "func routers() {\n    " + RoutesMarker + "\n}\n",
+			want:   "func routers() {\n    router.BlogPostRouter(s.deps)\n    " + RoutesMarker + "\n}\n",
+		},
+		{
+			name:    "marker missing",
+			routes:  "func (s *server) routers() {\n\trouter.UserRouter(s.deps)\n}\n",
+			wantErr: true,
+		},
+		{
+			name:    "marker in a longer comment",
+			routes:  "func (s *server) routers() {\n\t" + RoutesMarker + ", by the generator\n}\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFile(t, root, RoutesFile, tt.routes)
+
+			got, err := newTestResource(t).registerRouter(root)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "register router.BlogPostRouter by hand") {
+					t.Fatalf("got %v, want an error asking to register the router by hand", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("register: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Fatalf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateRefusesToOverwrite(t *testing.T) {
+	resource := newTestResource(t)
+	files, err := resource.files(time.Now())
+	if err != nil {
+		t.Fatalf("files: %v", err)
+	}
+
+	const routes = "package rest\n\n" + RoutesMarker + "\n"
+	for _, existing := range files {
+		t.Run(existing.path, func(t *testing.T) {
+			root := t.TempDir()
+			writeFile(t, root, "go.mod", "module example.com/blog\n")
+			writeFile(t, root, RoutesFile, routes)
+			writeFile(t, root, existing.path, "kept")
+
+			paths, err := Generate(root, resource, time.Now())
+			if err == nil || !strings.Contains(err.Error(), existing.path+" already exists") {
+				t.Fatalf("got %v, want an error naming %s", err, existing.path)
+			}
+			if len(paths) != 0 {
+				t.Fatalf("wrote %v", paths)
+			}
+
+			if content, _ := os.ReadFile(filepath.Join(root, existing.path)); string(content) != "kept" {
+				t.Fatalf("%s was overwritten", existing.path)
+			}
+			if content, _ := os.ReadFile(filepath.Join(root, RoutesFile)); string(content) != routes {
+				t.Fatalf("%s was changed", RoutesFile)
+			}
+			for _, f := range files {
+				if _, err := os.Stat(filepath.Join(root, f.path)); f.path != existing.path && err == nil {
+					t.Fatalf("%s was written", f.path)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateWritesNothingWithoutTheMarker(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "go.mod", "module example.com/blog\n")
+	writeFile(t, root, RoutesFile, "package rest\n")
+
+	if _, err := Generate(root, newTestResource(t), time.Now()); err == nil {
+		t.Fatal("generated without the marker")
+	}
+	if _, err := os.Stat(filepath.Join(root, "internal/entity")); err == nil {
+		t.Fatal("files were written")
+	}
+}
+
+// TestGenerateGolden generates a resource into a copy of the repository, compares the files with
+// testdata/BlogPost, go test -update rewrites them, and vets the packages of the resource
+func TestGenerateGolden(t *testing.T) {
+	root := t.TempDir()
+	copyRepository(t, root)
+
+	resource := newTestResource(t)
+	paths, err := Generate(root, resource, time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	for _, path := range paths {
+		if path == RoutesFile {
+			continue
+		}
+		got, err := os.ReadFile(filepath.Join(root, path))
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+
+		golden := filepath.Join("testdata", resource.Name.Pascal, path+".golden")
+		if *update {
+			if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+				t.Fatalf("create testdata: %v", err)
+			}
+			if err := os.WriteFile(golden, got, 0o644); err != nil {
+				t.Fatalf("write %s: %v", golden, err)
+			}
+			continue
+		}
+		want, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatalf("read %s: %v, run go test -update to create it", golden, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s does not match %s, run go test -update to accept it\n--- got\n%s", path, golden, got)
+		}
+	}
+
+	routes, err := os.ReadFile(filepath.Join(root, RoutesFile))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !strings.Contains(string(routes), "router.BlogPostRouter(s.deps)\n\t"+RoutesMarker) {
+		t.Fatalf("the router is not registered in %s", RoutesFile)
+	}
+
+	if testing.Short() {
+		t.Skip("go vet of the generated packages is skipped in short mode")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not in PATH")
+	}
+	vet := exec.Command(goBin, "vet",
+		"./internal/entity/...",
+		"./pkg/dto/...",
+		"./internal/repository/"+resource.Name.Package+"/...",
+		"./internal/serializer/"+resource.Name.Package+"/...",
+		"./internal/usecase/"+resource.Name.Package+"/...",
+		"./internal/handler/...",
+		"./internal/server/rest/...",
+	)
+	vet.Dir = root
+	vet.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=-mod=readonly")
+	if output, err := vet.CombinedOutput(); err != nil {
+		t.Fatalf("go vet: %v\n%s", err, output)
+	}
+}
+
+// copyRepository copies the repository into dst, hidden files and the testdata of the generator aside
+func copyRepository(t *testing.T, dst string) {
+	t.Helper()
+
+	src := os.DirFS(repositoryRoot)
+	err := fs.WalkDir(src, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != "." && strings.HasPrefix(d.Name(), ".") || path == "internal/generator/testdata" {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return os.MkdirAll(filepath.Join(dst, path), 0o755)
+		}
+		content, err := fs.ReadFile(src, path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dst, path), content, 0o644)
+	})
+	if err != nil {
+		t.Fatalf("copy the repository: %v", err)
+	}
+}
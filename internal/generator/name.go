@@ -0,0 +1,126 @@
+package generator
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// initialisms are written in upper case in Go names, e.g. ImageURL
+var initialisms = map[string]bool{"id": true, "url": true, "uri": true, "api": true, "http": true, "json": true, "uuid": true, "ip": true, "sql": true, "sku": true}
+
+// name is an identifier in the cases the generated code needs
+type name struct {
+	// Pascal is the exported Go name, e.g. BlogPost
+	Pascal string
+	// Camel is the unexported Go name, e.g. blogPost
+	Camel string
+	// Snake is the column, table and JSON name, e.g. blog_post
+	Snake string
+	// Kebab is the path and query name, e.g. blog-post
+	Kebab string
+	// Package is the package name, e.g. blogpost
+	Package string
+	// Human is the name in messages, e.g. blog post
+	Human string
+	// Title is the name in documentation, e.g. Blog post or Author ID
+	Title string
+
+	words []string
+}
+
+// parseName splits s into lower case words, s is in any of the cases of name
+func parseName(s string) (name, error) {
+	var words []string
+	var word []rune
+	runes := []rune(strings.TrimSpace(s))
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || r == ' ':
+			flush()
+		case unicode.IsUpper(r):
+			// a new word starts at an upper case letter, unless it continues an initialism, e.g. URLPath
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				flush()
+			}
+			word = append(word, r)
+		case unicode.IsLower(r) || unicode.IsDigit(r):
+			word = append(word, r)
+		default:
+			return name{}, errors.New("names hold letters, digits, underscores and hyphens only")
+		}
+	}
+	flush()
+
+	if len(words) == 0 {
+		return name{}, errors.New("name is empty")
+	}
+	if r := rune(words[0][0]); r > unicode.MaxASCII || !unicode.IsLetter(r) {
+		return name{}, errors.New("names start with a letter")
+	}
+	for _, w := range words {
+		for _, r := range w {
+			if r > unicode.MaxASCII {
+				return name{}, errors.New("names hold ASCII letters and digits only")
+			}
+		}
+	}
+	return newName(words), nil
+}
+
+func newName(words []string) name {
+	n := name{words: words}
+	for i, w := range words {
+		exported := strings.ToUpper(w[:1]) + w[1:]
+		if initialisms[w] {
+			exported = strings.ToUpper(w)
+		}
+		n.Pascal += exported
+		if i == 0 {
+			n.Camel = w
+		} else {
+			n.Camel += exported
+		}
+	}
+	n.Snake = strings.Join(words, "_")
+	n.Kebab = strings.Join(words, "-")
+	n.Package = strings.Join(words, "")
+	n.Human = strings.Join(words, " ")
+
+	titleWords := make([]string, len(words))
+	for i, w := range words {
+		switch {
+		case initialisms[w]:
+			titleWords[i] = strings.ToUpper(w)
+		case i == 0:
+			titleWords[i] = strings.ToUpper(w[:1]) + w[1:]
+		default:
+			titleWords[i] = w
+		}
+	}
+	n.Title = strings.Join(titleWords, " ")
+	return n
+}
+
+// plural returns the plural of n, only its last word is made plural
+func (n name) plural() name {
+	words := append([]string(nil), n.words...)
+	last := words[len(words)-1]
+	switch {
+	case strings.HasSuffix(last, "s") || strings.HasSuffix(last, "x") || strings.HasSuffix(last, "z") ||
+		strings.HasSuffix(last, "ch") || strings.HasSuffix(last, "sh"):
+		last += "es"
+	case strings.HasSuffix(last, "y") && len(last) > 1 && !strings.ContainsRune("aeiou", rune(last[len(last)-2])):
+		last = last[:len(last)-1] + "ies"
+	default:
+		last += "s"
+	}
+	words[len(words)-1] = last
+	return newName(words)
+}
@@ -0,0 +1,75 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseName(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    name
+		wantErr bool
+	}{
+		{in: "BlogPost", want: name{Pascal: "BlogPost", Camel: "blogPost", Snake: "blog_post", Kebab: "blog-post", Package: "blogpost", Human: "blog post", Title: "Blog post"}},
+		{in: "blog_post", want: name{Pascal: "BlogPost", Camel: "blogPost", Snake: "blog_post", Kebab: "blog-post", Package: "blogpost", Human: "blog post", Title: "Blog post"}},
+		{in: " blog-post ", want: name{Pascal: "BlogPost", Camel: "blogPost", Snake: "blog_post", Kebab: "blog-post", Package: "blogpost", Human: "blog post", Title: "Blog post"}},
+		{in: "blog post", want: name{Pascal: "BlogPost", Camel: "blogPost", Snake: "blog_post", Kebab: "blog-post", Package: "blogpost", Human: "blog post", Title: "Blog post"}},
+		{in: "author_id", want: name{Pascal: "AuthorID", Camel: "authorID", Snake: "author_id", Kebab: "author-id", Package: "authorid", Human: "author id", Title: "Author ID"}},
+		{in: "ImageURL", want: name{Pascal: "ImageURL", Camel: "imageURL", Snake: "image_url", Kebab: "image-url", Package: "imageurl", Human: "image url", Title: "Image URL"}},
+		{in: "URLPath", want: name{Pascal: "URLPath", Camel: "urlPath", Snake: "url_path", Kebab: "url-path", Package: "urlpath", Human: "url path", Title: "URL path"}},
+		{in: "address2Line", want: name{Pascal: "Address2Line", Camel: "address2Line", Snake: "address2_line", Kebab: "address2-line", Package: "address2line", Human: "address2 line", Title: "Address2 line"}},
+		{in: "", wantErr: true},
+		{in: "__", wantErr: true},
+		{in: "2fa", wantErr: true},
+		{in: "blog.post", wantErr: true},
+		{in: "café", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseName(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			got.words = nil
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlural(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "post", want: "posts"},
+		{in: "BlogPost", want: "blog_posts"},
+		{in: "status", want: "statuses"},
+		{in: "box", want: "boxes"},
+		{in: "batch", want: "batches"},
+		{in: "wish", want: "wishes"},
+		{in: "category", want: "categories"},
+		{in: "ProductCategory", want: "product_categories"},
+		{in: "day", want: "days"},
+		{in: "y", want: "ys"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			n, err := parseName(tt.in)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if got := n.plural().Snake; got != tt.want {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
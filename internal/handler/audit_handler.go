@@ -0,0 +1,95 @@
+package handler
+
+import (
+	auditusecase "github.com/alxhtp/monogo/internal/usecase/audit"
+	"github.com/alxhtp/monogo/pkg/dto"
+	errorhelper "github.com/alxhtp/monogo/pkg/helper/error"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const userAuditEntityType = "user"
+
+type auditHandler struct {
+	auditUsecase auditusecase.AuditUsecase
+}
+
+func NewAuditHandler(auditUsecase auditusecase.AuditUsecase) *auditHandler {
+	return &auditHandler{auditUsecase: auditUsecase}
+}
+
+// GetUserHistory godoc
+// @Summary Get change history of a user
+// @Description Get change history of a user, newest first
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param actor query string false "Actor"
+// @Param operation query string false "Operation, one of create, update, delete"
+// @Param offset query int false "Offset"
+// @Param limit query int false "Limit"
+// @Param order-by query string false "Order By, default: -created_at"
+// @Param created-at-gte query time.Time false "Created At Greater Than or Equal To"
+// @Param created-at-lte query time.Time false "Created At Less Than or Equal To"
+// @Success 200 {object} dto.ResAuditLogList
+// @Router /users/{id}/history [get]
+func (h *auditHandler) GetUserHistory(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":    false,
+			"code":       fiber.StatusBadRequest,
+			"message":    err.Error(),
+			"stacktrace": errorhelper.ComposeStacktrace(err),
+		})
+	}
+
+	var req dto.ReqGetAuditLog
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":    false,
+			"code":       fiber.StatusBadRequest,
+			"message":    err.Error(),
+			"stacktrace": errorhelper.ComposeStacktrace(err),
+		})
+	}
+
+	res := h.auditUsecase.GetEntityHistory(c.Context(), userAuditEntityType, id.String(), &req)
+	return c.Status(res.Code).JSON(res)
+}
+
+// GetAuditLogs godoc
+// @Summary Get audit logs by filter
+// @Description Get audit logs of every entity by filter
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param entity-type query string false "Entity Type"
+// @Param entity-ids query string false "Entity IDs, comma separated"
+// @Param actor query string false "Actor"
+// @Param request-id query string false "Request ID"
+// @Param operation query string false "Operation, one of create, update, delete"
+// @Param show-count query bool false "Show Count"
+// @Param offset query int false "Offset"
+// @Param limit query int false "Limit"
+// @Param order-by query string false "Order By, default: -created_at"
+// @Param created-at-gte query time.Time false "Created At Greater Than or Equal To"
+// @Param created-at-lte query time.Time false "Created At Less Than or Equal To"
+// @Success 200 {object} dto.ResAuditLogList
+// @Security BasicAuth
+// @Router /admin/audit-logs [get]
+func (h *auditHandler) GetAuditLogs(c *fiber.Ctx) error {
+	var req dto.ReqGetAuditLog
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":    false,
+			"code":       fiber.StatusBadRequest,
+			"message":    err.Error(),
+			"stacktrace": errorhelper.ComposeStacktrace(err),
+		})
+	}
+
+	res := h.auditUsecase.GetAuditLogsByFilter(c.Context(), &req)
+	return c.Status(res.Code).JSON(res)
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	tenantusecase "github.com/alxhtp/monogo/internal/usecase/tenant"
+	"github.com/alxhtp/monogo/pkg/dto"
+	errorhelper "github.com/alxhtp/monogo/pkg/helper/error"
+	"github.com/gofiber/fiber/v2"
+)
+
+type tenantHandler struct {
+	tenantUsecase tenantusecase.TenantUsecase
+}
+
+func NewTenantHandler(tenantUsecase tenantusecase.TenantUsecase) *tenantHandler {
+	return &tenantHandler{tenantUsecase: tenantUsecase}
+}
+
+// CreateTenant godoc
+// @Summary Provision a tenant
+// @Description Register a tenant, in the schema mode its schema is created and migrated, an existing tenant is upgraded to the latest migrations
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param tenant body dto.ReqCreateTenant true "Tenant"
+// @Success 201 {object} dto.ResTenantSingle
+// @Success 200 {object} dto.ResTenantSingle
+// @Security BasicAuth
+// @Router /admin/tenants [post]
+func (h *tenantHandler) CreateTenant(c *fiber.Ctx) error {
+	var req dto.ReqCreateTenant
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":    false,
+			"code":       fiber.StatusBadRequest,
+			"message":    err.Error(),
+			"stacktrace": errorhelper.ComposeStacktrace(err),
+		})
+	}
+
+	res := h.tenantUsecase.CreateTenant(c.Context(), &req)
+	return c.Status(res.Code).JSON(res)
+}
+
+// GetTenants godoc
+// @Summary Get tenants
+// @Description Get every provisioned tenant with its migration state
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} dto.ResTenantList
+// @Security BasicAuth
+// @Router /admin/tenants [get]
+func (h *tenantHandler) GetTenants(c *fiber.Ctx) error {
+	res := h.tenantUsecase.GetTenants(c.Context())
+	return c.Status(res.Code).JSON(res)
+}
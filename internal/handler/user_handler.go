@@ -1,9 +1,18 @@
 package handler
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/alxhtp/monogo/config"
 	userusecase "github.com/alxhtp/monogo/internal/usecase/user"
 	"github.com/alxhtp/monogo/pkg/dto"
 	dtobase "github.com/alxhtp/monogo/pkg/dto/base"
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
 	errorhelper "github.com/alxhtp/monogo/pkg/helper/error"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -12,12 +21,24 @@ import (
 // init dtobase
 var _ = dtobase.BaseRes{}
 
+const (
+	HeaderLastEventID = "Last-Event-ID"
+
+	defaultEventsHeartbeat = 15 * time.Second
+)
+
 type userHandler struct {
-	userUsecase userusecase.UserUsecase
+	userUsecase     userusecase.UserUsecase
+	eventsHeartbeat time.Duration
 }
 
-func NewUserHandler(userUsecase userusecase.UserUsecase) *userHandler {
-	return &userHandler{userUsecase: userUsecase}
+func NewUserHandler(userUsecase userusecase.UserUsecase, eventsCfg config.UserEventsConfig) *userHandler {
+	eventsHeartbeat, err := time.ParseDuration(eventsCfg.UserEventsHeartbeatInterval)
+	if err != nil || eventsHeartbeat <= 0 {
+		eventsHeartbeat = defaultEventsHeartbeat
+	}
+
+	return &userHandler{userUsecase: userUsecase, eventsHeartbeat: eventsHeartbeat}
 }
 
 // CreateUser godoc
@@ -27,6 +48,7 @@ func NewUserHandler(userUsecase userusecase.UserUsecase) *userHandler {
 // @Accept json
 // @Produce json
 // @Param user body dto.ReqCreateUser true "User"
+// @Param Idempotency-Key header string false "Key replaying the stored response when the request is retried"
 // @Success 201 {object} dto.ResUserSingle
 // @Router /users [post]
 func (h *userHandler) CreateUser(c *fiber.Ctx) error {
@@ -54,8 +76,17 @@ func (h *userHandler) CreateUser(c *fiber.Ctx) error {
 // @Success 200 {object} dto.ResUserSingle
 // @Router /users/{id} [get]
 func (h *userHandler) GetUserByID(c *fiber.Ctx) error {
-	id := c.Params("id")
-	res := h.userUsecase.GetUserByID(c.Context(), uuid.MustParse(id))
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":    false,
+			"code":       fiber.StatusBadRequest,
+			"message":    err.Error(),
+			"stacktrace": errorhelper.ComposeStacktrace(err),
+		})
+	}
+
+	res := h.userUsecase.GetUserByID(c.Context(), id)
 	return c.Status(res.Code).JSON(res)
 }
 
@@ -109,7 +140,16 @@ func (h *userHandler) GetUsersByFilter(c *fiber.Ctx) error {
 // @Success 200 {object} dto.ResUserSingle
 // @Router /users/{id} [put]
 func (h *userHandler) UpdateUser(c *fiber.Ctx) error {
-	id := c.Params("id")
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":    false,
+			"code":       fiber.StatusBadRequest,
+			"message":    err.Error(),
+			"stacktrace": errorhelper.ComposeStacktrace(err),
+		})
+	}
+
 	var req dto.ReqUpdateUser
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -120,7 +160,7 @@ func (h *userHandler) UpdateUser(c *fiber.Ctx) error {
 		})
 	}
 
-	res := h.userUsecase.UpdateUser(c.Context(), uuid.MustParse(id), &req)
+	res := h.userUsecase.UpdateUser(c.Context(), id, &req)
 	return c.Status(res.Code).JSON(res)
 }
 
@@ -134,7 +174,218 @@ func (h *userHandler) UpdateUser(c *fiber.Ctx) error {
 // @Success 200 {object} dtobase.BaseRes
 // @Router /users/{id} [delete]
 func (h *userHandler) DeleteUser(c *fiber.Ctx) error {
-	id := c.Params("id")
-	res := h.userUsecase.DeleteUser(c.Context(), uuid.MustParse(id))
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":    false,
+			"code":       fiber.StatusBadRequest,
+			"message":    err.Error(),
+			"stacktrace": errorhelper.ComposeStacktrace(err),
+		})
+	}
+
+	res := h.userUsecase.DeleteUser(c.Context(), id)
+	return c.Status(res.Code).JSON(res)
+}
+
+// ImportUsers godoc
+// @Summary Import users from a CSV file
+// @Description Import users from a CSV file with columns name, email, sex, address and phone. Large files are processed as a background job.
+// @Tags User
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV file"
+// @Param dry_run query bool false "Validate rows without creating users"
+// @Param Idempotency-Key header string false "Key replaying the stored response when the request is retried"
+// @Success 200 {object} dto.ResUserImport
+// @Success 201 {object} dto.ResUserImport
+// @Success 202 {object} dto.ResUserImport
+// @Router /users/import [post]
+func (h *userHandler) ImportUsers(c *fiber.Ctx) error {
+	var req dto.ReqImportUser
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":    false,
+			"code":       fiber.StatusBadRequest,
+			"message":    err.Error(),
+			"stacktrace": errorhelper.ComposeStacktrace(err),
+		})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":    false,
+			"code":       fiber.StatusBadRequest,
+			"message":    err.Error(),
+			"stacktrace": errorhelper.ComposeStacktrace(err),
+		})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":    false,
+			"code":       fiber.StatusBadRequest,
+			"message":    err.Error(),
+			"stacktrace": errorhelper.ComposeStacktrace(err),
+		})
+	}
+	defer file.Close()
+	req.File = file
+
+	res := h.userUsecase.ImportUsers(c.Context(), &req)
 	return c.Status(res.Code).JSON(res)
 }
+
+// GetImportJob godoc
+// @Summary Get a user import job
+// @Description Get progress of a background user import job
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param id path string true "Import Job ID"
+// @Success 200 {object} dto.ResUserImportJobSingle
+// @Router /users/import/{id} [get]
+func (h *userHandler) GetImportJob(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":    false,
+			"code":       fiber.StatusBadRequest,
+			"message":    err.Error(),
+			"stacktrace": errorhelper.ComposeStacktrace(err),
+		})
+	}
+
+	res := h.userUsecase.GetImportJob(c.Context(), id)
+	return c.Status(res.Code).JSON(res)
+}
+
+// GetImportJobErrors godoc
+// @Summary Get a user import job error report
+// @Description Download the failed rows of a finished user import job as CSV
+// @Tags User
+// @Accept json
+// @Produce text/csv
+// @Param id path string true "Import Job ID"
+// @Success 200 {file} file
+// @Router /users/import/{id}/errors [get]
+func (h *userHandler) GetImportJobErrors(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":    false,
+			"code":       fiber.StatusBadRequest,
+			"message":    err.Error(),
+			"stacktrace": errorhelper.ComposeStacktrace(err),
+		})
+	}
+
+	content, res := h.userUsecase.GetImportJobErrors(c.Context(), id)
+	if !res.Success {
+		return c.Status(res.Code).JSON(res)
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="user-import-`+id.String()+`-errors.csv"`)
+	return c.Status(res.Code).Send(content)
+}
+
+// StreamUserEvents godoc
+// @Summary Stream user changes
+// @Description Server-Sent Events stream of user created, updated, status changed and deleted events matching the filter.
+// @Description Every event carries its id, reconnecting with the Last-Event-ID header replays the missed events.
+// @Tags User
+// @Produce text/event-stream
+// @Param ids query string false "User IDs, comma separated uuids"
+// @Param name query string false "Name"
+// @Param email query string false "Email"
+// @Param status query int false "Status"
+// @Param sex query string false "Sex"
+// @Param address query string false "Address"
+// @Param phone query string false "Phone"
+// @Param last-event-id query int false "Resume after this event id, overridden by the Last-Event-ID header"
+// @Param Last-Event-ID header int false "Resume after this event id"
+// @Success 200 {object} dto.ResUserEvent
+// @Router /users/events [get]
+func (h *userHandler) StreamUserEvents(c *fiber.Ctx) error {
+	var req dto.ReqGetUserEvent
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":    false,
+			"code":       fiber.StatusBadRequest,
+			"message":    err.Error(),
+			"stacktrace": errorhelper.ComposeStacktrace(err),
+		})
+	}
+
+	if lastEventID := c.Get(HeaderLastEventID); lastEventID != "" {
+		id, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success":    false,
+				"code":       fiber.StatusBadRequest,
+				"message":    err.Error(),
+				"stacktrace": errorhelper.ComposeStacktrace(err),
+			})
+		}
+		req.LastEventID = &id
+	}
+
+	// the stream outlives the handler, so it must not hold on to the pooled request context
+	ctx, cancel := context.WithCancel(contexthelper.Detach(c.Context()))
+
+	events, res := h.userUsecase.StreamUserEvents(ctx, &req)
+	if !res.Success {
+		cancel()
+		return c.Status(res.Code).JSON(res)
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	conn := c.Context().Conn()
+	heartbeat := h.eventsHeartbeat
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+
+		// the server write timeout covers the whole response, extend it on every write instead
+		flush := func() bool {
+			_ = conn.SetWriteDeadline(time.Now().Add(2 * heartbeat))
+			return w.Flush() == nil
+		}
+
+		fmt.Fprintf(w, "retry: %d\n\n", (2 * time.Second).Milliseconds())
+		if !flush() {
+			return
+		}
+
+		for {
+			select {
+			case evt, ok := <-events:
+				if !ok {
+					return
+				}
+				data, err := json.Marshal(evt)
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", evt.ID, evt.Type, data)
+			case <-ticker.C:
+				fmt.Fprint(w, ": heartbeat\n\n")
+			}
+
+			if !flush() {
+				return
+			}
+		}
+	})
+
+	return nil
+}
@@ -0,0 +1,19 @@
+// Package handlerv2 serves the v2 REST API
+//
+// @title           Monogo API
+// @version         2.0
+// @description     Monogo API Collection, v2 exposes the user status by name
+// @termsOfService  http://swagger.io/terms/
+//
+// @contact.name   API Support
+// @contact.url    http://www.swagger.io/support
+// @contact.email  support@swagger.io
+//
+// @license.name  Apache 2.0
+// @license.url   http://www.apache.org/licenses/LICENSE-2.0.html
+//
+// @BasePath  /v2
+//
+// @externalDocs.description  OpenAPI
+// @externalDocs.url          https://swagger.io/resources/open-api/
+package handlerv2
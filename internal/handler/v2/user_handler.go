@@ -0,0 +1,249 @@
+package handlerv2
+
+import (
+	"fmt"
+
+	userusecase "github.com/alxhtp/monogo/internal/usecase/user"
+	"github.com/alxhtp/monogo/pkg/dto"
+	dtobase "github.com/alxhtp/monogo/pkg/dto/base"
+	dtov2 "github.com/alxhtp/monogo/pkg/dto/v2"
+	errorhelper "github.com/alxhtp/monogo/pkg/helper/error"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// init dtobase
+var _ = dtobase.BaseRes{}
+
+// userHandler serves the v2 user DTOs on top of the usecase shared with v1
+type userHandler struct {
+	userUsecase userusecase.UserUsecase
+}
+
+func NewUserHandler(userUsecase userusecase.UserUsecase) *userHandler {
+	return &userHandler{userUsecase: userUsecase}
+}
+
+// CreateUser godoc
+// @Summary Create a new user
+// @Description Create a new user
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param user body dtov2.ReqCreateUser true "User"
+// @Param Idempotency-Key header string false "Key replaying the stored response when the request is retried"
+// @Success 201 {object} dtov2.ResUserSingle
+// @Router /users [post]
+func (h *userHandler) CreateUser(c *fiber.Ctx) error {
+	var req dtov2.ReqCreateUser
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":    false,
+			"code":       fiber.StatusBadRequest,
+			"message":    err.Error(),
+			"stacktrace": errorhelper.ComposeStacktrace(err),
+		})
+	}
+
+	res := h.userUsecase.CreateUser(c.Context(), &dto.ReqCreateUser{
+		Name:     req.Name,
+		Email:    req.Email,
+		Metadata: req.Metadata,
+	})
+	return c.Status(res.Code).JSON(userSingleFromV1(res))
+}
+
+// GetUserByID godoc
+// @Summary Get a user by ID
+// @Description Get a user by ID
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} dtov2.ResUserSingle
+// @Router /users/{id} [get]
+func (h *userHandler) GetUserByID(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":    false,
+			"code":       fiber.StatusBadRequest,
+			"message":    err.Error(),
+			"stacktrace": errorhelper.ComposeStacktrace(err),
+		})
+	}
+
+	res := h.userUsecase.GetUserByID(c.Context(), id)
+	return c.Status(res.Code).JSON(userSingleFromV1(res))
+}
+
+// GetUsersByFilter godoc
+// @Summary Get users by filter
+// @Description Get users by filter
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param ids query string false "User IDs, comma separated uuids"
+// @Param name query string false "Name"
+// @Param email query string false "Email"
+// @Param status query string false "Status" Enums(inactive, active, banned)
+// @Param sex query string false "Sex"
+// @Param address query string false "Address"
+// @Param phone query string false "Phone"
+// @Param include-deleted query bool false "Include Deleted"
+// @Param show-count query bool false "Show Count"
+// @Param offset query int false "Offset"
+// @Param limit query int false "Limit"
+// @Param order-by query string false "Order By, default: +created_at"
+// @Param created-at-gte query time.Time false "Created At Greater Than or Equal To"
+// @Param created-at-lte query time.Time false "Created At Less Than or Equal To"
+// @Param updated-at-gte query time.Time false "Updated At Greater Than or Equal To"
+// @Param updated-at-lte query time.Time false "Updated At Less Than or Equal To"
+// @Success 200 {object} dtov2.ResUserList
+// @Router /users [get]
+func (h *userHandler) GetUsersByFilter(c *fiber.Ctx) error {
+	var req dtov2.ReqGetUser
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":    false,
+			"code":       fiber.StatusBadRequest,
+			"message":    err.Error(),
+			"stacktrace": errorhelper.ComposeStacktrace(err),
+		})
+	}
+
+	status, err := parseStatus(req.Status)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":    false,
+			"code":       fiber.StatusBadRequest,
+			"message":    err.Error(),
+			"stacktrace": errorhelper.ComposeStacktrace(err),
+		})
+	}
+
+	res := h.userUsecase.GetUsersByFilter(c.Context(), &dto.ReqGetUser{
+		IDs:                    req.IDs,
+		Name:                   req.Name,
+		Email:                  req.Email,
+		Status:                 status,
+		Sex:                    req.Sex,
+		Address:                req.Address,
+		Phone:                  req.Phone,
+		BaseReqQueryPagination: req.BaseReqQueryPagination,
+	})
+
+	users := make([]dtov2.ResUser, len(res.Data))
+	for i := range res.Data {
+		users[i] = userFromV1(&res.Data[i])
+	}
+	return c.Status(res.Code).JSON(dtov2.ResUserList{
+		BaseResPagination: res.BaseResPagination,
+		Data:              users,
+	})
+}
+
+// UpdateUser godoc
+// @Summary Update a user
+// @Description Update a user
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param user body dtov2.ReqUpdateUser true "User"
+// @Success 200 {object} dtov2.ResUserSingle
+// @Router /users/{id} [put]
+func (h *userHandler) UpdateUser(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":    false,
+			"code":       fiber.StatusBadRequest,
+			"message":    err.Error(),
+			"stacktrace": errorhelper.ComposeStacktrace(err),
+		})
+	}
+
+	var req dtov2.ReqUpdateUser
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":    false,
+			"code":       fiber.StatusBadRequest,
+			"message":    err.Error(),
+			"stacktrace": errorhelper.ComposeStacktrace(err),
+		})
+	}
+
+	status, err := parseStatus(req.Status)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":    false,
+			"code":       fiber.StatusBadRequest,
+			"message":    err.Error(),
+			"stacktrace": errorhelper.ComposeStacktrace(err),
+		})
+	}
+
+	res := h.userUsecase.UpdateUser(c.Context(), id, &dto.ReqUpdateUser{
+		Name:     req.Name,
+		Email:    req.Email,
+		Status:   status,
+		Metadata: req.Metadata,
+	})
+	return c.Status(res.Code).JSON(userSingleFromV1(res))
+}
+
+// DeleteUser godoc
+// @Summary Delete a user
+// @Description Delete a user
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} dtobase.BaseRes
+// @Router /users/{id} [delete]
+func (h *userHandler) DeleteUser(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":    false,
+			"code":       fiber.StatusBadRequest,
+			"message":    err.Error(),
+			"stacktrace": errorhelper.ComposeStacktrace(err),
+		})
+	}
+
+	res := h.userUsecase.DeleteUser(c.Context(), id)
+	return c.Status(res.Code).JSON(res)
+}
+
+func parseStatus(label *string) (*int, error) {
+	if label == nil {
+		return nil, nil
+	}
+
+	status, ok := dtov2.ParseUserStatus(*label)
+	if !ok {
+		return nil, fmt.Errorf("invalid status %q, expected one of inactive, active, banned", *label)
+	}
+	return &status, nil
+}
+
+func userFromV1(user *dto.ResUser) dtov2.ResUser {
+	return dtov2.ResUser{
+		ID:       user.ID,
+		Name:     user.Name,
+		Email:    user.Email,
+		Status:   dtov2.UserStatusLabel(user.Status),
+		Metadata: user.Metadata,
+	}
+}
+
+func userSingleFromV1(res dto.ResUserSingle) dtov2.ResUserSingle {
+	out := dtov2.ResUserSingle{BaseRes: res.BaseRes}
+	if res.Data != nil {
+		user := userFromV1(res.Data)
+		out.Data = &user
+	}
+	return out
+}
@@ -0,0 +1,250 @@
+package handler
+
+import (
+	webhookusecase "github.com/alxhtp/monogo/internal/usecase/webhook"
+	"github.com/alxhtp/monogo/pkg/dto"
+	dtobase "github.com/alxhtp/monogo/pkg/dto/base"
+	errorhelper "github.com/alxhtp/monogo/pkg/helper/error"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// init dtobase
+var _ = dtobase.BaseRes{}
+
+type webhookHandler struct {
+	webhookUsecase webhookusecase.WebhookUsecase
+}
+
+func NewWebhookHandler(webhookUsecase webhookusecase.WebhookUsecase) *webhookHandler {
+	return &webhookHandler{webhookUsecase: webhookUsecase}
+}
+
+// CreateWebhook godoc
+// @Summary Create a new webhook
+// @Description Create a new webhook subscribed to user events. The signing secret is generated when empty and only returned in this response.
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param webhook body dto.ReqCreateWebhook true "Webhook"
+// @Param Idempotency-Key header string false "Key replaying the stored response when the request is retried"
+// @Success 201 {object} dto.ResWebhookSingle
+// @Security BasicAuth
+// @Router /admin/webhooks [post]
+func (h *webhookHandler) CreateWebhook(c *fiber.Ctx) error {
+	var req dto.ReqCreateWebhook
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":    false,
+			"code":       fiber.StatusBadRequest,
+			"message":    err.Error(),
+			"stacktrace": errorhelper.ComposeStacktrace(err),
+		})
+	}
+
+	res := h.webhookUsecase.CreateWebhook(c.Context(), &req)
+	return c.Status(res.Code).JSON(res)
+}
+
+// GetWebhookByID godoc
+// @Summary Get a webhook by ID
+// @Description Get a webhook by ID
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} dto.ResWebhookSingle
+// @Security BasicAuth
+// @Router /admin/webhooks/{id} [get]
+func (h *webhookHandler) GetWebhookByID(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":    false,
+			"code":       fiber.StatusBadRequest,
+			"message":    err.Error(),
+			"stacktrace": errorhelper.ComposeStacktrace(err),
+		})
+	}
+
+	res := h.webhookUsecase.GetWebhookByID(c.Context(), id)
+	return c.Status(res.Code).JSON(res)
+}
+
+// GetWebhooksByFilter godoc
+// @Summary Get webhooks by filter
+// @Description Get webhooks by filter
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param ids query string false "Webhook IDs, comma separated uuids"
+// @Param name query string false "Name"
+// @Param active query bool false "Active"
+// @Param event query string false "Subscribed event type"
+// @Param include-deleted query bool false "Include Deleted"
+// @Param show-count query bool false "Show Count"
+// @Param offset query int false "Offset"
+// @Param limit query int false "Limit"
+// @Param order-by query string false "Order By, default: +created_at"
+// @Param created-at-gte query time.Time false "Created At Greater Than or Equal To"
+// @Param created-at-lte query time.Time false "Created At Less Than or Equal To"
+// @Param updated-at-gte query time.Time false "Updated At Greater Than or Equal To"
+// @Param updated-at-lte query time.Time false "Updated At Less Than or Equal To"
+// @Success 200 {object} dto.ResWebhookList
+// @Security BasicAuth
+// @Router /admin/webhooks [get]
+func (h *webhookHandler) GetWebhooksByFilter(c *fiber.Ctx) error {
+	var req dto.ReqGetWebhook
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":    false,
+			"code":       fiber.StatusBadRequest,
+			"message":    err.Error(),
+			"stacktrace": errorhelper.ComposeStacktrace(err),
+		})
+	}
+
+	res := h.webhookUsecase.GetWebhooksByFilter(c.Context(), &req)
+	return c.Status(res.Code).JSON(res)
+}
+
+// UpdateWebhook godoc
+// @Summary Update a webhook
+// @Description Update a webhook
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param webhook body dto.ReqUpdateWebhook true "Webhook"
+// @Success 200 {object} dto.ResWebhookSingle
+// @Security BasicAuth
+// @Router /admin/webhooks/{id} [put]
+func (h *webhookHandler) UpdateWebhook(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":    false,
+			"code":       fiber.StatusBadRequest,
+			"message":    err.Error(),
+			"stacktrace": errorhelper.ComposeStacktrace(err),
+		})
+	}
+
+	var req dto.ReqUpdateWebhook
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":    false,
+			"code":       fiber.StatusBadRequest,
+			"message":    err.Error(),
+			"stacktrace": errorhelper.ComposeStacktrace(err),
+		})
+	}
+
+	res := h.webhookUsecase.UpdateWebhook(c.Context(), id, &req)
+	return c.Status(res.Code).JSON(res)
+}
+
+// DeleteWebhook godoc
+// @Summary Delete a webhook
+// @Description Delete a webhook, its pending deliveries are dropped
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} dtobase.BaseRes
+// @Security BasicAuth
+// @Router /admin/webhooks/{id} [delete]
+func (h *webhookHandler) DeleteWebhook(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":    false,
+			"code":       fiber.StatusBadRequest,
+			"message":    err.Error(),
+			"stacktrace": errorhelper.ComposeStacktrace(err),
+		})
+	}
+
+	res := h.webhookUsecase.DeleteWebhook(c.Context(), id)
+	return c.Status(res.Code).JSON(res)
+}
+
+// GetWebhookDeliveries godoc
+// @Summary Get deliveries of a webhook
+// @Description Get the delivery log of a webhook
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param status query string false "Status, one of pending, succeeded, failed, dead"
+// @Param event-type query string false "Event Type"
+// @Param show-count query bool false "Show Count"
+// @Param offset query int false "Offset"
+// @Param limit query int false "Limit"
+// @Param order-by query string false "Order By, default: +created_at"
+// @Param created-at-gte query time.Time false "Created At Greater Than or Equal To"
+// @Param created-at-lte query time.Time false "Created At Less Than or Equal To"
+// @Success 200 {object} dto.ResWebhookDeliveryList
+// @Security BasicAuth
+// @Router /admin/webhooks/{id}/deliveries [get]
+func (h *webhookHandler) GetWebhookDeliveries(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":    false,
+			"code":       fiber.StatusBadRequest,
+			"message":    err.Error(),
+			"stacktrace": errorhelper.ComposeStacktrace(err),
+		})
+	}
+
+	var req dto.ReqGetWebhookDelivery
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":    false,
+			"code":       fiber.StatusBadRequest,
+			"message":    err.Error(),
+			"stacktrace": errorhelper.ComposeStacktrace(err),
+		})
+	}
+
+	res := h.webhookUsecase.GetDeliveriesByFilter(c.Context(), id, &req)
+	return c.Status(res.Code).JSON(res)
+}
+
+// RedeliverWebhook godoc
+// @Summary Redeliver a webhook delivery
+// @Description Queue a delivery for an immediate new round of attempts, including dead deliveries, up to WEBHOOK_MAX_REDELIVERIES times
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param delivery_id path string true "Delivery ID"
+// @Param Idempotency-Key header string false "Key replaying the stored response when the request is retried"
+// @Success 202 {object} dto.ResWebhookDeliverySingle
+// @Security BasicAuth
+// @Router /admin/webhooks/{id}/deliveries/{delivery_id}/redeliver [post]
+func (h *webhookHandler) RedeliverWebhook(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":    false,
+			"code":       fiber.StatusBadRequest,
+			"message":    err.Error(),
+			"stacktrace": errorhelper.ComposeStacktrace(err),
+		})
+	}
+
+	deliveryID, err := uuid.Parse(c.Params("delivery_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":    false,
+			"code":       fiber.StatusBadRequest,
+			"message":    err.Error(),
+			"stacktrace": errorhelper.ComposeStacktrace(err),
+		})
+	}
+
+	res := h.webhookUsecase.Redeliver(c.Context(), id, deliveryID)
+	return c.Status(res.Code).JSON(res)
+}
@@ -0,0 +1,32 @@
+package publisherimplementation
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/alxhtp/monogo/internal/publisher"
+	"github.com/alxhtp/monogo/pkg/event"
+)
+
+type logPublisher struct {
+	logger *slog.Logger
+}
+
+func NewLogPublisher() publisher.Publisher {
+	return &logPublisher{logger: slog.Default().With("publisher", PublisherLog)}
+}
+
+func (p *logPublisher) Name() string {
+	return PublisherLog
+}
+
+func (p *logPublisher) Publish(ctx context.Context, msg event.Message) error {
+	p.logger.InfoContext(ctx, "event published",
+		"id", msg.ID,
+		"type", msg.Type,
+		"aggregate_type", msg.AggregateType,
+		"aggregate_id", msg.AggregateID,
+		"payload", string(msg.Payload),
+	)
+	return nil
+}
@@ -0,0 +1,86 @@
+package publisherimplementation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alxhtp/monogo/config"
+	"github.com/alxhtp/monogo/internal/publisher"
+	webhookrepository "github.com/alxhtp/monogo/internal/repository/webhook/implementation"
+	"github.com/alxhtp/monogo/pkg/event"
+	"gorm.io/gorm"
+)
+
+const (
+	PublisherLog      = "log"
+	PublisherWebhook  = "webhook"
+	PublisherPgNotify = "pgnotify"
+
+	PublisherWebhookSubscriptions = "webhook-subscriptions"
+)
+
+type multiPublisher struct {
+	publishers []publisher.Publisher
+}
+
+// NewMultiPublisher fans a message out to every publisher, it fails when any of them fails
+func NewMultiPublisher(publishers ...publisher.Publisher) publisher.Publisher {
+	return &multiPublisher{publishers: publishers}
+}
+
+func (p *multiPublisher) Name() string {
+	names := make([]string, len(p.publishers))
+	for i := range p.publishers {
+		names[i] = p.publishers[i].Name()
+	}
+	return strings.Join(names, ",")
+}
+
+func (p *multiPublisher) Publish(ctx context.Context, msg event.Message) error {
+	var errs []error
+	for _, pub := range p.publishers {
+		if err := pub.Publish(ctx, msg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", pub.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NewPublisherFromConfig builds the publishers listed in OUTBOX_PUBLISHERS
+func NewPublisherFromConfig(cfg *config.OutboxConfig, db *gorm.DB) (publisher.Publisher, error) {
+	publishers := make([]publisher.Publisher, 0, len(cfg.OutboxPublishers))
+	for _, name := range cfg.OutboxPublishers {
+		switch strings.TrimSpace(name) {
+		case PublisherLog:
+			publishers = append(publishers, NewLogPublisher())
+		case PublisherWebhook:
+			if cfg.OutboxWebhookURL == "" {
+				return nil, errors.New("OUTBOX_WEBHOOK_URL is required by the webhook publisher")
+			}
+			timeout, err := time.ParseDuration(cfg.OutboxWebhookTimeout)
+			if err != nil {
+				return nil, fmt.Errorf("parse OUTBOX_WEBHOOK_TIMEOUT: %w", err)
+			}
+			publishers = append(publishers, NewWebhookPublisher(cfg.OutboxWebhookURL, timeout))
+		case PublisherPgNotify:
+			publishers = append(publishers, NewPgNotifyPublisher(db, cfg.OutboxNotifyChannel))
+		case PublisherWebhookSubscriptions:
+			publishers = append(publishers, NewWebhookSubscriptionPublisher(
+				webhookrepository.NewWebhookRepository(db),
+				webhookrepository.NewWebhookDeliveryRepository(db),
+			))
+		case "":
+		default:
+			return nil, fmt.Errorf("unknown outbox publisher %q", name)
+		}
+	}
+
+	if len(publishers) == 1 {
+		return publishers[0], nil
+	}
+
+	return NewMultiPublisher(publishers...), nil
+}
@@ -0,0 +1,49 @@
+package publisherimplementation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/alxhtp/monogo/internal/publisher"
+	"github.com/alxhtp/monogo/pkg/event"
+	"gorm.io/gorm"
+)
+
+// maxNotifyPayload stays below the 8000 bytes postgres NOTIFY payload limit
+const maxNotifyPayload = 7900
+
+type pgNotifyPublisher struct {
+	db      *gorm.DB
+	channel string
+}
+
+func NewPgNotifyPublisher(db *gorm.DB, channel string) publisher.Publisher {
+	return &pgNotifyPublisher{db: db, channel: channel}
+}
+
+func (p *pgNotifyPublisher) Name() string {
+	return PublisherPgNotify
+}
+
+func (p *pgNotifyPublisher) Publish(ctx context.Context, msg event.Message) error {
+	if p.db == nil {
+		return errors.New("database connection is not initialized")
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal message: %w", err)
+	}
+
+	// oversized payloads are dropped, listeners load them from the outbox by id
+	if len(body) > maxNotifyPayload {
+		msg.Payload = nil
+		if body, err = json.Marshal(msg); err != nil {
+			return fmt.Errorf("marshal message: %w", err)
+		}
+	}
+
+	return p.db.WithContext(ctx).Exec("SELECT pg_notify(?, ?)", p.channel, string(body)).Error
+}
@@ -0,0 +1,71 @@
+package publisherimplementation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/alxhtp/monogo/internal/entity"
+	"github.com/alxhtp/monogo/internal/publisher"
+	webhookrepository "github.com/alxhtp/monogo/internal/repository/webhook"
+	"github.com/alxhtp/monogo/pkg/constant"
+	"github.com/alxhtp/monogo/pkg/event"
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
+)
+
+type webhookSubscriptionPublisher struct {
+	webhookRepository  webhookrepository.WebhookRepository
+	deliveryRepository webhookrepository.WebhookDeliveryRepository
+}
+
+// NewWebhookSubscriptionPublisher queues a delivery for every webhook subscribed to the message type.
+// Deliveries are sent by the webhook dispatcher, a message published again does not queue them twice.
+func NewWebhookSubscriptionPublisher(
+	webhookRepository webhookrepository.WebhookRepository,
+	deliveryRepository webhookrepository.WebhookDeliveryRepository,
+) publisher.Publisher {
+	return &webhookSubscriptionPublisher{
+		webhookRepository:  webhookRepository,
+		deliveryRepository: deliveryRepository,
+	}
+}
+
+func (p *webhookSubscriptionPublisher) Name() string {
+	return PublisherWebhookSubscriptions
+}
+
+func (p *webhookSubscriptionPublisher) Publish(ctx context.Context, msg event.Message) error {
+	webhooks, err := p.webhookRepository.GetSubscribed(ctx, string(msg.Type))
+	if err != nil {
+		return fmt.Errorf("get subscribed webhooks: %w", err)
+	}
+
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal message: %w", err)
+	}
+
+	now := time.Now()
+	deliveries := make([]entity.WebhookDelivery, len(webhooks))
+	for i := range webhooks {
+		deliveries[i] = entity.WebhookDelivery{
+			WebhookID:     webhooks[i].ID,
+			EventID:       msg.ID,
+			EventType:     string(msg.Type),
+			Payload:       databasehelper.GormJsonType[json.RawMessage]{Item: body},
+			Status:        constant.WebhookDeliveryStatusPending,
+			NextAttemptAt: now,
+		}
+	}
+
+	if err := p.deliveryRepository.CreateMany(ctx, deliveries); err != nil {
+		return fmt.Errorf("queue webhook deliveries: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,14 @@
+package publisher
+
+import (
+	"context"
+
+	"github.com/alxhtp/monogo/pkg/event"
+)
+
+// Publisher delivers outbox messages to the outside world.
+// Delivery is at least once, a message is retried until Publish returns nil.
+type Publisher interface {
+	Name() string
+	Publish(ctx context.Context, msg event.Message) error
+}
@@ -0,0 +1,12 @@
+package auditrepository
+
+import (
+	"context"
+
+	"github.com/alxhtp/monogo/internal/entity"
+	entitybase "github.com/alxhtp/monogo/internal/entity/base"
+)
+
+type AuditRepository interface {
+	GetByFilter(ctx context.Context, filter *entity.AuditLogFilter) (output []entity.AuditLog, paginationResult entitybase.BasePaginationResult, err error)
+}
@@ -0,0 +1,70 @@
+package auditrepositoryimplementation
+
+import (
+	"context"
+	"errors"
+
+	"github.com/alxhtp/monogo/internal/entity"
+	entitybase "github.com/alxhtp/monogo/internal/entity/base"
+	auditrepository "github.com/alxhtp/monogo/internal/repository/audit"
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
+	"gorm.io/gorm"
+)
+
+type auditRepository struct {
+	db       *gorm.DB
+	auditLog entity.AuditLog
+}
+
+func NewAuditRepository(db *gorm.DB) auditrepository.AuditRepository {
+	return &auditRepository{db: db, auditLog: entity.AuditLog{}}
+}
+
+func (r *auditRepository) GetByFilter(ctx context.Context, filter *entity.AuditLogFilter) (output []entity.AuditLog, paginationResult entitybase.BasePaginationResult, err error) {
+	if r.db == nil {
+		return nil, entitybase.BasePaginationResult{}, errors.New("database connection is not initialized")
+	}
+
+	query := databasehelper.DBFromContext(ctx, r.db).Model(&output)
+	query, err = r.applyFilter(query, *filter)
+	if err != nil {
+		return nil, entitybase.BasePaginationResult{}, err
+	}
+
+	query = entitybase.PaginateEntityQuery(query, r.auditLog.TableName(), r.auditLog.OrderMap(), &filter.PaginationFilter, &paginationResult)
+
+	if err = query.Find(&output).Error; err != nil {
+		return
+	}
+
+	return output, paginationResult, nil
+}
+
+func (r *auditRepository) applyFilter(db *gorm.DB, filter entity.AuditLogFilter) (*gorm.DB, error) {
+	if db == nil {
+		return nil, errors.New("database connection is not initialized")
+	}
+
+	table := r.auditLog.TableName()
+	if filter.EntityType != nil {
+		db = db.Where(table+".entity_type = ?", filter.EntityType)
+	}
+
+	if filter.EntityIDs != nil {
+		db = db.Where(table+".entity_id IN (?)", filter.EntityIDs)
+	}
+
+	if filter.Actor != nil {
+		db = db.Where(table+".actor = ?", filter.Actor)
+	}
+
+	if filter.RequestID != nil {
+		db = db.Where(table+".request_id = ?", filter.RequestID)
+	}
+
+	if filter.Operation != nil {
+		db = db.Where(table+".operation = ?", filter.Operation)
+	}
+
+	return db, nil
+}
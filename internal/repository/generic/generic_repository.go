@@ -0,0 +1,18 @@
+package genericrepository
+
+import (
+	"context"
+
+	entitybase "github.com/alxhtp/monogo/internal/entity/base"
+	"github.com/google/uuid"
+)
+
+// Repository holds the CRUD operations of the entity E, listed by the filter F.
+// Entity repositories embed it and add the queries of their own.
+type Repository[E any, F any] interface {
+	Create(ctx context.Context, entity *E) (output *E, err error)
+	GetByID(ctx context.Context, id uuid.UUID) (output *E, err error)
+	GetByFilter(ctx context.Context, filter *F) (output []E, paginationResult entitybase.BasePaginationResult, err error)
+	Update(ctx context.Context, id uuid.UUID, updateMap map[string]any) (output *E, err error)
+	Delete(ctx context.Context, id uuid.UUID) (err error)
+}
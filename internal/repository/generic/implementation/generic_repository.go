@@ -0,0 +1,105 @@
+package genericrepositoryimplementation
+
+import (
+	"context"
+	"errors"
+
+	entitybase "github.com/alxhtp/monogo/internal/entity/base"
+	genericrepository "github.com/alxhtp/monogo/internal/repository/generic"
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Definition is what the generic repository needs to know of the entity E and its filter F
+type Definition[E any, F any] struct {
+	// Table is the table of E, it prefixes the columns of the filter and the pagination, never a client value
+	Table string
+	// OrderMap lists the columns E is ordered by, see entitybase.GenerateBaseOrderMap
+	OrderMap map[string]bool
+	// ApplyFilter narrows db to the entities matching filter
+	ApplyFilter func(db *gorm.DB, filter F) (*gorm.DB, error)
+	// Pagination returns the pagination of filter
+	Pagination func(filter *F) *entitybase.BasePaginationFilter
+}
+
+type repository[E any, F any] struct {
+	db         *gorm.DB
+	definition Definition[E, F]
+}
+
+func NewRepository[E any, F any](db *gorm.DB, definition Definition[E, F]) genericrepository.Repository[E, F] {
+	return &repository[E, F]{db: db, definition: definition}
+}
+
+func (r *repository[E, F]) Create(ctx context.Context, entity *E) (output *E, err error) {
+	if r.db == nil {
+		return nil, errors.New("database connection is not initialized")
+	}
+	err = databasehelper.DBFromContext(ctx, r.db).Create(entity).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// read back, the columns defaulted by the database are only known once the row is stored
+	identified, ok := any(entity).(entitybase.Identified)
+	if !ok {
+		return entity, nil
+	}
+	return r.GetByID(ctx, identified.GetID())
+}
+
+func (r *repository[E, F]) GetByID(ctx context.Context, id uuid.UUID) (output *E, err error) {
+	if r.db == nil {
+		return nil, errors.New("database connection is not initialized")
+	}
+
+	output = new(E)
+	if err := databasehelper.DBFromContext(ctx, r.db).Table(r.definition.Table).First(output, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+func (r *repository[E, F]) GetByFilter(ctx context.Context, filter *F) (output []E, paginationResult entitybase.BasePaginationResult, err error) {
+	if r.db == nil {
+		return nil, entitybase.BasePaginationResult{}, errors.New("database connection is not initialized")
+	}
+
+	query := databasehelper.DBFromContext(ctx, r.db).Model(&output)
+	query, err = r.definition.ApplyFilter(query, *filter)
+	if err != nil {
+		return nil, entitybase.BasePaginationResult{}, err
+	}
+
+	query = entitybase.PaginateEntityQuery(query, r.definition.Table, r.definition.OrderMap, r.definition.Pagination(filter), &paginationResult)
+
+	if err = query.Find(&output).Error; err != nil {
+		return
+	}
+
+	return output, paginationResult, nil
+}
+
+func (r *repository[E, F]) Update(ctx context.Context, id uuid.UUID, updateMap map[string]any) (output *E, err error) {
+	if r.db == nil {
+		return nil, errors.New("database connection is not initialized")
+	}
+
+	err = databasehelper.DBFromContext(ctx, r.db).Model(new(E)).Where("id = ?", id).Updates(updateMap).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+func (r *repository[E, F]) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	if r.db == nil {
+		return errors.New("database connection is not initialized")
+	}
+
+	err = databasehelper.DBFromContext(ctx, r.db).Where("id = ?", id).Delete(new(E)).Error
+	return err
+}
@@ -0,0 +1,405 @@
+// Package genericrepositorymemory implements the generic repository in memory, for tests that should not need a
+// database. It keeps the semantics of the GORM repository, checked by the conformance suites of the repositories:
+// soft deletes, pagination and ordering, unique constraints reported as postgres reports them, and the tenancy of
+// the row level mode, every operation sees the rows of the tenant of its context only.
+package genericrepositorymemory
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	entitybase "github.com/alxhtp/monogo/internal/entity/base"
+	genericrepository "github.com/alxhtp/monogo/internal/repository/generic"
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// pgInsufficientPrivilege is the SQLSTATE of a row written against the row level security policy
+const pgInsufficientPrivilege = "42501"
+
+// columns of entitybase.Base and entitybase.BaseTenant
+const (
+	columnID        = "id"
+	columnTenantID  = "tenant_id"
+	columnCreatedAt = "created_at"
+	columnUpdatedAt = "updated_at"
+	columnDeletedAt = "deleted_at"
+)
+
+// Definition is what the in-memory repository needs to know of the entity E and its filter F,
+// E embeds entitybase.Base and, when tenant-owned, entitybase.BaseTenant
+type Definition[E any, F any] struct {
+	// Table is the table of E, it names the constraints in the errors
+	Table string
+	// OrderMap lists the columns E is ordered by, see entitybase.GenerateBaseOrderMap
+	OrderMap map[string]bool
+	// Match reports whether entity satisfies filter, with the semantics of the filter of the GORM repository
+	Match func(entity *E, filter F) bool
+	// Pagination returns the pagination of filter
+	Pagination func(filter *F) *entitybase.BasePaginationFilter
+	// Unique lists the unique constraints of the table besides its primary key
+	Unique []Constraint
+}
+
+// Constraint is a unique constraint, Name is the name postgres reports, e.g. users_tenant_id_email_key
+type Constraint struct {
+	Name    string
+	Columns []string
+}
+
+type repository[E any, F any] struct {
+	definition Definition[E, F]
+	schema     *schema.Schema
+
+	mu sync.RWMutex
+	// rows are kept in the order of creation, soft deleted rows included
+	rows []E
+}
+
+// NewRepository returns an empty in-memory repository, it panics when E is not a GORM model
+func NewRepository[E any, F any](definition Definition[E, F]) genericrepository.Repository[E, F] {
+	entitySchema, err := schema.Parse(new(E), &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		panic(fmt.Sprintf("in-memory repository of %T: %v", *new(E), err))
+	}
+	for _, column := range []string{columnID, columnCreatedAt, columnUpdatedAt, columnDeletedAt} {
+		if entitySchema.LookUpField(column) == nil {
+			panic(fmt.Sprintf("in-memory repository of %T: no %s column, the entity does not embed entitybase.Base", *new(E), column))
+		}
+	}
+
+	return &repository[E, F]{definition: definition, schema: entitySchema}
+}
+
+func (r *repository[E, F]) Create(ctx context.Context, entity *E) (output *E, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row := *entity
+	if r.value(&row, columnID) == uuid.Nil {
+		if err := r.set(ctx, &row, columnID, uuid.New()); err != nil {
+			return nil, err
+		}
+	}
+
+	tenant := contexthelper.GetTenant(ctx)
+	if r.schema.LookUpField(columnTenantID) != nil {
+		if owner := r.tenant(&row); owner != "" && owner != tenant {
+			return nil, r.rowSecurityViolation()
+		}
+		if err := r.set(ctx, &row, columnTenantID, tenant); err != nil {
+			return nil, err
+		}
+	}
+
+	createdAt := now()
+	for _, column := range []string{columnCreatedAt, columnUpdatedAt} {
+		if r.value(&row, column).(time.Time).IsZero() {
+			if err := r.set(ctx, &row, column, createdAt); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := r.checkUnique(&row, -1); err != nil {
+		return nil, err
+	}
+
+	r.rows = append(r.rows, row)
+	// as gorm does, the entity created gets its id and timestamps
+	*entity = row
+	return &row, nil
+}
+
+func (r *repository[E, F]) GetByID(ctx context.Context, id uuid.UUID) (output *E, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	i, ok := r.find(ctx, id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	row := r.rows[i]
+	return &row, nil
+}
+
+func (r *repository[E, F]) GetByFilter(ctx context.Context, filter *F) (output []E, paginationResult entitybase.BasePaginationResult, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	pagination := r.definition.Pagination(filter)
+	withDeleted := pagination != nil && pagination.WithDeleted != nil && *pagination.WithDeleted
+
+	output = make([]E, 0)
+	for i := range r.rows {
+		row := &r.rows[i]
+		if !r.visible(ctx, row, withDeleted) || !r.definition.Match(row, *filter) || !r.inTimeRange(row, pagination) {
+			continue
+		}
+		output = append(output, *row)
+	}
+
+	// as entitybase.PaginateEntityQuery, nothing is counted, ordered nor limited without a pagination
+	if pagination == nil {
+		return output, paginationResult, nil
+	}
+	paginationResult.Count = len(output)
+
+	orderColumns := []entitybase.OrderColumn{{Column: columnCreatedAt, Desc: true}}
+	if pagination.OrderBy != nil {
+		paginationResult.OrderBy = *pagination.OrderBy
+		orderColumns = entitybase.OrderQueryColumns(*pagination.OrderBy, r.definition.OrderMap)
+	}
+	slices.SortStableFunc(output, func(a, b E) int {
+		for _, column := range orderColumns {
+			c := compare(r.value(&a, column.Column), r.value(&b, column.Column))
+			if column.Desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+
+	if pagination.Offset != nil && *pagination.Offset > 0 {
+		paginationResult.Offset = *pagination.Offset
+		output = output[min(*pagination.Offset, len(output)):]
+	}
+
+	paginationResult.Limit = entitybase.PageLimit(pagination)
+	output = output[:min(paginationResult.Limit, len(output))]
+
+	return output, paginationResult, nil
+}
+
+func (r *repository[E, F]) Update(ctx context.Context, id uuid.UUID, updateMap map[string]any) (output *E, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, ok := r.find(ctx, id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	row := r.rows[i]
+	for column, value := range updateMap {
+		field := r.schema.LookUpField(column)
+		if field == nil {
+			return nil, fmt.Errorf("column %q of relation %q does not exist", column, r.definition.Table)
+		}
+		if err := r.set(ctx, &row, field.DBName, value); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.schema.LookUpField(columnTenantID) != nil && r.tenant(&row) != contexthelper.GetTenant(ctx) {
+		return nil, r.rowSecurityViolation()
+	}
+
+	// as gorm does, updated_at is set unless the update sets it
+	if _, ok := updateMap[columnUpdatedAt]; !ok {
+		if err := r.set(ctx, &row, columnUpdatedAt, now()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := r.checkUnique(&row, i); err != nil {
+		return nil, err
+	}
+
+	r.rows[i] = row
+	return &row, nil
+}
+
+func (r *repository[E, F]) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, ok := r.find(ctx, id)
+	if !ok {
+		return nil
+	}
+
+	return r.set(ctx, &r.rows[i], columnDeletedAt, gorm.DeletedAt{Time: now(), Valid: true})
+}
+
+// find returns the index of the row id, when it is visible in ctx and not deleted
+func (r *repository[E, F]) find(ctx context.Context, id uuid.UUID) (int, bool) {
+	for i := range r.rows {
+		if r.value(&r.rows[i], columnID) == id && r.visible(ctx, &r.rows[i], false) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// visible reports whether row belongs to the tenant of ctx and, unless withDeleted, is not deleted
+func (r *repository[E, F]) visible(ctx context.Context, row *E, withDeleted bool) bool {
+	if r.tenant(row) != contexthelper.GetTenant(ctx) {
+		return false
+	}
+	return withDeleted || !r.value(row, columnDeletedAt).(gorm.DeletedAt).Valid
+}
+
+// inTimeRange reports whether row was created and updated in the bounds of pagination
+func (r *repository[E, F]) inTimeRange(row *E, pagination *entitybase.BasePaginationFilter) bool {
+	if pagination == nil {
+		return true
+	}
+
+	createdAt := r.value(row, columnCreatedAt).(time.Time)
+	updatedAt := r.value(row, columnUpdatedAt).(time.Time)
+	switch {
+	case pagination.MinCreated != nil && createdAt.Before(*pagination.MinCreated),
+		pagination.MaxCreated != nil && createdAt.After(*pagination.MaxCreated),
+		pagination.MinUpdated != nil && updatedAt.Before(*pagination.MinUpdated),
+		pagination.MaxUpdated != nil && updatedAt.After(*pagination.MaxUpdated):
+		return false
+	}
+	return true
+}
+
+// checkUnique returns the error postgres returns when row, stored at index self or -1 when new, duplicates a key.
+// As in the table, deleted rows and the rows of every tenant hold their keys.
+func (r *repository[E, F]) checkUnique(row *E, self int) error {
+	constraints := append([]Constraint{{Name: r.definition.Table + "_pkey", Columns: []string{columnID}}}, r.definition.Unique...)
+	for _, constraint := range constraints {
+		for i := range r.rows {
+			if i == self {
+				continue
+			}
+			duplicated := true
+			for _, column := range constraint.Columns {
+				if !reflect.DeepEqual(r.value(row, column), r.value(&r.rows[i], column)) {
+					duplicated = false
+					break
+				}
+			}
+			if duplicated {
+				return &pgconn.PgError{
+					Severity:       "ERROR",
+					Code:           databasehelper.PgUniqueViolation,
+					Message:        fmt.Sprintf("duplicate key value violates unique constraint %q", constraint.Name),
+					TableName:      r.definition.Table,
+					ConstraintName: constraint.Name,
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (r *repository[E, F]) rowSecurityViolation() error {
+	return &pgconn.PgError{
+		Severity:  "ERROR",
+		Code:      pgInsufficientPrivilege,
+		Message:   fmt.Sprintf("new row violates row-level security policy for table %q", r.definition.Table),
+		TableName: r.definition.Table,
+	}
+}
+
+// tenant returns the tenant owning row, empty for entities that are not tenant-owned
+func (r *repository[E, F]) tenant(row *E) string {
+	if r.schema.LookUpField(columnTenantID) == nil {
+		return ""
+	}
+	tenant, _ := r.value(row, columnTenantID).(string)
+	return tenant
+}
+
+func (r *repository[E, F]) value(row *E, column string) any {
+	value, _ := r.schema.LookUpField(column).ValueOf(context.Background(), reflect.ValueOf(row).Elem())
+	return value
+}
+
+func (r *repository[E, F]) set(ctx context.Context, row *E, column string, value any) error {
+	if _, ok := value.(clause.Expression); ok {
+		return fmt.Errorf("column %q: SQL expressions are not supported in memory", column)
+	}
+	return r.schema.LookUpField(column).Set(ctx, reflect.ValueOf(row).Elem(), value)
+}
+
+// now is the current time at the precision of a timestamptz
+func now() time.Time {
+	return time.Now().Truncate(time.Microsecond)
+}
+
+// compare orders a and b as postgres does, NULL after every value
+func compare(a, b any) int {
+	a, aNull := dereference(a)
+	b, bNull := dereference(b)
+	switch {
+	case aNull && bNull:
+		return 0
+	case aNull:
+		return 1
+	case bNull:
+		return -1
+	}
+
+	switch a := a.(type) {
+	case string:
+		return strings.Compare(a, b.(string))
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	case uuid.UUID:
+		bID := b.(uuid.UUID)
+		return slices.Compare(a[:], bID[:])
+	case bool:
+		switch bBool := b.(bool); {
+		case a == bBool:
+			return 0
+		case !a:
+			return -1
+		default:
+			return 1
+		}
+	}
+
+	av, bv := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch {
+	case av.CanInt():
+		return cmp.Compare(av.Int(), bv.Int())
+	case av.CanUint():
+		return cmp.Compare(av.Uint(), bv.Uint())
+	case av.CanFloat():
+		return cmp.Compare(av.Float(), bv.Float())
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// dereference returns the value v holds and whether it is NULL
+func dereference(v any) (any, bool) {
+	switch value := v.(type) {
+	case nil:
+		return nil, true
+	case gorm.DeletedAt:
+		return value.Time, !value.Valid
+	case sql.NullTime:
+		return value.Time, !value.Valid
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, true
+		}
+		return rv.Elem().Interface(), false
+	}
+	return v, false
+}
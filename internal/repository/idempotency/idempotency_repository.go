@@ -0,0 +1,20 @@
+package idempotencyrepository
+
+import (
+	"context"
+
+	"github.com/alxhtp/monogo/internal/entity"
+)
+
+type IdempotencyRepository interface {
+	// Acquire stores record as in flight, taking over an expired key or the abandoned lock of the same request.
+	// It returns false when the key is held by another request or already completed.
+	Acquire(ctx context.Context, record *entity.IdempotencyKey) (acquired bool, err error)
+	GetByKey(ctx context.Context, caller, key string) (output *entity.IdempotencyKey, err error)
+	// Complete stores the response of an in-flight key so it is replayed on retries
+	Complete(ctx context.Context, caller, key string, statusCode int, contentType string, body []byte) (err error)
+	// Release deletes an in-flight key so the request can be retried
+	Release(ctx context.Context, caller, key string) (err error)
+	// DeleteExpired deletes up to limit expired keys and returns how many were deleted
+	DeleteExpired(ctx context.Context, limit int) (deleted int64, err error)
+}
@@ -0,0 +1,106 @@
+package idempotencyrepositoryimplementation
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/alxhtp/monogo/internal/entity"
+	idempotencyrepository "github.com/alxhtp/monogo/internal/repository/idempotency"
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
+	"gorm.io/gorm"
+)
+
+type idempotencyRepository struct {
+	db          *gorm.DB
+	idempotency entity.IdempotencyKey
+}
+
+func NewIdempotencyRepository(db *gorm.DB) idempotencyrepository.IdempotencyRepository {
+	return &idempotencyRepository{db: db, idempotency: entity.IdempotencyKey{}}
+}
+
+func (r *idempotencyRepository) Acquire(ctx context.Context, record *entity.IdempotencyKey) (acquired bool, err error) {
+	if r.db == nil {
+		return false, errors.New("database connection is not initialized")
+	}
+
+	// the conditional upsert makes acquiring atomic: a live key is left untouched,
+	// an expired key is reused and the expired lock of a crashed request is taken over
+	table := r.idempotency.TableName()
+	result := databasehelper.DBFromContext(ctx, r.db).Exec(`
+		INSERT INTO `+table+` AS k (caller, key, method, path, request_hash, locked_until, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (tenant_id, caller, key) DO UPDATE SET
+			method = EXCLUDED.method,
+			path = EXCLUDED.path,
+			request_hash = EXCLUDED.request_hash,
+			status_code = NULL,
+			content_type = NULL,
+			response_body = NULL,
+			locked_until = EXCLUDED.locked_until,
+			expires_at = EXCLUDED.expires_at,
+			completed_at = NULL,
+			created_at = now()
+		WHERE k.expires_at <= now()
+			OR (k.status_code IS NULL AND k.locked_until <= now() AND k.request_hash = EXCLUDED.request_hash)`,
+		record.Caller, record.Key, record.Method, record.Path, record.RequestHash, record.LockedUntil, record.ExpiresAt,
+	)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (r *idempotencyRepository) GetByKey(ctx context.Context, caller, key string) (output *entity.IdempotencyKey, err error) {
+	if r.db == nil {
+		return nil, errors.New("database connection is not initialized")
+	}
+
+	// the key may have been completed by another instance, a replica could still miss it
+	ctx = databasehelper.WithPrimary(ctx)
+	if err := databasehelper.DBFromContext(ctx, r.db).Table(r.idempotency.TableName()).First(&output, "caller = ? AND key = ?", caller, key).Error; err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+func (r *idempotencyRepository) Complete(ctx context.Context, caller, key string, statusCode int, contentType string, body []byte) (err error) {
+	if r.db == nil {
+		return errors.New("database connection is not initialized")
+	}
+
+	return databasehelper.DBFromContext(ctx, r.db).Model(&r.idempotency).
+		Where("caller = ? AND key = ? AND status_code IS NULL", caller, key).
+		Updates(map[string]any{
+			"status_code":   statusCode,
+			"content_type":  contentType,
+			"response_body": body,
+			"completed_at":  time.Now(),
+		}).Error
+}
+
+func (r *idempotencyRepository) Release(ctx context.Context, caller, key string) (err error) {
+	if r.db == nil {
+		return errors.New("database connection is not initialized")
+	}
+
+	return databasehelper.DBFromContext(ctx, r.db).
+		Where("caller = ? AND key = ? AND status_code IS NULL", caller, key).
+		Delete(&r.idempotency).Error
+}
+
+func (r *idempotencyRepository) DeleteExpired(ctx context.Context, limit int) (deleted int64, err error) {
+	if r.db == nil {
+		return 0, errors.New("database connection is not initialized")
+	}
+
+	table := r.idempotency.TableName()
+	result := databasehelper.DBFromContext(ctx, r.db).Exec(`
+		DELETE FROM `+table+`
+		WHERE ctid IN (SELECT ctid FROM `+table+` WHERE expires_at <= now() LIMIT ?)`, limit)
+
+	return result.RowsAffected, result.Error
+}
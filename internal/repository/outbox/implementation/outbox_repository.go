@@ -0,0 +1,158 @@
+package outboxrepositoryimplementation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alxhtp/monogo/internal/entity"
+	outboxrepository "github.com/alxhtp/monogo/internal/repository/outbox"
+	"github.com/alxhtp/monogo/pkg/event"
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
+	"gorm.io/gorm"
+)
+
+// relayLockKey seeds the postgres advisory lock key held by the active outbox relay,
+// the key is derived from the schema so every tenant has its own relay lock
+const relayLockKey int64 = 0x6d6f6e6f676f01
+
+type outboxRepository struct {
+	db     *gorm.DB
+	outbox entity.OutboxMessage
+}
+
+func NewOutboxRepository(db *gorm.DB) outboxrepository.OutboxRepository {
+	return &outboxRepository{db: db, outbox: entity.OutboxMessage{}}
+}
+
+func (r *outboxRepository) Enqueue(ctx context.Context, events ...event.Event) (err error) {
+	if r.db == nil {
+		return errors.New("database connection is not initialized")
+	}
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	messages := make([]entity.OutboxMessage, len(events))
+	for i, evt := range events {
+		payload, err := json.Marshal(evt)
+		if err != nil {
+			return fmt.Errorf("marshal %s event: %w", evt.EventType(), err)
+		}
+
+		messages[i] = entity.OutboxMessage{
+			AggregateType: evt.AggregateType(),
+			AggregateID:   evt.AggregateID(),
+			EventType:     string(evt.EventType()),
+			Payload:       databasehelper.GormJsonType[json.RawMessage]{Item: payload},
+			Actor:         contexthelper.GetActor(ctx),
+			RequestID:     contexthelper.GetRequestID(ctx),
+			NextAttemptAt: time.Now(),
+		}
+	}
+
+	return databasehelper.DBFromContext(ctx, r.db).Create(&messages).Error
+}
+
+func (r *outboxRepository) AcquireRelayLock(ctx context.Context) (acquired bool, err error) {
+	if r.db == nil {
+		return false, errors.New("database connection is not initialized")
+	}
+
+	err = databasehelper.DBFromContext(ctx, r.db).Raw("SELECT pg_try_advisory_xact_lock(hashtextextended(current_schema(), ?))", relayLockKey).Scan(&acquired).Error
+	return acquired, err
+}
+
+func (r *outboxRepository) GetPending(ctx context.Context, limit int) (output []entity.OutboxMessage, err error) {
+	if r.db == nil {
+		return nil, errors.New("database connection is not initialized")
+	}
+
+	// only the head of every aggregate is eligible, so a failing message blocks
+	// later messages of the same aggregate and ordering is kept
+	table := r.outbox.TableName()
+	err = databasehelper.DBFromContext(ctx, r.db).Raw(`
+		SELECT * FROM (
+			SELECT DISTINCT ON (aggregate_type, aggregate_id) *
+			FROM `+table+`
+			WHERE published_at IS NULL
+			ORDER BY aggregate_type, aggregate_id, id
+		) heads
+		WHERE heads.next_attempt_at <= now()
+		ORDER BY heads.id
+		LIMIT ?`, limit).Scan(&output).Error
+
+	return output, err
+}
+
+func (r *outboxRepository) Claim(ctx context.Context, ids []int64, until time.Time) (err error) {
+	if r.db == nil {
+		return errors.New("database connection is not initialized")
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return databasehelper.DBFromContext(ctx, r.db).Model(&r.outbox).Where("id IN ?", ids).Updates(map[string]any{
+		"next_attempt_at": until,
+		"claimed_at":      time.Now(),
+	}).Error
+}
+
+func (r *outboxRepository) MarkPublished(ctx context.Context, id int64) (err error) {
+	if r.db == nil {
+		return errors.New("database connection is not initialized")
+	}
+
+	return databasehelper.DBFromContext(ctx, r.db).Model(&r.outbox).Where("id = ?", id).Updates(map[string]any{
+		"published_at": time.Now(),
+		"last_error":   nil,
+	}).Error
+}
+
+func (r *outboxRepository) MarkFailed(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time) (err error) {
+	if r.db == nil {
+		return errors.New("database connection is not initialized")
+	}
+
+	return databasehelper.DBFromContext(ctx, r.db).Model(&r.outbox).Where("id = ?", id).Updates(map[string]any{
+		"attempts":        gorm.Expr("attempts + 1"),
+		"last_error":      lastError,
+		"next_attempt_at": nextAttemptAt,
+	}).Error
+}
+
+func (r *outboxRepository) GetByID(ctx context.Context, id int64) (output *entity.OutboxMessage, err error) {
+	if r.db == nil {
+		return nil, errors.New("database connection is not initialized")
+	}
+
+	if err := databasehelper.DBFromContext(ctx, r.db).Table(r.outbox.TableName()).First(&output, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+func (r *outboxRepository) GetPublishedSince(ctx context.Context, aggregateType string, since time.Time, afterID int64, limit int) (output []entity.OutboxMessage, err error) {
+	if r.db == nil {
+		return nil, errors.New("database connection is not initialized")
+	}
+
+	table := r.outbox.TableName()
+	err = databasehelper.DBFromContext(ctx, r.db).
+		Where(table+".aggregate_type = ?", aggregateType).
+		Where(table+".published_at IS NOT NULL").
+		Where("("+table+".published_at, "+table+".id) > (?, ?)", since, afterID).
+		Order(table + ".published_at asc").
+		Order(table + ".id asc").
+		Limit(limit).
+		Find(&output).Error
+
+	return output, err
+}
@@ -0,0 +1,171 @@
+package outboxrepositorymemory
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/alxhtp/monogo/internal/entity"
+	outboxrepository "github.com/alxhtp/monogo/internal/repository/outbox"
+	"github.com/alxhtp/monogo/pkg/event"
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
+	"gorm.io/gorm"
+)
+
+type outboxRepository struct {
+	mu sync.Mutex
+	// messages are kept in the order of their ids
+	messages []entity.OutboxMessage
+}
+
+// NewOutboxRepository returns an empty in-memory outbox, for tests that should not need a database
+func NewOutboxRepository() outboxrepository.OutboxRepository {
+	return &outboxRepository{}
+}
+
+func (r *outboxRepository) Enqueue(ctx context.Context, events ...event.Event) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, evt := range events {
+		payload, err := json.Marshal(evt)
+		if err != nil {
+			return fmt.Errorf("marshal %s event: %w", evt.EventType(), err)
+		}
+
+		r.messages = append(r.messages, entity.OutboxMessage{
+			ID:            int64(len(r.messages) + 1),
+			AggregateType: evt.AggregateType(),
+			AggregateID:   evt.AggregateID(),
+			EventType:     string(evt.EventType()),
+			Payload:       databasehelper.GormJsonType[json.RawMessage]{Item: payload},
+			Actor:         contexthelper.GetActor(ctx),
+			RequestID:     contexthelper.GetRequestID(ctx),
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		})
+	}
+
+	return nil
+}
+
+// AcquireRelayLock always succeeds, the lock is only needed between processes
+func (r *outboxRepository) AcquireRelayLock(ctx context.Context) (acquired bool, err error) {
+	return true, nil
+}
+
+func (r *outboxRepository) GetPending(ctx context.Context, limit int) (output []entity.OutboxMessage, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// only the head of every aggregate is eligible, as in the GORM repository
+	now := time.Now()
+	heads := make(map[[2]string]bool)
+	for _, message := range r.messages {
+		aggregate := [2]string{message.AggregateType, message.AggregateID}
+		if message.PublishedAt != nil || heads[aggregate] {
+			continue
+		}
+		heads[aggregate] = true
+
+		if !message.NextAttemptAt.After(now) && len(output) < limit {
+			output = append(output, message)
+		}
+	}
+
+	return output, nil
+}
+
+func (r *outboxRepository) Claim(ctx context.Context, ids []int64, until time.Time) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	claimedAt := time.Now()
+	for _, id := range ids {
+		if message := r.find(id); message != nil {
+			message.NextAttemptAt = until
+			message.ClaimedAt = &claimedAt
+		}
+	}
+
+	return nil
+}
+
+func (r *outboxRepository) MarkPublished(ctx context.Context, id int64) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if message := r.find(id); message != nil {
+		publishedAt := time.Now()
+		message.PublishedAt = &publishedAt
+		message.LastError = ""
+	}
+
+	return nil
+}
+
+func (r *outboxRepository) MarkFailed(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if message := r.find(id); message != nil {
+		message.Attempts++
+		message.LastError = lastError
+		message.NextAttemptAt = nextAttemptAt
+	}
+
+	return nil
+}
+
+func (r *outboxRepository) GetByID(ctx context.Context, id int64) (output *entity.OutboxMessage, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	message := r.find(id)
+	if message == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	output = new(entity.OutboxMessage)
+	*output = *message
+	return output, nil
+}
+
+func (r *outboxRepository) GetPublishedSince(ctx context.Context, aggregateType string, since time.Time, afterID int64, limit int) (output []entity.OutboxMessage, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, message := range r.messages {
+		if message.AggregateType != aggregateType || message.PublishedAt == nil {
+			continue
+		}
+		if message.PublishedAt.Before(since) || message.PublishedAt.Equal(since) && message.ID <= afterID {
+			continue
+		}
+		output = append(output, message)
+	}
+
+	slices.SortFunc(output, func(a, b entity.OutboxMessage) int {
+		if c := a.PublishedAt.Compare(*b.PublishedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	if len(output) > limit {
+		output = output[:limit]
+	}
+	return output, nil
+}
+
+func (r *outboxRepository) find(id int64) *entity.OutboxMessage {
+	if id < 1 || id > int64(len(r.messages)) {
+		return nil
+	}
+	return &r.messages[id-1]
+}
@@ -0,0 +1,27 @@
+package outboxrepository
+
+import (
+	"context"
+	"time"
+
+	"github.com/alxhtp/monogo/internal/entity"
+	"github.com/alxhtp/monogo/pkg/event"
+)
+
+type OutboxRepository interface {
+	// Enqueue writes events into the outbox, call it inside the transaction of the change
+	Enqueue(ctx context.Context, events ...event.Event) (err error)
+	// AcquireRelayLock takes a transaction scoped lock so only one relay publishes at a time
+	AcquireRelayLock(ctx context.Context) (acquired bool, err error)
+	// GetPending returns the oldest unpublished message of every aggregate that is due
+	GetPending(ctx context.Context, limit int) (output []entity.OutboxMessage, err error)
+	// Claim defers the next attempt of messages to until, so no relay takes them again while they are published
+	Claim(ctx context.Context, ids []int64, until time.Time) (err error)
+	MarkPublished(ctx context.Context, id int64) (err error)
+	MarkFailed(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time) (err error)
+	GetByID(ctx context.Context, id int64) (output *entity.OutboxMessage, err error)
+	// GetPublishedSince returns published messages of aggregateType in publish order, ids are not in publish order
+	// since retried messages are published late. It starts after the message published at since with the id afterID,
+	// a zero afterID starts with the messages published after since.
+	GetPublishedSince(ctx context.Context, aggregateType string, since time.Time, afterID int64, limit int) (output []entity.OutboxMessage, err error)
+}
@@ -0,0 +1,129 @@
+package tenantrepositoryimplementation
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/alxhtp/monogo/internal/entity"
+	tenantrepository "github.com/alxhtp/monogo/internal/repository/tenant"
+	"github.com/alxhtp/monogo/migration"
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
+	"gorm.io/gorm"
+)
+
+// tenantSchemaPattern matches the tenant schemas, underscore is a LIKE wildcard so it is escaped
+const tenantSchemaPattern = `tenant\_%`
+
+// tenantRepository reads the tenants from the catalog through the shared connection, never through a tenant one.
+// Tenant ids are validated before they are used in a schema name. Concurrent provisioning of a tenant is serialized
+// by the migration lock of its schema, a single one of them reports the tenant as created.
+type tenantRepository struct {
+	db *gorm.DB
+}
+
+func NewTenantRepository(db *gorm.DB) tenantrepository.TenantRepository {
+	return &tenantRepository{db: db}
+}
+
+func (r *tenantRepository) Provision(ctx context.Context, id string) (output *entity.Tenant, created bool, err error) {
+	if r.db == nil {
+		return nil, false, errors.New("database connection is not initialized")
+	}
+	if err := databasehelper.ValidateTenantID(id); err != nil {
+		return nil, false, err
+	}
+
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return nil, false, err
+	}
+	_, created, err = migration.UpSchema(ctx, sqlDB, databasehelper.TenantSchema(id))
+	if err != nil {
+		return nil, false, err
+	}
+
+	output, err = r.GetByID(ctx, id)
+	return output, created, err
+}
+
+func (r *tenantRepository) GetByID(ctx context.Context, id string) (output *entity.Tenant, err error) {
+	if r.db == nil {
+		return nil, errors.New("database connection is not initialized")
+	}
+	if err := databasehelper.ValidateTenantID(id); err != nil {
+		return nil, err
+	}
+
+	// a tenant provisioned by another instance must be found right away, the catalog is read from the primary
+	ctx = databasehelper.WithPrimary(ctx)
+	var schemas []string
+	if err := r.db.WithContext(ctx).Raw(
+		"SELECT schema_name FROM information_schema.schemata WHERE schema_name = ?", databasehelper.TenantSchema(id),
+	).Scan(&schemas).Error; err != nil {
+		return nil, err
+	}
+	if len(schemas) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return r.load(ctx, id)
+}
+
+func (r *tenantRepository) GetAll(ctx context.Context) (output []entity.Tenant, err error) {
+	if r.db == nil {
+		return nil, errors.New("database connection is not initialized")
+	}
+
+	ctx = databasehelper.WithPrimary(ctx)
+	var schemas []string
+	if err := r.db.WithContext(ctx).Raw(
+		"SELECT schema_name FROM information_schema.schemata WHERE schema_name LIKE ? ORDER BY schema_name", tenantSchemaPattern,
+	).Scan(&schemas).Error; err != nil {
+		return nil, err
+	}
+
+	output = make([]entity.Tenant, 0, len(schemas))
+	for _, schema := range schemas {
+		// schemas created by hand with a name that is not a valid tenant are not tenants
+		id, ok := databasehelper.TenantFromSchema(schema)
+		if !ok {
+			continue
+		}
+
+		tenant, err := r.load(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		output = append(output, *tenant)
+	}
+
+	return output, nil
+}
+
+// load reads the migration state of the schema of a validated tenant id
+func (r *tenantRepository) load(ctx context.Context, id string) (*entity.Tenant, error) {
+	schema := databasehelper.TenantSchema(id)
+	output := &entity.Tenant{ID: id, Schema: schema}
+
+	table := `"` + schema + `".` + migration.TableName
+	var exists bool
+	if err := r.db.WithContext(ctx).Raw("SELECT to_regclass(?) IS NOT NULL", table).Scan(&exists).Error; err != nil {
+		return nil, err
+	}
+	if !exists {
+		return output, nil
+	}
+
+	var state struct {
+		Count     int
+		AppliedAt *time.Time
+	}
+	if err := r.db.WithContext(ctx).Raw("SELECT count(*) AS count, max(applied_at) AS applied_at FROM " + table).Scan(&state).Error; err != nil {
+		return nil, err
+	}
+	output.Migrations = state.Count
+	output.MigratedAt = state.AppliedAt
+
+	return output, nil
+}
@@ -0,0 +1,55 @@
+package tenantrepositoryimplementation_test
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	tenantrepository "github.com/alxhtp/monogo/internal/repository/tenant/implementation"
+	"github.com/alxhtp/monogo/migration/migrationtest"
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
+)
+
+func TestProvisionCreatesThenUpgradesTheTenantSchema(t *testing.T) {
+	db := migrationtest.OpenSchema(t)
+	repo := tenantrepository.NewTenantRepository(db)
+	ctx := context.Background()
+
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	id := "test_" + hex.EncodeToString(suffix)
+	t.Cleanup(func() { db.Exec(`DROP SCHEMA IF EXISTS "` + databasehelper.TenantSchema(id) + `" CASCADE`) })
+
+	tenant, created, err := repo.Provision(ctx, id)
+	if err != nil {
+		t.Fatalf("provision: %v", err)
+	}
+	if !created || tenant.Schema != databasehelper.TenantSchema(id) || tenant.Migrations == 0 || tenant.MigratedAt == nil {
+		t.Fatalf("provision: got %+v, created %t, want a migrated new tenant", tenant, created)
+	}
+
+	again, created, err := repo.Provision(ctx, id)
+	if err != nil {
+		t.Fatalf("provision again: %v", err)
+	}
+	if created || again.Migrations != tenant.Migrations {
+		t.Fatalf("provision again: got %+v, created %t, want the same tenant", again, created)
+	}
+
+	tenants, err := repo.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("get all: %v", err)
+	}
+	found := false
+	for _, listed := range tenants {
+		found = found || listed.ID == id
+	}
+	if !found {
+		t.Fatalf("tenant %s is not listed", id)
+	}
+
+	if _, _, err := repo.Provision(ctx, "Invalid-Tenant"); err == nil {
+		t.Fatal("an invalid tenant id was provisioned")
+	}
+}
@@ -0,0 +1,105 @@
+package tenantrepositoryrow
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/alxhtp/monogo/internal/entity"
+	tenantrepository "github.com/alxhtp/monogo/internal/repository/tenant"
+	"github.com/alxhtp/monogo/migration"
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
+	"gorm.io/gorm"
+)
+
+const tableName = "tenants"
+
+// tenantRepository registers the tenants of the row level mode in the tenants table of the shared schema.
+// Every tenant shares the schema and its migrations, which are applied with the migration CLI, not per tenant.
+type tenantRepository struct {
+	db *gorm.DB
+}
+
+func NewTenantRepository(db *gorm.DB) tenantrepository.TenantRepository {
+	return &tenantRepository{db: db}
+}
+
+func (r *tenantRepository) Provision(ctx context.Context, id string) (output *entity.Tenant, created bool, err error) {
+	if r.db == nil {
+		return nil, false, errors.New("database connection is not initialized")
+	}
+	if err := databasehelper.ValidateTenantID(id); err != nil {
+		return nil, false, err
+	}
+
+	result := r.db.WithContext(ctx).Exec("INSERT INTO "+tableName+" (id) VALUES (?) ON CONFLICT (id) DO NOTHING", id)
+	if result.Error != nil {
+		return nil, false, result.Error
+	}
+
+	output, err = r.GetByID(ctx, id)
+	return output, result.RowsAffected == 1, err
+}
+
+func (r *tenantRepository) GetByID(ctx context.Context, id string) (output *entity.Tenant, err error) {
+	if r.db == nil {
+		return nil, errors.New("database connection is not initialized")
+	}
+	if err := databasehelper.ValidateTenantID(id); err != nil {
+		return nil, err
+	}
+
+	// a tenant registered by another instance must be found right away, the registry is read from the primary
+	ctx = databasehelper.WithPrimary(ctx)
+	var ids []string
+	if err := r.db.WithContext(ctx).Raw("SELECT id FROM "+tableName+" WHERE id = ?", id).Scan(&ids).Error; err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	tenants, err := r.withMigrations(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return &tenants[0], nil
+}
+
+func (r *tenantRepository) GetAll(ctx context.Context) (output []entity.Tenant, err error) {
+	if r.db == nil {
+		return nil, errors.New("database connection is not initialized")
+	}
+
+	ctx = databasehelper.WithPrimary(ctx)
+	var ids []string
+	if err := r.db.WithContext(ctx).Raw("SELECT id FROM " + tableName + " ORDER BY id").Scan(&ids).Error; err != nil {
+		return nil, err
+	}
+
+	return r.withMigrations(ctx, ids)
+}
+
+// withMigrations returns the tenants of ids with the migration state of the shared schema
+func (r *tenantRepository) withMigrations(ctx context.Context, ids []string) ([]entity.Tenant, error) {
+	var state struct {
+		Count     int
+		AppliedAt *time.Time
+	}
+
+	var exists bool
+	if err := r.db.WithContext(ctx).Raw("SELECT to_regclass(?) IS NOT NULL", migration.TableName).Scan(&exists).Error; err != nil {
+		return nil, err
+	}
+	if exists {
+		if err := r.db.WithContext(ctx).Raw("SELECT count(*) AS count, max(applied_at) AS applied_at FROM " + migration.TableName).Scan(&state).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	output := make([]entity.Tenant, len(ids))
+	for i, id := range ids {
+		output[i] = entity.Tenant{ID: id, Schema: databasehelper.SharedSchema, Migrations: state.Count, MigratedAt: state.AppliedAt}
+	}
+	return output, nil
+}
@@ -0,0 +1,15 @@
+package tenantrepository
+
+import (
+	"context"
+
+	"github.com/alxhtp/monogo/internal/entity"
+)
+
+type TenantRepository interface {
+	// Provision registers the tenant when missing, creating its schema and applying the pending migrations to it
+	// in the schema-per-tenant mode. It is safe to run again, e.g. to upgrade a tenant. created reports whether the tenant was new.
+	Provision(ctx context.Context, id string) (output *entity.Tenant, created bool, err error)
+	GetByID(ctx context.Context, id string) (output *entity.Tenant, err error)
+	GetAll(ctx context.Context) (output []entity.Tenant, err error)
+}
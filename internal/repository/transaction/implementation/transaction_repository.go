@@ -0,0 +1,26 @@
+package transactionrepositoryimplementation
+
+import (
+	"context"
+	"errors"
+
+	transactionrepository "github.com/alxhtp/monogo/internal/repository/transaction"
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
+	"gorm.io/gorm"
+)
+
+type transactionRepository struct {
+	db *gorm.DB
+}
+
+func NewTransactionRepository(db *gorm.DB) transactionrepository.TransactionRepository {
+	return &transactionRepository{db: db}
+}
+
+func (r *transactionRepository) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if r.db == nil {
+		return errors.New("database connection is not initialized")
+	}
+
+	return databasehelper.WithTransaction(ctx, r.db, fn)
+}
@@ -0,0 +1,19 @@
+package transactionrepositorymemory
+
+import (
+	"context"
+
+	transactionrepository "github.com/alxhtp/monogo/internal/repository/transaction"
+)
+
+type transactionRepository struct{}
+
+// NewTransactionRepository returns the transaction repository of the in-memory repositories, it runs the steps
+// one after the other. Nothing is rolled back, the writes of the steps before a failing step are kept.
+func NewTransactionRepository() transactionrepository.TransactionRepository {
+	return &transactionRepository{}
+}
+
+func (r *transactionRepository) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
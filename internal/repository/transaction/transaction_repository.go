@@ -0,0 +1,9 @@
+package transactionrepository
+
+import "context"
+
+// TransactionRepository runs usecase steps atomically. Repositories called with the
+// context passed to fn join the transaction.
+type TransactionRepository interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,249 @@
+package userrepositorycache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/alxhtp/monogo/config"
+	"github.com/alxhtp/monogo/internal/entity"
+	entitybase "github.com/alxhtp/monogo/internal/entity/base"
+	userrepository "github.com/alxhtp/monogo/internal/repository/user"
+	"github.com/alxhtp/monogo/pkg/cache"
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
+	"github.com/alxhtp/monogo/pkg/metrics"
+	"github.com/google/uuid"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	defaultCacheTTL = time.Minute
+
+	keyPrefix           = "user:"
+	filterGenerationKey = "filter:generation"
+	idGenerationKey     = "id:generation:"
+
+	cacheByID     = "user_by_id"
+	cacheByFilter = "user_by_filter"
+)
+
+// userRepository caches users by id and filter results by a hash of the filter. Updates and deletions move the user
+// to a new generation, every write moves the filter results to a new generation so stale pages are never served.
+// A fetch that began before a write caches its result under the previous generation, which is never read again.
+// Reads inside a transaction bypass the cache, they may see uncommitted changes.
+type userRepository struct {
+	next   userrepository.UserRepository
+	store  cache.Store
+	ttl    time.Duration
+	group  singleflight.Group
+	logger *slog.Logger
+}
+
+type filterResult struct {
+	Users      []entity.User                   `json:"users"`
+	Pagination entitybase.BasePaginationResult `json:"pagination"`
+}
+
+func NewUserRepository(next userrepository.UserRepository, store cache.Store, cfg config.CacheConfig) userrepository.UserRepository {
+	ttl, err := time.ParseDuration(cfg.CacheTTL)
+	if err != nil || ttl <= 0 {
+		ttl = defaultCacheTTL
+	}
+
+	return &userRepository{
+		next:   next,
+		store:  store,
+		ttl:    ttl,
+		logger: slog.Default().With("cache", "user"),
+	}
+}
+
+func (r *userRepository) Create(ctx context.Context, user *entity.User) (output *entity.User, err error) {
+	output, err = r.next.Create(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	r.invalidate(ctx)
+	return output, nil
+}
+
+func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (output *entity.User, err error) {
+	if databasehelper.InTransaction(ctx) {
+		return r.next.GetByID(ctx, id)
+	}
+
+	key, err := r.idKey(ctx, id)
+	if err != nil {
+		r.logger.WarnContext(ctx, "user cache unavailable", "error", err.Error())
+		return r.next.GetByID(ctx, id)
+	}
+
+	err = r.load(ctx, cacheByID, key, &output, func(ctx context.Context) (any, error) {
+		return r.next.GetByID(ctx, id)
+	})
+	return output, err
+}
+
+func (r *userRepository) GetByFilter(ctx context.Context, filter *entity.UserFilter) (output []entity.User, paginationResult entitybase.BasePaginationResult, err error) {
+	if databasehelper.InTransaction(ctx) {
+		return r.next.GetByFilter(ctx, filter)
+	}
+
+	key, err := r.filterKey(ctx, filter)
+	if err != nil {
+		r.logger.WarnContext(ctx, "user cache unavailable", "error", err.Error())
+		return r.next.GetByFilter(ctx, filter)
+	}
+
+	var result filterResult
+	err = r.load(ctx, cacheByFilter, key, &result, func(ctx context.Context) (any, error) {
+		users, pagination, err := r.next.GetByFilter(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		return filterResult{Users: users, Pagination: pagination}, nil
+	})
+	return result.Users, result.Pagination, err
+}
+
+func (r *userRepository) Update(ctx context.Context, id uuid.UUID, updateMap map[string]any) (output *entity.User, err error) {
+	output, err = r.next.Update(ctx, id, updateMap)
+	if err != nil {
+		return nil, err
+	}
+
+	r.invalidate(ctx, id)
+	return output, nil
+}
+
+func (r *userRepository) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	if err := r.next.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	r.invalidate(ctx, id)
+	return nil
+}
+
+// load decodes the cached value of key into out, or fetches it once for all concurrent callers and caches it.
+// Errors of the store are logged and the value is fetched, errors of fetch are not cached. fetch reads from the primary,
+// a lagging replica would otherwise cache a value older than the write that just invalidated it. fetch does not end
+// with the context of the caller that started it, a caller giving up does not fail the others waiting for it.
+func (r *userRepository) load(ctx context.Context, cacheName, key string, out any, fetch func(ctx context.Context) (any, error)) error {
+	value, found, err := r.store.Get(ctx, key)
+	if err != nil {
+		r.logger.WarnContext(ctx, "user cache unavailable", "error", err.Error())
+	}
+	if found {
+		metrics.CacheRequestsTotal.WithLabelValues(cacheName, metrics.CacheHit).Inc()
+		return json.Unmarshal(value, out)
+	}
+	metrics.CacheRequestsTotal.WithLabelValues(cacheName, metrics.CacheMiss).Inc()
+
+	// every caller decodes its own copy, so a caller mutating its result does not affect the others
+	shared := r.group.DoChan(key, func() (any, error) {
+		ctx := context.WithoutCancel(ctx)
+
+		fetched, err := fetch(databasehelper.WithPrimary(ctx))
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := json.Marshal(fetched)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.store.Set(ctx, key, value, r.ttl); err != nil {
+			r.logger.WarnContext(ctx, "user cache unavailable", "error", err.Error())
+		}
+		return value, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case result := <-shared:
+		if result.Err != nil {
+			return result.Err
+		}
+		return json.Unmarshal(result.Val.([]byte), out)
+	}
+}
+
+// idKey returns the key of the user of id under its current generation
+func (r *userRepository) idKey(ctx context.Context, id uuid.UUID) (string, error) {
+	generation, err := r.generation(ctx, prefix(ctx)+idGenerationKey+id.String(), r.ttl)
+	if err != nil {
+		return "", err
+	}
+
+	return prefix(ctx) + "id:" + string(generation) + ":" + id.String(), nil
+}
+
+// filterKey hashes the filter under the current generation of filter results
+func (r *userRepository) filterKey(ctx context.Context, filter *entity.UserFilter) (string, error) {
+	generation, err := r.generation(ctx, prefix(ctx)+filterGenerationKey, 0)
+	if err != nil {
+		return "", err
+	}
+
+	encoded, err := json.Marshal(filter)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(encoded)
+
+	return prefix(ctx) + "filter:" + string(generation) + ":" + hex.EncodeToString(hash[:]), nil
+}
+
+// generation returns the generation stored under key, ttl bounds the generations of users that are no longer read
+func (r *userRepository) generation(ctx context.Context, key string, ttl time.Duration) ([]byte, error) {
+	generation, found, err := r.store.Get(ctx, key)
+	if err != nil || found {
+		return generation, err
+	}
+
+	// a new generation, rather than a default one, so entries of an evicted generation are not served again
+	return r.nextGeneration(ctx, key, ttl)
+}
+
+func (r *userRepository) nextGeneration(ctx context.Context, key string, ttl time.Duration) ([]byte, error) {
+	generation := []byte(strconv.FormatInt(time.Now().UnixNano(), 36))
+	return generation, r.store.Set(ctx, key, generation, ttl)
+}
+
+// invalidate moves the users of ids and every filter result to a new generation, again once the transaction of ctx
+// is committed so a read between the write and the commit cannot cache the previous state for long
+func (r *userRepository) invalidate(ctx context.Context, ids ...uuid.UUID) {
+	evict := func() {
+		// the commit hook runs after the request context may be done
+		ctx := context.WithoutCancel(ctx)
+		for _, id := range ids {
+			if _, err := r.nextGeneration(ctx, prefix(ctx)+idGenerationKey+id.String(), r.ttl); err != nil {
+				r.logger.ErrorContext(ctx, "user cache invalidation failed", "error", err.Error())
+			}
+		}
+		if _, err := r.nextGeneration(ctx, prefix(ctx)+filterGenerationKey, 0); err != nil {
+			r.logger.ErrorContext(ctx, "user cache invalidation failed", "error", err.Error())
+		}
+	}
+
+	evict()
+	if databasehelper.InTransaction(ctx) {
+		databasehelper.AfterCommit(ctx, evict)
+	}
+}
+
+// prefix scopes the keys to the tenant of ctx, tenants never share entries
+func prefix(ctx context.Context) string {
+	if tenant := contexthelper.GetTenant(ctx); tenant != "" {
+		return keyPrefix + tenant + ":"
+	}
+	return keyPrefix
+}
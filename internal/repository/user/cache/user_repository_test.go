@@ -0,0 +1,145 @@
+package userrepositorycache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alxhtp/monogo/config"
+	"github.com/alxhtp/monogo/internal/entity"
+	userrepository "github.com/alxhtp/monogo/internal/repository/user"
+	userrepositorycache "github.com/alxhtp/monogo/internal/repository/user/cache"
+	userrepositoryconformance "github.com/alxhtp/monogo/internal/repository/user/conformance"
+	userrepositorymemory "github.com/alxhtp/monogo/internal/repository/user/memory"
+	"github.com/alxhtp/monogo/pkg/cache"
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func TestUserRepositoryConformance(t *testing.T) {
+	userrepositoryconformance.Run(t, func(t *testing.T) userrepository.UserRepository {
+		return userrepositorycache.NewUserRepository(userrepositorymemory.NewUserRepository(), cache.NewLRU(100), config.CacheConfig{})
+	})
+}
+
+func TestUserRepositoryScopesKeysToTenant(t *testing.T) {
+	store := cache.NewLRU(100)
+	repo := userrepositorycache.NewUserRepository(userrepositorymemory.NewUserRepository(), store, config.CacheConfig{})
+	acme := contexthelper.WithTenant(context.Background(), "acme")
+	globex := contexthelper.WithTenant(context.Background(), "globex")
+
+	user, err := repo.Create(acme, &entity.User{Name: "Alice", Email: "alice@example.com"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := repo.GetByID(acme, user.ID); err != nil {
+		t.Fatalf("get by id: %v", err)
+	}
+
+	for key, want := range map[string]bool{
+		"user:acme:id:generation:" + user.ID.String():   true,
+		"user:globex:id:generation:" + user.ID.String(): false,
+		"user:id:generation:" + user.ID.String():        false,
+	} {
+		if _, found, _ := store.Get(context.Background(), key); found != want {
+			t.Errorf("%s cached = %t, want %t", key, found, want)
+		}
+	}
+
+	if _, err := repo.GetByID(globex, user.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("get by id from another tenant: got %v, want ErrRecordNotFound", err)
+	}
+}
+
+// slowRepository holds GetByID after reading the user until release is closed
+type slowRepository struct {
+	userrepository.UserRepository
+
+	fetched chan struct{}
+	release chan struct{}
+}
+
+func newSlowRepository() *slowRepository {
+	return &slowRepository{
+		UserRepository: userrepositorymemory.NewUserRepository(),
+		fetched:        make(chan struct{}, 1),
+		release:        make(chan struct{}),
+	}
+}
+
+func (r *slowRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+	user, err := r.UserRepository.GetByID(ctx, id)
+	r.fetched <- struct{}{}
+	<-r.release
+	return user, err
+}
+
+func TestUserRepositoryDoesNotCacheAFetchOlderThanAWrite(t *testing.T) {
+	ctx := context.Background()
+	next := newSlowRepository()
+	repo := userrepositorycache.NewUserRepository(next, cache.NewLRU(100), config.CacheConfig{CacheTTL: "1h"})
+
+	user, err := next.Create(ctx, &entity.User{Name: "Alice", Email: "alice@example.com"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := repo.GetByID(ctx, user.ID)
+		done <- err
+	}()
+	// the user is read before the update and cached after it
+	<-next.fetched
+	if _, err := repo.Update(ctx, user.ID, map[string]any{"name": "Alicia"}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	close(next.release)
+	if err := <-done; err != nil {
+		t.Fatalf("get by id: %v", err)
+	}
+
+	got, err := repo.GetByID(ctx, user.ID)
+	<-next.fetched
+	if err != nil || got.Name != "Alicia" {
+		t.Fatalf("get by id = %+v, %v, want the updated user", got, err)
+	}
+}
+
+func TestUserRepositoryFetchOutlivesACancelledCaller(t *testing.T) {
+	ctx := context.Background()
+	next := newSlowRepository()
+	repo := userrepositorycache.NewUserRepository(next, cache.NewLRU(100), config.CacheConfig{})
+
+	user, err := next.Create(ctx, &entity.User{Name: "Alice", Email: "alice@example.com"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	first := make(chan error, 1)
+	go func() {
+		_, err := repo.GetByID(cancelled, user.ID)
+		first <- err
+	}()
+	<-next.fetched
+
+	second := make(chan *entity.User, 1)
+	go func() {
+		got, _ := repo.GetByID(ctx, user.ID)
+		second <- got
+	}()
+
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled caller: got %v, want context.Canceled", err)
+	}
+	// the second caller joined the fetch of the first one
+	time.Sleep(10 * time.Millisecond)
+	close(next.release)
+	if got := <-second; got == nil || got.ID != user.ID {
+		t.Fatalf("waiting caller = %+v, want the user", got)
+	}
+}
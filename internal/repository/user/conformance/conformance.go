@@ -0,0 +1,459 @@
+// Package userrepositoryconformance is the behaviour every user repository implements, run by the tests of the GORM
+// and the in-memory implementations so the two stay in sync.
+package userrepositoryconformance
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alxhtp/monogo/internal/entity"
+	entitybase "github.com/alxhtp/monogo/internal/entity/base"
+	userrepository "github.com/alxhtp/monogo/internal/repository/user"
+	"github.com/alxhtp/monogo/pkg/constant"
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// NewRepository returns an empty user repository, its rows are dropped once t ends
+type NewRepository func(t *testing.T) userrepository.UserRepository
+
+// Run runs the conformance suite against the repositories of newRepository, each test gets a repository of its own.
+// Names are lower case and ordered the same by every collation.
+func Run(t *testing.T, newRepository NewRepository) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, repo userrepository.UserRepository)
+	}{
+		{"CreateAndGetByID", testCreateAndGetByID},
+		{"GetByIDMissing", testGetByIDMissing},
+		{"CreateDuplicateEmail", testCreateDuplicateEmail},
+		{"Update", testUpdate},
+		{"UpdateMissing", testUpdateMissing},
+		{"UpdateDuplicateEmail", testUpdateDuplicateEmail},
+		{"SoftDelete", testSoftDelete},
+		{"FilterFields", testFilterFields},
+		{"FilterLikeSemantics", testFilterLikeSemantics},
+		{"FilterTimeRange", testFilterTimeRange},
+		{"Order", testOrder},
+		{"LimitAndOffset", testLimitAndOffset},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newRepository(t))
+		})
+	}
+}
+
+func testCreateAndGetByID(t *testing.T, repo userrepository.UserRepository) {
+	ctx := context.Background()
+	user := newUser("alice", "alice@example.com")
+
+	created, err := repo.Create(ctx, &user)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if created.ID == uuid.Nil {
+		t.Fatal("created user has no id")
+	}
+	if user.ID != created.ID {
+		t.Fatalf("id of the created entity = %s, want %s", user.ID, created.ID)
+	}
+	if created.CreatedAt.IsZero() || created.UpdatedAt.IsZero() {
+		t.Fatalf("created user has no timestamps: %+v", created.BaseTime)
+	}
+	if created.DeletedAt.Valid {
+		t.Fatal("created user is deleted")
+	}
+	assertUser(t, created, user)
+
+	got, err := repo.GetByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("get by id: %v", err)
+	}
+	assertUser(t, got, user)
+	if !got.CreatedAt.Equal(created.CreatedAt) {
+		t.Fatalf("created_at = %v, want %v", got.CreatedAt, created.CreatedAt)
+	}
+}
+
+func testGetByIDMissing(t *testing.T, repo userrepository.UserRepository) {
+	if _, err := repo.GetByID(context.Background(), uuid.New()); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("get by id of a missing user: got %v, want ErrRecordNotFound", err)
+	}
+}
+
+func testCreateDuplicateEmail(t *testing.T, repo userrepository.UserRepository) {
+	ctx := context.Background()
+	alice := create(t, repo, newUser("alice", "alice@example.com"))
+
+	duplicate := newUser("alice again", "alice@example.com")
+	if _, err := repo.Create(ctx, &duplicate); !databasehelper.IsUniqueViolation(err) {
+		t.Fatalf("create with a duplicate email: got %v, want a unique violation", err)
+	}
+
+	// deleted users keep their email
+	if err := repo.Delete(ctx, alice.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := repo.Create(ctx, &duplicate); !databasehelper.IsUniqueViolation(err) {
+		t.Fatalf("create with the email of a deleted user: got %v, want a unique violation", err)
+	}
+
+	// emails are case sensitive
+	if _, err := repo.Create(ctx, ptr(newUser("alice", "Alice@example.com"))); err != nil {
+		t.Fatalf("create with an email differing in case: %v", err)
+	}
+}
+
+func testUpdate(t *testing.T, repo userrepository.UserRepository) {
+	ctx := context.Background()
+	alice := create(t, repo, newUser("alice", "alice@example.com"))
+
+	// the values of the update map are those of the user serializer
+	name, email, status := "alicia", "alicia@example.com", int(constant.UserStatusBanned)
+	metadata := entity.UserMetadata{Sex: "female", Address: "2 Main St", Phone: "+14155550199"}
+	updated, err := repo.Update(ctx, alice.ID, map[string]any{
+		"name":     &name,
+		"email":    &email,
+		"status":   &status,
+		"metadata": databasehelper.GormJsonType[entity.UserMetadata]{Item: metadata},
+	})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	want := alice
+	want.Name, want.Email, want.Status, want.Metadata.Item = name, email, constant.UserStatusBanned, metadata
+	assertUser(t, updated, want)
+	if !updated.CreatedAt.Equal(alice.CreatedAt) {
+		t.Fatalf("created_at changed from %v to %v", alice.CreatedAt, updated.CreatedAt)
+	}
+	if updated.UpdatedAt.Before(alice.UpdatedAt) {
+		t.Fatalf("updated_at went back from %v to %v", alice.UpdatedAt, updated.UpdatedAt)
+	}
+
+	got, err := repo.GetByID(ctx, alice.ID)
+	if err != nil {
+		t.Fatalf("get by id: %v", err)
+	}
+	assertUser(t, got, want)
+
+	// columns left out of the map keep their value
+	updated, err = repo.Update(ctx, alice.ID, map[string]any{"name": "ali"})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	want.Name = "ali"
+	assertUser(t, updated, want)
+}
+
+func testUpdateMissing(t *testing.T, repo userrepository.UserRepository) {
+	if _, err := repo.Update(context.Background(), uuid.New(), map[string]any{"name": "nobody"}); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("update of a missing user: got %v, want ErrRecordNotFound", err)
+	}
+}
+
+func testUpdateDuplicateEmail(t *testing.T, repo userrepository.UserRepository) {
+	ctx := context.Background()
+	create(t, repo, newUser("alice", "alice@example.com"))
+	bob := create(t, repo, newUser("bob", "bob@example.com"))
+
+	if _, err := repo.Update(ctx, bob.ID, map[string]any{"email": "alice@example.com"}); !databasehelper.IsUniqueViolation(err) {
+		t.Fatalf("update to a duplicate email: got %v, want a unique violation", err)
+	}
+
+	got, err := repo.GetByID(ctx, bob.ID)
+	if err != nil {
+		t.Fatalf("get by id: %v", err)
+	}
+	if got.Email != "bob@example.com" {
+		t.Fatalf("email = %q after a failed update, want bob@example.com", got.Email)
+	}
+
+	// a user may keep its own email
+	if _, err := repo.Update(ctx, bob.ID, map[string]any{"email": "bob@example.com"}); err != nil {
+		t.Fatalf("update to its own email: %v", err)
+	}
+}
+
+func testSoftDelete(t *testing.T, repo userrepository.UserRepository) {
+	ctx := context.Background()
+	alice := create(t, repo, newUser("alice", "alice@example.com"))
+	bob := create(t, repo, newUser("bob", "bob@example.com"))
+
+	if err := repo.Delete(ctx, alice.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := repo.Delete(ctx, alice.ID); err != nil {
+		t.Fatalf("delete of a deleted user: %v", err)
+	}
+	if err := repo.Delete(ctx, uuid.New()); err != nil {
+		t.Fatalf("delete of a missing user: %v", err)
+	}
+
+	if _, err := repo.GetByID(ctx, alice.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("get by id of a deleted user: got %v, want ErrRecordNotFound", err)
+	}
+	if _, err := repo.Update(ctx, alice.ID, map[string]any{"name": "alicia"}); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("update of a deleted user: got %v, want ErrRecordNotFound", err)
+	}
+
+	assertIDs(t, "without deleted", list(t, repo, entity.UserFilter{}), bob.ID)
+
+	withDeleted := true
+	users := list(t, repo, entity.UserFilter{PaginationFilter: pagination(func(p *entitybase.BasePaginationFilter) { p.WithDeleted = &withDeleted })})
+	assertIDs(t, "with deleted", users, bob.ID, alice.ID)
+	for _, user := range users {
+		if deleted := user.ID == alice.ID; user.DeletedAt.Valid != deleted {
+			t.Fatalf("deleted_at of %s is valid = %v, want %v", user.Name, user.DeletedAt.Valid, deleted)
+		}
+	}
+}
+
+func testFilterFields(t *testing.T, repo userrepository.UserRepository) {
+	alice := newUser("alice", "alice@example.com")
+	alice.Metadata.Item = entity.UserMetadata{Sex: "female", Address: "1 Infinite Loop, Cupertino", Phone: "+14155550101"}
+	alice = create(t, repo, alice)
+
+	bob := newUser("bob", "bob@example.com")
+	bob.Status = constant.UserStatusBanned
+	bob.Metadata.Item = entity.UserMetadata{Sex: "male", Address: "10 Downing Street, London", Phone: "+442079250918"}
+	bob = create(t, repo, bob)
+
+	banned := constant.UserStatusBanned
+	tests := []struct {
+		name   string
+		filter entity.UserFilter
+		want   []uuid.UUID
+	}{
+		{"none", entity.UserFilter{}, []uuid.UUID{bob.ID, alice.ID}},
+		{"ids", entity.UserFilter{IDs: []uuid.UUID{alice.ID, uuid.New()}}, []uuid.UUID{alice.ID}},
+		{"empty ids", entity.UserFilter{IDs: []uuid.UUID{}}, nil},
+		{"name", entity.UserFilter{Name: ptr("LIC")}, []uuid.UUID{alice.ID}},
+		{"email", entity.UserFilter{Email: ptr("bob@example.com")}, []uuid.UUID{bob.ID}},
+		{"email is exact", entity.UserFilter{Email: ptr("bob@")}, nil},
+		{"email is case sensitive", entity.UserFilter{Email: ptr("BOB@example.com")}, nil},
+		{"status", entity.UserFilter{Status: &banned}, []uuid.UUID{bob.ID}},
+		{"sex", entity.UserFilter{Sex: ptr("female")}, []uuid.UUID{alice.ID}},
+		{"address", entity.UserFilter{Address: ptr("downing")}, []uuid.UUID{bob.ID}},
+		{"phone", entity.UserFilter{Phone: ptr("+14155550101")}, []uuid.UUID{alice.ID}},
+		{"phone is exact", entity.UserFilter{Phone: ptr("+1415")}, nil},
+		{"every field", entity.UserFilter{Name: ptr("o"), Status: &banned, Sex: ptr("male"), Address: ptr("LONDON")}, []uuid.UUID{bob.ID}},
+		{"no match", entity.UserFilter{Name: ptr("alice"), Sex: ptr("male")}, nil},
+	}
+	for _, tt := range tests {
+		assertIDs(t, tt.name, list(t, repo, tt.filter), tt.want...)
+	}
+}
+
+// testFilterLikeSemantics checks the name and address filters behave as ILIKE '%value%'
+func testFilterLikeSemantics(t *testing.T, repo userrepository.UserRepository) {
+	percent := create(t, repo, newUser("100% pure", "percent@example.com"))
+	underscore := create(t, repo, newUser("snake_case", "underscore@example.com"))
+	plain := create(t, repo, newUser("snakescase", "plain@example.com"))
+
+	tests := []struct {
+		name string
+		like string
+		want []uuid.UUID
+	}{
+		{"empty matches every name", "", []uuid.UUID{plain.ID, underscore.ID, percent.ID}},
+		{"case insensitive", "SNAKE", []uuid.UUID{plain.ID, underscore.ID}},
+		{"underscore matches one character", "snake_case", []uuid.UUID{plain.ID, underscore.ID}},
+		{"escaped underscore", `snake\_case`, []uuid.UUID{underscore.ID}},
+		{"percent matches any characters", "1%pure", []uuid.UUID{percent.ID}},
+		{"escaped percent", `0\% p`, []uuid.UUID{percent.ID}},
+		{"no match", "camel", nil},
+	}
+	for _, tt := range tests {
+		assertIDs(t, tt.name, list(t, repo, entity.UserFilter{Name: &tt.like}), tt.want...)
+	}
+}
+
+func testFilterTimeRange(t *testing.T, repo userrepository.UserRepository) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var ids []uuid.UUID
+	for i, name := range []string{"alice", "bob", "carol"} {
+		user := newUser(name, name+"@example.com")
+		user.CreatedAt = start.Add(time.Duration(i) * time.Hour)
+		user.UpdatedAt = user.CreatedAt
+		ids = append(ids, create(t, repo, user).ID)
+	}
+
+	minCreated, maxCreated := start.Add(time.Hour), start.Add(2*time.Hour)
+	users := list(t, repo, entity.UserFilter{PaginationFilter: pagination(func(p *entitybase.BasePaginationFilter) { p.MinCreated = &minCreated })})
+	assertIDs(t, "min created, inclusive", users, ids[2], ids[1])
+
+	users = list(t, repo, entity.UserFilter{PaginationFilter: pagination(func(p *entitybase.BasePaginationFilter) { p.MaxCreated = &minCreated })})
+	assertIDs(t, "max created, inclusive", users, ids[1], ids[0])
+
+	users = list(t, repo, entity.UserFilter{PaginationFilter: pagination(func(p *entitybase.BasePaginationFilter) {
+		p.MinUpdated, p.MaxUpdated = &maxCreated, &maxCreated
+	})})
+	assertIDs(t, "updated", users, ids[2])
+}
+
+func testOrder(t *testing.T, repo userrepository.UserRepository) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	users := []entity.User{
+		newUser("bob", "b2@example.com"),
+		newUser("alice", "a@example.com"),
+		newUser("bob", "b1@example.com"),
+	}
+	ids := make([]uuid.UUID, len(users))
+	for i := range users {
+		users[i].CreatedAt = start.Add(time.Duration(i) * time.Hour)
+		ids[i] = create(t, repo, users[i]).ID
+	}
+
+	tests := []struct {
+		orderBy *string
+		want    []uuid.UUID
+	}{
+		{nil, []uuid.UUID{ids[2], ids[1], ids[0]}},
+		{ptr("name,email"), []uuid.UUID{ids[1], ids[2], ids[0]}},
+		{ptr("+name,-email"), []uuid.UUID{ids[1], ids[0], ids[2]}},
+		{ptr("-name, email"), []uuid.UUID{ids[2], ids[0], ids[1]}},
+		{ptr("created_at"), []uuid.UUID{ids[0], ids[1], ids[2]}},
+		{ptr("unknown,email"), []uuid.UUID{ids[1], ids[2], ids[0]}},
+	}
+	for _, tt := range tests {
+		name := "default"
+		if tt.orderBy != nil {
+			name = *tt.orderBy
+		}
+
+		output, result, err := repo.GetByFilter(context.Background(), &entity.UserFilter{
+			PaginationFilter: pagination(func(p *entitybase.BasePaginationFilter) { p.OrderBy = tt.orderBy }),
+		})
+		if err != nil {
+			t.Fatalf("%s: get by filter: %v", name, err)
+		}
+		assertOrder(t, name, output, tt.want...)
+		if tt.orderBy != nil && result.OrderBy != *tt.orderBy {
+			t.Fatalf("%s: order by of the result = %q", name, result.OrderBy)
+		}
+	}
+}
+
+func testLimitAndOffset(t *testing.T, repo userrepository.UserRepository) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var ids []uuid.UUID
+	for i, name := range []string{"alice", "bob", "carol", "dave", "erin"} {
+		user := newUser(name, name+"@example.com")
+		user.CreatedAt = start.Add(time.Duration(i) * time.Hour)
+		ids = append(ids, create(t, repo, user).ID)
+	}
+	slices.Reverse(ids)
+
+	tests := []struct {
+		name          string
+		offset, limit *int
+		want          []uuid.UUID
+		wantOffset    int
+		wantLimit     int
+	}{
+		{"default", nil, nil, ids, 0, 100},
+		{"limit", nil, ptr(2), ids[:2], 0, 2},
+		{"offset", ptr(3), nil, ids[3:], 3, 100},
+		{"page", ptr(1), ptr(2), ids[1:3], 1, 2},
+		{"past the end", ptr(10), ptr(2), nil, 10, 2},
+		{"limit above the max", nil, ptr(1001), ids, 0, 100},
+		{"negative", ptr(-1), ptr(-1), ids, 0, 100},
+	}
+	for _, tt := range tests {
+		output, result, err := repo.GetByFilter(context.Background(), &entity.UserFilter{
+			PaginationFilter: pagination(func(p *entitybase.BasePaginationFilter) { p.Offset, p.Limit = tt.offset, tt.limit }),
+		})
+		if err != nil {
+			t.Fatalf("%s: get by filter: %v", tt.name, err)
+		}
+		assertOrder(t, tt.name, output, tt.want...)
+		if result.Count != len(ids) || result.Offset != tt.wantOffset || result.Limit != tt.wantLimit {
+			t.Fatalf("%s: pagination result = %+v, want count %d, offset %d, limit %d", tt.name, result, len(ids), tt.wantOffset, tt.wantLimit)
+		}
+	}
+}
+
+func newUser(name, email string) entity.User {
+	user := entity.User{Name: name, Email: email, Status: constant.UserStatusActive}
+	user.Metadata.Item = entity.UserMetadata{Sex: "female", Address: "1 Main St", Phone: "+14155550101"}
+	return user
+}
+
+// pagination returns the pagination filter set sets
+func pagination(set func(p *entitybase.BasePaginationFilter)) entitybase.BasePaginationFilter {
+	var p entitybase.BasePaginationFilter
+	set(&p)
+	return p
+}
+
+func create(t *testing.T, repo userrepository.UserRepository, user entity.User) entity.User {
+	t.Helper()
+
+	created, err := repo.Create(context.Background(), &user)
+	if err != nil {
+		t.Fatalf("create %s: %v", user.Name, err)
+	}
+	return *created
+}
+
+func list(t *testing.T, repo userrepository.UserRepository, filter entity.UserFilter) []entity.User {
+	t.Helper()
+
+	users, _, err := repo.GetByFilter(context.Background(), &filter)
+	if err != nil {
+		t.Fatalf("get by filter: %v", err)
+	}
+	return users
+}
+
+func assertUser(t *testing.T, got *entity.User, want entity.User) {
+	t.Helper()
+
+	if got.Name != want.Name || got.Email != want.Email || got.Status != want.Status ||
+		got.Metadata.Item != want.Metadata.Item || got.TenantID != want.TenantID {
+		t.Fatalf("user = %+v, want %+v", *got, want)
+	}
+	if want.ID != uuid.Nil && got.ID != want.ID {
+		t.Fatalf("id = %s, want %s", got.ID, want.ID)
+	}
+}
+
+// assertIDs checks users are the users of ids, in any order
+func assertIDs(t *testing.T, name string, users []entity.User, ids ...uuid.UUID) {
+	t.Helper()
+
+	got, want := userIDs(users), slices.Clone(ids)
+	sortIDs := func(a, b uuid.UUID) int { return slices.Compare(a[:], b[:]) }
+	slices.SortFunc(got, sortIDs)
+	slices.SortFunc(want, sortIDs)
+	if !slices.Equal(got, want) {
+		t.Fatalf("%s: got users %v, want %v", name, got, want)
+	}
+}
+
+// assertOrder checks users are the users of ids, in order
+func assertOrder(t *testing.T, name string, users []entity.User, ids ...uuid.UUID) {
+	t.Helper()
+
+	if got := userIDs(users); !slices.Equal(got, ids) {
+		t.Fatalf("%s: got users %v, want %v", name, got, ids)
+	}
+}
+
+func userIDs(users []entity.User) []uuid.UUID {
+	ids := make([]uuid.UUID, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+	return ids
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -1,75 +1,34 @@
 package userrepositoryimplementation
 
 import (
-	"context"
 	"errors"
 
 	"github.com/alxhtp/monogo/internal/entity"
 	entitybase "github.com/alxhtp/monogo/internal/entity/base"
+	genericrepositoryimplementation "github.com/alxhtp/monogo/internal/repository/generic/implementation"
 	userrepository "github.com/alxhtp/monogo/internal/repository/user"
-	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
-type userRepository struct {
-	db   *gorm.DB
-	user entity.User
-}
-
 func NewUserRepository(db *gorm.DB) userrepository.UserRepository {
-	return &userRepository{db: db, user: entity.User{}}
-}
-
-func (r *userRepository) Create(ctx context.Context, user *entity.User) (output *entity.User, err error) {
-	if r.db == nil {
-		return nil, errors.New("database connection is not initialized")
-	}
-	err = r.db.WithContext(ctx).Create(user).Error
-	if err != nil {
-		return nil, err
-	}
-
-	return r.GetByID(ctx, user.ID)
-}
-
-func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (output *entity.User, err error) {
-	if r.db == nil {
-		return nil, errors.New("database connection is not initialized")
-	}
-
-	if err := r.db.WithContext(ctx).Table(r.user.TableName()).First(&output, "id = ?", id).Error; err != nil {
-		return nil, err
-	}
-
-	return
-}
-
-func (r *userRepository) GetByFilter(ctx context.Context, filter *entity.UserFilter) (output []entity.User, paginationResult entitybase.BasePaginationResult, err error) {
-	if r.db == nil {
-		return nil, entitybase.BasePaginationResult{}, errors.New("database connection is not initialized")
-	}
-
-	query := r.db.WithContext(ctx).Model(&output)
-	query, err = r.applyFilter(query, *filter)
-	if err != nil {
-		return nil, entitybase.BasePaginationResult{}, err
-	}
-
-	query = entitybase.PaginateEntityQuery(query, r.user.TableName(), r.user.OrderMap(), &filter.PaginationFilter, &paginationResult)
-
-	if err = query.Find(&output).Error; err != nil {
-		return
-	}
-
-	return output, paginationResult, nil
-}
-
-func (r *userRepository) applyFilter(db *gorm.DB, filter entity.UserFilter) (*gorm.DB, error) {
+	user := &entity.User{}
+	return genericrepositoryimplementation.NewRepository(db, genericrepositoryimplementation.Definition[entity.User, entity.UserFilter]{
+		Table:       user.TableName(),
+		OrderMap:    user.OrderMap(),
+		ApplyFilter: applyUserFilter,
+		Pagination: func(filter *entity.UserFilter) *entitybase.BasePaginationFilter {
+			return &filter.PaginationFilter
+		},
+	})
+}
+
+// applyUserFilter narrows db to the users matching filter
+func applyUserFilter(db *gorm.DB, filter entity.UserFilter) (*gorm.DB, error) {
 	if db == nil {
 		return nil, errors.New("database connection is not initialized")
 	}
 
-	table := r.user.TableName()
+	table := (&entity.User{}).TableName()
 	if filter.IDs != nil {
 		db = db.Where(table+".id IN (?)", filter.IDs)
 	}
@@ -101,25 +60,3 @@ func (r *userRepository) applyFilter(db *gorm.DB, filter entity.UserFilter) (*go
 
 	return db, nil
 }
-
-func (r *userRepository) Update(ctx context.Context, id uuid.UUID, updateMap map[string]any) (output *entity.User, err error) {
-	if r.db == nil {
-		return nil, errors.New("database connection is not initialized")
-	}
-
-	err = r.db.WithContext(ctx).Model(&r.user).Where("id = ?", id).Updates(updateMap).Error
-	if err != nil {
-		return nil, err
-	}
-
-	return r.GetByID(ctx, id)
-}
-
-func (r *userRepository) Delete(ctx context.Context, id uuid.UUID) (err error) {
-	if r.db == nil {
-		return errors.New("database connection is not initialized")
-	}
-
-	err = r.db.WithContext(ctx).Where("id = ?", id).Delete(&r.user).Error
-	return err
-}
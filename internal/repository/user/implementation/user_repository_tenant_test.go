@@ -0,0 +1,121 @@
+package userrepositoryimplementation_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/alxhtp/monogo/config"
+	"github.com/alxhtp/monogo/internal/entity"
+	userrepository "github.com/alxhtp/monogo/internal/repository/user"
+	userrepositoryimplementation "github.com/alxhtp/monogo/internal/repository/user/implementation"
+	"github.com/alxhtp/monogo/migration/migrationtest"
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
+	"gorm.io/gorm"
+)
+
+// newRowTenancyRepository migrates a throwaway schema and returns a user repository over it in the row level mode.
+// The role of migrationtest.DSNEnv must not be a superuser nor BYPASSRLS, row level security never applies to those.
+func newRowTenancyRepository(t *testing.T) (userrepository.UserRepository, *gorm.DB) {
+	t.Helper()
+
+	db := migrationtest.OpenSchema(t)
+	err := databasehelper.EnableTenancy(context.Background(), db, &config.DatabaseConfig{}, &config.TenantConfig{TenantMode: databasehelper.TenantModeRow})
+	if errors.Is(err, databasehelper.ErrRowSecurityBypassed) {
+		t.Skipf("%s: %v", migrationtest.DSNEnv, err)
+	}
+	if err != nil {
+		t.Fatalf("enable tenancy: %v", err)
+	}
+
+	return userrepositoryimplementation.NewUserRepository(db), db
+}
+
+func TestRowTenancyCrossTenantReadsFail(t *testing.T) {
+	repo, _ := newRowTenancyRepository(t)
+	acme := contexthelper.WithTenant(context.Background(), "acme")
+	globex := contexthelper.WithTenant(context.Background(), "globex")
+
+	user, err := repo.Create(acme, &entity.User{Name: "Alice", Email: "alice@example.com"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if user.TenantID != "acme" {
+		t.Fatalf("tenant_id = %q, want acme", user.TenantID)
+	}
+
+	if _, err := repo.GetByID(globex, user.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("get by id from another tenant: got %v, want ErrRecordNotFound", err)
+	}
+	if _, err := repo.GetByID(context.Background(), user.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("get by id without tenant: got %v, want ErrRecordNotFound", err)
+	}
+
+	users, _, err := repo.GetByFilter(globex, &entity.UserFilter{})
+	if err != nil {
+		t.Fatalf("get by filter: %v", err)
+	}
+	if len(users) != 0 {
+		t.Fatalf("another tenant listed %d users, want none", len(users))
+	}
+
+	users, _, err = repo.GetByFilter(acme, &entity.UserFilter{})
+	if err != nil {
+		t.Fatalf("get by filter: %v", err)
+	}
+	if len(users) != 1 || users[0].ID != user.ID {
+		t.Fatalf("tenant listed %v, want its own user", users)
+	}
+}
+
+func TestRowTenancyCrossTenantWritesFail(t *testing.T) {
+	repo, db := newRowTenancyRepository(t)
+	acme := contexthelper.WithTenant(context.Background(), "acme")
+	globex := contexthelper.WithTenant(context.Background(), "globex")
+
+	user, err := repo.Create(acme, &entity.User{Name: "Alice", Email: "alice@example.com"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	if _, err := repo.Update(globex, user.ID, map[string]any{"name": "Mallory"}); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("update from another tenant: got %v, want ErrRecordNotFound", err)
+	}
+	if err := repo.Delete(globex, user.ID); err != nil {
+		t.Fatalf("delete from another tenant: %v", err)
+	}
+
+	got, err := repo.GetByID(acme, user.ID)
+	if err != nil {
+		t.Fatalf("user of the tenant is gone after a write of another tenant: %v", err)
+	}
+	if got.Name != "Alice" || got.DeletedAt.Valid {
+		t.Fatalf("user of the tenant changed by another tenant: %+v", got)
+	}
+
+	// a row of another tenant cannot be written, even by naming its tenant_id
+	err = databasehelper.WithTransaction(globex, db, func(ctx context.Context) error {
+		return databasehelper.DBFromContext(ctx, db).Exec(
+			"INSERT INTO users (name, email, tenant_id) VALUES (?, ?, ?)", "Mallory", "mallory@example.com", "acme",
+		).Error
+	})
+	if err == nil || !strings.Contains(err.Error(), "row-level security") {
+		t.Fatalf("insert into another tenant: got %v, want a row-level security violation", err)
+	}
+
+	// nor can a row of the tenant be handed over to another tenant
+	bob, err := repo.Create(globex, &entity.User{Name: "Bob", Email: "bob@example.com"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := repo.Update(globex, bob.ID, map[string]any{"tenant_id": "acme"}); err == nil || !strings.Contains(err.Error(), "row-level security") {
+		t.Fatalf("move into another tenant: got %v, want a row-level security violation", err)
+	}
+
+	// emails are unique per tenant, the email of another tenant does not leak through a conflict
+	if _, err := repo.Create(globex, &entity.User{Name: "Alice", Email: "alice@example.com"}); err != nil {
+		t.Fatalf("create with the email of another tenant: %v", err)
+	}
+}
@@ -0,0 +1,16 @@
+package userrepositoryimplementation_test
+
+import (
+	"testing"
+
+	userrepository "github.com/alxhtp/monogo/internal/repository/user"
+	userrepositoryconformance "github.com/alxhtp/monogo/internal/repository/user/conformance"
+	userrepositoryimplementation "github.com/alxhtp/monogo/internal/repository/user/implementation"
+	"github.com/alxhtp/monogo/migration/migrationtest"
+)
+
+func TestUserRepositoryConformance(t *testing.T) {
+	userrepositoryconformance.Run(t, func(t *testing.T) userrepository.UserRepository {
+		return userrepositoryimplementation.NewUserRepository(migrationtest.OpenSchema(t))
+	})
+}
@@ -0,0 +1,26 @@
+package userrepositorymemory
+
+import (
+	"github.com/alxhtp/monogo/internal/entity"
+	entitybase "github.com/alxhtp/monogo/internal/entity/base"
+	genericrepositorymemory "github.com/alxhtp/monogo/internal/repository/generic/memory"
+	userrepository "github.com/alxhtp/monogo/internal/repository/user"
+)
+
+// NewUserRepository returns an empty in-memory user repository, for tests that should not need a database
+func NewUserRepository() userrepository.UserRepository {
+	user := &entity.User{}
+	return genericrepositorymemory.NewRepository(genericrepositorymemory.Definition[entity.User, entity.UserFilter]{
+		Table:    user.TableName(),
+		OrderMap: user.OrderMap(),
+		Match: func(user *entity.User, filter entity.UserFilter) bool {
+			return filter.Match(user)
+		},
+		Pagination: func(filter *entity.UserFilter) *entitybase.BasePaginationFilter {
+			return &filter.PaginationFilter
+		},
+		Unique: []genericrepositorymemory.Constraint{
+			{Name: "users_tenant_id_email_key", Columns: []string{"tenant_id", "email"}},
+		},
+	})
+}
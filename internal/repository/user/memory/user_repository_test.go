@@ -0,0 +1,69 @@
+package userrepositorymemory_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/alxhtp/monogo/internal/entity"
+	entitybase "github.com/alxhtp/monogo/internal/entity/base"
+	userrepository "github.com/alxhtp/monogo/internal/repository/user"
+	userrepositoryconformance "github.com/alxhtp/monogo/internal/repository/user/conformance"
+	userrepositorymemory "github.com/alxhtp/monogo/internal/repository/user/memory"
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+	"gorm.io/gorm"
+)
+
+func TestUserRepositoryConformance(t *testing.T) {
+	userrepositoryconformance.Run(t, func(t *testing.T) userrepository.UserRepository {
+		return userrepositorymemory.NewUserRepository()
+	})
+}
+
+// TestUserRepositoryRowTenancy checks the in-memory repository isolates tenants as the row level mode does,
+// see TestRowTenancyCrossTenantReadsFail and TestRowTenancyCrossTenantWritesFail of the GORM repository
+func TestUserRepositoryRowTenancy(t *testing.T) {
+	repo := userrepositorymemory.NewUserRepository()
+	acme := contexthelper.WithTenant(context.Background(), "acme")
+	globex := contexthelper.WithTenant(context.Background(), "globex")
+
+	user, err := repo.Create(acme, &entity.User{Name: "Alice", Email: "alice@example.com"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if user.TenantID != "acme" {
+		t.Fatalf("tenant_id = %q, want acme", user.TenantID)
+	}
+
+	for name, ctx := range map[string]context.Context{"another tenant": globex, "no tenant": context.Background()} {
+		if _, err := repo.GetByID(ctx, user.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("get by id from %s: got %v, want ErrRecordNotFound", name, err)
+		}
+		if users, _, err := repo.GetByFilter(ctx, &entity.UserFilter{}); err != nil || len(users) != 0 {
+			t.Fatalf("%s listed %d users, %v, want none", name, len(users), err)
+		}
+	}
+
+	if _, err := repo.Update(globex, user.ID, map[string]any{"name": "Mallory"}); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("update from another tenant: got %v, want ErrRecordNotFound", err)
+	}
+	if err := repo.Delete(globex, user.ID); err != nil {
+		t.Fatalf("delete from another tenant: %v", err)
+	}
+	if _, err := repo.GetByID(acme, user.ID); err != nil {
+		t.Fatalf("user of the tenant is gone after a delete of another tenant: %v", err)
+	}
+
+	if _, err := repo.Create(globex, &entity.User{Name: "Mallory", Email: "mallory@example.com", BaseTenant: entitybase.BaseTenant{TenantID: "acme"}}); err == nil || !strings.Contains(err.Error(), "row-level security") {
+		t.Fatalf("create in another tenant: got %v, want a row-level security violation", err)
+	}
+	if _, err := repo.Update(acme, user.ID, map[string]any{"tenant_id": "globex"}); err == nil || !strings.Contains(err.Error(), "row-level security") {
+		t.Fatalf("move into another tenant: got %v, want a row-level security violation", err)
+	}
+
+	// emails are unique per tenant
+	if _, err := repo.Create(globex, &entity.User{Name: "Alice", Email: "alice@example.com"}); err != nil {
+		t.Fatalf("create with the email of another tenant: %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package webhookrepositoryimplementation
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/alxhtp/monogo/internal/entity"
+	entitybase "github.com/alxhtp/monogo/internal/entity/base"
+	webhookrepository "github.com/alxhtp/monogo/internal/repository/webhook"
+	"github.com/alxhtp/monogo/pkg/constant"
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type webhookDeliveryRepository struct {
+	db       *gorm.DB
+	delivery entity.WebhookDelivery
+}
+
+func NewWebhookDeliveryRepository(db *gorm.DB) webhookrepository.WebhookDeliveryRepository {
+	return &webhookDeliveryRepository{db: db, delivery: entity.WebhookDelivery{}}
+}
+
+func (r *webhookDeliveryRepository) CreateMany(ctx context.Context, deliveries []entity.WebhookDelivery) (err error) {
+	if r.db == nil {
+		return errors.New("database connection is not initialized")
+	}
+
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	for i := range deliveries {
+		if deliveries[i].ID == uuid.Nil {
+			deliveries[i].ID = uuid.New()
+		}
+		deliveries[i].CreatedAt = now
+		deliveries[i].UpdatedAt = now
+	}
+
+	// the outbox relay is at least once, an event may be fanned out more than once
+	return databasehelper.DBFromContext(ctx, r.db).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "webhook_id"}, {Name: "event_id"}}, DoNothing: true}).
+		Create(&deliveries).Error
+}
+
+func (r *webhookDeliveryRepository) GetByID(ctx context.Context, id uuid.UUID) (output *entity.WebhookDelivery, err error) {
+	if r.db == nil {
+		return nil, errors.New("database connection is not initialized")
+	}
+
+	if err := databasehelper.DBFromContext(ctx, r.db).Table(r.delivery.TableName()).First(&output, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+func (r *webhookDeliveryRepository) GetByFilter(ctx context.Context, filter *entity.WebhookDeliveryFilter) (output []entity.WebhookDelivery, paginationResult entitybase.BasePaginationResult, err error) {
+	if r.db == nil {
+		return nil, entitybase.BasePaginationResult{}, errors.New("database connection is not initialized")
+	}
+
+	query := databasehelper.DBFromContext(ctx, r.db).Model(&output)
+	query, err = r.applyFilter(query, *filter)
+	if err != nil {
+		return nil, entitybase.BasePaginationResult{}, err
+	}
+
+	query = entitybase.PaginateEntityQuery(query, r.delivery.TableName(), r.delivery.OrderMap(), &filter.PaginationFilter, &paginationResult)
+
+	if err = query.Find(&output).Error; err != nil {
+		return
+	}
+
+	return output, paginationResult, nil
+}
+
+func (r *webhookDeliveryRepository) GetDue(ctx context.Context, limit int) (output []entity.WebhookDelivery, err error) {
+	if r.db == nil {
+		return nil, errors.New("database connection is not initialized")
+	}
+
+	table := r.delivery.TableName()
+	err = databasehelper.DBFromContext(ctx, r.db).
+		Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate, Options: clause.LockingOptionsSkipLocked}).
+		Where(table+".status IN (?)", []constant.WebhookDeliveryStatus{constant.WebhookDeliveryStatusPending, constant.WebhookDeliveryStatusFailed}).
+		Where(table+".next_attempt_at <= ?", time.Now()).
+		Order(table + ".next_attempt_at asc").
+		Limit(limit).
+		Find(&output).Error
+
+	return output, err
+}
+
+func (r *webhookDeliveryRepository) applyFilter(db *gorm.DB, filter entity.WebhookDeliveryFilter) (*gorm.DB, error) {
+	if db == nil {
+		return nil, errors.New("database connection is not initialized")
+	}
+
+	table := r.delivery.TableName()
+	if filter.IDs != nil {
+		db = db.Where(table+".id IN (?)", filter.IDs)
+	}
+
+	if filter.WebhookID != nil {
+		db = db.Where(table+".webhook_id = ?", filter.WebhookID)
+	}
+
+	if filter.EventType != nil {
+		db = db.Where(table+".event_type = ?", filter.EventType)
+	}
+
+	if filter.Status != nil {
+		db = db.Where(table+".status = ?", filter.Status)
+	}
+
+	return db, nil
+}
+
+func (r *webhookDeliveryRepository) Update(ctx context.Context, id uuid.UUID, updateMap map[string]any) (output *entity.WebhookDelivery, err error) {
+	if r.db == nil {
+		return nil, errors.New("database connection is not initialized")
+	}
+
+	updateMap[databasehelper.ColUpdatedAt] = time.Now()
+	err = databasehelper.DBFromContext(ctx, r.db).Model(&r.delivery).Where("id = ?", id).Updates(updateMap).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, id)
+}
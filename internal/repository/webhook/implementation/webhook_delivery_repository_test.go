@@ -0,0 +1,78 @@
+package webhookrepositoryimplementation_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alxhtp/monogo/internal/entity"
+	webhookrepositoryimplementation "github.com/alxhtp/monogo/internal/repository/webhook/implementation"
+	"github.com/alxhtp/monogo/migration/migrationtest"
+	"github.com/alxhtp/monogo/pkg/constant"
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
+)
+
+func TestGetDueSkipsDeliveriesLockedByAnotherTransaction(t *testing.T) {
+	db := migrationtest.OpenSchema(t)
+	ctx := context.Background()
+	webhooks := webhookrepositoryimplementation.NewWebhookRepository(db)
+	deliveries := webhookrepositoryimplementation.NewWebhookDeliveryRepository(db)
+
+	webhook, err := webhooks.Create(ctx, &entity.Webhook{
+		Name:   "crm",
+		URL:    "https://example.com/hooks",
+		Events: databasehelper.GormJsonType[[]string]{Item: []string{entity.WebhookEventAll}},
+		Secret: "secret",
+		Active: true,
+	})
+	if err != nil {
+		t.Fatalf("create webhook: %v", err)
+	}
+	due := make([]entity.WebhookDelivery, 2)
+	for i := range due {
+		due[i] = entity.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       int64(i + 1),
+			EventType:     "user.created",
+			Payload:       databasehelper.GormJsonType[json.RawMessage]{Item: json.RawMessage(`{}`)},
+			Status:        constant.WebhookDeliveryStatusPending,
+			NextAttemptAt: time.Now().Add(-time.Minute),
+		}
+	}
+	if err := deliveries.CreateMany(ctx, due); err != nil {
+		t.Fatalf("create deliveries: %v", err)
+	}
+
+	// the first transaction locks one delivery, a concurrent claim gets the other one only
+	locked := make(chan struct{})
+	release := make(chan struct{})
+	first := make(chan error, 1)
+	go func() {
+		first <- databasehelper.WithTransaction(ctx, db, func(ctx context.Context) error {
+			claimed, err := deliveries.GetDue(ctx, 1)
+			if err == nil && len(claimed) != 1 {
+				t.Errorf("first claim = %d deliveries, want 1", len(claimed))
+			}
+			close(locked)
+			<-release
+			return err
+		})
+	}()
+	<-locked
+
+	err = databasehelper.WithTransaction(ctx, db, func(ctx context.Context) error {
+		claimed, err := deliveries.GetDue(ctx, 2)
+		if err == nil && len(claimed) != 1 {
+			t.Errorf("concurrent claim = %d deliveries, want the one not locked", len(claimed))
+		}
+		return err
+	})
+	close(release)
+	if err != nil {
+		t.Fatalf("concurrent claim: %v", err)
+	}
+	if err := <-first; err != nil {
+		t.Fatalf("first claim: %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package webhookrepositoryimplementation
+
+import (
+	"context"
+	"errors"
+
+	"github.com/alxhtp/monogo/internal/entity"
+	entitybase "github.com/alxhtp/monogo/internal/entity/base"
+	genericrepository "github.com/alxhtp/monogo/internal/repository/generic"
+	genericrepositoryimplementation "github.com/alxhtp/monogo/internal/repository/generic/implementation"
+	webhookrepository "github.com/alxhtp/monogo/internal/repository/webhook"
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
+	"github.com/alxhtp/monogo/pkg/jsonconvert"
+	"gorm.io/gorm"
+)
+
+// webhookRepository adds the queries of the delivery worker to the generic CRUD operations
+type webhookRepository struct {
+	genericrepository.Repository[entity.Webhook, entity.WebhookFilter]
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) webhookrepository.WebhookRepository {
+	webhook := &entity.Webhook{}
+	return &webhookRepository{
+		Repository: genericrepositoryimplementation.NewRepository(db, genericrepositoryimplementation.Definition[entity.Webhook, entity.WebhookFilter]{
+			Table:       webhook.TableName(),
+			OrderMap:    webhook.OrderMap(),
+			ApplyFilter: applyWebhookFilter,
+			Pagination: func(filter *entity.WebhookFilter) *entitybase.BasePaginationFilter {
+				return &filter.PaginationFilter
+			},
+		}),
+		db: db,
+	}
+}
+
+func (r *webhookRepository) GetSubscribed(ctx context.Context, eventType string) (output []entity.Webhook, err error) {
+	if r.db == nil {
+		return nil, errors.New("database connection is not initialized")
+	}
+
+	active := true
+	query, err := applyWebhookFilter(databasehelper.DBFromContext(ctx, r.db).Model(&output), entity.WebhookFilter{
+		Active:    &active,
+		EventType: &eventType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.Find(&output).Error
+	return output, err
+}
+
+// applyWebhookFilter narrows db to the webhooks matching filter
+func applyWebhookFilter(db *gorm.DB, filter entity.WebhookFilter) (*gorm.DB, error) {
+	if db == nil {
+		return nil, errors.New("database connection is not initialized")
+	}
+
+	table := (&entity.Webhook{}).TableName()
+	if filter.IDs != nil {
+		db = db.Where(table+".id IN (?)", filter.IDs)
+	}
+
+	if filter.Name != nil {
+		// ignore case
+		db = db.Where(table+".name ILIKE ?", "%"+*filter.Name+"%")
+	}
+
+	if filter.Active != nil {
+		db = db.Where(table+".active = ?", filter.Active)
+	}
+
+	if filter.EventType != nil {
+		db = db.Where("("+table+".events @> ? OR "+table+".events @> ?)",
+			jsonconvert.Serialize([]string{*filter.EventType}),
+			jsonconvert.Serialize([]string{entity.WebhookEventAll}),
+		)
+	}
+
+	return db, nil
+}
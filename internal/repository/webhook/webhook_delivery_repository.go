@@ -0,0 +1,19 @@
+package webhookrepository
+
+import (
+	"context"
+
+	"github.com/alxhtp/monogo/internal/entity"
+	entitybase "github.com/alxhtp/monogo/internal/entity/base"
+	"github.com/google/uuid"
+)
+
+type WebhookDeliveryRepository interface {
+	// CreateMany skips deliveries already created for the same webhook and event
+	CreateMany(ctx context.Context, deliveries []entity.WebhookDelivery) (err error)
+	GetByID(ctx context.Context, id uuid.UUID) (output *entity.WebhookDelivery, err error)
+	GetByFilter(ctx context.Context, filter *entity.WebhookDeliveryFilter) (output []entity.WebhookDelivery, paginationResult entitybase.BasePaginationResult, err error)
+	// GetDue locks due deliveries, call it inside a transaction
+	GetDue(ctx context.Context, limit int) (output []entity.WebhookDelivery, err error)
+	Update(ctx context.Context, id uuid.UUID, updateMap map[string]any) (output *entity.WebhookDelivery, err error)
+}
@@ -0,0 +1,14 @@
+package webhookrepository
+
+import (
+	"context"
+
+	"github.com/alxhtp/monogo/internal/entity"
+	genericrepository "github.com/alxhtp/monogo/internal/repository/generic"
+)
+
+type WebhookRepository interface {
+	genericrepository.Repository[entity.Webhook, entity.WebhookFilter]
+	// GetSubscribed returns active webhooks subscribed to eventType
+	GetSubscribed(ctx context.Context, eventType string) (output []entity.Webhook, err error)
+}
@@ -0,0 +1,151 @@
+package seed
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/alxhtp/monogo/pkg/dto"
+)
+
+const (
+	sexMale   = "male"
+	sexFemale = "female"
+)
+
+// Person is a fake person, its name matches its sex and its phone number the country of its address
+type Person struct {
+	Sex       string
+	FirstName string
+	LastName  string
+	Address   string
+	Phone     string
+}
+
+// country holds the places and the E.164 phone numbering of a country
+type country struct {
+	name    string
+	cities  []string
+	streets []string
+	// phone returns a national significant number, the country code excluded
+	phone       func(r *rand.Rand) string
+	countryCode string
+}
+
+var (
+	maleNames   = []string{"James", "Oliver", "Liam", "Noah", "Lucas", "Mateo", "Budi", "Rizky", "Hiro", "Arjun", "Felix", "Jonas", "Samuel", "Daniel", "Ethan", "Adam"}
+	femaleNames = []string{"Olivia", "Emma", "Amelia", "Sofia", "Mia", "Isla", "Siti", "Putri", "Yuki", "Priya", "Hannah", "Lena", "Clara", "Chloe", "Grace", "Nadia"}
+	lastNames   = []string{"Smith", "Johnson", "Brown", "Taylor", "Wilson", "Walker", "Wijaya", "Santoso", "Tanaka", "Sharma", "Müller", "Schmidt", "Garcia", "Martin", "Lee", "Nguyen"}
+
+	countries = []country{
+		{
+			name:        "USA",
+			cities:      []string{"New York, NY", "Austin, TX", "Seattle, WA", "Denver, CO", "Chicago, IL"},
+			streets:     []string{"Main Street", "Oak Avenue", "Maple Drive", "Park Lane", "Cedar Road"},
+			countryCode: "1",
+			// NANP, the area code and the exchange do not start with 0 or 1
+			phone: func(r *rand.Rand) string {
+				return fmt.Sprintf("%d%02d%d%02d%04d", 2+r.IntN(8), r.IntN(100), 2+r.IntN(8), r.IntN(100), r.IntN(10000))
+			},
+		},
+		{
+			name:        "United Kingdom",
+			cities:      []string{"London", "Manchester", "Bristol", "Leeds", "Edinburgh"},
+			streets:     []string{"High Street", "Station Road", "Church Lane", "Victoria Road", "Mill Lane"},
+			countryCode: "44",
+			// mobile numbers, 7 followed by 9 digits
+			phone: func(r *rand.Rand) string {
+				return fmt.Sprintf("7%09d", r.IntN(1_000_000_000))
+			},
+		},
+		{
+			name:        "Indonesia",
+			cities:      []string{"Jakarta", "Bandung", "Surabaya", "Yogyakarta", "Denpasar"},
+			streets:     []string{"Jl. Sudirman", "Jl. Thamrin", "Jl. Diponegoro", "Jl. Gatot Subroto", "Jl. Merdeka"},
+			countryCode: "62",
+			// mobile numbers, 8 followed by 9 to 11 digits
+			phone: func(r *rand.Rand) string {
+				digits := 9 + r.IntN(3)
+				return "8" + fmt.Sprintf("%0*d", digits, r.Int64N(pow10(digits)))
+			},
+		},
+		{
+			name:        "Germany",
+			cities:      []string{"Berlin", "Hamburg", "Munich", "Cologne", "Leipzig"},
+			streets:     []string{"Hauptstraße", "Schulstraße", "Gartenstraße", "Bahnhofstraße", "Dorfstraße"},
+			countryCode: "49",
+			// mobile numbers, 15, 16 or 17 followed by 9 digits
+			phone: func(r *rand.Rand) string {
+				return fmt.Sprintf("1%d%09d", 5+r.IntN(3), r.IntN(1_000_000_000))
+			},
+		},
+	}
+)
+
+// NewPerson returns the fake person n of seed, the same seed and n always give the same person
+func NewPerson(seed uint64, n int) Person {
+	r := rand.New(rand.NewPCG(seed, uint64(n)))
+
+	person := Person{Sex: sexMale, FirstName: pick(r, maleNames)}
+	if r.IntN(2) == 0 {
+		person.Sex, person.FirstName = sexFemale, pick(r, femaleNames)
+	}
+	person.LastName = pick(r, lastNames)
+
+	c := countries[r.IntN(len(countries))]
+	street := pick(r, c.streets)
+	number := 1 + r.IntN(250)
+	// Indonesian and German addresses put the number after the street
+	if c.countryCode == "62" || c.countryCode == "49" {
+		person.Address = fmt.Sprintf("%s %d, %s, %s", street, number, pick(r, c.cities), c.name)
+	} else {
+		person.Address = fmt.Sprintf("%d %s, %s, %s", number, street, pick(r, c.cities), c.name)
+	}
+	person.Phone = "+" + c.countryCode + c.phone(r)
+
+	return person
+}
+
+func (p Person) Name() string {
+	return p.FirstName + " " + p.LastName
+}
+
+// Email returns an address at the reserved example.com domain, n keeps the addresses of people sharing a name apart
+func (p Person) Email(n int) string {
+	local := strings.ToLower(p.FirstName + "." + p.LastName)
+	local = strings.NewReplacer("ü", "ue", "ö", "oe", "ä", "ae", "ß", "ss").Replace(local)
+	return fmt.Sprintf("%s.%d@example.com", local, n)
+}
+
+func (p Person) UserMetadata() dto.UserMetadata {
+	return dto.UserMetadata{Sex: p.Sex, Address: p.Address, Phone: p.Phone}
+}
+
+// generateVars returns the placeholders of the fixture n of a generate block
+func generateVars(seed uint64, n int) map[string]any {
+	person := NewPerson(seed, n)
+	return map[string]any{
+		"n": n,
+		"fake": map[string]any{
+			"name":       person.Name(),
+			"first_name": person.FirstName,
+			"last_name":  person.LastName,
+			"email":      person.Email(n),
+			"sex":        person.Sex,
+			"address":    person.Address,
+			"phone":      person.Phone,
+		},
+	}
+}
+
+func pick(r *rand.Rand, values []string) string {
+	return values[r.IntN(len(values))]
+}
+
+func pow10(n int) int64 {
+	output := int64(1)
+	for range n {
+		output *= 10
+	}
+	return output
+}
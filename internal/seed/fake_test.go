@@ -0,0 +1,40 @@
+package seed_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/alxhtp/monogo/internal/seed"
+	"github.com/go-playground/validator/v10"
+)
+
+func TestNewPersonIsValidAndDeterministic(t *testing.T) {
+	validate := validator.New()
+	emailPattern := regexp.MustCompile(`^[a-z]+\.[a-z]+\.\d+@example\.com$`)
+
+	for n := 1; n <= 500; n++ {
+		person := seed.NewPerson(1, n)
+		if err := validate.Var(person.Phone, "required,e164"); err != nil {
+			t.Fatalf("person %d: phone %q is not E.164: %v", n, person.Phone, err)
+		}
+		if person.Sex != "male" && person.Sex != "female" {
+			t.Fatalf("person %d: sex %q", n, person.Sex)
+		}
+		if email := person.Email(n); !emailPattern.MatchString(email) {
+			t.Fatalf("person %d: email %q", n, email)
+		}
+		if person != seed.NewPerson(1, n) {
+			t.Fatalf("person %d: the same seed gave another person", n)
+		}
+	}
+
+	same := 0
+	for n := 1; n <= 10; n++ {
+		if seed.NewPerson(1, n) == seed.NewPerson(2, n) {
+			same++
+		}
+	}
+	if same == 10 {
+		t.Fatal("another seed gave the same people")
+	}
+}
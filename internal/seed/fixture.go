@@ -0,0 +1,147 @@
+package seed
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// refKey names a fixture so later fixtures reference it as ${kind.ref.field}
+	refKey = "ref"
+	// generateKey holds the blocks generating fixtures with fake data
+	generateKey = "generate"
+)
+
+// placeholderPattern matches ${name}, $${ is written as a literal ${
+var placeholderPattern = regexp.MustCompile(`\$?\$\{([^}]+)\}`)
+
+// section holds the fixtures of a kind, or the blocks of generate, in the order of the file
+type section struct {
+	kind     string
+	fixtures []any
+}
+
+// generateSpec generates count fixtures of kind from the fixture template.
+// The template reads ${n}, the 1-based index of the fixture, and ${fake.*}, a fake person derived from seed and n.
+type generateSpec struct {
+	Kind    string `json:"kind"`
+	Count   int    `json:"count"`
+	Seed    uint64 `json:"seed"`
+	Fixture any    `json:"fixture"`
+}
+
+// readFile decodes a YAML or JSON fixture file, JSON being a subset of YAML, into its sections in order
+func readFile(path string) ([]section, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	if len(document.Content) == 0 {
+		return nil, nil
+	}
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, errors.New("a fixture file must be a mapping of kinds to fixture lists")
+	}
+
+	sections := make([]section, 0, len(root.Content)/2)
+	for i := 0; i < len(root.Content); i += 2 {
+		kind := root.Content[i].Value
+
+		var fixtures []any
+		if err := root.Content[i+1].Decode(&fixtures); err != nil {
+			return nil, fmt.Errorf("%s must be a list: %w", kind, err)
+		}
+		sections = append(sections, section{kind: kind, fixtures: fixtures})
+	}
+	return sections, nil
+}
+
+func parseGenerate(block any) (*generateSpec, error) {
+	data, err := json.Marshal(block)
+	if err != nil {
+		return nil, err
+	}
+	var spec generateSpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, err
+	}
+	if spec.Kind == "" || spec.Count <= 0 || spec.Fixture == nil {
+		return nil, errors.New("a generate block needs a kind, a positive count and a fixture")
+	}
+	return &spec, nil
+}
+
+// fixtureRef returns the ref of a fixture, empty when it is not referenced
+func fixtureRef(fields map[string]any) (string, error) {
+	value, ok := fields[refKey]
+	if !ok {
+		return "", nil
+	}
+	ref, ok := value.(string)
+	if !ok || ref == "" || strings.Contains(ref, ".") {
+		return "", fmt.Errorf("%s must be a name without dots", refKey)
+	}
+	return ref, nil
+}
+
+// resolve returns a copy of the decoded fixture v with its placeholders replaced by the values of lookup.
+// A string that is a single placeholder takes the value as is, e.g. a number, otherwise the value is formatted into it.
+func resolve(v any, lookup func(name string) (any, error)) (any, error) {
+	switch v := v.(type) {
+	case map[string]any:
+		output := make(map[string]any, len(v))
+		for key, value := range v {
+			resolved, err := resolve(value, lookup)
+			if err != nil {
+				return nil, err
+			}
+			output[key] = resolved
+		}
+		return output, nil
+	case []any:
+		output := make([]any, len(v))
+		for i, value := range v {
+			resolved, err := resolve(value, lookup)
+			if err != nil {
+				return nil, err
+			}
+			output[i] = resolved
+		}
+		return output, nil
+	case string:
+		return resolveString(v, lookup)
+	}
+	return v, nil
+}
+
+func resolveString(s string, lookup func(name string) (any, error)) (any, error) {
+	if match := placeholderPattern.FindStringSubmatch(s); match != nil && match[0] == s && !strings.HasPrefix(s, "$$") {
+		return lookup(strings.TrimSpace(match[1]))
+	}
+
+	var err error
+	output := placeholderPattern.ReplaceAllStringFunc(s, func(placeholder string) string {
+		if strings.HasPrefix(placeholder, "$$") {
+			return placeholder[1:]
+		}
+		value, lookupErr := lookup(strings.TrimSpace(placeholder[2 : len(placeholder)-1]))
+		if lookupErr != nil {
+			err = errors.Join(err, lookupErr)
+			return placeholder
+		}
+		return fmt.Sprint(value)
+	})
+	return output, err
+}
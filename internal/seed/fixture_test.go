@@ -0,0 +1,64 @@
+package seed
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	values := map[string]any{
+		"n":                   float64(7),
+		"fake.name":           "Alice Smith",
+		"users.alice.id":      "0b8e5cf4-1b9a-4c3e-9b1a-7f1d2f1a9c10",
+		"users.alice.profile": map[string]any{"sex": "female"},
+	}
+	lookup := func(name string) (any, error) {
+		value, ok := values[name]
+		if !ok {
+			return nil, errors.New("unknown placeholder " + name)
+		}
+		return value, nil
+	}
+
+	tests := []struct {
+		name    string
+		input   any
+		want    any
+		wantErr bool
+	}{
+		{name: "plain string", input: "Alice", want: "Alice"},
+		{name: "single placeholder keeps the type", input: "${n}", want: float64(7)},
+		{name: "single placeholder keeps a mapping", input: "${users.alice.profile}", want: map[string]any{"sex": "female"}},
+		{name: "spaces around the name", input: "${ fake.name }", want: "Alice Smith"},
+		{name: "placeholders formatted into a string", input: "user-${n}@${fake.name}", want: "user-7@Alice Smith"},
+		{name: "escaped placeholder", input: "$${n}", want: "${n}"},
+		{name: "escaped next to a placeholder", input: "$${n} is ${n}", want: "${n} is 7"},
+		{
+			name:  "nested mappings and lists",
+			input: map[string]any{"owner": "${users.alice.id}", "tags": []any{"${n}", "fixed", true}},
+			want:  map[string]any{"owner": "0b8e5cf4-1b9a-4c3e-9b1a-7f1d2f1a9c10", "tags": []any{float64(7), "fixed", true}},
+		},
+		{name: "other values are kept", input: float64(3), want: float64(3)},
+		{name: "unknown placeholder", input: "${users.bob.id}", wantErr: true},
+		{name: "unknown placeholder in a string", input: "id ${users.bob.id} of ${n}", wantErr: true},
+		{name: "unknown placeholder in a list", input: []any{"${nope}"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolve(tt.input, lookup)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolve: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
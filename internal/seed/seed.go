@@ -0,0 +1,250 @@
+package seed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Kind seeds the fixtures of an entity kind, e.g. users
+type Kind interface {
+	// Name is the key of the fixtures of the kind in a fixture file
+	Name() string
+	// Seed creates the entity described by fixture unless it already exists, found by its natural key.
+	// It returns the entity as seeded, the fields later fixtures reference, and whether it was created.
+	Seed(ctx context.Context, fixture json.RawMessage) (entity any, created bool, err error)
+}
+
+// Result counts the fixtures of a kind
+type Result struct {
+	Kind     string
+	Created  int
+	Existing int
+}
+
+// Seeder seeds fixture files. Fixtures are seeded in the order of the files, the kinds of a file in the order
+// they appear and the fixtures of a kind in order, so a fixture references the fixtures seeded before it.
+type Seeder struct {
+	kinds map[string]Kind
+}
+
+func NewSeeder(kinds ...Kind) *Seeder {
+	s := &Seeder{kinds: make(map[string]Kind, len(kinds))}
+	for _, kind := range kinds {
+		s.kinds[kind.Name()] = kind
+	}
+	return s
+}
+
+// Run seeds the fixture files in order and returns the counts of every kind seeded. It stops at the first fixture
+// failing, the fixtures seeded before it are kept and are found as existing when the files are seeded again.
+func (s *Seeder) Run(ctx context.Context, files []string) ([]Result, error) {
+	run := &run{seeder: s, refs: map[string]any{}, counts: map[string]*Result{}}
+	for _, file := range files {
+		if err := run.file(ctx, file); err != nil {
+			return run.results(), fmt.Errorf("%s: %w", file, err)
+		}
+	}
+	return run.results(), nil
+}
+
+// SetFiles returns the fixture files of the seed set of environment, the YAML and JSON files of dir/environment in name order
+func SetFiles(dir, environment string) ([]string, error) {
+	setDir := filepath.Join(dir, environment)
+	entries, err := os.ReadDir(setDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no seed set for environment %q, %s does not exist", environment, setDir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".yaml", ".yml", ".json":
+			files = append(files, filepath.Join(setDir, entry.Name()))
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("seed set %s holds no fixture files", setDir)
+	}
+	slices.Sort(files)
+	return files, nil
+}
+
+// run holds the state of a Run, refs holds the seeded entities by kind.ref
+type run struct {
+	seeder *Seeder
+	refs   map[string]any
+	counts map[string]*Result
+	order  []string
+}
+
+func (r *run) file(ctx context.Context, path string) error {
+	sections, err := readFile(path)
+	if err != nil {
+		return err
+	}
+
+	for _, section := range sections {
+		if section.kind == generateKey {
+			if err := r.generate(ctx, section.fixtures); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for i, fixture := range section.fixtures {
+			if err := r.seed(ctx, section.kind, fixture, nil); err != nil {
+				return fmt.Errorf("%s[%d]: %w", section.kind, i, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *run) generate(ctx context.Context, blocks []any) error {
+	for i, block := range blocks {
+		spec, err := parseGenerate(block)
+		if err != nil {
+			return fmt.Errorf("%s[%d]: %w", generateKey, i, err)
+		}
+
+		for n := 1; n <= spec.Count; n++ {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			vars := generateVars(spec.Seed, n)
+			if err := r.seed(ctx, spec.Kind, spec.Fixture, vars); err != nil {
+				return fmt.Errorf("%s[%d] %s %d: %w", generateKey, i, spec.Kind, n, err)
+			}
+		}
+	}
+	return nil
+}
+
+// seed resolves the placeholders of fixture against vars and the seeded entities, then seeds it
+func (r *run) seed(ctx context.Context, kindName string, fixture any, vars map[string]any) error {
+	kind, ok := r.seeder.kinds[kindName]
+	if !ok {
+		return fmt.Errorf("unknown kind %q", kindName)
+	}
+
+	resolved, err := resolve(fixture, func(name string) (any, error) {
+		return r.lookup(name, vars)
+	})
+	if err != nil {
+		return err
+	}
+
+	fields, ok := resolved.(map[string]any)
+	if !ok {
+		return errors.New("a fixture must be a mapping")
+	}
+	ref, err := fixtureRef(fields)
+	if err != nil {
+		return err
+	}
+	delete(fields, refKey)
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	entity, created, err := kind.Seed(ctx, data)
+	if err != nil {
+		return err
+	}
+
+	if ref != "" {
+		key := kindName + "." + ref
+		if _, ok := r.refs[key]; ok {
+			return fmt.Errorf("duplicate ref %q", key)
+		}
+		// references read the entity as it would be serialized, by its json field names
+		if r.refs[key], err = toValue(entity); err != nil {
+			return err
+		}
+	}
+
+	result := r.count(kindName)
+	if created {
+		result.Created++
+	} else {
+		result.Existing++
+	}
+	return nil
+}
+
+// lookup returns the value of placeholder name, a generate variable or a field of a seeded entity, e.g. users.alice.id
+func (r *run) lookup(name string, vars map[string]any) (any, error) {
+	path := strings.Split(name, ".")
+	if value, ok := lookupPath(vars, path); ok {
+		return value, nil
+	}
+
+	if len(path) < 3 {
+		return nil, fmt.Errorf("unknown placeholder ${%s}, expected ${kind.ref.field}", name)
+	}
+	entity, ok := r.refs[path[0]+"."+path[1]]
+	if !ok {
+		return nil, fmt.Errorf("placeholder ${%s} references %s.%s, which is not seeded before it", name, path[0], path[1])
+	}
+	value, ok := lookupPath(entity, path[2:])
+	if !ok {
+		return nil, fmt.Errorf("placeholder ${%s}: %s.%s has no field %s", name, path[0], path[1], strings.Join(path[2:], "."))
+	}
+	return value, nil
+}
+
+func (r *run) count(kind string) *Result {
+	result, ok := r.counts[kind]
+	if !ok {
+		result = &Result{Kind: kind}
+		r.counts[kind] = result
+		r.order = append(r.order, kind)
+	}
+	return result
+}
+
+func (r *run) results() []Result {
+	results := make([]Result, len(r.order))
+	for i, kind := range r.order {
+		results[i] = *r.counts[kind]
+	}
+	return results
+}
+
+// lookupPath returns the value at path in the decoded JSON value v
+func lookupPath(v any, path []string) (any, bool) {
+	for _, key := range path {
+		fields, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if v, ok = fields[key]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// toValue decodes v the way encoding/json serializes it
+func toValue(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	err = json.Unmarshal(data, &value)
+	return value, err
+}
@@ -0,0 +1,90 @@
+package seed_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/alxhtp/monogo/config"
+	outboxrepositorymemory "github.com/alxhtp/monogo/internal/repository/outbox/memory"
+	transactionrepositorymemory "github.com/alxhtp/monogo/internal/repository/transaction/memory"
+	userrepositorymemory "github.com/alxhtp/monogo/internal/repository/user/memory"
+	"github.com/alxhtp/monogo/internal/seed"
+	userserializerimplementation "github.com/alxhtp/monogo/internal/serializer/user/implementation"
+	userusecase "github.com/alxhtp/monogo/internal/usecase/user"
+	userusecaseimplementation "github.com/alxhtp/monogo/internal/usecase/user/implementation"
+	"github.com/alxhtp/monogo/pkg/dto"
+)
+
+func newUserUsecase() userusecase.UserUsecase {
+	return userusecaseimplementation.NewUserUsecase(
+		userrepositorymemory.NewUserRepository(),
+		outboxrepositorymemory.NewOutboxRepository(),
+		transactionrepositorymemory.NewTransactionRepository(),
+		userserializerimplementation.NewUserSerializer(),
+		nil,
+		config.UserImportConfig{},
+		config.UserEventsConfig{},
+	)
+}
+
+func TestSeedingTheDevelopmentSetAgainIsANoOp(t *testing.T) {
+	files, err := seed.SetFiles("../../fixtures", "development")
+	if err != nil {
+		t.Fatalf("set files: %v", err)
+	}
+	usecase := newUserUsecase()
+	seeder := seed.NewSeeder(seed.Users(usecase))
+	ctx := context.Background()
+
+	results, err := seeder.Run(ctx, files)
+	if err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	if want := []seed.Result{{Kind: "users", Created: 28}}; !reflect.DeepEqual(results, want) {
+		t.Fatalf("first run: got %+v, want %+v", results, want)
+	}
+
+	results, err = seeder.Run(ctx, files)
+	if err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	if want := []seed.Result{{Kind: "users", Existing: 28}}; !reflect.DeepEqual(results, want) {
+		t.Fatalf("second run: got %+v, want %+v", results, want)
+	}
+
+	// Bob references the address of Alice
+	email := "bob@example.com"
+	bob := usecase.GetUsersByFilter(ctx, &dto.ReqGetUser{Email: &email})
+	if len(bob.Data) != 1 || bob.Data[0].Metadata.Address != "1 Infinite Loop, Cupertino, USA" {
+		t.Fatalf("bob: %+v", bob.Data)
+	}
+}
+
+func TestSeedRejectsInvalidFixtures(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+	}{
+		{name: "unknown kind", fixture: "posts:\n  - title: Hello\n"},
+		{name: "missing email", fixture: "users:\n  - name: Alice\n"},
+		{name: "unknown field", fixture: "users:\n  - name: Alice\n    email: alice@example.com\n    role: admin\n"},
+		{name: "reference to a later fixture", fixture: "users:\n  - name: ${users.bob.name}\n    email: alice@example.com\n"},
+		{name: "duplicate ref", fixture: "users:\n" +
+			"  - {ref: a, name: Alice, email: alice@example.com, metadata: {sex: female, address: Home, phone: \"+14155550101\"}}\n" +
+			"  - {ref: a, name: Bob, email: bob@example.com, metadata: {sex: male, address: Home, phone: \"+14155550102\"}}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "fixtures.yaml")
+			if err := os.WriteFile(path, []byte(tt.fixture), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := seed.NewSeeder(seed.Users(newUserUsecase())).Run(context.Background(), []string{path}); err == nil {
+				t.Fatal("got no error")
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package seed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	userusecase "github.com/alxhtp/monogo/internal/usecase/user"
+	"github.com/alxhtp/monogo/pkg/dto"
+	dtobase "github.com/alxhtp/monogo/pkg/dto/base"
+)
+
+const usersKind = "users"
+
+// users seeds users through the user usecase, a user is identified by its email
+type users struct {
+	userUsecase userusecase.UserUsecase
+}
+
+// Users returns the users kind, its fixtures are create user request bodies
+func Users(userUsecase userusecase.UserUsecase) Kind {
+	return &users{userUsecase: userUsecase}
+}
+
+func (k *users) Name() string {
+	return usersKind
+}
+
+func (k *users) Seed(ctx context.Context, fixture json.RawMessage) (any, bool, error) {
+	var req dto.ReqCreateUser
+	decoder := json.NewDecoder(bytes.NewReader(fixture))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		return nil, false, err
+	}
+	if req.Email == "" {
+		return nil, false, errors.New("email is required, it identifies the user on the next runs")
+	}
+
+	// deleted users keep their email, they are not created again either
+	includeDeleted := true
+	existing := k.userUsecase.GetUsersByFilter(ctx, &dto.ReqGetUser{Email: &req.Email, BaseReqQueryPagination: dtobase.BaseReqQueryPagination{IncludeDeleted: &includeDeleted}})
+	if !existing.Success {
+		return nil, false, fmt.Errorf("find user %s: %w", req.Email, &existing.BaseRes)
+	}
+	if len(existing.Data) > 0 {
+		return existing.Data[0], false, nil
+	}
+
+	res := k.userUsecase.CreateUser(ctx, &req)
+	if !res.Success {
+		return nil, false, fmt.Errorf("create user %s: %w", req.Email, &res.BaseRes)
+	}
+	return res.Data, true, nil
+}
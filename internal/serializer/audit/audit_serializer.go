@@ -0,0 +1,14 @@
+package auditserializer
+
+import (
+	"github.com/alxhtp/monogo/internal/entity"
+	entitybase "github.com/alxhtp/monogo/internal/entity/base"
+	"github.com/alxhtp/monogo/pkg/dto"
+)
+
+type AuditSerializer interface {
+	FilterDTOToEntity(filter dto.ReqGetAuditLog) (entity.AuditLogFilter, error)
+
+	EntityToResponse(entity entity.AuditLog) dto.ResAuditLog
+	EntityToResponseList(entities []entity.AuditLog, pagination entitybase.BasePaginationResult, code int, message string, stacktrace *string) dto.ResAuditLogList
+}
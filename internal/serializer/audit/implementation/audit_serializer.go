@@ -0,0 +1,98 @@
+package auditserializerimplementation
+
+import (
+	"net/http"
+
+	"github.com/alxhtp/monogo/internal/entity"
+	entitybase "github.com/alxhtp/monogo/internal/entity/base"
+	auditserializer "github.com/alxhtp/monogo/internal/serializer/audit"
+	"github.com/alxhtp/monogo/pkg/dto"
+	dtobase "github.com/alxhtp/monogo/pkg/dto/base"
+	parserhelper "github.com/alxhtp/monogo/pkg/helper/parser"
+	queryhelper "github.com/alxhtp/monogo/pkg/helper/query"
+)
+
+type auditSerializer struct{}
+
+func NewAuditSerializer() auditserializer.AuditSerializer {
+	return &auditSerializer{}
+}
+
+func (s *auditSerializer) FilterDTOToEntity(filter dto.ReqGetAuditLog) (entity.AuditLogFilter, error) {
+	var (
+		output entity.AuditLogFilter
+		err    error
+	)
+
+	if filter.EntityType != nil {
+		output.EntityType = filter.EntityType
+	}
+
+	if filter.EntityIDs != nil {
+		output.EntityIDs = parserhelper.SliceStringsStr(*filter.EntityIDs)
+	}
+
+	if filter.Actor != nil {
+		output.Actor = filter.Actor
+	}
+
+	if filter.RequestID != nil {
+		output.RequestID = filter.RequestID
+	}
+
+	if filter.Operation != nil {
+		output.Operation = filter.Operation
+	}
+
+	output.PaginationFilter = queryhelper.SerializeFilterPaginationDtoToEntity(filter.BaseReqQueryPagination)
+	// audit logs are append only, they have no updated_at and deleted_at columns
+	output.PaginationFilter.MinUpdated = nil
+	output.PaginationFilter.MaxUpdated = nil
+	output.PaginationFilter.WithDeleted = nil
+
+	return output, err
+}
+
+func (s *auditSerializer) EntityToResponse(entity entity.AuditLog) dto.ResAuditLog {
+	changes := make(map[string]dto.AuditChange, len(entity.Changes.Item))
+	for column, change := range entity.Changes.Item {
+		changes[column] = dto.AuditChange{
+			Before: change.Before,
+			After:  change.After,
+		}
+	}
+
+	return dto.ResAuditLog{
+		ID:         entity.ID,
+		Actor:      entity.Actor,
+		RequestID:  entity.RequestID,
+		EntityType: entity.EntityType,
+		EntityID:   entity.EntityID,
+		Operation:  entity.Operation,
+		Before:     entity.Before.Item,
+		After:      entity.After.Item,
+		Changes:    changes,
+		CreatedAt:  entity.CreatedAt,
+	}
+}
+
+func (s *auditSerializer) EntityToResponseList(entities []entity.AuditLog, pagination entitybase.BasePaginationResult, code int, message string, stacktrace *string) dto.ResAuditLogList {
+	responses := make([]dto.ResAuditLog, len(entities))
+	for i, entity := range entities {
+		responses[i] = s.EntityToResponse(entity)
+	}
+	isSuccess := code >= http.StatusOK && code < http.StatusMultipleChoices
+
+	return dto.ResAuditLogList{
+		BaseResPagination: dtobase.BaseResPagination{
+			BaseRes: dtobase.BaseRes{Code: code, Message: message, Stacktrace: stacktrace, Success: isSuccess},
+			Page: dtobase.BasePagination{
+				Offset:  pagination.Offset,
+				Limit:   pagination.Limit,
+				Count:   pagination.Count,
+				OrderBy: pagination.OrderBy,
+			},
+		},
+		Data: responses,
+	}
+}
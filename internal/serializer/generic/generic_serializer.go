@@ -0,0 +1,11 @@
+package genericserializer
+
+// Serializer converts the request DTOs of the entity E into E, its filter F and update maps, and E into its response DTO.
+// Entity serializers embed it and add the conversions of their own.
+type Serializer[E, F, CreateReq, UpdateReq, FilterReq, Res any] interface {
+	FilterDTOToEntity(filter FilterReq) (F, error)
+	UpdateDTOToMap(update UpdateReq) (map[string]any, error)
+	CreateDTOToEntity(create CreateReq) (E, error)
+
+	EntityToResponse(entity E) Res
+}
@@ -0,0 +1,62 @@
+package tenantserializerimplementation
+
+import (
+	"net/http"
+
+	"github.com/alxhtp/monogo/internal/entity"
+	tenantserializer "github.com/alxhtp/monogo/internal/serializer/tenant"
+	"github.com/alxhtp/monogo/pkg/dto"
+	dtobase "github.com/alxhtp/monogo/pkg/dto/base"
+)
+
+type tenantSerializer struct{}
+
+func NewTenantSerializer() tenantserializer.TenantSerializer {
+	return &tenantSerializer{}
+}
+
+func (s *tenantSerializer) EntityToResponse(entity entity.Tenant) dto.ResTenant {
+	return dto.ResTenant{
+		ID:         entity.ID,
+		Schema:     entity.Schema,
+		Migrations: entity.Migrations,
+		MigratedAt: entity.MigratedAt,
+	}
+}
+
+func (s *tenantSerializer) EntityToResponseSingle(entity *entity.Tenant, code int, message string, stacktrace *string) dto.ResTenantSingle {
+	var data *dto.ResTenant
+	if entity != nil {
+		res := s.EntityToResponse(*entity)
+		data = &res
+	}
+
+	isSuccess := code >= http.StatusOK && code < http.StatusMultipleChoices
+	return dto.ResTenantSingle{
+		BaseRes: dtobase.BaseRes{
+			Success:    isSuccess,
+			Code:       code,
+			Message:    message,
+			Stacktrace: stacktrace,
+		},
+		Data: data,
+	}
+}
+
+func (s *tenantSerializer) EntityToResponseList(entities []entity.Tenant, code int, message string, stacktrace *string) dto.ResTenantList {
+	responses := make([]dto.ResTenant, len(entities))
+	for i, entity := range entities {
+		responses[i] = s.EntityToResponse(entity)
+	}
+
+	isSuccess := code >= http.StatusOK && code < http.StatusMultipleChoices
+	return dto.ResTenantList{
+		BaseRes: dtobase.BaseRes{
+			Success:    isSuccess,
+			Code:       code,
+			Message:    message,
+			Stacktrace: stacktrace,
+		},
+		Data: responses,
+	}
+}
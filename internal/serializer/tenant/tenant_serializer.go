@@ -0,0 +1,12 @@
+package tenantserializer
+
+import (
+	"github.com/alxhtp/monogo/internal/entity"
+	"github.com/alxhtp/monogo/pkg/dto"
+)
+
+type TenantSerializer interface {
+	EntityToResponse(entity entity.Tenant) dto.ResTenant
+	EntityToResponseSingle(entity *entity.Tenant, code int, message string, stacktrace *string) dto.ResTenantSingle
+	EntityToResponseList(entities []entity.Tenant, code int, message string, stacktrace *string) dto.ResTenantList
+}
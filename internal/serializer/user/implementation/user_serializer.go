@@ -1,15 +1,11 @@
 package userserializerimplementation
 
 import (
-	"net/http"
-
 	"github.com/alxhtp/monogo/internal/entity"
-	entitybase "github.com/alxhtp/monogo/internal/entity/base"
 	userserializer "github.com/alxhtp/monogo/internal/serializer/user"
 	"github.com/alxhtp/monogo/pkg/constant"
 
 	"github.com/alxhtp/monogo/pkg/dto"
-	dtobase "github.com/alxhtp/monogo/pkg/dto/base"
 	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
 	parserhelper "github.com/alxhtp/monogo/pkg/helper/parser"
 	queryhelper "github.com/alxhtp/monogo/pkg/helper/query"
@@ -133,42 +129,3 @@ func (s *userSerializer) EntityToResponse(entity entity.User) dto.ResUser {
 		Metadata: userMetadata,
 	}
 }
-
-func (s *userSerializer) EntityToResponseSingle(entity *entity.User, code int, message string, stacktrace *string) dto.ResUserSingle {
-	var data *dto.ResUser
-	if entity != nil {
-		res := s.EntityToResponse(*entity)
-		data = &res
-	}
-
-	isSuccess := code >= http.StatusOK && code < http.StatusMultipleChoices
-	return dto.ResUserSingle{
-		BaseRes: dtobase.BaseRes{
-			Success: isSuccess,
-			Code:    code,
-			Message: message,
-		},
-		Data: data,
-	}
-}
-
-func (s *userSerializer) EntityToResponseList(entities []entity.User, pagination entitybase.BasePaginationResult, code int, message string, stacktrace *string) dto.ResUserList {
-	responses := make([]dto.ResUser, len(entities))
-	for i, entity := range entities {
-		responses[i] = s.EntityToResponse(entity)
-	}
-	isSuccess := code >= http.StatusOK && code < http.StatusMultipleChoices
-
-	return dto.ResUserList{
-		BaseResPagination: dtobase.BaseResPagination{
-			BaseRes: dtobase.BaseRes{Code: code, Message: message, Stacktrace: stacktrace, Success: isSuccess},
-			Page: dtobase.BasePagination{
-				Offset:  pagination.Offset,
-				Limit:   pagination.Limit,
-				Count:   pagination.Count,
-				OrderBy: pagination.OrderBy,
-			},
-		},
-		Data: responses,
-	}
-}
@@ -0,0 +1,219 @@
+package webhookserializerimplementation
+
+import (
+	"net/http"
+
+	"github.com/alxhtp/monogo/internal/entity"
+	entitybase "github.com/alxhtp/monogo/internal/entity/base"
+	webhookserializer "github.com/alxhtp/monogo/internal/serializer/webhook"
+	"github.com/alxhtp/monogo/pkg/constant"
+	"github.com/alxhtp/monogo/pkg/dto"
+	dtobase "github.com/alxhtp/monogo/pkg/dto/base"
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
+	parserhelper "github.com/alxhtp/monogo/pkg/helper/parser"
+	queryhelper "github.com/alxhtp/monogo/pkg/helper/query"
+	webhookhelper "github.com/alxhtp/monogo/pkg/helper/webhook"
+)
+
+type webhookSerializer struct{}
+
+func NewWebhookSerializer() webhookserializer.WebhookSerializer {
+	return &webhookSerializer{}
+}
+
+func (s *webhookSerializer) FilterDTOToEntity(filter dto.ReqGetWebhook) (entity.WebhookFilter, error) {
+	var (
+		output entity.WebhookFilter
+		err    error
+	)
+
+	if filter.IDs != nil {
+		output.IDs, err = parserhelper.SliceUUIDsStr(*filter.IDs)
+		if err != nil {
+			return output, err
+		}
+	}
+
+	if filter.Name != nil {
+		output.Name = filter.Name
+	}
+
+	if filter.Active != nil {
+		output.Active = filter.Active
+	}
+
+	if filter.Event != nil {
+		output.EventType = filter.Event
+	}
+
+	output.PaginationFilter = queryhelper.SerializeFilterPaginationDtoToEntity(filter.BaseReqQueryPagination)
+
+	return output, err
+}
+
+func (s *webhookSerializer) UpdateDTOToMap(update dto.ReqUpdateWebhook) (map[string]any, error) {
+	var (
+		output = make(map[string]any)
+		err    error
+	)
+
+	if update.Name != nil {
+		output["name"] = update.Name
+	}
+
+	if update.URL != nil {
+		output["url"] = update.URL
+	}
+
+	if update.Events != nil {
+		output["events"] = databasehelper.GormJsonType[[]string]{Item: *update.Events}
+	}
+
+	if update.Secret != nil {
+		output["secret"] = update.Secret
+	}
+
+	if update.Active != nil {
+		output["active"] = update.Active
+	}
+
+	return output, err
+}
+
+func (s *webhookSerializer) CreateDTOToEntity(create dto.ReqCreateWebhook) (entity.Webhook, error) {
+	var (
+		output entity.Webhook
+		err    error
+	)
+
+	output = entity.Webhook{
+		Name:   create.Name,
+		URL:    create.URL,
+		Events: databasehelper.GormJsonType[[]string]{Item: create.Events},
+		Active: true,
+	}
+
+	if create.Active != nil {
+		output.Active = *create.Active
+	}
+
+	if create.Secret != nil {
+		output.Secret = *create.Secret
+	} else {
+		output.Secret, err = webhookhelper.GenerateSecret()
+	}
+
+	return output, err
+}
+
+func (s *webhookSerializer) EntityToResponse(entity entity.Webhook) dto.ResWebhook {
+	return dto.ResWebhook{
+		ID:        entity.ID,
+		Name:      entity.Name,
+		URL:       entity.URL,
+		Events:    entity.Events.Item,
+		Active:    entity.Active,
+		CreatedAt: entity.CreatedAt,
+		UpdatedAt: entity.UpdatedAt,
+	}
+}
+
+func (s *webhookSerializer) EntityToResponseSingle(entity *entity.Webhook, code int, message string, stacktrace *string) dto.ResWebhookSingle {
+	var data *dto.ResWebhook
+	if entity != nil {
+		res := s.EntityToResponse(*entity)
+		data = &res
+	}
+
+	isSuccess := code >= http.StatusOK && code < http.StatusMultipleChoices
+	return dto.ResWebhookSingle{
+		BaseRes: dtobase.BaseRes{
+			Success:    isSuccess,
+			Code:       code,
+			Message:    message,
+			Stacktrace: stacktrace,
+		},
+		Data: data,
+	}
+}
+
+func (s *webhookSerializer) DeliveryFilterDTOToEntity(filter dto.ReqGetWebhookDelivery) (entity.WebhookDeliveryFilter, error) {
+	var (
+		output entity.WebhookDeliveryFilter
+		err    error
+	)
+
+	if filter.Status != nil {
+		status := constant.WebhookDeliveryStatus(*filter.Status)
+		output.Status = &status
+	}
+
+	if filter.EventType != nil {
+		output.EventType = filter.EventType
+	}
+
+	output.PaginationFilter = queryhelper.SerializeFilterPaginationDtoToEntity(filter.BaseReqQueryPagination)
+	// deliveries are never soft deleted
+	output.PaginationFilter.WithDeleted = nil
+
+	return output, err
+}
+
+func (s *webhookSerializer) DeliveryEntityToResponse(entity entity.WebhookDelivery) dto.ResWebhookDelivery {
+	return dto.ResWebhookDelivery{
+		ID:             entity.ID,
+		WebhookID:      entity.WebhookID,
+		EventID:        entity.EventID,
+		EventType:      entity.EventType,
+		Status:         string(entity.Status),
+		Attempts:       entity.Attempts,
+		Redeliveries:   entity.Redeliveries,
+		NextAttemptAt:  entity.NextAttemptAt,
+		LastStatusCode: entity.LastStatusCode,
+		LastError:      entity.LastError,
+		LastResponse:   entity.LastResponse,
+		DeliveredAt:    entity.DeliveredAt,
+		CreatedAt:      entity.CreatedAt,
+		UpdatedAt:      entity.UpdatedAt,
+	}
+}
+
+func (s *webhookSerializer) DeliveryEntityToResponseSingle(entity *entity.WebhookDelivery, code int, message string, stacktrace *string) dto.ResWebhookDeliverySingle {
+	var data *dto.ResWebhookDelivery
+	if entity != nil {
+		res := s.DeliveryEntityToResponse(*entity)
+		data = &res
+	}
+
+	isSuccess := code >= http.StatusOK && code < http.StatusMultipleChoices
+	return dto.ResWebhookDeliverySingle{
+		BaseRes: dtobase.BaseRes{
+			Success:    isSuccess,
+			Code:       code,
+			Message:    message,
+			Stacktrace: stacktrace,
+		},
+		Data: data,
+	}
+}
+
+func (s *webhookSerializer) DeliveryEntityToResponseList(entities []entity.WebhookDelivery, pagination entitybase.BasePaginationResult, code int, message string, stacktrace *string) dto.ResWebhookDeliveryList {
+	responses := make([]dto.ResWebhookDelivery, len(entities))
+	for i, entity := range entities {
+		responses[i] = s.DeliveryEntityToResponse(entity)
+	}
+	isSuccess := code >= http.StatusOK && code < http.StatusMultipleChoices
+
+	return dto.ResWebhookDeliveryList{
+		BaseResPagination: dtobase.BaseResPagination{
+			BaseRes: dtobase.BaseRes{Code: code, Message: message, Stacktrace: stacktrace, Success: isSuccess},
+			Page: dtobase.BasePagination{
+				Offset:  pagination.Offset,
+				Limit:   pagination.Limit,
+				Count:   pagination.Count,
+				OrderBy: pagination.OrderBy,
+			},
+		},
+		Data: responses,
+	}
+}
@@ -0,0 +1,19 @@
+package webhookserializer
+
+import (
+	"github.com/alxhtp/monogo/internal/entity"
+	entitybase "github.com/alxhtp/monogo/internal/entity/base"
+	genericserializer "github.com/alxhtp/monogo/internal/serializer/generic"
+	"github.com/alxhtp/monogo/pkg/dto"
+)
+
+type WebhookSerializer interface {
+	genericserializer.Serializer[entity.Webhook, entity.WebhookFilter, dto.ReqCreateWebhook, dto.ReqUpdateWebhook, dto.ReqGetWebhook, dto.ResWebhook]
+
+	EntityToResponseSingle(entity *entity.Webhook, code int, message string, stacktrace *string) dto.ResWebhookSingle
+
+	DeliveryFilterDTOToEntity(filter dto.ReqGetWebhookDelivery) (entity.WebhookDeliveryFilter, error)
+	DeliveryEntityToResponse(entity entity.WebhookDelivery) dto.ResWebhookDelivery
+	DeliveryEntityToResponseSingle(entity *entity.WebhookDelivery, code int, message string, stacktrace *string) dto.ResWebhookDeliverySingle
+	DeliveryEntityToResponseList(entities []entity.WebhookDelivery, pagination entitybase.BasePaginationResult, code int, message string, stacktrace *string) dto.ResWebhookDeliveryList
+}
@@ -0,0 +1,214 @@
+package graphqlserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/alxhtp/monogo/config"
+	userusecase "github.com/alxhtp/monogo/internal/usecase/user"
+	errorhelper "github.com/alxhtp/monogo/pkg/helper/error"
+	"github.com/gofiber/fiber/v2"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// Request is a single GraphQL operation, several can be sent at once as a JSON array
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+type Handler struct {
+	schema       graphql.Schema
+	userUsecase  userusecase.UserUsecase
+	limits       queryLimits
+	maxBatchSize int
+}
+
+func NewHandler(cfg config.GraphqlConfig, userUsecase userusecase.UserUsecase) (*Handler, error) {
+	schema, err := NewSchema(userUsecase)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Handler{
+		schema:      schema,
+		userUsecase: userUsecase,
+		limits: queryLimits{
+			maxDepth:      cfg.GraphqlMaxDepth,
+			maxComplexity: cfg.GraphqlMaxComplexity,
+		},
+		maxBatchSize: cfg.GraphqlMaxBatchSize,
+	}, nil
+}
+
+// Serve executes the operations of a POST body, or of the query string of a GET request where mutations are refused.
+// Operations of a batch share the user loader, so a user fetched by one of them is not fetched again.
+// POST bodies must be sent as application/json, which a cross-site form cannot do without a preflight.
+func (h *Handler) Serve(c *fiber.Ctx) error {
+	if c.Method() == fiber.MethodPost && !isJSON(c) {
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(errorResult(errors.New("content type must be application/json")))
+	}
+
+	requests, batch, err := h.parseRequests(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorResult(err))
+	}
+	if batch && h.maxBatchSize > 0 && len(requests) > h.maxBatchSize {
+		return c.Status(fiber.StatusBadRequest).JSON(errorResult(fmt.Errorf("batch of %d operations exceeds the maximum of %d", len(requests), h.maxBatchSize)))
+	}
+
+	ctx := withUserLoader(c.Context(), newUserLoader(h.userUsecase))
+	queryOnly := c.Method() == fiber.MethodGet
+
+	results := make([]*graphql.Result, len(requests))
+	rejected := false
+	for i, req := range requests {
+		doc, err := h.prepare(req, queryOnly)
+		if err != nil {
+			results[i] = errorResult(err)
+			rejected = true
+			continue
+		}
+
+		results[i] = graphql.Execute(graphql.ExecuteParams{
+			Schema:        h.schema,
+			AST:           doc,
+			OperationName: req.OperationName,
+			Args:          req.Variables,
+			Context:       ctx,
+		})
+		results[i].Errors = withExtensions(results[i].Errors)
+	}
+
+	if batch {
+		return c.JSON(results)
+	}
+	if rejected {
+		return c.Status(fiber.StatusBadRequest).JSON(results[0])
+	}
+	return c.JSON(results[0])
+}
+
+func (h *Handler) parseRequests(c *fiber.Ctx) ([]Request, bool, error) {
+	if c.Method() == fiber.MethodGet {
+		req := Request{
+			Query:         c.Query("query"),
+			OperationName: c.Query("operationName"),
+		}
+		if variables := c.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				return nil, false, fmt.Errorf("invalid variables: %w", err)
+			}
+		}
+		return []Request{req}, false, nil
+	}
+
+	body := strings.TrimSpace(string(c.Body()))
+	if strings.HasPrefix(body, "[") {
+		var requests []Request
+		if err := json.Unmarshal([]byte(body), &requests); err != nil {
+			return nil, false, fmt.Errorf("invalid request body: %w", err)
+		}
+		if len(requests) == 0 {
+			return nil, false, errors.New("empty batch")
+		}
+		return requests, true, nil
+	}
+
+	var req Request
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		return nil, false, fmt.Errorf("invalid request body: %w", err)
+	}
+	return []Request{req}, false, nil
+}
+
+func isJSON(c *fiber.Ctx) bool {
+	mediaType, _, _ := strings.Cut(string(c.Request().Header.ContentType()), ";")
+	return strings.EqualFold(strings.TrimSpace(mediaType), fiber.MIMEApplicationJSON)
+}
+
+// prepare parses and validates req and enforces the query limits before anything is resolved
+func (h *Handler) prepare(req Request, queryOnly bool) (*ast.Document, error) {
+	if strings.TrimSpace(req.Query) == "" {
+		return nil, errors.New("query is required")
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if validation := graphql.ValidateDocument(&h.schema, doc, nil); !validation.IsValid {
+		return nil, validationErrors(validation.Errors)
+	}
+
+	if queryOnly {
+		for _, definition := range doc.Definitions {
+			if operation, ok := definition.(*ast.OperationDefinition); ok && operation.Operation != ast.OperationTypeQuery {
+				return nil, fmt.Errorf("%s operations must be sent with POST", operation.Operation)
+			}
+		}
+	}
+
+	if err := h.limits.check(doc, req.Variables); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// validationErrors keeps every validation error of a document
+type validationErrors []gqlerrors.FormattedError
+
+func (e validationErrors) Error() string {
+	return e[0].Message
+}
+
+func errorResult(err error) *graphql.Result {
+	var validation validationErrors
+	if errors.As(err, &validation) {
+		return &graphql.Result{Errors: validation}
+	}
+
+	return &graphql.Result{Errors: []gqlerrors.FormattedError{{
+		Message:    err.Error(),
+		Extensions: map[string]any{"code": errorhelper.ErrBadRequest},
+	}}}
+}
+
+// withExtensions restores the extensions of errors returned by batched resolvers,
+// graphql-go drops them while wrapping the error of a deferred resolver
+func withExtensions(errs []gqlerrors.FormattedError) []gqlerrors.FormattedError {
+	for i := range errs {
+		if errs[i].Extensions != nil {
+			continue
+		}
+
+		var err error = errs[i]
+		for err != nil {
+			if extended, ok := err.(gqlerrors.ExtendedError); ok {
+				errs[i].Extensions = extended.Extensions()
+				break
+			}
+
+			switch wrapped := err.(type) {
+			case gqlerrors.FormattedError:
+				err = wrapped.OriginalError()
+			case *gqlerrors.Error:
+				err = wrapped.OriginalError
+			default:
+				err = nil
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,155 @@
+package graphqlserver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+const (
+	// defaultListLimit and maxListLimit mirror the pagination defaults of the repositories
+	defaultListLimit = 100
+	maxListLimit     = 1000
+)
+
+// listFields are the fields returning a page of items, their selections are multiplied by the requested limit
+var listFields = map[string]bool{
+	"users": true,
+}
+
+// queryLimits rejects documents nested deeper than maxDepth or whose estimated cost exceeds maxComplexity.
+// Every selected field costs 1, the selections below a list field cost once per item of the requested page.
+// Introspection fields are not counted so tooling keeps working. A zero limit disables the check.
+type queryLimits struct {
+	maxDepth      int
+	maxComplexity int
+}
+
+type limitWalker struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]any
+}
+
+func (l queryLimits) check(doc *ast.Document, variables map[string]any) error {
+	walker := &limitWalker{
+		fragments: make(map[string]*ast.FragmentDefinition),
+		variables: variables,
+	}
+	for _, definition := range doc.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			walker.fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	for _, definition := range doc.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+
+		depth, complexity := walker.selectionSet(operation.SelectionSet, map[string]bool{})
+		if l.maxDepth > 0 && depth > l.maxDepth {
+			return fmt.Errorf("query depth %d exceeds the maximum of %d", depth, l.maxDepth)
+		}
+		if l.maxComplexity > 0 && complexity > l.maxComplexity {
+			return fmt.Errorf("query complexity %d exceeds the maximum of %d", complexity, l.maxComplexity)
+		}
+	}
+
+	return nil
+}
+
+// selectionSet returns the depth and the complexity of set, visited guards against fragment cycles
+func (w *limitWalker) selectionSet(set *ast.SelectionSet, visited map[string]bool) (int, int) {
+	if set == nil {
+		return 0, 0
+	}
+
+	depth, complexity := 0, 0
+	for _, selection := range set.Selections {
+		var selectionDepth, selectionComplexity int
+
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(selection.Name.Value, "__") {
+				continue
+			}
+
+			childDepth, childComplexity := w.selectionSet(selection.SelectionSet, visited)
+			if listFields[selection.Name.Value] {
+				childComplexity *= w.listLimit(selection)
+			}
+			selectionDepth, selectionComplexity = childDepth+1, childComplexity+1
+		case *ast.InlineFragment:
+			selectionDepth, selectionComplexity = w.selectionSet(selection.SelectionSet, visited)
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			fragment, ok := w.fragments[name]
+			if !ok || visited[name] {
+				continue
+			}
+			visited[name] = true
+			selectionDepth, selectionComplexity = w.selectionSet(fragment.SelectionSet, visited)
+			delete(visited, name)
+		}
+
+		depth = max(depth, selectionDepth)
+		complexity += selectionComplexity
+	}
+
+	return depth, complexity
+}
+
+// listLimit reads pagination.limit of field, either inline or from the variables, capped like the repositories
+func (w *limitWalker) listLimit(field *ast.Field) int {
+	limit := defaultListLimit
+
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != "pagination" {
+			continue
+		}
+
+		switch value := argument.Value.(type) {
+		case *ast.Variable:
+			if pagination, ok := w.variables[value.Name.Value].(map[string]any); ok {
+				if value, ok := toInt(pagination["limit"]); ok {
+					limit = value
+				}
+			}
+		case *ast.ObjectValue:
+			for _, objectField := range value.Fields {
+				if objectField.Name.Value != "limit" {
+					continue
+				}
+				switch value := objectField.Value.(type) {
+				case *ast.IntValue:
+					if parsed, err := strconv.Atoi(value.Value); err == nil {
+						limit = parsed
+					}
+				case *ast.Variable:
+					if parsed, ok := toInt(w.variables[value.Name.Value]); ok {
+						limit = parsed
+					}
+				}
+			}
+		}
+	}
+
+	if limit <= 0 {
+		return defaultListLimit
+	}
+	return min(limit, maxListLimit)
+}
+
+// toInt converts a decoded JSON number
+func toInt(value any) (int, bool) {
+	switch value := value.(type) {
+	case float64:
+		return int(value), true
+	case int:
+		return value, true
+	}
+	return 0, false
+}
@@ -0,0 +1,117 @@
+package graphqlserver
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	userusecase "github.com/alxhtp/monogo/internal/usecase/user"
+	"github.com/alxhtp/monogo/pkg/dto"
+	"github.com/google/uuid"
+)
+
+// loaderBatchSize is the number of ids fetched by a single GetUsersByFilter call, well below the pagination max limit
+const loaderBatchSize = 100
+
+type loaderKey struct{}
+
+// userLoader collects the user ids requested while a query level is resolved and fetches them
+// with one GetUsersByFilter call per batch once the first result is needed. Results are cached for the request.
+type userLoader struct {
+	userUsecase userusecase.UserUsecase
+
+	mu      sync.Mutex
+	pending []uuid.UUID
+	results map[uuid.UUID]*loaderResult
+}
+
+type loaderResult struct {
+	user map[string]any
+	err  error
+	done bool
+}
+
+func newUserLoader(userUsecase userusecase.UserUsecase) *userLoader {
+	return &userLoader{
+		userUsecase: userUsecase,
+		results:     make(map[uuid.UUID]*loaderResult),
+	}
+}
+
+func withUserLoader(ctx context.Context, loader *userLoader) context.Context {
+	return context.WithValue(ctx, loaderKey{}, loader)
+}
+
+// loaderFromContext returns the loader of the request, or a fresh one when the schema is executed without the handler
+func loaderFromContext(ctx context.Context, userUsecase userusecase.UserUsecase) *userLoader {
+	if loader, ok := ctx.Value(loaderKey{}).(*userLoader); ok {
+		return loader
+	}
+	return newUserLoader(userUsecase)
+}
+
+// Load queues id and returns a thunk resolved by graphql-go after the other fields of the level were visited
+func (l *userLoader) Load(ctx context.Context, id uuid.UUID) func() (any, error) {
+	l.mu.Lock()
+	if _, ok := l.results[id]; !ok {
+		l.results[id] = &loaderResult{}
+		l.pending = append(l.pending, id)
+	}
+	l.mu.Unlock()
+
+	return func() (any, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		result := l.results[id]
+		if !result.done {
+			l.dispatch(ctx)
+		}
+		if result.err != nil {
+			return nil, result.err
+		}
+		if result.user == nil {
+			return nil, nil
+		}
+		return result.user, nil
+	}
+}
+
+// dispatch fetches every pending id, callers must hold mu
+func (l *userLoader) dispatch(ctx context.Context) {
+	pending := l.pending
+	l.pending = nil
+
+	for start := 0; start < len(pending); start += loaderBatchSize {
+		batch := pending[start:min(start+loaderBatchSize, len(pending))]
+
+		values := make([]string, len(batch))
+		for i, id := range batch {
+			values[i] = id.String()
+		}
+		ids := strings.Join(values, ",")
+		limit := len(batch)
+
+		filter := &dto.ReqGetUser{IDs: &ids}
+		filter.Limit = &limit
+
+		res := l.userUsecase.GetUsersByFilter(ctx, filter)
+		if !res.Success {
+			err := newResponseError(res.BaseRes)
+			for _, id := range batch {
+				l.results[id].err = err
+				l.results[id].done = true
+			}
+			continue
+		}
+
+		for i := range res.Data {
+			if result, ok := l.results[res.Data[i].ID]; ok {
+				result.user = userToMap(&res.Data[i])
+			}
+		}
+		for _, id := range batch {
+			l.results[id].done = true
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package graphqlserver
+
+import (
+	"html/template"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// playgroundTemplate renders GraphiQL, its assets are loaded from a CDN
+var playgroundTemplate = template.Must(template.New("playground").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8" />
+  <title>GraphQL Playground</title>
+  <link rel="stylesheet" href="https://unpkg.com/graphiql@3/graphiql.min.css" />
+  <style>body { height: 100vh; margin: 0; } #graphiql { height: 100vh; }</style>
+</head>
+<body>
+  <div id="graphiql"></div>
+  <script crossorigin src="https://unpkg.com/react@18/umd/react.production.min.js"></script>
+  <script crossorigin src="https://unpkg.com/react-dom@18/umd/react-dom.production.min.js"></script>
+  <script crossorigin src="https://unpkg.com/graphiql@3/graphiql.min.js"></script>
+  <script>
+    const fetcher = GraphiQL.createFetcher({ url: {{.Endpoint}} });
+    ReactDOM.createRoot(document.getElementById('graphiql')).render(React.createElement(GraphiQL, { fetcher }));
+  </script>
+</body>
+</html>
+`))
+
+// Playground serves GraphiQL targeting endpoint
+func Playground(endpoint string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Type("html", "utf-8")
+		return playgroundTemplate.Execute(c, map[string]string{"Endpoint": endpoint})
+	}
+}
@@ -0,0 +1,352 @@
+package graphqlserver
+
+import (
+	"strings"
+	"time"
+
+	userusecase "github.com/alxhtp/monogo/internal/usecase/user"
+	"github.com/alxhtp/monogo/pkg/dto"
+	dtobase "github.com/alxhtp/monogo/pkg/dto/base"
+	errorhelper "github.com/alxhtp/monogo/pkg/helper/error"
+	"github.com/google/uuid"
+	"github.com/graphql-go/graphql"
+)
+
+var (
+	userMetadataType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "UserMetadata",
+		Fields: graphql.Fields{
+			"sex":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"address": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"phone":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+
+	userType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"id":       &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"name":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"email":    &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"status":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"metadata": &graphql.Field{Type: graphql.NewNonNull(userMetadataType)},
+		},
+	})
+
+	pageType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Page",
+		Fields: graphql.Fields{
+			"offset":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"limit":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"count":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"orderBy": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+
+	userListType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "UserList",
+		Fields: graphql.Fields{
+			"items": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(userType)))},
+			"page":  &graphql.Field{Type: graphql.NewNonNull(pageType)},
+		},
+	})
+
+	// userFilterInput mirrors dto.ReqGetUser
+	userFilterInput = graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "UserFilter",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"ids":     &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.ID))},
+			"name":    &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"email":   &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"status":  &graphql.InputObjectFieldConfig{Type: graphql.Int},
+			"sex":     &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"address": &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"phone":   &graphql.InputObjectFieldConfig{Type: graphql.String},
+		},
+	})
+
+	// paginationInput mirrors dtobase.BaseReqQueryPagination
+	paginationInput = graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "Pagination",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"createdAtGte":   &graphql.InputObjectFieldConfig{Type: graphql.DateTime},
+			"createdAtLte":   &graphql.InputObjectFieldConfig{Type: graphql.DateTime},
+			"updatedAtGte":   &graphql.InputObjectFieldConfig{Type: graphql.DateTime},
+			"updatedAtLte":   &graphql.InputObjectFieldConfig{Type: graphql.DateTime},
+			"includeDeleted": &graphql.InputObjectFieldConfig{Type: graphql.Boolean},
+			"showCount":      &graphql.InputObjectFieldConfig{Type: graphql.Boolean},
+			"offset":         &graphql.InputObjectFieldConfig{Type: graphql.Int},
+			"limit":          &graphql.InputObjectFieldConfig{Type: graphql.Int, Description: "Defaults to 100, at most 1000"},
+			"orderBy":        &graphql.InputObjectFieldConfig{Type: graphql.String, Description: "e.g. +created_at,-name"},
+		},
+	})
+
+	userMetadataInput = graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "UserMetadataInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"sex":     &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"address": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"phone":   &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+
+	createUserInput = graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "CreateUserInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"name":     &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"email":    &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"metadata": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(userMetadataInput)},
+		},
+	})
+
+	updateUserInput = graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "UpdateUserInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"name":     &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"email":    &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"status":   &graphql.InputObjectFieldConfig{Type: graphql.Int},
+			"metadata": &graphql.InputObjectFieldConfig{Type: userMetadataInput},
+		},
+	})
+)
+
+// NewSchema builds the user schema backed by userUsecase, user lookups by id are batched per request
+func NewSchema(userUsecase userusecase.UserUsecase) (graphql.Schema, error) {
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"user": &graphql.Field{
+				Type: userType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					id, err := uuid.Parse(p.Args["id"].(string))
+					if err != nil {
+						return nil, errInvalidID
+					}
+					return loaderFromContext(p.Context, userUsecase).Load(p.Context, id), nil
+				},
+			},
+			"users": &graphql.Field{
+				Type: graphql.NewNonNull(userListType),
+				Args: graphql.FieldConfigArgument{
+					"filter":     &graphql.ArgumentConfig{Type: userFilterInput},
+					"pagination": &graphql.ArgumentConfig{Type: paginationInput},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					res := userUsecase.GetUsersByFilter(p.Context, userFilterFromArgs(p.Args))
+					if !res.Success {
+						return nil, newResponseError(res.BaseRes)
+					}
+
+					items := make([]map[string]any, len(res.Data))
+					for i := range res.Data {
+						items[i] = userToMap(&res.Data[i])
+					}
+					return map[string]any{
+						"items": items,
+						"page": map[string]any{
+							"offset":  res.Page.Offset,
+							"limit":   res.Page.Limit,
+							"count":   res.Page.Count,
+							"orderBy": res.Page.OrderBy,
+						},
+					}, nil
+				},
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createUser": &graphql.Field{
+				Type: graphql.NewNonNull(userType),
+				Args: graphql.FieldConfigArgument{
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(createUserInput)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					input, _ := p.Args["input"].(map[string]any)
+					res := userUsecase.CreateUser(p.Context, &dto.ReqCreateUser{
+						Name:     stringValue(input["name"]),
+						Email:    stringValue(input["email"]),
+						Metadata: userMetadataFromArgs(input["metadata"]),
+					})
+					if !res.Success {
+						return nil, newResponseError(res.BaseRes)
+					}
+					return userToMap(res.Data), nil
+				},
+			},
+			"updateUser": &graphql.Field{
+				Type: graphql.NewNonNull(userType),
+				Args: graphql.FieldConfigArgument{
+					"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(updateUserInput)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					id, err := uuid.Parse(p.Args["id"].(string))
+					if err != nil {
+						return nil, errInvalidID
+					}
+
+					input, _ := p.Args["input"].(map[string]any)
+					req := &dto.ReqUpdateUser{
+						Name:   stringPtr(input["name"]),
+						Email:  stringPtr(input["email"]),
+						Status: intPtr(input["status"]),
+					}
+					if metadata, ok := input["metadata"]; ok && metadata != nil {
+						value := userMetadataFromArgs(metadata)
+						req.Metadata = &value
+					}
+
+					res := userUsecase.UpdateUser(p.Context, id, req)
+					if !res.Success {
+						return nil, newResponseError(res.BaseRes)
+					}
+					return userToMap(res.Data), nil
+				},
+			},
+			"deleteUser": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					id, err := uuid.Parse(p.Args["id"].(string))
+					if err != nil {
+						return nil, errInvalidID
+					}
+
+					res := userUsecase.DeleteUser(p.Context, id)
+					if !res.Success {
+						return nil, newResponseError(res)
+					}
+					return true, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    query,
+		Mutation: mutation,
+	})
+}
+
+var errInvalidID = &responseError{message: "invalid id", code: errorhelper.ErrBadRequest}
+
+// responseError exposes the errorhelper code of a failed usecase response in the error extensions
+type responseError struct {
+	message string
+	code    string
+}
+
+func newResponseError(res dtobase.BaseRes) *responseError {
+	return &responseError{message: res.Message, code: errorhelper.CodeFromStatus(res.Code)}
+}
+
+func (e *responseError) Error() string {
+	return e.message
+}
+
+func (e *responseError) Extensions() map[string]any {
+	return map[string]any{"code": e.code}
+}
+
+func userToMap(user *dto.ResUser) map[string]any {
+	if user == nil {
+		return nil
+	}
+
+	return map[string]any{
+		"id":     user.ID.String(),
+		"name":   user.Name,
+		"email":  user.Email,
+		"status": user.Status,
+		"metadata": map[string]any{
+			"sex":     user.Metadata.Sex,
+			"address": user.Metadata.Address,
+			"phone":   user.Metadata.Phone,
+		},
+	}
+}
+
+func userFilterFromArgs(args map[string]any) *dto.ReqGetUser {
+	filter := &dto.ReqGetUser{}
+
+	if input, ok := args["filter"].(map[string]any); ok {
+		if ids, ok := input["ids"].([]any); ok && len(ids) > 0 {
+			values := make([]string, 0, len(ids))
+			for _, id := range ids {
+				values = append(values, stringValue(id))
+			}
+			joined := strings.Join(values, ",")
+			filter.IDs = &joined
+		}
+		filter.Name = stringPtr(input["name"])
+		filter.Email = stringPtr(input["email"])
+		filter.Status = intPtr(input["status"])
+		filter.Sex = stringPtr(input["sex"])
+		filter.Address = stringPtr(input["address"])
+		filter.Phone = stringPtr(input["phone"])
+	}
+
+	if input, ok := args["pagination"].(map[string]any); ok {
+		filter.CreatedAtGTE = timePtr(input["createdAtGte"])
+		filter.CreatedAtLTE = timePtr(input["createdAtLte"])
+		filter.UpdatedAtGTE = timePtr(input["updatedAtGte"])
+		filter.UpdatedAtLTE = timePtr(input["updatedAtLte"])
+		filter.IncludeDeleted = boolPtr(input["includeDeleted"])
+		filter.ShowCount = boolPtr(input["showCount"])
+		filter.Offset = intPtr(input["offset"])
+		filter.Limit = intPtr(input["limit"])
+		filter.OrderBy = stringPtr(input["orderBy"])
+	}
+
+	return filter
+}
+
+func userMetadataFromArgs(value any) dto.UserMetadata {
+	input, _ := value.(map[string]any)
+	return dto.UserMetadata{
+		Sex:     stringValue(input["sex"]),
+		Address: stringValue(input["address"]),
+		Phone:   stringValue(input["phone"]),
+	}
+}
+
+func stringValue(value any) string {
+	out, _ := value.(string)
+	return out
+}
+
+func stringPtr(value any) *string {
+	if out, ok := value.(string); ok {
+		return &out
+	}
+	return nil
+}
+
+func intPtr(value any) *int {
+	if out, ok := value.(int); ok {
+		return &out
+	}
+	return nil
+}
+
+func boolPtr(value any) *bool {
+	if out, ok := value.(bool); ok {
+		return &out
+	}
+	return nil
+}
+
+func timePtr(value any) *time.Time {
+	if out, ok := value.(time.Time); ok {
+		return &out
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	tenantusecase "github.com/alxhtp/monogo/internal/usecase/tenant"
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	MetadataRequestID     = "x-request-id"
+	MetadataAuthorization = "authorization"
+	MetadataTenantID      = "x-tenant-id"
+
+	bearerPrefix = "bearer "
+
+	// anyTenant in GRPC_TOKEN_TENANTS lets a caller use every tenant
+	anyTenant = "*"
+)
+
+// publicMethodPrefixes are served without authentication, so probes and tooling keep working
+var publicMethodPrefixes = []string{
+	"/grpc.health.v1.Health/",
+	"/grpc.reflection.",
+}
+
+// recoveryInterceptor turns a panic into an Internal status instead of crashing the process
+func recoveryInterceptor(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res any, err error) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				logger.ErrorContext(ctx, "panic recovered", "method", info.FullMethod, "panic", rec, "stacktrace", string(debug.Stack()))
+				err = status.Error(codes.Internal, "internal server error")
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// requestContextInterceptor stores the request id like the REST RequestContext middleware, the request id is taken
+// from x-request-id or generated and sent back in the response header. The actor is anonymous until authInterceptor
+// verifies the caller, it is never taken from the metadata as the audit trail relies on it.
+func requestContextInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+
+		requestID := firstMetadata(md, MetadataRequestID)
+		if requestID == "" {
+			requestID = uuid.NewString()
+		}
+		_ = grpc.SetHeader(ctx, metadata.Pairs(MetadataRequestID, requestID))
+		ctx = contexthelper.WithRequestID(ctx, requestID)
+
+		ctx = contexthelper.WithActor(ctx, contexthelper.ActorAnonymous)
+
+		return handler(ctx, req)
+	}
+}
+
+// loggingInterceptor logs every call with its status code and duration
+func loggingInterceptor(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		res, err := handler(ctx, req)
+
+		code := status.Code(err)
+		attrs := []any{
+			"method", info.FullMethod,
+			"code", code.String(),
+			"duration", time.Since(start).String(),
+			"request_id", contexthelper.GetRequestID(ctx),
+		}
+		if code == codes.Internal || code == codes.Unknown {
+			logger.ErrorContext(ctx, "grpc call failed", append(attrs, "error", err.Error())...)
+		} else {
+			logger.InfoContext(ctx, "grpc call", attrs...)
+		}
+
+		return res, err
+	}
+}
+
+// authInterceptor accepts a bearer token of GRPC_AUTH_TOKENS and attributes the call to the matching caller,
+// who is the verified principal of the call.
+func authInterceptor(tokens map[string]string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if isPublicMethod(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		authorization := firstMetadata(md, MetadataAuthorization)
+		if len(authorization) <= len(bearerPrefix) || !strings.EqualFold(authorization[:len(bearerPrefix)], bearerPrefix) {
+			return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+		}
+		token := authorization[len(bearerPrefix):]
+
+		for caller, expected := range tokens {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1 {
+				ctx = contexthelper.WithPrincipal(ctx, caller)
+				return handler(contexthelper.WithActor(ctx, caller), req)
+			}
+		}
+
+		return nil, status.Error(codes.Unauthenticated, "invalid bearer token")
+	}
+}
+
+// tenantInterceptor binds the call to a tenant the token of the caller may use, like the verified credential of the
+// REST Tenant middleware. x-tenant-id is a hint picking one of them, it may be left out when the token is bound to a
+// single tenant. Unknown and forbidden tenants are rejected alike, so tenants cannot be probed.
+// Public methods are served without a tenant.
+func tenantInterceptor(logger *slog.Logger, tenantUsecase tenantusecase.TenantUsecase, tokenTenants map[string]map[string]bool) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if isPublicMethod(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		allowed := tokenTenants[contexthelper.GetPrincipal(ctx)]
+		md, _ := metadata.FromIncomingContext(ctx)
+		tenant := strings.TrimSpace(firstMetadata(md, MetadataTenantID))
+		if tenant == "" {
+			tenant = onlyTenant(allowed)
+		}
+		if tenant == "" {
+			return nil, status.Error(codes.InvalidArgument, "tenant is required")
+		}
+		if !allowed[tenant] && !allowed[anyTenant] {
+			return nil, status.Error(codes.PermissionDenied, "tenant not allowed")
+		}
+
+		found, err := tenantUsecase.ResolveTenant(ctx, tenant)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to resolve tenant", "tenant", tenant, "error", err.Error())
+			return nil, status.Error(codes.Internal, "internal server error")
+		}
+		if !found {
+			return nil, status.Error(codes.PermissionDenied, "tenant not allowed")
+		}
+
+		return handler(contexthelper.WithTenant(ctx, tenant), req)
+	}
+}
+
+// parseTokenTenants reads GRPC_TOKEN_TENANTS, every caller of tokens must be bound to at least one tenant
+func parseTokenTenants(tokens, tokenTenants map[string]string) (map[string]map[string]bool, error) {
+	parsed := make(map[string]map[string]bool, len(tokenTenants))
+	for caller := range tokens {
+		tenants := make(map[string]bool)
+		for _, tenant := range strings.Split(tokenTenants[caller], "|") {
+			if tenant = strings.TrimSpace(tenant); tenant != "" {
+				tenants[tenant] = true
+			}
+		}
+		if len(tenants) == 0 {
+			return nil, fmt.Errorf("GRPC_TOKEN_TENANTS: caller %s is bound to no tenant", caller)
+		}
+		parsed[caller] = tenants
+	}
+	return parsed, nil
+}
+
+// onlyTenant returns the tenant of a caller bound to a single tenant, empty otherwise
+func onlyTenant(allowed map[string]bool) string {
+	if len(allowed) != 1 {
+		return ""
+	}
+	for tenant := range allowed {
+		if tenant != anyTenant {
+			return tenant
+		}
+	}
+	return ""
+}
+
+// streamRecoveryInterceptor is recoveryInterceptor for streaming calls, e.g. health watch and reflection
+func streamRecoveryInterceptor(logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				logger.ErrorContext(stream.Context(), "panic recovered", "method", info.FullMethod, "panic", rec, "stacktrace", string(debug.Stack()))
+				err = status.Error(codes.Internal, "internal server error")
+			}
+		}()
+
+		return handler(srv, stream)
+	}
+}
+
+func isPublicMethod(method string) bool {
+	for _, prefix := range publicMethodPrefixes {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func firstMetadata(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,195 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/alxhtp/monogo/config"
+	tenantusecase "github.com/alxhtp/monogo/internal/usecase/tenant"
+	userusecase "github.com/alxhtp/monogo/internal/usecase/user"
+	"github.com/alxhtp/monogo/pkg/dto"
+	dtobase "github.com/alxhtp/monogo/pkg/dto/base"
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+	userv1 "github.com/alxhtp/monogo/pkg/pb/user/v1"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// userUsecaseStub answers GetUserByID and records the caller of the last call
+type userUsecaseStub struct {
+	userusecase.UserUsecase
+
+	mu     sync.Mutex
+	actor  string
+	tenant string
+}
+
+func (u *userUsecaseStub) GetUserByID(ctx context.Context, id uuid.UUID) dto.ResUserSingle {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.actor = contexthelper.GetActor(ctx)
+	u.tenant = contexthelper.GetTenant(ctx)
+	return dto.ResUserSingle{BaseRes: dtobase.BaseRes{Success: true, Code: http.StatusOK}, Data: &dto.ResUser{ID: id}}
+}
+
+func (u *userUsecaseStub) caller() (actor, tenant string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.actor, u.tenant
+}
+
+// tenantUsecaseStub knows the tenants set to true, resolving any other tenant fails
+type tenantUsecaseStub struct {
+	tenantusecase.TenantUsecase
+
+	tenants map[string]bool
+}
+
+func (u tenantUsecaseStub) ResolveTenant(_ context.Context, id string) (bool, error) {
+	found, ok := u.tenants[id]
+	if !ok {
+		return false, errors.New(`relation "tenants" does not exist`)
+	}
+	return found, nil
+}
+
+// newTestConn serves a gRPC server of cfg over an in-memory listener and returns a connection to it
+func newTestConn(t *testing.T, cfg *config.Config, users userusecase.UserUsecase, tenants tenantusecase.TenantUsecase) *grpc.ClientConn {
+	t.Helper()
+
+	server, err := NewGrpcServer(cfg, users, tenants)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	listener := bufconn.Listen(1 << 20)
+	go func() { _ = server.server.Serve(listener) }()
+	t.Cleanup(server.server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func TestAuthInterceptorRequiresAToken(t *testing.T) {
+	users := &userUsecaseStub{}
+	cfg := &config.Config{GrpcConfig: config.GrpcConfig{GrpcAuthTokens: map[string]string{"billing": "token1"}}}
+	conn := newTestConn(t, cfg, users, nil)
+	client := userv1.NewUserServiceClient(conn)
+
+	tests := []struct {
+		name          string
+		authorization string
+		want          codes.Code
+	}{
+		{name: "missing token", want: codes.Unauthenticated},
+		{name: "empty bearer", authorization: "Bearer ", want: codes.Unauthenticated},
+		{name: "another scheme", authorization: "Basic token1", want: codes.Unauthenticated},
+		{name: "invalid token", authorization: "Bearer token2", want: codes.Unauthenticated},
+		{name: "valid token", authorization: "Bearer token1", want: codes.OK},
+		{name: "case insensitive scheme", authorization: "bearer token1", want: codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.authorization != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, MetadataAuthorization, tt.authorization)
+			}
+			// the actor is the caller of the token, never the one claimed by the metadata
+			ctx = metadata.AppendToOutgoingContext(ctx, "x-actor-id", "admin")
+
+			_, err := client.GetUser(ctx, &userv1.GetUserRequest{Id: uuid.NewString()})
+			if code := status.Code(err); code != tt.want {
+				t.Fatalf("code = %s, want %s: %v", code, tt.want, err)
+			}
+			if actor, _ := users.caller(); tt.want == codes.OK && actor != "billing" {
+				t.Fatalf("actor = %q, want billing", actor)
+			}
+		})
+	}
+
+	// probes are served without a token
+	res, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil || res.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("health = %v, %v, want serving without a token", res, err)
+	}
+}
+
+func TestTenantInterceptorBindsTokensToTheirTenants(t *testing.T) {
+	users := &userUsecaseStub{}
+	cfg := &config.Config{GrpcConfig: config.GrpcConfig{
+		GrpcAuthTokens:   map[string]string{"billing": "token1", "crm": "token2", "support": "token3"},
+		GrpcTokenTenants: map[string]string{"billing": "acme|initech", "crm": "*", "support": "acme"},
+	}}
+	cfg.TenantEnabled = true
+	tenants := tenantUsecaseStub{tenants: map[string]bool{"acme": true, "globex": true, "initech": false}}
+	client := userv1.NewUserServiceClient(newTestConn(t, cfg, users, tenants))
+
+	tests := []struct {
+		name       string
+		token      string
+		tenant     string
+		want       codes.Code
+		wantTenant string
+	}{
+		{name: "allowed tenant", token: "token1", tenant: "acme", want: codes.OK, wantTenant: "acme"},
+		{name: "forbidden tenant", token: "token1", tenant: "globex", want: codes.PermissionDenied},
+		{name: "allowed unknown tenant", token: "token1", tenant: "initech", want: codes.PermissionDenied},
+		{name: "missing tenant of several", token: "token1", want: codes.InvalidArgument},
+		{name: "any tenant", token: "token2", tenant: "globex", want: codes.OK, wantTenant: "globex"},
+		{name: "unknown tenant of any", token: "token2", tenant: "initech", want: codes.PermissionDenied},
+		{name: "single tenant by default", token: "token3", want: codes.OK, wantTenant: "acme"},
+		{name: "resolve failure", token: "token2", tenant: "umbrella", want: codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.AppendToOutgoingContext(context.Background(), MetadataAuthorization, "Bearer "+tt.token)
+			if tt.tenant != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, MetadataTenantID, tt.tenant)
+			}
+
+			_, err := client.GetUser(ctx, &userv1.GetUserRequest{Id: uuid.NewString()})
+			if code := status.Code(err); code != tt.want {
+				t.Fatalf("code = %s, want %s: %v", code, tt.want, err)
+			}
+			if tt.want == codes.PermissionDenied && status.Convert(err).Message() != "tenant not allowed" {
+				t.Fatalf("message = %q, want the same for unknown and forbidden tenants", status.Convert(err).Message())
+			}
+			if tt.want == codes.Internal && status.Convert(err).Message() != "internal server error" {
+				t.Fatalf("message = %q, want the resolve error hidden", status.Convert(err).Message())
+			}
+			if _, tenant := users.caller(); tt.want == codes.OK && tenant != tt.wantTenant {
+				t.Fatalf("tenant = %q, want %q", tenant, tt.wantTenant)
+			}
+		})
+	}
+}
+
+func TestNewGrpcServerRequiresTheTenantsOfEveryToken(t *testing.T) {
+	cfg := &config.Config{GrpcConfig: config.GrpcConfig{
+		GrpcAuthTokens:   map[string]string{"billing": "token1", "crm": "token2"},
+		GrpcTokenTenants: map[string]string{"billing": "acme"},
+	}}
+	cfg.TenantEnabled = true
+
+	if _, err := NewGrpcServer(cfg, &userUsecaseStub{}, tenantUsecaseStub{}); err == nil {
+		t.Fatal("started with a token bound to no tenant")
+	}
+}
@@ -0,0 +1,127 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+
+	"github.com/alxhtp/monogo/config"
+	tenantusecase "github.com/alxhtp/monogo/internal/usecase/tenant"
+	userusecase "github.com/alxhtp/monogo/internal/usecase/user"
+	"github.com/alxhtp/monogo/pkg/lifecycle"
+	userv1 "github.com/alxhtp/monogo/pkg/pb/user/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+type GrpcServer struct {
+	server *grpc.Server
+	health *health.Server
+	cfg    *config.Config
+	logger *slog.Logger
+}
+
+// NewGrpcServer serves the user usecase over gRPC, with the standard health service and,
+// when GRPC_REFLECTION is set, server reflection
+func NewGrpcServer(cfg *config.Config, userUsecase userusecase.UserUsecase, tenantUsecase tenantusecase.TenantUsecase) (*GrpcServer, error) {
+	if cfg == nil {
+		return nil, errors.New("config is nil")
+	}
+	if userUsecase == nil {
+		return nil, errors.New("user usecase is nil")
+	}
+	if cfg.TenantEnabled && tenantUsecase == nil {
+		return nil, errors.New("tenant usecase is nil")
+	}
+	// The listener is plaintext, callers must at least present a token
+	if len(cfg.GrpcAuthTokens) == 0 {
+		return nil, errors.New("GRPC_AUTH_TOKENS is required when gRPC is enabled")
+	}
+
+	logger := slog.Default().With("server", "grpc")
+
+	interceptors := []grpc.UnaryServerInterceptor{
+		recoveryInterceptor(logger),
+		requestContextInterceptor(),
+		loggingInterceptor(logger),
+		authInterceptor(cfg.GrpcAuthTokens),
+	}
+	if cfg.TenantEnabled {
+		tokenTenants, err := parseTokenTenants(cfg.GrpcAuthTokens, cfg.GrpcTokenTenants)
+		if err != nil {
+			return nil, err
+		}
+		interceptors = append(interceptors, tenantInterceptor(logger, tenantUsecase, tokenTenants))
+	}
+
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(interceptors...),
+		grpc.ChainStreamInterceptor(
+			streamRecoveryInterceptor(logger),
+		),
+	)
+
+	userv1.RegisterUserServiceServer(server, newUserService(userUsecase))
+
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	if cfg.GrpcReflection {
+		reflection.Register(server)
+	}
+
+	return &GrpcServer{
+		server: server,
+		health: healthServer,
+		cfg:    cfg,
+		logger: logger,
+	}, nil
+}
+
+// Register appends the gRPC server to manager. It is stopped gracefully: the health service reports
+// NOT_SERVING, then in-flight calls are drained, and pending calls are cancelled once the drain timeout is exceeded.
+func (s *GrpcServer) Register(manager *lifecycle.Manager) {
+	manager.Append(lifecycle.Hook{
+		Name: "grpc-server",
+		OnStart: func(ctx context.Context) error {
+			addr := fmt.Sprintf(":%d", s.cfg.GrpcPort)
+			ln, err := net.Listen("tcp", addr)
+			if err != nil {
+				return err
+			}
+
+			s.health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+			s.health.SetServingStatus(userv1.UserService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+
+			s.logger.InfoContext(ctx, "gRPC server listening", "addr", addr)
+			go func() {
+				if err := s.server.Serve(ln); err != nil {
+					s.logger.ErrorContext(ctx, "gRPC server stopped", "error", err.Error())
+					manager.Fail("grpc-server", err)
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			s.health.Shutdown()
+
+			stopped := make(chan struct{})
+			go func() {
+				s.server.GracefulStop()
+				close(stopped)
+			}()
+
+			select {
+			case <-stopped:
+				return nil
+			case <-ctx.Done():
+				s.server.Stop()
+				return ctx.Err()
+			}
+		},
+	})
+}
@@ -0,0 +1,33 @@
+package grpcserver
+
+import (
+	dtobase "github.com/alxhtp/monogo/pkg/dto/base"
+	errorhelper "github.com/alxhtp/monogo/pkg/helper/error"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var codeByErrorCode = map[string]codes.Code{
+	errorhelper.ErrNotFound:       codes.NotFound,
+	errorhelper.ErrBadRequest:     codes.InvalidArgument,
+	errorhelper.ErrValidation:     codes.InvalidArgument,
+	errorhelper.ErrMissingID:      codes.InvalidArgument,
+	errorhelper.ErrUnauthorized:   codes.Unauthenticated,
+	errorhelper.ErrForbidden:      codes.PermissionDenied,
+	errorhelper.ErrDuplicateEntry: codes.AlreadyExists,
+}
+
+// statusFromResponse returns nil for a successful usecase response,
+// otherwise a status whose code is derived from the errorhelper code of the response status
+func statusFromResponse(res dtobase.BaseRes) error {
+	if res.Success {
+		return nil
+	}
+
+	code, ok := codeByErrorCode[errorhelper.CodeFromStatus(res.Code)]
+	if !ok {
+		code = codes.Internal
+	}
+
+	return status.Error(code, res.Message)
+}
@@ -0,0 +1,183 @@
+package grpcserver
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	userusecase "github.com/alxhtp/monogo/internal/usecase/user"
+	"github.com/alxhtp/monogo/pkg/dto"
+	userv1 "github.com/alxhtp/monogo/pkg/pb/user/v1"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// userService maps the protobuf messages to the DTOs of the user usecase
+type userService struct {
+	userv1.UnimplementedUserServiceServer
+	userUsecase userusecase.UserUsecase
+}
+
+func newUserService(userUsecase userusecase.UserUsecase) *userService {
+	return &userService{userUsecase: userUsecase}
+}
+
+func (s *userService) CreateUser(ctx context.Context, req *userv1.CreateUserRequest) (*userv1.CreateUserResponse, error) {
+	res := s.userUsecase.CreateUser(ctx, &dto.ReqCreateUser{
+		Name:     req.GetName(),
+		Email:    req.GetEmail(),
+		Metadata: userMetadataFromProto(req.GetMetadata()),
+	})
+	if err := statusFromResponse(res.BaseRes); err != nil {
+		return nil, err
+	}
+
+	return &userv1.CreateUserResponse{User: userToProto(res.Data)}, nil
+}
+
+func (s *userService) GetUser(ctx context.Context, req *userv1.GetUserRequest) (*userv1.GetUserResponse, error) {
+	id, err := parseID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	res := s.userUsecase.GetUserByID(ctx, id)
+	if err := statusFromResponse(res.BaseRes); err != nil {
+		return nil, err
+	}
+
+	return &userv1.GetUserResponse{User: userToProto(res.Data)}, nil
+}
+
+func (s *userService) ListUsers(ctx context.Context, req *userv1.ListUsersRequest) (*userv1.ListUsersResponse, error) {
+	filter := &dto.ReqGetUser{
+		Name:    req.Name,
+		Email:   req.Email,
+		Status:  intPtr(req.Status),
+		Sex:     req.Sex,
+		Address: req.Address,
+		Phone:   req.Phone,
+	}
+	if len(req.GetIds()) > 0 {
+		ids := strings.Join(req.GetIds(), ",")
+		filter.IDs = &ids
+	}
+	filter.CreatedAtGTE = timePtr(req.GetCreatedAtGte())
+	filter.CreatedAtLTE = timePtr(req.GetCreatedAtLte())
+	filter.UpdatedAtGTE = timePtr(req.GetUpdatedAtGte())
+	filter.UpdatedAtLTE = timePtr(req.GetUpdatedAtLte())
+	filter.IncludeDeleted = req.IncludeDeleted
+	filter.ShowCount = req.ShowCount
+	filter.Offset = intPtr(req.Offset)
+	filter.Limit = intPtr(req.Limit)
+	filter.OrderBy = req.OrderBy
+
+	res := s.userUsecase.GetUsersByFilter(ctx, filter)
+	if err := statusFromResponse(res.BaseRes); err != nil {
+		return nil, err
+	}
+
+	users := make([]*userv1.User, len(res.Data))
+	for i := range res.Data {
+		users[i] = userToProto(&res.Data[i])
+	}
+
+	return &userv1.ListUsersResponse{
+		Users: users,
+		Page: &userv1.Page{
+			Offset:  int32(res.Page.Offset),
+			Limit:   int32(res.Page.Limit),
+			Count:   int32(res.Page.Count),
+			OrderBy: res.Page.OrderBy,
+		},
+	}, nil
+}
+
+func (s *userService) UpdateUser(ctx context.Context, req *userv1.UpdateUserRequest) (*userv1.UpdateUserResponse, error) {
+	id, err := parseID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	update := &dto.ReqUpdateUser{
+		Name:   req.Name,
+		Email:  req.Email,
+		Status: intPtr(req.Status),
+	}
+	if req.Metadata != nil {
+		metadata := userMetadataFromProto(req.Metadata)
+		update.Metadata = &metadata
+	}
+
+	res := s.userUsecase.UpdateUser(ctx, id, update)
+	if err := statusFromResponse(res.BaseRes); err != nil {
+		return nil, err
+	}
+
+	return &userv1.UpdateUserResponse{User: userToProto(res.Data)}, nil
+}
+
+func (s *userService) DeleteUser(ctx context.Context, req *userv1.DeleteUserRequest) (*userv1.DeleteUserResponse, error) {
+	id, err := parseID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := statusFromResponse(s.userUsecase.DeleteUser(ctx, id)); err != nil {
+		return nil, err
+	}
+
+	return &userv1.DeleteUserResponse{}, nil
+}
+
+func parseID(value string) (uuid.UUID, error) {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, status.Errorf(codes.InvalidArgument, "invalid id %q", value)
+	}
+	return id, nil
+}
+
+func userToProto(user *dto.ResUser) *userv1.User {
+	if user == nil {
+		return nil
+	}
+
+	return &userv1.User{
+		Id:     user.ID.String(),
+		Name:   user.Name,
+		Email:  user.Email,
+		Status: int32(user.Status),
+		Metadata: &userv1.UserMetadata{
+			Sex:     user.Metadata.Sex,
+			Address: user.Metadata.Address,
+			Phone:   user.Metadata.Phone,
+		},
+	}
+}
+
+func userMetadataFromProto(metadata *userv1.UserMetadata) dto.UserMetadata {
+	return dto.UserMetadata{
+		Sex:     metadata.GetSex(),
+		Address: metadata.GetAddress(),
+		Phone:   metadata.GetPhone(),
+	}
+}
+
+func intPtr(value *int32) *int {
+	if value == nil {
+		return nil
+	}
+	out := int(*value)
+	return &out
+}
+
+func timePtr(value *timestamppb.Timestamp) *time.Time {
+	if value == nil {
+		return nil
+	}
+	out := value.AsTime()
+	return &out
+}
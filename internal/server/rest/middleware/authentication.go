@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/alxhtp/monogo/config"
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+	errorhelper "github.com/alxhtp/monogo/pkg/helper/error"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// localsClaims holds the claims of the verified bearer token, see Authentication
+const localsClaims = "jwt_claims"
+
+var errTokenSubjectMissing = errors.New("invalid bearer token: subject is required")
+
+// Authentication verifies the HS256 bearer token signed with JWT_SECRET_KEY and makes its subject the principal
+// and the actor of the request. Requests without a bearer token stay unauthenticated, an invalid token is rejected.
+// A verified client certificate takes precedence, so it must run after ClientIdentity.
+func Authentication(cfg config.JWTConfig) fiber.Handler {
+	parser := newJWTParser(cfg)
+	secret := []byte(cfg.SecretKey)
+
+	return func(c *fiber.Ctx) error {
+		token, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+		if !ok || token == "" {
+			return c.Next()
+		}
+
+		claims := jwt.MapClaims{}
+		if _, err := parser.ParseWithClaims(token, claims, func(*jwt.Token) (any, error) {
+			return secret, nil
+		}); err != nil {
+			return authenticationError(c, fmt.Errorf("invalid bearer token: %w", err))
+		}
+		subject, err := claims.GetSubject()
+		if err != nil || subject == "" {
+			return authenticationError(c, errTokenSubjectMissing)
+		}
+
+		c.Locals(localsClaims, claims)
+		if contexthelper.GetPrincipal(c.Context()) == "" {
+			c.Locals(contexthelper.KeyPrincipal, subject)
+			c.Locals(contexthelper.KeyActor, subject)
+		}
+
+		return c.Next()
+	}
+}
+
+func newJWTParser(cfg config.JWTConfig) *jwt.Parser {
+	options := []jwt.ParserOption{jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()})}
+	if cfg.TokenIssuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.TokenIssuer))
+	}
+	if cfg.TokenAudience != "" {
+		options = append(options, jwt.WithAudience(cfg.TokenAudience))
+	}
+	return jwt.NewParser(options...)
+}
+
+func authenticationError(c *fiber.Ctx, err error) error {
+	return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+		"success":    false,
+		"code":       fiber.StatusUnauthorized,
+		"message":    err.Error(),
+		"stacktrace": errorhelper.ComposeStacktrace(err),
+	})
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+	tlshelper "github.com/alxhtp/monogo/pkg/helper/tls"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ClientIdentity stores the verified TLS client certificate of the caller, see contexthelper.GetClientIdentity.
+// The certificate common name is the principal and the actor of the request.
+// Must run after RequestContext.
+func ClientIdentity() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		state := c.Context().TLSConnectionState()
+		if state == nil || len(state.VerifiedChains) == 0 || len(state.PeerCertificates) == 0 {
+			return c.Next()
+		}
+
+		cert := state.PeerCertificates[0]
+		identity := &contexthelper.ClientIdentity{
+			CommonName:   cert.Subject.CommonName,
+			Organization: cert.Subject.Organization,
+			DNSNames:     cert.DNSNames,
+			SerialNumber: cert.SerialNumber.String(),
+			Fingerprint:  tlshelper.Fingerprint(cert),
+		}
+		for _, uri := range cert.URIs {
+			identity.URIs = append(identity.URIs, uri.String())
+		}
+		c.Locals(contexthelper.KeyClientIdentity, identity)
+
+		if identity.CommonName != "" {
+			c.Locals(contexthelper.KeyPrincipal, identity.CommonName)
+			c.Locals(contexthelper.KeyActor, identity.CommonName)
+		}
+
+		return c.Next()
+	}
+}
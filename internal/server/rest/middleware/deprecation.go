@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+	"github.com/alxhtp/monogo/pkg/metrics"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	HeaderDeprecation = "Deprecation"
+	HeaderSunset      = "Sunset"
+)
+
+// DeprecationConfig describes a deprecated version or endpoint. Sunset and Link are optional.
+type DeprecationConfig struct {
+	Version    string
+	Deprecated time.Time
+	Sunset     time.Time
+
+	// Link points to the replacement, it is sent with the successor-version relation
+	Link string
+}
+
+// Deprecation announces the deprecation with the Deprecation (RFC 9745) and Sunset (RFC 8594) headers,
+// and logs and counts every call so the remaining consumers can be identified before the sunset.
+func Deprecation(cfg DeprecationConfig) fiber.Handler {
+	deprecation := "@" + strconv.FormatInt(cfg.Deprecated.Unix(), 10)
+
+	return func(c *fiber.Ctx) error {
+		c.Set(HeaderDeprecation, deprecation)
+		if !cfg.Sunset.IsZero() {
+			c.Set(HeaderSunset, cfg.Sunset.UTC().Format(http.TimeFormat))
+		}
+		if cfg.Link != "" {
+			c.Append(fiber.HeaderLink, "<"+cfg.Link+`>; rel="successor-version"`)
+		}
+
+		err := c.Next()
+
+		// the route template is only known once the request was matched by the handler
+		route, _ := routeAndStatus(c, err)
+		metrics.HTTPDeprecatedRequestsTotal.WithLabelValues(cfg.Version, c.Method(), route).Inc()
+		slog.WarnContext(c.Context(), "deprecated endpoint called",
+			"version", cfg.Version,
+			"method", c.Method(),
+			"route", route,
+			"actor", contexthelper.GetActor(c.Context()),
+			"user_agent", c.Get(fiber.HeaderUserAgent),
+			"request_id", contexthelper.GetRequestID(c.Context()),
+		)
+
+		return err
+	}
+}
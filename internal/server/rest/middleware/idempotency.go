@@ -0,0 +1,223 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alxhtp/monogo/config"
+	"github.com/alxhtp/monogo/internal/entity"
+	idempotencyrepository "github.com/alxhtp/monogo/internal/repository/idempotency"
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+	errorhelper "github.com/alxhtp/monogo/pkg/helper/error"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	HeaderIdempotencyKey          = "Idempotency-Key"
+	HeaderIdempotentReplayed      = "Idempotent-Replayed"
+	maxIdempotencyKeyLength       = 255
+	idempotencyPollInterval       = 100 * time.Millisecond
+	defaultIdempotencyTTL         = 24 * time.Hour
+	defaultIdempotencyLockTimeout = time.Minute
+	defaultIdempotencyLockWait    = 5 * time.Second
+)
+
+var (
+	errIdempotencyKeyTooLong  = fmt.Errorf("%s must be at most %d characters", HeaderIdempotencyKey, maxIdempotencyKeyLength)
+	errIdempotencyKeyReused   = fmt.Errorf("%s was already used with a different request", HeaderIdempotencyKey)
+	errIdempotencyKeyInFlight = fmt.Errorf("a request with this %s is still being processed", HeaderIdempotencyKey)
+	errIdempotencyAnonymous   = fmt.Errorf("%s requires an authenticated caller", HeaderIdempotencyKey)
+	errIdempotencyUnavailable = fmt.Errorf("%s could not be checked", HeaderIdempotencyKey)
+)
+
+// Idempotency replays the stored response of a request retried with the same Idempotency-Key header.
+// Keys are scoped to the authenticated principal and the tenant of the request, and bound to a hash of the request,
+// reusing a key for another request fails with 422. Unauthenticated requests sending a key are rejected with 401.
+// A retry arriving while the first request is in flight waits for its response, then fails with 409.
+// Requests without the header, failing with a 5xx status or with an error are not stored and can be retried.
+func Idempotency(repository idempotencyrepository.IdempotencyRepository, cfg config.IdempotencyConfig) fiber.Handler {
+	ttl, err := time.ParseDuration(cfg.IdempotencyTTL)
+	if err != nil || ttl <= 0 {
+		ttl = defaultIdempotencyTTL
+	}
+
+	lockTimeout, err := time.ParseDuration(cfg.IdempotencyLockTimeout)
+	if err != nil || lockTimeout <= 0 {
+		lockTimeout = defaultIdempotencyLockTimeout
+	}
+
+	lockWait, err := time.ParseDuration(cfg.IdempotencyLockWait)
+	if err != nil || lockWait < 0 {
+		lockWait = defaultIdempotencyLockWait
+	}
+
+	return func(c *fiber.Ctx) error {
+		key := c.Get(HeaderIdempotencyKey)
+		if key == "" || c.Method() != fiber.MethodPost {
+			return c.Next()
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return idempotencyError(c, fiber.StatusBadRequest, errIdempotencyKeyTooLong)
+		}
+
+		ctx := c.Context()
+		caller := idempotencyCaller(ctx)
+		if caller == "" {
+			return idempotencyError(c, fiber.StatusUnauthorized, errIdempotencyAnonymous)
+		}
+
+		hash, err := requestHash(c)
+		if err != nil {
+			return idempotencyError(c, fiber.StatusBadRequest, err)
+		}
+
+		deadline := time.Now().Add(lockWait)
+
+		for {
+			now := time.Now()
+			acquired, err := repository.Acquire(ctx, &entity.IdempotencyKey{
+				Caller:      caller,
+				Key:         key,
+				Method:      c.Method(),
+				Path:        c.Path(),
+				RequestHash: hash,
+				LockedUntil: now.Add(lockTimeout),
+				ExpiresAt:   now.Add(ttl),
+			})
+			if err != nil {
+				return idempotencyServerError(c, key, caller, err)
+			}
+			if acquired {
+				return handleIdempotent(c, repository, caller, key)
+			}
+
+			existing, err := repository.GetByKey(ctx, caller, key)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return idempotencyServerError(c, key, caller, err)
+			}
+
+			// a missing key was released meanwhile, it is acquired again on the next attempt
+			if existing != nil {
+				if existing.RequestHash != hash {
+					return idempotencyError(c, fiber.StatusUnprocessableEntity, errIdempotencyKeyReused)
+				}
+				if existing.StatusCode != nil {
+					c.Set(HeaderIdempotentReplayed, "true")
+					if existing.ContentType != "" {
+						c.Set(fiber.HeaderContentType, existing.ContentType)
+					}
+					return c.Status(*existing.StatusCode).Send(existing.ResponseBody)
+				}
+			}
+
+			if time.Now().After(deadline) {
+				c.Set(fiber.HeaderRetryAfter, "1")
+				return idempotencyError(c, fiber.StatusConflict, errIdempotencyKeyInFlight)
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(idempotencyPollInterval):
+			}
+		}
+	}
+}
+
+// handleIdempotent runs the handler holding the key and stores its response, or releases the key when it failed
+func handleIdempotent(c *fiber.Ctx, repository idempotencyrepository.IdempotencyRepository, caller, key string) error {
+	ctx := c.Context()
+
+	err := c.Next()
+	status := c.Response().StatusCode()
+	if err != nil || status >= fiber.StatusInternalServerError {
+		if releaseErr := repository.Release(ctx, caller, key); releaseErr != nil {
+			slog.ErrorContext(ctx, "failed to release idempotency key", "key", key, "caller", caller, "error", releaseErr.Error())
+		}
+		return err
+	}
+
+	contentType := string(c.Response().Header.ContentType())
+	if err := repository.Complete(ctx, caller, key, status, contentType, c.Response().Body()); err != nil {
+		// the response was produced, a retry waits for the lock to expire and runs the request again
+		slog.ErrorContext(ctx, "failed to store idempotent response", "key", key, "caller", caller, "error", err.Error())
+	}
+
+	return nil
+}
+
+// idempotencyCaller returns the namespace of the keys of the caller, empty for unauthenticated callers
+func idempotencyCaller(ctx context.Context) string {
+	principal := contexthelper.GetPrincipal(ctx)
+	if principal == "" {
+		return ""
+	}
+	if tenant := contexthelper.GetTenant(ctx); tenant != "" {
+		return tenant + "/" + principal
+	}
+	return principal
+}
+
+// requestHash identifies a request by its method, path, query and body. The parts of a multipart body
+// are hashed instead of the raw body, since clients pick a new boundary on every attempt.
+func requestHash(c *fiber.Ctx) (string, error) {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\n%s\n%s\n", c.Method(), c.Path(), c.Request().URI().QueryString())
+
+	if !strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
+		hash.Write(c.Body())
+		return hex.EncodeToString(hash.Sum(nil)), nil
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return "", err
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(form.Value)) {
+		fmt.Fprintf(hash, "value %s=%q\n", name, form.Value[name])
+	}
+	for _, name := range slices.Sorted(maps.Keys(form.File)) {
+		for _, fileHeader := range form.File[name] {
+			fmt.Fprintf(hash, "file %s=%s\n", name, fileHeader.Filename)
+
+			file, err := fileHeader.Open()
+			if err != nil {
+				return "", err
+			}
+			_, err = io.Copy(hash, file)
+			file.Close()
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// idempotencyServerError logs a failure of the repository and answers 500 without it, the database error
+// names tables and constraints
+func idempotencyServerError(c *fiber.Ctx, key, caller string, err error) error {
+	slog.ErrorContext(c.Context(), "failed to check idempotency key", "key", key, "caller", caller, "error", err.Error())
+	return idempotencyError(c, fiber.StatusInternalServerError, errIdempotencyUnavailable)
+}
+
+func idempotencyError(c *fiber.Ctx, status int, err error) error {
+	return c.Status(status).JSON(fiber.Map{
+		"success":    false,
+		"code":       status,
+		"message":    err.Error(),
+		"stacktrace": errorhelper.ComposeStacktrace(err),
+	})
+}
@@ -0,0 +1,271 @@
+package middleware_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alxhtp/monogo/config"
+	"github.com/alxhtp/monogo/internal/entity"
+	"github.com/alxhtp/monogo/internal/server/rest/middleware"
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// idempotencyRepository keeps the keys in memory, an existing key is never taken over
+type idempotencyRepository struct {
+	mu   sync.Mutex
+	keys map[string]entity.IdempotencyKey
+}
+
+func newIdempotencyRepository() *idempotencyRepository {
+	return &idempotencyRepository{keys: make(map[string]entity.IdempotencyKey)}
+}
+
+func (r *idempotencyRepository) Acquire(_ context.Context, record *entity.IdempotencyKey) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := record.Caller + " " + record.Key
+	if _, ok := r.keys[id]; ok {
+		return false, nil
+	}
+	r.keys[id] = *record
+	return true, nil
+}
+
+func (r *idempotencyRepository) GetByKey(_ context.Context, caller, key string) (*entity.IdempotencyKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, ok := r.keys[caller+" "+key]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &record, nil
+}
+
+func (r *idempotencyRepository) Complete(_ context.Context, caller, key string, statusCode int, contentType string, body []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record := r.keys[caller+" "+key]
+	record.StatusCode = &statusCode
+	record.ContentType = contentType
+	record.ResponseBody = bytes.Clone(body)
+	r.keys[caller+" "+key] = record
+	return nil
+}
+
+func (r *idempotencyRepository) Release(_ context.Context, caller, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.keys, caller+" "+key)
+	return nil
+}
+
+func (r *idempotencyRepository) DeleteExpired(context.Context, int) (int64, error) {
+	return 0, nil
+}
+
+// newIdempotentApp serves handler behind the idempotency middleware for an authenticated caller
+func newIdempotentApp(repository *idempotencyRepository, lockWait string, handler fiber.Handler) *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals(contexthelper.KeyPrincipal, "alice")
+		return c.Next()
+	})
+	app.Use(middleware.Idempotency(repository, config.IdempotencyConfig{IdempotencyLockWait: lockWait}))
+	app.Post("/users", handler)
+	return app
+}
+
+type idempotentResponse struct {
+	status   int
+	replayed string
+	body     string
+}
+
+func postIdempotent(t *testing.T, app *fiber.App, key, contentType string, body []byte) idempotentResponse {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/users", bytes.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, contentType)
+	req.Header.Set(middleware.HeaderIdempotencyKey, key)
+	res, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return idempotentResponse{status: res.StatusCode, replayed: res.Header.Get(middleware.HeaderIdempotentReplayed), body: string(data)}
+}
+
+func TestIdempotencyReplaysTheStoredResponse(t *testing.T) {
+	var calls atomic.Int32
+	app := newIdempotentApp(newIdempotencyRepository(), "", func(c *fiber.Ctx) error {
+		calls.Add(1)
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{"call": calls.Load()})
+	})
+
+	first := postIdempotent(t, app, "key-1", fiber.MIMEApplicationJSON, []byte(`{"name":"Alice"}`))
+	if first.status != fiber.StatusCreated || first.replayed != "" {
+		t.Fatalf("first = %+v", first)
+	}
+	retry := postIdempotent(t, app, "key-1", fiber.MIMEApplicationJSON, []byte(`{"name":"Alice"}`))
+	if retry.status != fiber.StatusCreated || retry.replayed != "true" || retry.body != first.body {
+		t.Fatalf("retry = %+v, want the replay of %+v", retry, first)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("the handler ran %d times", calls.Load())
+	}
+
+	// the key is bound to the request it was first used for
+	reused := postIdempotent(t, app, "key-1", fiber.MIMEApplicationJSON, []byte(`{"name":"Bob"}`))
+	if reused.status != fiber.StatusUnprocessableEntity {
+		t.Fatalf("reused = %+v, want 422", reused)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("the handler ran %d times", calls.Load())
+	}
+}
+
+func TestIdempotencyRejectsARetryOfARequestInFlight(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	app := newIdempotentApp(newIdempotencyRepository(), "50ms", func(c *fiber.Ctx) error {
+		close(started)
+		<-release
+		return c.SendStatus(fiber.StatusCreated)
+	})
+
+	done := make(chan idempotentResponse)
+	go func() {
+		done <- postIdempotent(t, app, "key-1", fiber.MIMEApplicationJSON, []byte(`{}`))
+	}()
+	<-started
+
+	start := time.Now()
+	retry := postIdempotent(t, app, "key-1", fiber.MIMEApplicationJSON, []byte(`{}`))
+	if retry.status != fiber.StatusConflict {
+		t.Fatalf("retry = %+v, want 409", retry)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Fatalf("the retry failed after %s, before waiting for the first request", elapsed)
+	}
+
+	close(release)
+	if first := <-done; first.status != fiber.StatusCreated {
+		t.Fatalf("first = %+v", first)
+	}
+	if replay := postIdempotent(t, app, "key-1", fiber.MIMEApplicationJSON, []byte(`{}`)); replay.replayed != "true" {
+		t.Fatalf("replay = %+v, want the stored response once the first request completed", replay)
+	}
+}
+
+func TestIdempotencyReleasesTheKeyOfAFailedRequest(t *testing.T) {
+	var calls atomic.Int32
+	app := newIdempotentApp(newIdempotencyRepository(), "", func(c *fiber.Ctx) error {
+		if calls.Add(1) == 1 {
+			return c.SendStatus(fiber.StatusServiceUnavailable)
+		}
+		return c.SendStatus(fiber.StatusCreated)
+	})
+
+	if first := postIdempotent(t, app, "key-1", fiber.MIMEApplicationJSON, []byte(`{}`)); first.status != fiber.StatusServiceUnavailable {
+		t.Fatalf("first = %+v", first)
+	}
+	retry := postIdempotent(t, app, "key-1", fiber.MIMEApplicationJSON, []byte(`{}`))
+	if retry.status != fiber.StatusCreated || retry.replayed != "" || calls.Load() != 2 {
+		t.Fatalf("retry = %+v after %d calls, want the request run again", retry, calls.Load())
+	}
+}
+
+// multipartBody encodes a name field and a file under boundary
+func multipartBody(t *testing.T, boundary, file string) (string, []byte) {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.SetBoundary(boundary); err != nil {
+		t.Fatalf("boundary: %v", err)
+	}
+	if err := writer.WriteField("name", "users"); err != nil {
+		t.Fatalf("field: %v", err)
+	}
+	part, err := writer.CreateFormFile("file", "users.csv")
+	if err != nil {
+		t.Fatalf("file: %v", err)
+	}
+	if _, err := io.Copy(part, strings.NewReader(file)); err != nil {
+		t.Fatalf("file: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	return writer.FormDataContentType(), body.Bytes()
+}
+
+func TestIdempotencyHashesMultipartPartsRegardlessOfTheBoundary(t *testing.T) {
+	var calls atomic.Int32
+	app := newIdempotentApp(newIdempotencyRepository(), "", func(c *fiber.Ctx) error {
+		calls.Add(1)
+		return c.SendStatus(fiber.StatusAccepted)
+	})
+
+	contentType, body := multipartBody(t, "first-boundary", "name,email\nAlice,alice@example.com\n")
+	if first := postIdempotent(t, app, "key-1", contentType, body); first.status != fiber.StatusAccepted {
+		t.Fatalf("first = %+v", first)
+	}
+
+	contentType, body = multipartBody(t, "second-boundary", "name,email\nAlice,alice@example.com\n")
+	if retry := postIdempotent(t, app, "key-1", contentType, body); retry.replayed != "true" || calls.Load() != 1 {
+		t.Fatalf("retry = %+v, want the replay of the same parts under another boundary", retry)
+	}
+
+	contentType, body = multipartBody(t, "second-boundary", "name,email\nBob,bob@example.com\n")
+	if reused := postIdempotent(t, app, "key-1", contentType, body); reused.status != fiber.StatusUnprocessableEntity {
+		t.Fatalf("reused = %+v, want 422 for another file", reused)
+	}
+}
+
+// unavailableIdempotencyRepository fails every Acquire
+type unavailableIdempotencyRepository struct {
+	*idempotencyRepository
+}
+
+func (unavailableIdempotencyRepository) Acquire(context.Context, *entity.IdempotencyKey) (bool, error) {
+	return false, errors.New(`ERROR: relation "idempotency_keys" does not exist (SQLSTATE 42P01)`)
+}
+
+func TestIdempotencyHidesRepositoryErrors(t *testing.T) {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals(contexthelper.KeyPrincipal, "alice")
+		return c.Next()
+	})
+	app.Use(middleware.Idempotency(unavailableIdempotencyRepository{newIdempotencyRepository()}, config.IdempotencyConfig{}))
+	app.Post("/users", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusCreated) })
+
+	res := postIdempotent(t, app, "key-1", fiber.MIMEApplicationJSON, []byte(`{}`))
+	if res.status != fiber.StatusInternalServerError {
+		t.Fatalf("res = %+v, want 500", res)
+	}
+	if strings.Contains(res.body, "idempotency_keys") || strings.Contains(res.body, "SQLSTATE") {
+		t.Fatalf("body names the database error: %s", res.body)
+	}
+}
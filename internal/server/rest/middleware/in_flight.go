@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// InFlight tracks the requests being handled, so shutdown can report what it is waiting for
+type InFlight struct {
+	mu       sync.Mutex
+	requests map[uint64]inFlightRequest
+	nextID   uint64
+}
+
+type inFlightRequest struct {
+	method    string
+	path      string
+	startedAt time.Time
+}
+
+func NewInFlight() *InFlight {
+	return &InFlight{requests: make(map[uint64]inFlightRequest)}
+}
+
+// Handler registers every request until its handler returns
+func (f *InFlight) Handler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// fiber reuses the request buffers, copy what outlives the handler
+		request := inFlightRequest{
+			method:    c.Method(),
+			path:      string([]byte(c.Path())),
+			startedAt: time.Now(),
+		}
+
+		f.mu.Lock()
+		id := f.nextID
+		f.nextID++
+		f.requests[id] = request
+		f.mu.Unlock()
+
+		defer func() {
+			f.mu.Lock()
+			delete(f.requests, id)
+			f.mu.Unlock()
+		}()
+
+		return c.Next()
+	}
+}
+
+// Requests describes the requests being handled, the oldest first
+func (f *InFlight) Requests() []string {
+	f.mu.Lock()
+	requests := make([]inFlightRequest, 0, len(f.requests))
+	for _, request := range f.requests {
+		requests = append(requests, request)
+	}
+	f.mu.Unlock()
+
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].startedAt.Before(requests[j].startedAt)
+	})
+
+	out := make([]string, len(requests))
+	for i, request := range requests {
+		out[i] = fmt.Sprintf("%s %s (%s)", request.method, request.path, time.Since(request.startedAt).Round(time.Millisecond))
+	}
+	return out
+}
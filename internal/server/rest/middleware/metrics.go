@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/alxhtp/monogo/pkg/metrics"
+	"github.com/gofiber/fiber/v2"
+)
+
+const unmatchedRoute = "unmatched"
+
+// Metrics records request count and latency labelled by route template, so path parameters
+// do not create a series per id
+func Metrics() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		route, status := routeAndStatus(c, err)
+		labels := []string{c.Method(), route, strconv.Itoa(status)}
+		metrics.HTTPRequestsTotal.WithLabelValues(labels...).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+
+		return err
+	}
+}
+
+// routeAndStatus returns the matched route template and the response status of a handled request.
+// The error handler writes the status after the middleware chain returns, so it is derived from err.
+func routeAndStatus(c *fiber.Ctx, err error) (route string, status int) {
+	route = c.Route().Path
+	status = c.Response().StatusCode()
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		status = fiberErr.Code
+		if status == fiber.StatusNotFound {
+			route = unmatchedRoute
+		}
+	} else if err != nil {
+		status = fiber.StatusInternalServerError
+	}
+
+	return route, status
+}
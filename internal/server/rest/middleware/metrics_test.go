@@ -0,0 +1,77 @@
+package middleware_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alxhtp/monogo/internal/server/rest/middleware"
+	"github.com/alxhtp/monogo/pkg/metrics"
+	"github.com/gofiber/fiber/v2"
+)
+
+// requestsTotal returns the value of monogo_http_requests_total for method, route and status
+func requestsTotal(t *testing.T, method, route, status string) float64 {
+	t.Helper()
+
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != "monogo_http_requests_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["method"] == method && labels["route"] == route && labels["status"] == status {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+func TestMetricsLabelRequestsByRouteTemplate(t *testing.T) {
+	app := fiber.New()
+	app.Use(middleware.Metrics())
+	app.Get("/metrics-test/:id", func(c *fiber.Ctx) error {
+		if c.Params("id") == "broken" {
+			return errors.New("broken")
+		}
+		return c.SendStatus(http.StatusOK)
+	})
+
+	before := map[string]float64{
+		"200": requestsTotal(t, http.MethodGet, "/metrics-test/:id", "200"),
+		"500": requestsTotal(t, http.MethodGet, "/metrics-test/:id", "500"),
+		"404": requestsTotal(t, http.MethodGet, "unmatched", "404"),
+	}
+	for _, path := range []string{"/metrics-test/1", "/metrics-test/2", "/metrics-test/broken", "/missing/3"} {
+		res, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+		if err != nil {
+			t.Fatalf("request %s: %v", path, err)
+		}
+		res.Body.Close()
+	}
+
+	// ids share the series of their route, unmatched paths share a single series
+	want := map[string]float64{"200": 2, "500": 1, "404": 1}
+	got := map[string]float64{
+		"200": requestsTotal(t, http.MethodGet, "/metrics-test/:id", "200") - before["200"],
+		"500": requestsTotal(t, http.MethodGet, "/metrics-test/:id", "500") - before["500"],
+		"404": requestsTotal(t, http.MethodGet, "unmatched", "404") - before["404"],
+	}
+	for status, count := range want {
+		if got[status] != count {
+			t.Errorf("status %s: %v requests, want %v", status, got[status], count)
+		}
+	}
+	if requestsTotal(t, http.MethodGet, "/metrics-test/1", "200") != 0 {
+		t.Fatal("a series was created for a request path")
+	}
+}
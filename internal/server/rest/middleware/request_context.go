@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const (
+	HeaderRequestID = fiber.HeaderXRequestID
+)
+
+// RequestContext stores the request id into the request context, taken from X-Request-ID or generated.
+// The actor is anonymous until the caller is authenticated, see ClientIdentity and Authentication,
+// it is never taken from a request header as the audit trail relies on it.
+func RequestContext() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		requestID := c.Get(HeaderRequestID)
+		if requestID == "" {
+			requestID = uuid.NewString()
+		}
+		c.Set(HeaderRequestID, requestID)
+		c.Locals(contexthelper.KeyRequestID, requestID)
+		c.Locals(contexthelper.KeyActor, contexthelper.ActorAnonymous)
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,77 @@
+package middleware_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alxhtp/monogo/internal/server/rest/middleware"
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestRequestContextTakesTheActorOfTheAuthenticatedCaller(t *testing.T) {
+	app := fiber.New()
+	app.Use(middleware.RequestContext(), middleware.Authentication(testJWTConfig))
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(contexthelper.GetActor(c.Context()))
+	})
+
+	tests := []struct {
+		name      string
+		token     string
+		wantActor string
+	}{
+		{name: "anonymous", wantActor: contexthelper.ActorAnonymous},
+		{name: "bearer token", token: signToken(t, jwt.MapClaims{"sub": "alice"}), wantActor: "alice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("X-Actor-ID", "admin")
+			if tt.token != "" {
+				req.Header.Set(fiber.HeaderAuthorization, "Bearer "+tt.token)
+			}
+
+			res, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request: %v", err)
+			}
+			defer res.Body.Close()
+			body, _ := io.ReadAll(res.Body)
+
+			if string(body) != tt.wantActor {
+				t.Fatalf("actor = %q, want %q", body, tt.wantActor)
+			}
+		})
+	}
+}
+
+func TestRequestContextKeepsOrGeneratesTheRequestID(t *testing.T) {
+	app := fiber.New()
+	app.Use(middleware.RequestContext())
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(contexthelper.GetRequestID(c.Context()))
+	})
+
+	for _, requestID := range []string{"req-1", ""} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if requestID != "" {
+			req.Header.Set(middleware.HeaderRequestID, requestID)
+		}
+
+		res, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request: %v", err)
+		}
+		body, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+
+		header := res.Header.Get(middleware.HeaderRequestID)
+		if header == "" || string(body) != header || requestID != "" && header != requestID {
+			t.Fatalf("request id %q: header %q, context %q", requestID, header, body)
+		}
+	}
+}
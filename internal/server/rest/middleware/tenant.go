@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/alxhtp/monogo/config"
+	tenantusecase "github.com/alxhtp/monogo/internal/usecase/tenant"
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+	errorhelper "github.com/alxhtp/monogo/pkg/helper/error"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Tenant resolvers of TENANT_RESOLVERS. jwt and certificate read the tenant from a verified credential,
+// header and subdomain are hints that must name the same tenant.
+const (
+	TenantResolverHeader      = "header"
+	TenantResolverSubdomain   = "subdomain"
+	TenantResolverJWT         = "jwt"
+	TenantResolverCertificate = "certificate"
+)
+
+var (
+	errTenantMissing      = errors.New("tenant is required")
+	errTenantDenied       = errors.New("tenant access denied")
+	errTenantUnverifiable = errors.New("TENANT_RESOLVERS: at least one of jwt or certificate is required")
+)
+
+// tenantResolver returns the tenant named by the request, empty when it names none
+type tenantResolver func(c *fiber.Ctx) string
+
+// Tenant resolves the tenant of the request and stores it into the request context, every query of the request
+// then goes to the schema of that tenant. The tenant is only taken from a verified credential: the claim of the
+// bearer token checked by Authentication or the client certificate checked by ClientIdentity, so both must run before.
+// Requests without a verified tenant are rejected with 401. Verified credentials or hints naming another tenant,
+// and unknown tenants, are all rejected with the same 403 so tenants cannot be enumerated.
+func Tenant(cfg config.TenantConfig, tenantUsecase tenantusecase.TenantUsecase) (fiber.Handler, error) {
+	var verified, hints []tenantResolver
+	for _, name := range cfg.TenantResolvers {
+		switch strings.TrimSpace(name) {
+		case TenantResolverHeader:
+			hints = append(hints, headerTenantResolver(cfg.TenantHeader))
+		case TenantResolverSubdomain:
+			if cfg.TenantBaseDomain == "" {
+				return nil, errors.New("TENANT_BASE_DOMAIN is required by the subdomain tenant resolver")
+			}
+			hints = append(hints, subdomainTenantResolver(cfg.TenantBaseDomain))
+		case TenantResolverJWT:
+			verified = append(verified, jwtTenantResolver(cfg.TenantJWTClaim))
+		case TenantResolverCertificate:
+			verified = append(verified, certificateTenantResolver())
+		default:
+			return nil, fmt.Errorf("TENANT_RESOLVERS: unknown resolver %q, expected one of header, subdomain, jwt, certificate", name)
+		}
+	}
+	if len(verified) == 0 {
+		return nil, errTenantUnverifiable
+	}
+
+	return func(c *fiber.Ctx) error {
+		// already resolved by the middleware of an enclosing group
+		if contexthelper.GetTenant(c.Context()) != "" {
+			return c.Next()
+		}
+
+		var tenant string
+		for _, resolve := range verified {
+			resolved := resolve(c)
+			if resolved == "" {
+				continue
+			}
+			if tenant != "" && tenant != resolved {
+				return tenantError(c, fiber.StatusForbidden, errTenantDenied)
+			}
+			tenant = resolved
+		}
+		if tenant == "" {
+			return tenantError(c, fiber.StatusUnauthorized, errTenantMissing)
+		}
+		for _, resolve := range hints {
+			if hint := resolve(c); hint != "" && hint != tenant {
+				return tenantError(c, fiber.StatusForbidden, errTenantDenied)
+			}
+		}
+
+		found, err := tenantUsecase.ResolveTenant(c.Context(), tenant)
+		if err != nil {
+			return tenantError(c, fiber.StatusInternalServerError, err)
+		}
+		if !found {
+			return tenantError(c, fiber.StatusForbidden, errTenantDenied)
+		}
+
+		c.Locals(contexthelper.KeyTenant, tenant)
+		return c.Next()
+	}, nil
+}
+
+func headerTenantResolver(header string) tenantResolver {
+	return func(c *fiber.Ctx) string {
+		return strings.TrimSpace(c.Get(header))
+	}
+}
+
+// subdomainTenantResolver takes the tenant from the first label of hosts under baseDomain, e.g. acme.example.com
+func subdomainTenantResolver(baseDomain string) tenantResolver {
+	suffix := "." + strings.ToLower(strings.Trim(baseDomain, "."))
+	return func(c *fiber.Ctx) string {
+		subdomain, ok := strings.CutSuffix(strings.ToLower(c.Hostname()), suffix)
+		if !ok || strings.Contains(subdomain, ".") {
+			return ""
+		}
+		return subdomain
+	}
+}
+
+// jwtTenantResolver takes the tenant from a claim of the bearer token verified by Authentication
+func jwtTenantResolver(claim string) tenantResolver {
+	return func(c *fiber.Ctx) string {
+		claims, _ := c.Locals(localsClaims).(jwt.MapClaims)
+		tenant, _ := claims[claim].(string)
+		return tenant
+	}
+}
+
+// certificateTenantResolver takes the tenant from the first organization (O) of the client certificate verified by ClientIdentity
+func certificateTenantResolver() tenantResolver {
+	return func(c *fiber.Ctx) string {
+		identity := contexthelper.GetClientIdentity(c.Context())
+		if identity == nil || len(identity.Organization) == 0 {
+			return ""
+		}
+		return identity.Organization[0]
+	}
+}
+
+func tenantError(c *fiber.Ctx, code int, err error) error {
+	return c.Status(code).JSON(fiber.Map{
+		"success":    false,
+		"code":       code,
+		"message":    err.Error(),
+		"stacktrace": errorhelper.ComposeStacktrace(err),
+	})
+}
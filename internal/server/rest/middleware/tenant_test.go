@@ -0,0 +1,171 @@
+package middleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alxhtp/monogo/config"
+	"github.com/alxhtp/monogo/internal/server/rest/middleware"
+	"github.com/alxhtp/monogo/pkg/dto"
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var testJWTConfig = config.JWTConfig{SecretKey: "secret", TokenIssuer: "monogo", TokenAudience: "monogo"}
+
+// tenantUsecase knows the tenants of its keys
+type tenantUsecase map[string]bool
+
+func (u tenantUsecase) CreateTenant(context.Context, *dto.ReqCreateTenant) dto.ResTenantSingle {
+	return dto.ResTenantSingle{}
+}
+
+func (u tenantUsecase) GetTenants(context.Context) dto.ResTenantList {
+	return dto.ResTenantList{}
+}
+
+func (u tenantUsecase) ResolveTenant(_ context.Context, id string) (bool, error) {
+	return u[id], nil
+}
+
+func newTenantApp(t *testing.T, resolvers ...string) *fiber.App {
+	t.Helper()
+
+	tenant, err := middleware.Tenant(config.TenantConfig{
+		TenantResolvers:  resolvers,
+		TenantHeader:     "X-Tenant-ID",
+		TenantBaseDomain: "example.com",
+		TenantJWTClaim:   "tenant",
+	}, tenantUsecase{"acme": true, "globex": true})
+	if err != nil {
+		t.Fatalf("tenant middleware: %v", err)
+	}
+
+	app := fiber.New()
+	app.Use(middleware.Authentication(testJWTConfig))
+	app.Get("/", tenant, func(c *fiber.Ctx) error {
+		return c.SendString(contexthelper.GetTenant(c.Context()))
+	})
+	return app
+}
+
+func signToken(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+
+	claims["iss"] = testJWTConfig.TokenIssuer
+	claims["aud"] = testJWTConfig.TokenAudience
+	claims["exp"] = time.Now().Add(time.Hour).Unix()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testJWTConfig.SecretKey))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	return token
+}
+
+func TestTenantTakesTheTenantOfVerifiedCredentials(t *testing.T) {
+	app := newTenantApp(t, middleware.TenantResolverHeader, middleware.TenantResolverSubdomain, middleware.TenantResolverJWT)
+	acme := signToken(t, jwt.MapClaims{"sub": "alice", "tenant": "acme"})
+
+	tests := []struct {
+		name       string
+		host       string
+		token      string
+		header     string
+		wantStatus int
+		wantTenant string
+	}{
+		{name: "claim", token: acme, wantStatus: http.StatusOK, wantTenant: "acme"},
+		{name: "matching hints", host: "acme.example.com", token: acme, header: "acme", wantStatus: http.StatusOK, wantTenant: "acme"},
+		{name: "header alone", header: "acme", wantStatus: http.StatusUnauthorized},
+		{name: "subdomain alone", host: "acme.example.com", wantStatus: http.StatusUnauthorized},
+		{name: "token without claim", token: signToken(t, jwt.MapClaims{"sub": "alice"}), header: "acme", wantStatus: http.StatusUnauthorized},
+		{name: "invalid token", token: acme + "x", wantStatus: http.StatusUnauthorized},
+		{name: "header naming another tenant", token: acme, header: "globex", wantStatus: http.StatusForbidden},
+		{name: "subdomain naming another tenant", host: "globex.example.com", token: acme, wantStatus: http.StatusForbidden},
+		{name: "unknown tenant", token: signToken(t, jwt.MapClaims{"sub": "alice", "tenant": "umbrella"}), wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.host != "" {
+				req.Host = tt.host
+			}
+			if tt.token != "" {
+				req.Header.Set(fiber.HeaderAuthorization, "Bearer "+tt.token)
+			}
+			if tt.header != "" {
+				req.Header.Set("X-Tenant-ID", tt.header)
+			}
+
+			res, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request: %v", err)
+			}
+			defer res.Body.Close()
+			body, _ := io.ReadAll(res.Body)
+
+			if res.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", res.StatusCode, tt.wantStatus, body)
+			}
+			if tt.wantTenant != "" && string(body) != tt.wantTenant {
+				t.Fatalf("tenant = %q, want %q", body, tt.wantTenant)
+			}
+		})
+	}
+}
+
+func TestTenantDoesNotTellUnknownTenantsApart(t *testing.T) {
+	app := newTenantApp(t, middleware.TenantResolverHeader, middleware.TenantResolverJWT)
+
+	messages := make(map[string]string)
+	for name, req := range map[string]struct{ tenant, hint string }{
+		"another tenant": {tenant: "acme", hint: "globex"},
+		"unknown tenant": {tenant: "umbrella"},
+	} {
+		httpReq := httptest.NewRequest(http.MethodGet, "/", nil)
+		httpReq.Header.Set(fiber.HeaderAuthorization, "Bearer "+signToken(t, jwt.MapClaims{"sub": "alice", "tenant": req.tenant}))
+		if req.hint != "" {
+			httpReq.Header.Set("X-Tenant-ID", req.hint)
+		}
+
+		res, err := app.Test(httpReq)
+		if err != nil {
+			t.Fatalf("request: %v", err)
+		}
+		var body struct {
+			Message string `json:"message"`
+		}
+		err = json.NewDecoder(res.Body).Decode(&body)
+		res.Body.Close()
+		if err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if res.StatusCode != http.StatusForbidden {
+			t.Fatalf("%s: status = %d, want 403", name, res.StatusCode)
+		}
+		messages[name] = body.Message
+	}
+
+	if messages["another tenant"] != messages["unknown tenant"] {
+		t.Fatalf("messages differ: %q, %q", messages["another tenant"], messages["unknown tenant"])
+	}
+}
+
+func TestTenantRequiresAVerifiedResolver(t *testing.T) {
+	for _, resolvers := range [][]string{
+		{middleware.TenantResolverHeader},
+		{middleware.TenantResolverHeader, middleware.TenantResolverSubdomain},
+		{},
+	} {
+		_, err := middleware.Tenant(config.TenantConfig{TenantResolvers: resolvers, TenantBaseDomain: "example.com"}, tenantUsecase{})
+		if err == nil {
+			t.Errorf("resolvers %v: got no error", resolvers)
+		}
+	}
+}
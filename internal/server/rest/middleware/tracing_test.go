@@ -0,0 +1,86 @@
+package middleware_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alxhtp/monogo/internal/server/rest/middleware"
+	"github.com/alxhtp/monogo/pkg/tracing"
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordSpans installs a tracer provider recording every span until t ends
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	})
+	return recorder
+}
+
+func TestTracingContinuesTheTraceAcrossUsecases(t *testing.T) {
+	recorder := recordSpans(t)
+
+	app := fiber.New()
+	app.Use(middleware.Tracing())
+	app.Get("/users/:id", func(c *fiber.Ctx) error {
+		// handlers pass the fasthttp context to usecases, their spans are children of the request span
+		_, span := tracing.Start(c.Context(), "userUsecase.GetUserByID")
+		tracing.End(span, errors.New("connection refused"))
+		return c.SendStatus(http.StatusInternalServerError)
+	})
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	res.Body.Close()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want the usecase and the request spans", len(spans))
+	}
+	usecase, request := spans[0], spans[1]
+
+	if request.Name() != "GET /users/:id" || request.SpanKind() != trace.SpanKindServer {
+		t.Fatalf("request span = %s %s, want a server span named by the route template", request.Name(), request.SpanKind())
+	}
+	if request.SpanContext().TraceID().String() != traceID || request.Parent().SpanID().String() != "00f067aa0ba902b7" {
+		t.Fatalf("request span trace = %s, parent %s, want the trace of traceparent", request.SpanContext().TraceID(), request.Parent().SpanID())
+	}
+	if request.Status().Code != codes.Error {
+		t.Fatalf("request span status = %v, want an error for a 500", request.Status())
+	}
+	attributes := map[string]string{}
+	for _, attribute := range request.Attributes() {
+		attributes[string(attribute.Key)] = attribute.Value.Emit()
+	}
+	if attributes[string(semconv.HTTPRouteKey)] != "/users/:id" || attributes[string(semconv.HTTPResponseStatusCodeKey)] != "500" {
+		t.Fatalf("request span attributes = %v", attributes)
+	}
+
+	if usecase.Name() != "userUsecase.GetUserByID" || usecase.Parent().SpanID() != request.SpanContext().SpanID() {
+		t.Fatalf("usecase span = %s, parent %s, want a child of the request span", usecase.Name(), usecase.Parent().SpanID())
+	}
+	if usecase.Status().Code != codes.Error || len(usecase.Events()) != 1 {
+		t.Fatalf("usecase span status = %v, events %d, want the error recorded", usecase.Status(), len(usecase.Events()))
+	}
+}
@@ -0,0 +1,90 @@
+package resttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the responses")
+
+// goldenHeaders are the response headers recorded in golden files
+var goldenHeaders = []string{fiber.HeaderContentType, fiber.HeaderContentDisposition}
+
+var (
+	uuidPattern       = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+	timePattern       = regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})`)
+	stacktracePattern = regexp.MustCompile(`"stacktrace": "(?:[^"\\]|\\.)*"`)
+)
+
+// AssertGolden compares res with testdata/<name>.golden, go test -update rewrites the file.
+// Generated values are normalized: uuids become <uuid-N> numbered in order of appearance,
+// timestamps <time> and stacktraces <stacktrace>, JSON bodies are indented.
+func AssertGolden(t testing.TB, name string, res *Response) {
+	t.Helper()
+
+	got := Golden(res)
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("create testdata: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v, run go test -update to create it", path, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("response does not match %s, run go test -update to accept it\n--- got\n%s\n--- want\n%s", path, got, want)
+	}
+}
+
+// Golden renders res as recorded in golden files: status, headers of goldenHeaders and normalized body
+func Golden(res *Response) []byte {
+	var output strings.Builder
+	fmt.Fprintf(&output, "%d %s\n", res.Status, http.StatusText(res.Status))
+	for _, header := range goldenHeaders {
+		if value := res.Header.Get(header); value != "" {
+			fmt.Fprintf(&output, "%s: %s\n", header, value)
+		}
+	}
+	output.WriteString("\n")
+
+	body := res.Body
+	var indented bytes.Buffer
+	if json.Valid(body) && json.Indent(&indented, body, "", "  ") == nil {
+		body = append(indented.Bytes(), '\n')
+	}
+	output.Write(body)
+
+	return normalize(output.String())
+}
+
+// normalize replaces the values differing from run to run
+func normalize(text string) []byte {
+	uuids := make(map[string]string)
+	text = uuidPattern.ReplaceAllStringFunc(text, func(id string) string {
+		id = strings.ToLower(id)
+		if _, ok := uuids[id]; !ok {
+			uuids[id] = fmt.Sprintf("<uuid-%d>", len(uuids)+1)
+		}
+		return uuids[id]
+	})
+	text = timePattern.ReplaceAllString(text, "<time>")
+	text = stacktracePattern.ReplaceAllString(text, `"stacktrace": "<stacktrace>"`)
+	return []byte(text)
+}
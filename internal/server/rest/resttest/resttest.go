@@ -0,0 +1,231 @@
+// Package resttest exercises the REST server in tests through fiber.App.Test, without a listener.
+// The server is built over in-memory repositories, see NewMemoryServer, or over a throwaway postgres schema,
+// see NewDatabaseServer, and responses are compared against golden files, see AssertGolden.
+package resttest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/alxhtp/monogo/config"
+	outboxrepositoryinterface "github.com/alxhtp/monogo/internal/repository/outbox"
+	outboxrepository "github.com/alxhtp/monogo/internal/repository/outbox/implementation"
+	outboxrepositorymemory "github.com/alxhtp/monogo/internal/repository/outbox/memory"
+	transactionrepositorymemory "github.com/alxhtp/monogo/internal/repository/transaction/memory"
+	userrepositorymemory "github.com/alxhtp/monogo/internal/repository/user/memory"
+	restserver "github.com/alxhtp/monogo/internal/server/rest"
+	"github.com/alxhtp/monogo/internal/server/rest/router"
+	"github.com/alxhtp/monogo/migration/migrationtest"
+	"github.com/alxhtp/monogo/pkg/event"
+	"github.com/gofiber/fiber/v2"
+)
+
+// requiredEnv are the settings config.Load refuses to start without, they get a test value when unset
+var requiredEnv = map[string]string{
+	"JWT_SECRET_KEY":   "resttest",
+	"SWAGGER_USERNAME": "resttest",
+	"SWAGGER_PASSWORD": "resttest",
+}
+
+// Config loads the config of the environment, turned into a self-contained one: no multi-tenancy, cache,
+// idempotency, metrics, tracing, migrations or deprecated versions, and imports of more than 2 rows run as background jobs.
+func Config(t testing.TB) *config.Config {
+	t.Helper()
+
+	for key, value := range requiredEnv {
+		if os.Getenv(key) == "" {
+			t.Setenv(key, value)
+		}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+
+	cfg.Scheme = "http"
+	cfg.BodyLimit = 4 << 20
+	cfg.MigrationAuto = false
+	cfg.MigrationCheck = false
+	cfg.TenantEnabled = false
+	cfg.CacheEnabled = false
+	cfg.IdempotencyEnabled = false
+	cfg.MetricsEnabled = false
+	cfg.TracingExporter = "none"
+	cfg.HealthDiskMinFreeMB = 0
+	cfg.AdminUsername = ""
+	cfg.AdminPassword = ""
+	cfg.APIDeprecatedVersions = nil
+	cfg.APISunsetVersions = nil
+	cfg.ImportAsyncThreshold = 2
+	return cfg
+}
+
+// Server is a REST server under test
+type Server struct {
+	t   testing.TB
+	App *fiber.App
+
+	// Outbox holds the events of the user routes, e.g. to publish them for the event stream replay
+	Outbox outboxrepositoryinterface.OutboxRepository
+}
+
+// NewMemoryServer serves cfg over in-memory repositories, only the user routes are usable without a database
+func NewMemoryServer(t testing.TB, cfg *config.Config) *Server {
+	t.Helper()
+
+	outbox := outboxrepositorymemory.NewOutboxRepository()
+	return newServer(t, cfg, outbox,
+		restserver.WithDB(nil),
+		restserver.WithRepositories(router.Repositories{
+			User:        userrepositorymemory.NewUserRepository(),
+			Outbox:      outbox,
+			Transaction: transactionrepositorymemory.NewTransactionRepository(),
+		}),
+	)
+}
+
+// NewDatabaseServer serves cfg over a throwaway postgres schema, t is skipped when migrationtest.DSNEnv is not set
+func NewDatabaseServer(t testing.TB, cfg *config.Config) *Server {
+	t.Helper()
+
+	db := migrationtest.OpenSchema(t)
+	return newServer(t, cfg, outboxrepository.NewOutboxRepository(db), restserver.WithDB(db))
+}
+
+func newServer(t testing.TB, cfg *config.Config, outbox outboxrepositoryinterface.OutboxRepository, opts ...restserver.Option) *Server {
+	t.Helper()
+
+	// events are only replayed, the live stream ends right away so event requests complete
+	opts = append(opts, restserver.WithSubscriber(closedSubscriber{}))
+	server, err := restserver.NewRestServer(context.Background(), cfg, opts...)
+	if err != nil {
+		t.Fatalf("new rest server: %v", err)
+	}
+	app, err := server.App()
+	if err != nil {
+		t.Fatalf("set up rest server: %v", err)
+	}
+
+	return &Server{t: t, App: app, Outbox: outbox}
+}
+
+// Response is a response read in full
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Do serves req and reads its response
+func (s *Server) Do(req *http.Request) *Response {
+	s.t.Helper()
+
+	res, err := s.App.Test(req, -1)
+	if err != nil {
+		s.t.Fatalf("%s %s: %v", req.Method, req.URL, err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		s.t.Fatalf("%s %s: read body: %v", req.Method, req.URL, err)
+	}
+
+	return &Response{Status: res.StatusCode, Header: res.Header, Body: body}
+}
+
+// Call serves req and decodes its JSON body as T, e.g. dto.ResUserSingle or dto.ResUserList
+func Call[T any](s *Server, req *http.Request) (*Response, T) {
+	s.t.Helper()
+
+	res := s.Do(req)
+	return res, Decode[T](s.t, res)
+}
+
+// Decode decodes the JSON body of res as T
+func Decode[T any](t testing.TB, res *Response) T {
+	t.Helper()
+
+	var output T
+	if err := json.Unmarshal(res.Body, &output); err != nil {
+		t.Fatalf("decode %T from %q: %v", output, res.Body, err)
+	}
+	return output
+}
+
+// NewRequest returns a request to target, body is sent as is when it is a string or []byte, as JSON otherwise
+func NewRequest(t testing.TB, method, target string, body any) *http.Request {
+	t.Helper()
+
+	var reader io.Reader
+	contentType := ""
+	switch body := body.(type) {
+	case nil:
+	case string:
+		reader = bytes.NewBufferString(body)
+		contentType = fiber.MIMEApplicationJSON
+	case []byte:
+		reader = bytes.NewReader(body)
+		contentType = fiber.MIMEApplicationJSON
+	default:
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("encode %T: %v", body, err)
+		}
+		reader = bytes.NewReader(data)
+		contentType = fiber.MIMEApplicationJSON
+	}
+
+	req := httptest.NewRequest(method, target, reader)
+	if contentType != "" {
+		req.Header.Set(fiber.HeaderContentType, contentType)
+	}
+	return req
+}
+
+// NewFileRequest returns a multipart request to target uploading content as the file of field
+func NewFileRequest(t testing.TB, method, target, field, filename string, content []byte) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile(field, filename)
+	if err != nil {
+		t.Fatalf("create form file: %v", err)
+	}
+	if _, err := part.Write(content); err != nil {
+		t.Fatalf("write form file: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close multipart writer: %v", err)
+	}
+
+	req := httptest.NewRequest(method, target, &body)
+	req.Header.Set(fiber.HeaderContentType, writer.FormDataContentType())
+	return req
+}
+
+// closedSubscriber is a subscriber without live messages, its subscriptions are closed right away
+type closedSubscriber struct{}
+
+func (closedSubscriber) Name() string {
+	return "resttest"
+}
+
+func (closedSubscriber) Subscribe() (<-chan event.Message, func()) {
+	messages := make(chan event.Message)
+	close(messages)
+	return messages, func() {}
+}
+
+func (closedSubscriber) Run(ctx context.Context) {
+	<-ctx.Done()
+}
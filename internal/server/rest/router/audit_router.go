@@ -0,0 +1,26 @@
+package router
+
+import (
+	"github.com/alxhtp/monogo/internal/handler"
+	auditrepository "github.com/alxhtp/monogo/internal/repository/audit/implementation"
+	auditserializer "github.com/alxhtp/monogo/internal/serializer/audit/implementation"
+	auditusecase "github.com/alxhtp/monogo/internal/usecase/audit/implementation"
+)
+
+func AuditRouter(deps *Dependencies) {
+	auditRepository := auditrepository.NewAuditRepository(deps.DB)
+	auditSerializer := auditserializer.NewAuditSerializer()
+	auditUsecase := auditusecase.NewAuditUsecase(auditRepository, auditSerializer)
+	auditHandler := handler.NewAuditHandler(auditUsecase)
+
+	deps.Version(V1).Get("/users/:id/history", deps.Tenant, auditHandler.GetUserHistory)
+
+	// admin routes stay unregistered until admin credentials are configured
+	adminGroup, ok := deps.Admin()
+	if !ok {
+		return
+	}
+
+	// audit logs are kept per tenant, admins read them tenant by tenant
+	adminGroup.Get("/audit-logs", deps.Tenant, auditHandler.GetAuditLogs)
+}
@@ -2,7 +2,30 @@ package router
 
 import (
 	"github.com/alxhtp/monogo/config"
+	idempotencyrepository "github.com/alxhtp/monogo/internal/repository/idempotency/implementation"
+	outboxrepositoryinterface "github.com/alxhtp/monogo/internal/repository/outbox"
+	outboxrepository "github.com/alxhtp/monogo/internal/repository/outbox/implementation"
+	tenantrepositoryinterface "github.com/alxhtp/monogo/internal/repository/tenant"
+	tenantrepository "github.com/alxhtp/monogo/internal/repository/tenant/implementation"
+	tenantrepositoryrow "github.com/alxhtp/monogo/internal/repository/tenant/row"
+	transactionrepositoryinterface "github.com/alxhtp/monogo/internal/repository/transaction"
+	transactionrepository "github.com/alxhtp/monogo/internal/repository/transaction/implementation"
+	userrepositoryinterface "github.com/alxhtp/monogo/internal/repository/user"
+	userrepositorycache "github.com/alxhtp/monogo/internal/repository/user/cache"
+	userrepository "github.com/alxhtp/monogo/internal/repository/user/implementation"
+	tenantserializer "github.com/alxhtp/monogo/internal/serializer/tenant/implementation"
+	userserializer "github.com/alxhtp/monogo/internal/serializer/user/implementation"
+	"github.com/alxhtp/monogo/internal/server/rest/middleware"
+	"github.com/alxhtp/monogo/internal/subscriber"
+	tenantusecase "github.com/alxhtp/monogo/internal/usecase/tenant"
+	tenantusecaseimplementation "github.com/alxhtp/monogo/internal/usecase/tenant/implementation"
+	userusecase "github.com/alxhtp/monogo/internal/usecase/user"
+	userusecaseimplementation "github.com/alxhtp/monogo/internal/usecase/user/implementation"
+	"github.com/alxhtp/monogo/pkg/cache"
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/basicauth"
 	"gorm.io/gorm"
 )
 
@@ -10,12 +33,133 @@ type Dependencies struct {
 	App *fiber.App
 	DB  *gorm.DB
 	Cfg *config.Config
+
+	EventSubscriber subscriber.Subscriber
+
+	// UserUsecase is shared by every transport serving users, e.g. the gRPC server
+	UserUsecase userusecase.UserUsecase
+
+	// TenantUsecase resolves and provisions tenants, shared by every transport
+	TenantUsecase tenantusecase.TenantUsecase
+
+	// TenantRepository lists the tenants the workers go through, nil when multi-tenancy is disabled
+	TenantRepository tenantrepositoryinterface.TenantRepository
+
+	// Idempotency guards POST routes with the Idempotency-Key header, a pass-through when disabled
+	Idempotency fiber.Handler
+
+	// Tenant binds the request to its tenant, a pass-through when multi-tenancy is disabled.
+	// It must run before any handler touching tenant data, including Idempotency.
+	Tenant fiber.Handler
+
+	// versions holds the route group of every API version, see MountVersions
+	versions map[string]fiber.Router
+	// admin is the admin route group, see Admin
+	admin fiber.Router
+}
+
+// Repositories overrides the repositories the user routes are built on, e.g. with in-memory repositories in tests.
+// The repositories left nil are built over the database.
+type Repositories struct {
+	User        userrepositoryinterface.UserRepository
+	Outbox      outboxrepositoryinterface.OutboxRepository
+	Transaction transactionrepositoryinterface.TransactionRepository
 }
 
-func NewDependencies(app *fiber.App, db *gorm.DB, cfg *config.Config) *Dependencies {
+func NewDependencies(app *fiber.App, db *gorm.DB, cfg *config.Config, eventSubscriber subscriber.Subscriber, repositories Repositories) (*Dependencies, error) {
+	passThrough := func(c *fiber.Ctx) error {
+		return c.Next()
+	}
+
+	idempotency := passThrough
+	if cfg.IdempotencyEnabled {
+		idempotency = middleware.Idempotency(idempotencyrepository.NewIdempotencyRepository(db), cfg.IdempotencyConfig)
+	}
+
+	var tenantRepository tenantrepositoryinterface.TenantRepository = tenantrepository.NewTenantRepository(db)
+	if cfg.TenantMode == databasehelper.TenantModeRow {
+		tenantRepository = tenantrepositoryrow.NewTenantRepository(db)
+	}
+	tenantUsecase := tenantusecaseimplementation.NewTenantUsecase(
+		tenantRepository,
+		tenantserializer.NewTenantSerializer(),
+		cfg.TenantConfig,
+	)
+	tenant := passThrough
+	if cfg.TenantEnabled {
+		var err error
+		if tenant, err = middleware.Tenant(cfg.TenantConfig, tenantUsecase); err != nil {
+			return nil, err
+		}
+	} else {
+		tenantRepository = nil
+	}
+
+	userRepository := repositories.User
+	if userRepository == nil {
+		userRepository = userrepository.NewUserRepository(db)
+	}
+	if cfg.CacheEnabled {
+		userRepository = userrepositorycache.NewUserRepository(userRepository, cache.NewLRU(cfg.CacheMaxEntries), cfg.CacheConfig)
+	}
+
+	outboxRepository := repositories.Outbox
+	if outboxRepository == nil {
+		outboxRepository = outboxrepository.NewOutboxRepository(db)
+	}
+	transactionRepository := repositories.Transaction
+	if transactionRepository == nil {
+		transactionRepository = transactionrepository.NewTransactionRepository(db)
+	}
+
 	return &Dependencies{
 		App: app,
 		DB:  db,
 		Cfg: cfg,
+
+		EventSubscriber: eventSubscriber,
+
+		UserUsecase: userusecaseimplementation.NewUserUsecase(
+			userRepository,
+			outboxRepository,
+			transactionRepository,
+			userserializer.NewUserSerializer(),
+			eventSubscriber,
+			cfg.UserImportConfig,
+			cfg.UserEventsConfig,
+		),
+
+		TenantUsecase:    tenantUsecase,
+		TenantRepository: tenantRepository,
+
+		Idempotency: idempotency,
+		Tenant:      tenant,
+
+		versions: make(map[string]fiber.Router),
+	}, nil
+}
+
+// Admin returns the v1 route group of admin endpoints behind basic auth,
+// false while admin credentials are not configured and admin routes must stay unregistered
+func (d *Dependencies) Admin() (fiber.Router, bool) {
+	if d.Cfg.AdminUsername == "" || d.Cfg.AdminPassword == "" {
+		return nil, false
+	}
+
+	if d.admin == nil {
+		d.admin = d.Version(V1).Group("/admin", basicauth.New(basicauth.Config{
+			Users: map[string]string{
+				d.Cfg.AdminUsername: d.Cfg.AdminPassword,
+			},
+		}), d.adminActor)
+	}
+	return d.admin, true
+}
+
+// adminActor makes the admin the actor of admin requests made without a verified principal
+func (d *Dependencies) adminActor(c *fiber.Ctx) error {
+	if contexthelper.GetPrincipal(c.Context()) == "" {
+		c.Locals(contexthelper.KeyActor, d.Cfg.AdminUsername)
 	}
+	return c.Next()
 }
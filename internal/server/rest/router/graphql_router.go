@@ -0,0 +1,31 @@
+package router
+
+import (
+	graphqlserver "github.com/alxhtp/monogo/internal/server/graphql"
+	"github.com/gofiber/fiber/v2/middleware/basicauth"
+)
+
+func GraphqlRouter(deps *Dependencies) error {
+	if !deps.Cfg.GraphqlEnabled {
+		return nil
+	}
+
+	graphqlHandler, err := graphqlserver.NewHandler(deps.Cfg.GraphqlConfig, deps.UserUsecase)
+	if err != nil {
+		return err
+	}
+
+	deps.App.Get("/graphql", deps.Tenant, graphqlHandler.Serve)
+	deps.App.Post("/graphql", deps.Tenant, graphqlHandler.Serve)
+
+	// the playground is gated like swagger
+	if deps.Cfg.GraphqlPlayground {
+		deps.App.Get("/graphql/playground", basicauth.New(basicauth.Config{
+			Users: map[string]string{
+				deps.Cfg.SwaggerUsername: deps.Cfg.SwaggerPassword,
+			},
+		}), graphqlserver.Playground("/graphql"))
+	}
+
+	return nil
+}
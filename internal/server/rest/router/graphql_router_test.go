@@ -0,0 +1,63 @@
+package router_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	graphqlserver "github.com/alxhtp/monogo/internal/server/graphql"
+	"github.com/alxhtp/monogo/internal/server/rest/resttest"
+)
+
+type graphqlResult struct {
+	Data   map[string]any `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func TestGraphqlQueryLimits(t *testing.T) {
+	const (
+		// user > metadata > sex is 3 levels deep
+		deepQuery = `{ user(id: "6f1c5a8e-2b47-4c1d-9a8e-3f5b7c9d1e2a") { metadata { sex } } }`
+		// items costs 3 per user with id and name, twice for a page of 2, plus users itself
+		costlyQuery = `{ users(pagination: {limit: 2}) { items { id name } } }`
+	)
+
+	tests := []struct {
+		name          string
+		maxDepth      int
+		maxComplexity int
+		query         string
+		wantError     string
+	}{
+		{name: "depth at the limit", maxDepth: 3, query: deepQuery},
+		{name: "depth over the limit", maxDepth: 2, query: deepQuery, wantError: "query depth 3 exceeds the maximum of 2"},
+		{name: "complexity at the limit", maxComplexity: 7, query: costlyQuery},
+		{name: "complexity over the limit", maxComplexity: 6, query: costlyQuery, wantError: "query complexity 7 exceeds the maximum of 6"},
+		{name: "limits disabled", query: deepQuery},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := resttest.Config(t)
+			cfg.GraphqlEnabled = true
+			cfg.GraphqlMaxDepth = tt.maxDepth
+			cfg.GraphqlMaxComplexity = tt.maxComplexity
+			s := resttest.NewMemoryServer(t, cfg)
+
+			res, body := resttest.Call[graphqlResult](s, resttest.NewRequest(t, http.MethodPost, "/graphql", graphqlserver.Request{Query: tt.query}))
+			if tt.wantError == "" {
+				if res.Status != http.StatusOK || len(body.Errors) != 0 || body.Data == nil {
+					t.Fatalf("%d %s, want the query executed", res.Status, res.Body)
+				}
+				return
+			}
+			if res.Status != http.StatusBadRequest || len(body.Errors) != 1 || !strings.Contains(body.Errors[0].Message, tt.wantError) {
+				t.Fatalf("%d %s, want rejected with %q", res.Status, res.Body, tt.wantError)
+			}
+			if body.Data != nil {
+				t.Fatalf("data = %v, nothing must be resolved", body.Data)
+			}
+		})
+	}
+}
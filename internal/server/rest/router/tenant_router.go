@@ -0,0 +1,18 @@
+package router
+
+import (
+	"github.com/alxhtp/monogo/internal/handler"
+)
+
+// TenantRouter serves the tenant administration, tenants are managed through the admin endpoints only
+func TenantRouter(deps *Dependencies) {
+	tenantHandler := handler.NewTenantHandler(deps.TenantUsecase)
+
+	adminGroup, ok := deps.Admin()
+	if !ok {
+		return
+	}
+
+	adminGroup.Post("/tenants", tenantHandler.CreateTenant)
+	adminGroup.Get("/tenants", tenantHandler.GetTenants)
+}
@@ -2,20 +2,33 @@ package router
 
 import (
 	"github.com/alxhtp/monogo/internal/handler"
-	userrepository "github.com/alxhtp/monogo/internal/repository/user/implementation"
-	userserializer "github.com/alxhtp/monogo/internal/serializer/user/implementation"
-	userusecase "github.com/alxhtp/monogo/internal/usecase/user/implementation"
+	handlerv2 "github.com/alxhtp/monogo/internal/handler/v2"
 )
 
 func UserRouter(deps *Dependencies) {
-	userRepository := userrepository.NewUserRepository(deps.DB)
-	userSerializer := userserializer.NewUserSerializer()
-	userUsecase := userusecase.NewUserUsecase(userRepository, userSerializer)
-	userHandler := handler.NewUserHandler(userUsecase)
+	userHandler := handler.NewUserHandler(deps.UserUsecase, deps.Cfg.UserEventsConfig)
 
-	userGroup := deps.App.Group("/v1/users")
+	userGroup := deps.Version(V1).Group("/users", deps.Tenant)
 
-	userGroup.Post("/", userHandler.CreateUser)
+	userGroup.Post("/", deps.Idempotency, userHandler.CreateUser)
+	userGroup.Post("/import", deps.Idempotency, userHandler.ImportUsers)
+	userGroup.Get("/import/:id", userHandler.GetImportJob)
+	userGroup.Get("/import/:id/errors", userHandler.GetImportJobErrors)
+	userGroup.Get("/events", userHandler.StreamUserEvents)
+	// /:id matches any segment, the static routes above are registered first
+	userGroup.Get("/:id", userHandler.GetUserByID)
+	userGroup.Get("/", userHandler.GetUsersByFilter)
+	userGroup.Put("/:id", userHandler.UpdateUser)
+	userGroup.Delete("/:id", userHandler.DeleteUser)
+}
+
+// UserRouterV2 serves the v2 user DTOs, backed by the same usecase as v1
+func UserRouterV2(deps *Dependencies) {
+	userHandler := handlerv2.NewUserHandler(deps.UserUsecase)
+
+	userGroup := deps.Version(V2).Group("/users", deps.Tenant)
+
+	userGroup.Post("/", deps.Idempotency, userHandler.CreateUser)
 	userGroup.Get("/:id", userHandler.GetUserByID)
 	userGroup.Get("/", userHandler.GetUsersByFilter)
 	userGroup.Put("/:id", userHandler.UpdateUser)
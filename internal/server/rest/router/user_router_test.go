@@ -0,0 +1,385 @@
+package router_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alxhtp/monogo/internal/handler"
+	"github.com/alxhtp/monogo/internal/server/rest/resttest"
+	"github.com/alxhtp/monogo/pkg/dto"
+	"github.com/google/uuid"
+)
+
+const usersPath = "/v1/users"
+
+func TestUserRouter(t *testing.T) {
+	testUserRouter(t, func(t *testing.T) *resttest.Server {
+		return resttest.NewMemoryServer(t, resttest.Config(t))
+	})
+}
+
+func TestUserRouterDatabase(t *testing.T) {
+	testUserRouter(t, func(t *testing.T) *resttest.Server {
+		return resttest.NewDatabaseServer(t, resttest.Config(t))
+	})
+}
+
+// testUserRouter goes through every route of router.UserRouter, the responses of both servers match the same golden files
+func testUserRouter(t *testing.T, newServer func(t *testing.T) *resttest.Server) {
+	t.Run("CreateUser", func(t *testing.T) {
+		s := newServer(t)
+
+		res, body := resttest.Call[dto.ResUserSingle](s, resttest.NewRequest(t, http.MethodPost, usersPath, newUser("Alice", "alice@example.com")))
+		if res.Status != http.StatusCreated || body.Data == nil || body.Data.Email != "alice@example.com" {
+			t.Fatalf("create: %d %+v", res.Status, body)
+		}
+		resttest.AssertGolden(t, "create_user", res)
+	})
+
+	t.Run("CreateUserInvalidBody", func(t *testing.T) {
+		s := newServer(t)
+
+		res := s.Do(resttest.NewRequest(t, http.MethodPost, usersPath, "{"))
+		resttest.AssertGolden(t, "create_user_invalid_body", res)
+	})
+
+	t.Run("CreateUserValidation", func(t *testing.T) {
+		s := newServer(t)
+
+		res := s.Do(resttest.NewRequest(t, http.MethodPost, usersPath, newUser("Alice", "not-an-email")))
+		resttest.AssertGolden(t, "create_user_validation", res)
+	})
+
+	t.Run("CreateUserDuplicateEmail", func(t *testing.T) {
+		s := newServer(t)
+		createUser(t, s, "Alice", "alice@example.com")
+
+		res := s.Do(resttest.NewRequest(t, http.MethodPost, usersPath, newUser("Alicia", "alice@example.com")))
+		assertNoDatabaseError(t, res)
+		resttest.AssertGolden(t, "create_user_duplicate_email", res)
+	})
+
+	t.Run("GetUserByID", func(t *testing.T) {
+		s := newServer(t)
+		user := createUser(t, s, "Alice", "alice@example.com")
+
+		res, body := resttest.Call[dto.ResUserSingle](s, resttest.NewRequest(t, http.MethodGet, usersPath+"/"+user.ID.String(), nil))
+		if res.Status != http.StatusOK || body.Data == nil || body.Data.ID != user.ID {
+			t.Fatalf("get: %d %+v", res.Status, body)
+		}
+		resttest.AssertGolden(t, "get_user", res)
+	})
+
+	t.Run("GetUserByIDMissing", func(t *testing.T) {
+		s := newServer(t)
+
+		res := s.Do(resttest.NewRequest(t, http.MethodGet, usersPath+"/"+uuid.NewString(), nil))
+		resttest.AssertGolden(t, "get_user_missing", res)
+	})
+
+	t.Run("GetUserByIDMalformed", func(t *testing.T) {
+		s := newServer(t)
+
+		res := s.Do(resttest.NewRequest(t, http.MethodGet, usersPath+"/not-a-uuid", nil))
+		resttest.AssertGolden(t, "get_user_malformed_id", res)
+	})
+
+	t.Run("GetUsersByFilter", func(t *testing.T) {
+		s := newServer(t)
+		createUser(t, s, "Alice", "alice@example.com")
+		createUser(t, s, "Bob", "bob@example.com")
+		createUser(t, s, "Alina", "alina@example.com")
+
+		res, body := resttest.Call[dto.ResUserList](s, resttest.NewRequest(t, http.MethodGet, usersPath+"?name=ali&order-by=%2Bname&show-count=true", nil))
+		if res.Status != http.StatusOK || len(body.Data) != 2 || body.Page.Count != 2 {
+			t.Fatalf("get by filter: %d %+v", res.Status, body)
+		}
+		resttest.AssertGolden(t, "get_users_by_filter", res)
+	})
+
+	t.Run("GetUsersByFilterPage", func(t *testing.T) {
+		s := newServer(t)
+		createUser(t, s, "Alice", "alice@example.com")
+		createUser(t, s, "Bob", "bob@example.com")
+		createUser(t, s, "Carol", "carol@example.com")
+
+		res := s.Do(resttest.NewRequest(t, http.MethodGet, usersPath+"?order-by=-email&offset=1&limit=1", nil))
+		resttest.AssertGolden(t, "get_users_by_filter_page", res)
+	})
+
+	t.Run("GetUsersByFilterInvalidIDs", func(t *testing.T) {
+		s := newServer(t)
+
+		res := s.Do(resttest.NewRequest(t, http.MethodGet, usersPath+"?ids=not-a-uuid", nil))
+		resttest.AssertGolden(t, "get_users_by_filter_invalid_ids", res)
+	})
+
+	t.Run("GetUsersByFilterInvalidQuery", func(t *testing.T) {
+		s := newServer(t)
+
+		res := s.Do(resttest.NewRequest(t, http.MethodGet, usersPath+"?limit=many", nil))
+		resttest.AssertGolden(t, "get_users_by_filter_invalid_query", res)
+	})
+
+	t.Run("UpdateUser", func(t *testing.T) {
+		s := newServer(t)
+		user := createUser(t, s, "Alice", "alice@example.com")
+
+		name := "Alicia"
+		res, body := resttest.Call[dto.ResUserSingle](s, resttest.NewRequest(t, http.MethodPut, usersPath+"/"+user.ID.String(), dto.ReqUpdateUser{Name: &name}))
+		if res.Status != http.StatusOK || body.Data == nil || body.Data.Name != name {
+			t.Fatalf("update: %d %+v", res.Status, body)
+		}
+		resttest.AssertGolden(t, "update_user", res)
+	})
+
+	t.Run("UpdateUserInvalidBody", func(t *testing.T) {
+		s := newServer(t)
+		user := createUser(t, s, "Alice", "alice@example.com")
+
+		res := s.Do(resttest.NewRequest(t, http.MethodPut, usersPath+"/"+user.ID.String(), "{"))
+		resttest.AssertGolden(t, "update_user_invalid_body", res)
+	})
+
+	t.Run("UpdateUserMissing", func(t *testing.T) {
+		s := newServer(t)
+
+		name := "Alicia"
+		res := s.Do(resttest.NewRequest(t, http.MethodPut, usersPath+"/"+uuid.NewString(), dto.ReqUpdateUser{Name: &name}))
+		resttest.AssertGolden(t, "update_user_missing", res)
+	})
+
+	t.Run("UpdateUserMalformed", func(t *testing.T) {
+		s := newServer(t)
+
+		name := "Alicia"
+		res := s.Do(resttest.NewRequest(t, http.MethodPut, usersPath+"/not-a-uuid", dto.ReqUpdateUser{Name: &name}))
+		resttest.AssertGolden(t, "update_user_malformed_id", res)
+	})
+
+	t.Run("UpdateUserDuplicateEmail", func(t *testing.T) {
+		s := newServer(t)
+		createUser(t, s, "Alice", "alice@example.com")
+		user := createUser(t, s, "Bob", "bob@example.com")
+
+		email := "alice@example.com"
+		res := s.Do(resttest.NewRequest(t, http.MethodPut, usersPath+"/"+user.ID.String(), dto.ReqUpdateUser{Email: &email}))
+		assertNoDatabaseError(t, res)
+		resttest.AssertGolden(t, "update_user_duplicate_email", res)
+	})
+
+	t.Run("DeleteUser", func(t *testing.T) {
+		s := newServer(t)
+		user := createUser(t, s, "Alice", "alice@example.com")
+
+		res := s.Do(resttest.NewRequest(t, http.MethodDelete, usersPath+"/"+user.ID.String(), nil))
+		resttest.AssertGolden(t, "delete_user", res)
+
+		if res := s.Do(resttest.NewRequest(t, http.MethodGet, usersPath+"/"+user.ID.String(), nil)); res.Status == http.StatusOK {
+			t.Fatalf("get a deleted user: %d %s", res.Status, res.Body)
+		}
+	})
+
+	t.Run("DeleteUserMissing", func(t *testing.T) {
+		s := newServer(t)
+
+		res := s.Do(resttest.NewRequest(t, http.MethodDelete, usersPath+"/"+uuid.NewString(), nil))
+		resttest.AssertGolden(t, "delete_user_missing", res)
+	})
+
+	t.Run("DeleteUserMalformed", func(t *testing.T) {
+		s := newServer(t)
+
+		res := s.Do(resttest.NewRequest(t, http.MethodDelete, usersPath+"/not-a-uuid", nil))
+		resttest.AssertGolden(t, "delete_user_malformed_id", res)
+	})
+
+	t.Run("ImportUsers", func(t *testing.T) {
+		s := newServer(t)
+
+		res := s.Do(importRequest(t, "", importCSV("alice@example.com", "bob@example.com")))
+		resttest.AssertGolden(t, "import_users", res)
+	})
+
+	t.Run("ImportUsersDryRun", func(t *testing.T) {
+		s := newServer(t)
+
+		res := s.Do(importRequest(t, "?dry_run=true", importCSV("alice@example.com", "not-an-email")))
+		resttest.AssertGolden(t, "import_users_dry_run", res)
+	})
+
+	t.Run("ImportUsersPartial", func(t *testing.T) {
+		s := newServer(t)
+		createUser(t, s, "Alice", "alice@example.com")
+
+		res := s.Do(importRequest(t, "", importCSV("alice@example.com", "bob@example.com")))
+		resttest.AssertGolden(t, "import_users_partial", res)
+	})
+
+	t.Run("ImportUsersMissingFile", func(t *testing.T) {
+		s := newServer(t)
+
+		res := s.Do(resttest.NewRequest(t, http.MethodPost, usersPath+"/import", nil))
+		resttest.AssertGolden(t, "import_users_missing_file", res)
+	})
+
+	t.Run("ImportUsersJob", func(t *testing.T) {
+		s := newServer(t)
+		createUser(t, s, "Alice", "alice@example.com")
+
+		// more rows than resttest.Config's async threshold
+		res, body := resttest.Call[dto.ResUserImport](s, importRequest(t, "", importCSV("alice@example.com", "bob@example.com", "carol@example.com")))
+		if res.Status != http.StatusAccepted || body.Data == nil || body.Data.Job == nil {
+			t.Fatalf("import: %d %+v", res.Status, body)
+		}
+		resttest.AssertGolden(t, "import_users_job_queued", res)
+
+		jobPath := usersPath + "/import/" + body.Data.Job.ID.String()
+		res = waitImportJob(t, s, jobPath)
+		resttest.AssertGolden(t, "import_users_job", res)
+
+		res = s.Do(resttest.NewRequest(t, http.MethodGet, jobPath+"/errors", nil))
+		resttest.AssertGolden(t, "import_users_job_errors", res)
+	})
+
+	t.Run("GetImportJobMissing", func(t *testing.T) {
+		s := newServer(t)
+
+		res := s.Do(resttest.NewRequest(t, http.MethodGet, usersPath+"/import/"+uuid.NewString(), nil))
+		resttest.AssertGolden(t, "get_import_job_missing", res)
+	})
+
+	t.Run("GetImportJobErrorsMissing", func(t *testing.T) {
+		s := newServer(t)
+
+		res := s.Do(resttest.NewRequest(t, http.MethodGet, usersPath+"/import/"+uuid.NewString()+"/errors", nil))
+		resttest.AssertGolden(t, "get_import_job_errors_missing", res)
+	})
+
+	t.Run("StreamUserEvents", func(t *testing.T) {
+		s := newServer(t)
+		createUser(t, s, "Alice", "alice@example.com")
+		createUser(t, s, "Bob", "bob@example.com")
+		ids := publishEvents(t, s)
+
+		// the stream replays the events published after Last-Event-ID, then ends with the subscription of the harness
+		req := resttest.NewRequest(t, http.MethodGet, usersPath+"/events", nil)
+		req.Header.Set(handler.HeaderLastEventID, ids[0])
+		res := s.Do(req)
+		resttest.AssertGolden(t, "stream_user_events", res)
+	})
+
+	t.Run("StreamUserEventsFilter", func(t *testing.T) {
+		s := newServer(t)
+		createUser(t, s, "Alice", "alice@example.com")
+		createUser(t, s, "Bob", "bob@example.com")
+		createUser(t, s, "Carol", "carol@example.com")
+		ids := publishEvents(t, s)
+
+		res := s.Do(resttest.NewRequest(t, http.MethodGet, usersPath+"/events?name=carol&last-event-id="+ids[0], nil))
+		resttest.AssertGolden(t, "stream_user_events_filter", res)
+	})
+
+	t.Run("StreamUserEventsInvalidLastEventID", func(t *testing.T) {
+		s := newServer(t)
+
+		req := resttest.NewRequest(t, http.MethodGet, usersPath+"/events", nil)
+		req.Header.Set(handler.HeaderLastEventID, "latest")
+		res := s.Do(req)
+		resttest.AssertGolden(t, "stream_user_events_invalid_last_event_id", res)
+	})
+}
+
+func newUser(name, email string) dto.ReqCreateUser {
+	return dto.ReqCreateUser{
+		Name:  name,
+		Email: email,
+		Metadata: dto.UserMetadata{
+			Sex:     "female",
+			Address: "1 Main St",
+			Phone:   "+14155550101",
+		},
+	}
+}
+
+func createUser(t *testing.T, s *resttest.Server, name, email string) dto.ResUser {
+	t.Helper()
+
+	res, body := resttest.Call[dto.ResUserSingle](s, resttest.NewRequest(t, http.MethodPost, usersPath, newUser(name, email)))
+	if res.Status != http.StatusCreated || body.Data == nil {
+		t.Fatalf("create %s: %d %s", email, res.Status, body.Message)
+	}
+	return *body.Data
+}
+
+// importCSV returns an import file with a row per email
+func importCSV(emails ...string) []byte {
+	content := "name,email,sex,address,phone\n"
+	for _, email := range emails {
+		content += "User," + email + ",male,1 Main St,+14155550101\n"
+	}
+	return []byte(content)
+}
+
+func importRequest(t *testing.T, query string, content []byte) *http.Request {
+	return resttest.NewFileRequest(t, http.MethodPost, usersPath+"/import"+query, "file", "users.csv", content)
+}
+
+// waitImportJob polls the import job until it is no longer queued or running
+func waitImportJob(t *testing.T, s *resttest.Server, jobPath string) *resttest.Response {
+	t.Helper()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		res, body := resttest.Call[dto.ResUserImportJobSingle](s, resttest.NewRequest(t, http.MethodGet, jobPath, nil))
+		if res.Status != http.StatusOK || body.Data == nil {
+			t.Fatalf("get import job: %d %s", res.Status, body.Message)
+		}
+		if body.Data.FinishedAt != nil {
+			return res
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("import job is still %s", body.Data.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// publishEvents marks the pending events published as the outbox relay does, and returns their ids in order
+func publishEvents(t *testing.T, s *resttest.Server) []string {
+	t.Helper()
+
+	ctx := context.Background()
+	var ids []string
+	for {
+		pending, err := s.Outbox.GetPending(ctx, 100)
+		if err != nil {
+			t.Fatalf("get pending events: %v", err)
+		}
+		if len(pending) == 0 {
+			return ids
+		}
+		for _, msg := range pending {
+			if err := s.Outbox.MarkPublished(ctx, msg.ID); err != nil {
+				t.Fatalf("mark event %d published: %v", msg.ID, err)
+			}
+			ids = append(ids, strconv.FormatInt(msg.ID, 10))
+		}
+	}
+}
+
+// assertNoDatabaseError fails when the body, stacktrace included, carries the postgres error of a duplicate key,
+// which names the constraint and the SQLSTATE
+func assertNoDatabaseError(t *testing.T, res *resttest.Response) {
+	t.Helper()
+
+	for _, leak := range []string{"duplicate key", "constraint", "SQLSTATE", "23505"} {
+		if strings.Contains(string(res.Body), leak) {
+			t.Fatalf("body names %q: %s", leak, res.Body)
+		}
+	}
+}
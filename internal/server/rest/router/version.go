@@ -0,0 +1,80 @@
+package router
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/alxhtp/monogo/internal/server/rest/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+// REST API versions, every version is mounted under /<version> and documented on /swagger/<version>/
+const (
+	V1 = "v1"
+	V2 = "v2"
+)
+
+// Versions lists the REST API versions from the oldest, the last one is the current version
+var Versions = []string{V1, V2}
+
+const versionDateLayout = "2006-01-02"
+
+// MountVersions creates the route group of every API version. Versions listed in API_DEPRECATED_VERSIONS
+// send the Deprecation and Sunset headers on every response, with a link to the docs of the current version.
+func MountVersions(deps *Dependencies) error {
+	for version := range deps.Cfg.APIDeprecatedVersions {
+		if !slices.Contains(Versions, version) {
+			return fmt.Errorf("API_DEPRECATED_VERSIONS: unknown version %q", version)
+		}
+	}
+	for version := range deps.Cfg.APISunsetVersions {
+		if _, ok := deps.Cfg.APIDeprecatedVersions[version]; !ok {
+			return fmt.Errorf("API_SUNSET_VERSIONS: version %q is not deprecated", version)
+		}
+	}
+
+	current := Versions[len(Versions)-1]
+	for _, version := range Versions {
+		var handlers []fiber.Handler
+
+		if value, ok := deps.Cfg.APIDeprecatedVersions[version]; ok {
+			deprecated, err := time.Parse(versionDateLayout, value)
+			if err != nil {
+				return fmt.Errorf("API_DEPRECATED_VERSIONS: %s: %w", version, err)
+			}
+
+			var sunset time.Time
+			if value, ok := deps.Cfg.APISunsetVersions[version]; ok {
+				if sunset, err = time.Parse(versionDateLayout, value); err != nil {
+					return fmt.Errorf("API_SUNSET_VERSIONS: %s: %w", version, err)
+				}
+			}
+
+			handlers = append(handlers, middleware.Deprecation(middleware.DeprecationConfig{
+				Version:    version,
+				Deprecated: deprecated,
+				Sunset:     sunset,
+				Link:       SwaggerPath(current),
+			}))
+		}
+
+		deps.versions[version] = deps.App.Group("/"+version, handlers...)
+	}
+
+	return nil
+}
+
+// Version returns the route group of an API version mounted by MountVersions
+func (d *Dependencies) Version(version string) fiber.Router {
+	group, ok := d.versions[version]
+	if !ok {
+		panic(fmt.Sprintf("API version %q is not mounted", version))
+	}
+	return group
+}
+
+// SwaggerPath returns the swagger UI of version
+func SwaggerPath(version string) string {
+	return "/swagger/" + version + "/index.html"
+}
@@ -0,0 +1,74 @@
+package router_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	restserver "github.com/alxhtp/monogo/internal/server/rest"
+	"github.com/alxhtp/monogo/internal/server/rest/middleware"
+	"github.com/alxhtp/monogo/internal/server/rest/resttest"
+	"github.com/alxhtp/monogo/internal/server/rest/router"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestDeprecatedVersionsAnnounceTheirSunset(t *testing.T) {
+	cfg := resttest.Config(t)
+	cfg.APIDeprecatedVersions = map[string]string{router.V1: "2026-10-01"}
+	cfg.APISunsetVersions = map[string]string{router.V1: "2027-04-01"}
+	s := resttest.NewMemoryServer(t, cfg)
+
+	v1 := s.Do(resttest.NewRequest(t, http.MethodGet, "/v1/users", nil))
+	if v1.Status != http.StatusOK {
+		t.Fatalf("v1: %d %s", v1.Status, v1.Body)
+	}
+	deprecated := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC).Unix()
+	if got := v1.Header.Get(middleware.HeaderDeprecation); got != "@"+strconv.FormatInt(deprecated, 10) {
+		t.Errorf("Deprecation = %q", got)
+	}
+	if got := v1.Header.Get(middleware.HeaderSunset); got != "Thu, 01 Apr 2027 00:00:00 GMT" {
+		t.Errorf("Sunset = %q", got)
+	}
+	if got := v1.Header.Get(fiber.HeaderLink); got != `</swagger/v2/index.html>; rel="successor-version"` {
+		t.Errorf("Link = %q", got)
+	}
+
+	v2 := s.Do(resttest.NewRequest(t, http.MethodGet, "/v2/users", nil))
+	if v2.Status != http.StatusOK {
+		t.Fatalf("v2: %d %s", v2.Status, v2.Body)
+	}
+	for _, header := range []string{middleware.HeaderDeprecation, middleware.HeaderSunset, fiber.HeaderLink} {
+		if got := v2.Header.Get(header); got != "" {
+			t.Errorf("v2 %s = %q, want none on the current version", header, got)
+		}
+	}
+}
+
+func TestMountVersionsRejectsInvalidVersions(t *testing.T) {
+	tests := []struct {
+		name       string
+		deprecated map[string]string
+		sunset     map[string]string
+	}{
+		{name: "unknown version", deprecated: map[string]string{"v0": "2026-10-01"}},
+		{name: "sunset of a version not deprecated", sunset: map[string]string{router.V1: "2027-04-01"}},
+		{name: "invalid date", deprecated: map[string]string{router.V1: "October 2026"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := resttest.Config(t)
+			cfg.APIDeprecatedVersions = tt.deprecated
+			cfg.APISunsetVersions = tt.sunset
+
+			server, err := restserver.NewRestServer(context.Background(), cfg, restserver.WithDB(nil))
+			if err == nil {
+				_, err = server.App()
+			}
+			if err == nil {
+				t.Fatal("the versions were mounted")
+			}
+		})
+	}
+}
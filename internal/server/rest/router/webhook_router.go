@@ -0,0 +1,33 @@
+package router
+
+import (
+	"github.com/alxhtp/monogo/internal/handler"
+	webhookrepository "github.com/alxhtp/monogo/internal/repository/webhook/implementation"
+	webhookserializer "github.com/alxhtp/monogo/internal/serializer/webhook/implementation"
+	webhookusecase "github.com/alxhtp/monogo/internal/usecase/webhook/implementation"
+)
+
+// WebhookRouter serves webhook subscriptions to admins, a webhook receives every event of its tenant,
+// the routes stay unregistered until admin credentials are configured
+func WebhookRouter(deps *Dependencies) {
+	adminGroup, ok := deps.Admin()
+	if !ok {
+		return
+	}
+
+	webhookRepository := webhookrepository.NewWebhookRepository(deps.DB)
+	deliveryRepository := webhookrepository.NewWebhookDeliveryRepository(deps.DB)
+	webhookSerializer := webhookserializer.NewWebhookSerializer()
+	webhookUsecase := webhookusecase.NewWebhookUsecase(webhookRepository, deliveryRepository, webhookSerializer, deps.Cfg.WebhookConfig)
+	webhookHandler := handler.NewWebhookHandler(webhookUsecase)
+
+	webhookGroup := adminGroup.Group("/webhooks", deps.Tenant)
+
+	webhookGroup.Post("/", deps.Idempotency, webhookHandler.CreateWebhook)
+	webhookGroup.Get("/:id", webhookHandler.GetWebhookByID)
+	webhookGroup.Get("/", webhookHandler.GetWebhooksByFilter)
+	webhookGroup.Put("/:id", webhookHandler.UpdateWebhook)
+	webhookGroup.Delete("/:id", webhookHandler.DeleteWebhook)
+	webhookGroup.Get("/:id/deliveries", webhookHandler.GetWebhookDeliveries)
+	webhookGroup.Post("/:id/deliveries/:delivery_id/redeliver", deps.Idempotency, webhookHandler.RedeliverWebhook)
+}
@@ -2,19 +2,41 @@ package restserver
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"log"
-	"os"
-	"os/signal"
+	"log/slog"
+	"net"
+	"strconv"
 	"strings"
-	"syscall"
 	"time"
 
 	"github.com/alxhtp/monogo/config"
+	docsv1 "github.com/alxhtp/monogo/docs/v1"
+	docsv2 "github.com/alxhtp/monogo/docs/v2"
+	publisher "github.com/alxhtp/monogo/internal/publisher/implementation"
+	idempotencyrepository "github.com/alxhtp/monogo/internal/repository/idempotency/implementation"
+	outboxrepository "github.com/alxhtp/monogo/internal/repository/outbox/implementation"
+	tenantrepository "github.com/alxhtp/monogo/internal/repository/tenant/implementation"
+	transactionrepository "github.com/alxhtp/monogo/internal/repository/transaction/implementation"
+	webhookrepository "github.com/alxhtp/monogo/internal/repository/webhook/implementation"
+	"github.com/alxhtp/monogo/internal/server/rest/middleware"
 	"github.com/alxhtp/monogo/internal/server/rest/router"
+	"github.com/alxhtp/monogo/internal/subscriber"
+	subscriberimplementation "github.com/alxhtp/monogo/internal/subscriber/implementation"
+	tenantusecase "github.com/alxhtp/monogo/internal/usecase/tenant"
+	userusecase "github.com/alxhtp/monogo/internal/usecase/user"
+	"github.com/alxhtp/monogo/internal/worker"
+	"github.com/alxhtp/monogo/migration"
+	"github.com/alxhtp/monogo/pkg/health"
 	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
+	tlshelper "github.com/alxhtp/monogo/pkg/helper/tls"
+	"github.com/alxhtp/monogo/pkg/lifecycle"
+	"github.com/alxhtp/monogo/pkg/metrics"
+	"github.com/alxhtp/monogo/pkg/tracing"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
 	"github.com/gofiber/fiber/v2/middleware/basicauth"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
@@ -23,16 +45,70 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	schemeHTTP  = "http"
+	schemeHTTPS = "https"
+)
+
 type RestServer struct {
-	app *fiber.App
-	cfg *config.Config
-	db  *gorm.DB
-	ctx context.Context
+	app        *fiber.App
+	metricsApp *fiber.App
+	cfg        *config.Config
+	db         *gorm.DB
+	subscriber subscriber.Subscriber
+	health     *health.Registry
+	inFlight   *middleware.InFlight
+	deps       *router.Dependencies
+
+	tlsConfig   *tls.Config
+	tlsReloader *tlshelper.Reloader
+
+	// isSetUp is set once the middleware and routes are registered, see setup
+	isSetUp bool
+}
+
+// Option customizes the components NewRestServer builds from the config, e.g. to serve tests
+type Option func(*options)
+
+type options struct {
+	db           *gorm.DB
+	withDB       bool
+	subscriber   subscriber.Subscriber
+	repositories router.Repositories
+}
+
+// WithDB serves over db instead of connecting to the database of the config, db is not migrated.
+// A nil db serves without a database, the user routes are then served over the repositories of WithRepositories
+// and the routes of the other resources fail.
+func WithDB(db *gorm.DB) Option {
+	return func(o *options) {
+		o.db = db
+		o.withDB = true
+	}
 }
 
-func NewRestServer(ctx context.Context, cfg *config.Config) *RestServer {
+// WithRepositories builds the user routes over repositories, see router.Repositories
+func WithRepositories(repositories router.Repositories) Option {
+	return func(o *options) {
+		o.repositories = repositories
+	}
+}
+
+// WithSubscriber streams the events of eventSubscriber instead of the notifications of the database
+func WithSubscriber(eventSubscriber subscriber.Subscriber) Option {
+	return func(o *options) {
+		o.subscriber = eventSubscriber
+	}
+}
+
+func NewRestServer(ctx context.Context, cfg *config.Config, opts ...Option) (*RestServer, error) {
 	if cfg == nil {
-		log.Fatalf("config is nil")
+		return nil, errors.New("config is nil")
+	}
+
+	var o options
+	for _, opt := range opts {
+		opt(&o)
 	}
 
 	app := fiber.New(fiber.Config{
@@ -43,26 +119,211 @@ func NewRestServer(ctx context.Context, cfg *config.Config) *RestServer {
 		AppName:      cfg.AppName,
 	})
 
-	db, err := databasehelper.NewGormDB(ctx, &cfg.DatabaseConfig)
+	db := o.db
+	if !o.withDB {
+		var err error
+		if db, err = databasehelper.NewGormDBWithRetry(ctx, &cfg.DatabaseConfig); err != nil {
+			return nil, fmt.Errorf("failed to connect to database: %w", err)
+		}
+		if err := migrateOnStartup(ctx, db, cfg); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.TenantEnabled {
+		if db == nil {
+			return nil, errors.New("multi-tenancy requires a database")
+		}
+		if err := databasehelper.EnableTenancy(ctx, db, &cfg.DatabaseConfig, &cfg.TenantConfig); err != nil {
+			return nil, fmt.Errorf("failed to enable multi-tenancy: %w", err)
+		}
+	}
+
+	eventSubscriber := o.subscriber
+	if eventSubscriber == nil {
+		eventSubscriber = subscriberimplementation.NewPgNotifySubscriber(
+			&cfg.DatabaseConfig,
+			cfg.OutboxNotifyChannel,
+			cfg.UserEventsBufferSize,
+			outboxrepository.NewOutboxRepository(db),
+		)
+	}
+
+	checkTimeout, err := time.ParseDuration(cfg.HealthCheckTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid HEALTH_CHECK_TIMEOUT: %w", err)
+	}
+	healthRegistry := health.NewRegistry(checkTimeout)
+	if db != nil {
+		healthRegistry.Register(
+			health.DatabaseChecker(db),
+			health.MigrationChecker(db, cfg.HealthMigrationTable, cfg.HealthMigrationVersion),
+		)
+		if schemaTenancy(cfg) {
+			healthRegistry.Register(tenantMigrationChecker(db))
+		}
+	}
+	if cfg.HealthDiskMinFreeMB > 0 {
+		healthRegistry.Register(health.DiskSpaceChecker(cfg.HealthDiskPath, cfg.HealthDiskMinFreeMB<<20))
+	}
+
+	deps, err := router.NewDependencies(app, db, cfg, eventSubscriber, o.repositories)
 	if err != nil {
-		log.Fatalf("failed to connect to database: %v", err)
+		return nil, err
 	}
 
-	return &RestServer{
-		app: app,
-		cfg: cfg,
-		db:  db,
-		ctx: ctx,
+	server := &RestServer{
+		app:        app,
+		cfg:        cfg,
+		db:         db,
+		subscriber: eventSubscriber,
+		health:     healthRegistry,
+		inFlight:   middleware.NewInFlight(),
+		deps:       deps,
 	}
+	if err := server.setupTLS(); err != nil {
+		return nil, err
+	}
+
+	return server, nil
 }
 
-func (s *RestServer) Start() error {
+// setupTLS loads the certificates when APP_SCHEME is https
+func (s *RestServer) setupTLS() error {
+	switch s.cfg.Scheme {
+	case schemeHTTP:
+		return nil
+	case schemeHTTPS:
+	default:
+		return fmt.Errorf("unsupported APP_SCHEME %q", s.cfg.Scheme)
+	}
+
+	if s.cfg.TLSCertFile == "" || s.cfg.TLSKeyFile == "" {
+		return errors.New("TLS_CERT_FILE and TLS_KEY_FILE are required when APP_SCHEME is https")
+	}
+
+	clientAuth := tls.NoClientCert
+	if s.cfg.TLSClientCAFile != "" {
+		var err error
+		if clientAuth, err = tlshelper.ParseClientAuth(s.cfg.TLSClientAuth); err != nil {
+			return fmt.Errorf("invalid TLS_CLIENT_AUTH: %w", err)
+		}
+	}
+	minVersion, err := tlshelper.ParseVersion(s.cfg.TLSMinVersion)
+	if err != nil {
+		return fmt.Errorf("invalid TLS_MIN_VERSION: %w", err)
+	}
+
+	reloader, err := tlshelper.NewReloader(s.cfg.TLSCertFile, s.cfg.TLSKeyFile, s.cfg.TLSClientCAFile)
+	if err != nil {
+		return fmt.Errorf("load certificates: %w", err)
+	}
+
+	s.tlsReloader = reloader
+	s.tlsConfig = reloader.ServerConfig(clientAuth, minVersion)
+	return nil
+}
+
+// Register sets up the middleware and routes and appends the server components to manager.
+// They start in order: database, user import jobs, workers, metrics server, HTTP server, HTTPS redirect, event subscriber, readiness,
+// and stop in reverse: readiness turns down first, the event subscriber closes the event streams,
+// the HTTP server drains the in-flight requests, then workers and import jobs stop before the database pool is closed.
+func (s *RestServer) Register(manager *lifecycle.Manager) error {
+	if err := s.setup(); err != nil {
+		return err
+	}
+
+	manager.Append(lifecycle.Hook{
+		Name: "database",
+		OnStop: func(ctx context.Context) error {
+			return databasehelper.CloseGormDB(s.db)
+		},
+	})
+	manager.Append(lifecycle.Hook{
+		Name:    "user-import-jobs",
+		OnStop:  s.deps.UserUsecase.StopImportJobs,
+		Pending: s.deps.UserUsecase.RunningImportJobs,
+	})
+
+	workers, err := s.workerHooks()
+	if err != nil {
+		return err
+	}
+	manager.Append(workers...)
+
+	if s.metricsApp != nil {
+		manager.Append(s.listenHook(manager, "metrics-server", s.metricsApp, s.cfg.MetricsPort, nil, nil))
+	}
+	if s.tlsReloader != nil {
+		reloadInterval, err := time.ParseDuration(s.cfg.TLSReloadInterval)
+		if err != nil || reloadInterval <= 0 {
+			return fmt.Errorf("invalid TLS_RELOAD_INTERVAL %q", s.cfg.TLSReloadInterval)
+		}
+		manager.Append(lifecycle.Worker("tls-reloader", func(ctx context.Context) {
+			s.tlsReloader.Run(ctx, reloadInterval)
+		}))
+	}
+	manager.Append(s.listenHook(manager, "http-server", s.app, s.cfg.AppPort, s.tlsConfig, s.inFlight.Requests))
+	if s.tlsConfig != nil && s.cfg.TLSRedirectPort != 0 {
+		manager.Append(s.listenHook(manager, "https-redirect", s.redirectApp(), s.cfg.TLSRedirectPort, nil, nil))
+	}
+
+	manager.Append(
+		lifecycle.Worker("event-subscriber", s.subscriber.Run),
+		lifecycle.Hook{
+			Name: "readiness",
+			OnStop: func(ctx context.Context) error {
+				s.health.SetShuttingDown()
+
+				// keep serving until load balancers saw readiness turn down, then the listeners close
+				delay := time.NewTimer(time.Duration(s.cfg.ShutdownDelay) * time.Second)
+				defer delay.Stop()
+				select {
+				case <-delay.C:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			},
+		},
+	)
+
+	return nil
+}
+
+// App sets up the middleware and routes and returns the app serving them, e.g. to exercise the routes with
+// fiber.App.Test without a listener
+func (s *RestServer) App() (*fiber.App, error) {
+	if err := s.setup(); err != nil {
+		return nil, err
+	}
+	return s.app, nil
+}
+
+// setup registers the middleware and routes, once
+func (s *RestServer) setup() error {
+	if s.isSetUp {
+		return nil
+	}
+	s.isSetUp = true
+
 	// Add global middleware
+	s.app.Use(s.inFlight.Handler())
+	if tracing.Enabled(&s.cfg.TracingConfig) {
+		s.app.Use(middleware.Tracing())
+	}
+	if s.cfg.MetricsEnabled {
+		s.app.Use(middleware.Metrics())
+	}
 	s.app.Use(recover.New(recover.Config{
 		EnableStackTrace: true,
 	}))
+	s.app.Use(middleware.RequestContext())
+	if s.tlsConfig != nil {
+		s.app.Use(middleware.ClientIdentity())
+	}
+	s.app.Use(middleware.Authentication(s.cfg.JWTConfig))
 	s.app.Use(logger.New(logger.Config{
-		Format:     "${time} ${status} ${method} ${path}\n",
+		Format:     "${time} ${status} ${method} ${path} ${respHeader:X-Request-ID}\n",
 		TimeFormat: "2006-01-02 15:04:05",
 		TimeZone:   "Asia/Jakarta",
 	}))
@@ -72,56 +333,271 @@ func (s *RestServer) Start() error {
 		AllowHeaders: strings.Join(s.cfg.AllowedHeaders, ","),
 	}))
 
-	// Add routes
-	s.app.Get("/health", func(c *fiber.Ctx) error {
-		return c.SendString("OK")
-	})
+	// Health routes
+	s.RegisterHealth()
 
-	// Swagger routes
-	s.app.Get("/swagger/*", basicauth.New(basicauth.Config{
+	// Swagger routes, one document per API version, /swagger/ keeps serving v1 for existing links
+	docsv1.SwaggerInfov1.Schemes = []string{s.cfg.Scheme}
+	docsv2.SwaggerInfov2.Schemes = []string{s.cfg.Scheme}
+	swaggerAuth := basicauth.New(basicauth.Config{
 		Users: map[string]string{
 			s.cfg.SwaggerUsername: s.cfg.SwaggerPassword,
 		},
-	}), swagger.New())
+	})
+	for _, version := range router.Versions {
+		s.app.Get("/swagger/"+version+"/*", swaggerAuth, swagger.New(swagger.Config{InstanceName: version}))
+	}
+	s.app.Get("/swagger/*", swaggerAuth, swagger.New(swagger.Config{InstanceName: router.V1}))
+
+	// Metrics routes
+	s.RegisterMetrics()
 
 	// Register routes
-	s.RegisterRoutes()
-
-	// Start server
-	errCh := make(chan error, 1)
-	go func() {
-		addr := fmt.Sprintf(":%d", s.cfg.AppPort)
-		log.Printf("HTTP server listening on %s", addr)
-		if err := s.app.Listen(addr); err != nil {
-			errCh <- err
-		}
-		close(errCh)
-	}()
-
-	// Graceful shutdown on signal or context cancellation
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-	select {
-	case <-s.ctx.Done():
-		return s.Shutdown()
-	case sig := <-sigCh:
-		log.Printf("received signal: %s, shutting down", sig)
-		return s.Shutdown()
-	case err := <-errCh:
-		return errors.New("failed to start server: " + err.Error())
-	}
-}
-
-func (s *RestServer) Shutdown() error {
-	if s.app == nil {
+	return s.RegisterRoutes()
+}
+
+// listenHook serves app on port, over TLS when tlsConfig is set.
+// The listener is opened on start so address errors abort the startup, a failure while serving stops manager.
+func (s *RestServer) listenHook(manager *lifecycle.Manager, name string, app *fiber.App, port int, tlsConfig *tls.Config, pending func() []string) lifecycle.Hook {
+	return lifecycle.Hook{
+		Name: name,
+		OnStart: func(ctx context.Context) error {
+			addr := fmt.Sprintf(":%d", port)
+			ln, err := net.Listen("tcp", addr)
+			if err != nil {
+				return err
+			}
+			if tlsConfig != nil {
+				ln = tls.NewListener(ln, tlsConfig)
+			}
+
+			log.Printf("%s listening on %s", name, addr)
+			go func() {
+				if err := app.Listener(ln); err != nil {
+					log.Printf("%s stopped: %v", name, err)
+					manager.Fail(name, err)
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			return app.ShutdownWithContext(ctx)
+		},
+		Pending: pending,
+	}
+}
+
+// redirectApp answers every plain HTTP request with a permanent redirect to the HTTPS port
+func (s *RestServer) redirectApp() *fiber.App {
+	app := fiber.New(fiber.Config{
+		AppName:               s.cfg.AppName + "-redirect",
+		DisableStartupMessage: true,
+	})
+	app.Use(func(c *fiber.Ctx) error {
+		host := c.Hostname()
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if s.cfg.AppPort != 443 {
+			host = net.JoinHostPort(host, strconv.Itoa(s.cfg.AppPort))
+		}
+		return c.Redirect(schemeHTTPS+"://"+host+c.OriginalURL(), fiber.StatusPermanentRedirect)
+	})
+	return app
+}
+
+// UserUsecase returns the user usecase serving the REST routes, so other transports share its state
+func (s *RestServer) UserUsecase() userusecase.UserUsecase {
+	return s.deps.UserUsecase
+}
+
+// migrateOnStartup applies the pending migrations with MIGRATION_AUTO, otherwise refuses to start while some are pending.
+// In the schema-per-tenant mode the schemas of the tenants are migrated or checked too.
+func migrateOnStartup(ctx context.Context, db *gorm.DB, cfg *config.Config) error {
+	if !cfg.MigrationAuto && !cfg.MigrationCheck {
 		return nil
 	}
-	shutdownCtx, cancel := context.WithTimeout(s.ctx, 10*time.Second)
-	defer cancel()
-	return s.app.ShutdownWithContext(shutdownCtx)
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	if cfg.MigrationAuto {
+		applied, err := migration.Up(ctx, sqlDB, 0)
+		if err != nil {
+			return fmt.Errorf("failed to migrate: %w", err)
+		}
+		slog.InfoContext(ctx, "database migrated", "applied", applied)
+	} else if err := migration.Check(ctx, sqlDB); err != nil {
+		return fmt.Errorf("%w, run `monogo migrate up` or set MIGRATION_AUTO=true", err)
+	}
+	if !schemaTenancy(cfg) {
+		return nil
+	}
+
+	tenants := tenantrepository.NewTenantRepository(db)
+	all, err := tenants.GetAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list tenants: %w", err)
+	}
+	for _, tenant := range all {
+		if !cfg.MigrationAuto {
+			if err := migration.CheckSchema(ctx, sqlDB, tenant.Schema); err != nil {
+				return fmt.Errorf("tenant %s: %w, run `monogo migrate up` or set MIGRATION_AUTO=true", tenant.ID, err)
+			}
+			continue
+		}
+		// provisioning an existing tenant applies its pending migrations
+		if _, _, err := tenants.Provision(ctx, tenant.ID); err != nil {
+			return fmt.Errorf("failed to migrate tenant %s: %w", tenant.ID, err)
+		}
+	}
+	if cfg.MigrationAuto {
+		slog.InfoContext(ctx, "tenant schemas migrated", "tenants", len(all))
+	}
+	return nil
 }
 
-func (s *RestServer) RegisterRoutes() {
-	dependencies := router.NewDependencies(s.app, s.db, s.cfg)
-	router.UserRouter(dependencies)
+// schemaTenancy reports whether the tenants of cfg have a schema of their own, migrated apart from the shared one
+func schemaTenancy(cfg *config.Config) bool {
+	return cfg.TenantEnabled && (cfg.TenantMode == databasehelper.TenantModeSchema || cfg.TenantMode == "")
+}
+
+// tenantMigrationChecker fails while the schema of a tenant misses embedded migrations,
+// an instance is not ready to serve tenants whose tables it does not know yet
+func tenantMigrationChecker(db *gorm.DB) health.Checker {
+	tenants := tenantrepository.NewTenantRepository(db)
+	return health.CheckFunc("tenant_migrations", func(ctx context.Context) (any, error) {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+		all, err := tenants.GetAll(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list tenants: %w", err)
+		}
+
+		behind := []string{}
+		for _, tenant := range all {
+			err := migration.CheckSchema(ctx, sqlDB, tenant.Schema)
+			if errors.Is(err, migration.ErrSchemaBehind) {
+				behind = append(behind, tenant.ID)
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		details := map[string]any{"tenants": len(all), "behind": behind}
+		if len(behind) > 0 {
+			return details, fmt.Errorf("%d tenant schemas are behind", len(behind))
+		}
+		return details, nil
+	})
+}
+
+// TenantUsecase returns the tenant usecase, so other transports resolve tenants the same way
+func (s *RestServer) TenantUsecase() tenantusecase.TenantUsecase {
+	return s.deps.TenantUsecase
+}
+
+// Health returns the readiness checker registry, custom checks can be registered before the server starts
+func (s *RestServer) Health() *health.Registry {
+	return s.health
+}
+
+// RegisterHealth serves the liveness and readiness probes, /health is kept as an alias of readiness
+func (s *RestServer) RegisterHealth() {
+	live := func(c *fiber.Ctx) error {
+		return healthResponse(c, s.health.Live())
+	}
+	ready := func(c *fiber.Ctx) error {
+		return healthResponse(c, s.health.Ready(c.UserContext()))
+	}
+
+	s.app.Get("/health", ready)
+	s.app.Get("/health/live", live)
+	s.app.Get("/health/ready", ready)
+}
+
+func healthResponse(c *fiber.Ctx, report health.Report) error {
+	status := fiber.StatusOK
+	if !report.Up() {
+		status = fiber.StatusServiceUnavailable
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(status).JSON(report)
+}
+
+// RegisterMetrics serves prometheus metrics on the API port, or on METRICS_PORT when set
+func (s *RestServer) RegisterMetrics() {
+	if !s.cfg.MetricsEnabled {
+		return
+	}
+
+	handler := adaptor.HTTPHandler(metrics.Handler())
+	if s.cfg.MetricsPort == 0 || s.cfg.MetricsPort == s.cfg.AppPort {
+		s.app.Get(s.cfg.MetricsPath, handler)
+		return
+	}
+
+	s.metricsApp = fiber.New(fiber.Config{
+		AppName:               s.cfg.AppName + "-metrics",
+		DisableStartupMessage: true,
+	})
+	s.metricsApp.Get(s.cfg.MetricsPath, handler)
+}
+
+func (s *RestServer) workerHooks() ([]lifecycle.Hook, error) {
+	// with multi-tenancy workers go through every tenant, a nil repository runs them once without a tenant
+	tenantRepository := s.deps.TenantRepository
+
+	eventPublisher, err := publisher.NewPublisherFromConfig(&s.cfg.OutboxConfig, s.db)
+	if err != nil {
+		return nil, fmt.Errorf("outbox publisher: %w", err)
+	}
+
+	relay := worker.NewOutboxRelay(
+		outboxrepository.NewOutboxRepository(s.db),
+		transactionrepository.NewTransactionRepository(s.db),
+		eventPublisher,
+		tenantRepository,
+		&s.cfg.OutboxConfig,
+	)
+
+	dispatcher := worker.NewWebhookDispatcher(
+		webhookrepository.NewWebhookRepository(s.db),
+		webhookrepository.NewWebhookDeliveryRepository(s.db),
+		transactionrepository.NewTransactionRepository(s.db),
+		tenantRepository,
+		&s.cfg.WebhookConfig,
+	)
+
+	hooks := []lifecycle.Hook{
+		lifecycle.Worker("outbox-relay", relay.Run),
+		lifecycle.Worker("webhook-dispatcher", dispatcher.Run),
+	}
+
+	if s.cfg.IdempotencyEnabled {
+		cleaner := worker.NewIdempotencyCleaner(idempotencyrepository.NewIdempotencyRepository(s.db), tenantRepository, &s.cfg.IdempotencyConfig)
+		hooks = append(hooks, lifecycle.Worker("idempotency-cleaner", cleaner.Run))
+	}
+
+	return hooks, nil
+}
+
+func (s *RestServer) RegisterRoutes() error {
+	if err := router.MountVersions(s.deps); err != nil {
+		return err
+	}
+
+	router.UserRouter(s.deps)
+	router.UserRouterV2(s.deps)
+	router.AuditRouter(s.deps)
+	router.TenantRouter(s.deps)
+	router.WebhookRouter(s.deps)
+	// routers of generated resources are registered above
+	return router.GraphqlRouter(s.deps)
 }
@@ -0,0 +1,150 @@
+package subscriberimplementation
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+
+	"github.com/alxhtp/monogo/config"
+	outboxrepository "github.com/alxhtp/monogo/internal/repository/outbox"
+	"github.com/alxhtp/monogo/internal/subscriber"
+	"github.com/alxhtp/monogo/pkg/event"
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
+)
+
+const (
+	SubscriberPgNotify = "pgnotify"
+
+	defaultSubscriptionBuffer = 256
+)
+
+type pgNotifySubscriber struct {
+	dbCfg            *config.DatabaseConfig
+	channel          string
+	bufferSize       int
+	outboxRepository outboxrepository.OutboxRepository
+	logger           *slog.Logger
+
+	mu            sync.Mutex
+	subscriptions map[uint64]chan event.Message
+	nextID        uint64
+	connected     bool
+	stopped       bool
+}
+
+// NewPgNotifySubscriber listens to the NOTIFY channel written by the pgnotify publisher,
+// so every instance receives the messages relayed by any instance
+func NewPgNotifySubscriber(
+	dbCfg *config.DatabaseConfig,
+	channel string,
+	bufferSize int,
+	outboxRepository outboxrepository.OutboxRepository,
+) subscriber.Subscriber {
+	if bufferSize <= 0 {
+		bufferSize = defaultSubscriptionBuffer
+	}
+
+	return &pgNotifySubscriber{
+		dbCfg:            dbCfg,
+		channel:          channel,
+		bufferSize:       bufferSize,
+		outboxRepository: outboxRepository,
+		logger:           slog.Default().With("subscriber", SubscriberPgNotify, "channel", channel),
+		subscriptions:    make(map[uint64]chan event.Message),
+	}
+}
+
+func (s *pgNotifySubscriber) Name() string {
+	return SubscriberPgNotify
+}
+
+func (s *pgNotifySubscriber) Subscribe() (<-chan event.Message, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	messages := make(chan event.Message, s.bufferSize)
+	if s.stopped {
+		close(messages)
+		return messages, func() {}
+	}
+
+	id := s.nextID
+	s.nextID++
+	s.subscriptions[id] = messages
+
+	return messages, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.closeSubscription(id)
+	}
+}
+
+func (s *pgNotifySubscriber) Run(ctx context.Context) {
+	s.logger.InfoContext(ctx, "subscriber started")
+	defer s.logger.InfoContext(ctx, "subscriber stopped")
+
+	databasehelper.Listen(ctx, s.dbCfg, s.channel, s.onConnected, func(payload string) {
+		s.handle(ctx, payload)
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopped = true
+	for id := range s.subscriptions {
+		s.closeSubscription(id)
+	}
+}
+
+// onConnected drops every subscription after a reconnection,
+// notifications sent while disconnected are lost and consumers must resume from the outbox
+func (s *pgNotifySubscriber) onConnected() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.connected {
+		for id := range s.subscriptions {
+			s.closeSubscription(id)
+		}
+	}
+	s.connected = true
+}
+
+func (s *pgNotifySubscriber) handle(ctx context.Context, payload string) {
+	var msg event.Message
+	if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+		s.logger.WarnContext(ctx, "invalid notification payload", "error", err.Error())
+		return
+	}
+
+	// the pgnotify publisher drops oversized payloads, load them from the outbox of the tenant
+	if len(msg.Payload) == 0 || string(msg.Payload) == "null" {
+		stored, err := s.outboxRepository.GetByID(contexthelper.WithTenant(ctx, msg.Tenant), msg.ID)
+		if err != nil {
+			s.logger.WarnContext(ctx, "load message payload failed", "id", msg.ID, "error", err.Error())
+			return
+		}
+		msg.Payload = stored.Payload.Item
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, messages := range s.subscriptions {
+		select {
+		case messages <- msg:
+		default:
+			s.logger.WarnContext(ctx, "subscription is full, closing it", "subscription", id)
+			s.closeSubscription(id)
+		}
+	}
+}
+
+// closeSubscription must be called with mu held
+func (s *pgNotifySubscriber) closeSubscription(id uint64) {
+	if messages, ok := s.subscriptions[id]; ok {
+		close(messages)
+		delete(s.subscriptions, id)
+	}
+}
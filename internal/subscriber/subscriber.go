@@ -0,0 +1,18 @@
+package subscriber
+
+import (
+	"context"
+
+	"github.com/alxhtp/monogo/pkg/event"
+)
+
+// Subscriber receives published outbox messages and fans them out to in-process consumers.
+// Delivery is best effort, a subscription channel is closed when its consumer falls behind
+// or messages may have been missed, consumers then resume from the outbox by message id.
+type Subscriber interface {
+	Name() string
+	// Subscribe returns a channel of messages, it is closed by unsubscribe or when the subscriber stops
+	Subscribe() (messages <-chan event.Message, unsubscribe func())
+	// Run receives messages until ctx is cancelled
+	Run(ctx context.Context)
+}
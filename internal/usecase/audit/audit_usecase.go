@@ -0,0 +1,12 @@
+package auditusecase
+
+import (
+	"context"
+
+	"github.com/alxhtp/monogo/pkg/dto"
+)
+
+type AuditUsecase interface {
+	GetAuditLogsByFilter(ctx context.Context, filter *dto.ReqGetAuditLog) dto.ResAuditLogList
+	GetEntityHistory(ctx context.Context, entityType string, entityID string, filter *dto.ReqGetAuditLog) dto.ResAuditLogList
+}
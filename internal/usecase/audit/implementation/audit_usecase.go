@@ -0,0 +1,88 @@
+package auditusecaseimplementation
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	entitybase "github.com/alxhtp/monogo/internal/entity/base"
+	auditrepository "github.com/alxhtp/monogo/internal/repository/audit"
+	auditserializer "github.com/alxhtp/monogo/internal/serializer/audit"
+	auditusecase "github.com/alxhtp/monogo/internal/usecase/audit"
+	"github.com/alxhtp/monogo/pkg/dto"
+	errorhelper "github.com/alxhtp/monogo/pkg/helper/error"
+	"github.com/alxhtp/monogo/pkg/message"
+	"github.com/alxhtp/monogo/pkg/tracing"
+)
+
+var (
+	auditEntityName = "audit log"
+)
+
+type auditUsecase struct {
+	auditRepository auditrepository.AuditRepository
+	auditSerializer auditserializer.AuditSerializer
+	logger          *slog.Logger
+}
+
+func NewAuditUsecase(auditRepository auditrepository.AuditRepository, auditSerializer auditserializer.AuditSerializer) auditusecase.AuditUsecase {
+	return &auditUsecase{
+		auditRepository: auditRepository,
+		auditSerializer: auditSerializer,
+		logger:          slog.Default().With("usecase", auditEntityName),
+	}
+}
+
+func (u *auditUsecase) GetAuditLogsByFilter(ctx context.Context, filter *dto.ReqGetAuditLog) dto.ResAuditLogList {
+	ctx, span := tracing.Start(ctx, "auditUsecase.GetAuditLogsByFilter")
+	defer span.End()
+
+	u.logger.InfoContext(ctx, "getting audit logs by filter", "filter", filter)
+	select {
+	case <-ctx.Done():
+		u.logger.ErrorContext(ctx, "GetAuditLogsByFilter: context done", "filter", filter, "error", ctx.Err().Error())
+		return u.auditSerializer.EntityToResponseList(nil, entitybase.BasePaginationResult{}, http.StatusInternalServerError, message.GetResponseMessage(message.FailedList, auditEntityName), errorhelper.ComposeStacktrace(ctx.Err()))
+	default:
+	}
+
+	if filter == nil {
+		u.logger.ErrorContext(ctx, "GetAuditLogsByFilter: filter is nil")
+		return u.auditSerializer.EntityToResponseList(nil, entitybase.BasePaginationResult{}, http.StatusBadRequest, message.GetResponseMessage(message.FailedList, auditEntityName), errorhelper.ComposeStacktrace(errors.New("filter is nil")))
+	}
+
+	auditFilter, err := u.auditSerializer.FilterDTOToEntity(*filter)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "GetAuditLogsByFilter: error converting filter to entity", "filter", filter, "error", err.Error())
+		return u.auditSerializer.EntityToResponseList(nil, entitybase.BasePaginationResult{}, http.StatusBadRequest, err.Error(), errorhelper.ComposeStacktrace(err))
+	}
+
+	output, paginationResult, err := u.auditRepository.GetByFilter(ctx, &auditFilter)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "GetAuditLogsByFilter: error getting audit logs by filter", "filter", filter, "error", err.Error())
+		return u.auditSerializer.EntityToResponseList(nil, entitybase.BasePaginationResult{}, http.StatusInternalServerError, err.Error(), errorhelper.ComposeStacktrace(err))
+	}
+
+	u.logger.InfoContext(ctx, "audit logs got by filter", "count", len(output))
+	return u.auditSerializer.EntityToResponseList(output, paginationResult, http.StatusOK, message.GetResponseMessage(message.SuccessList, auditEntityName), nil)
+}
+
+func (u *auditUsecase) GetEntityHistory(ctx context.Context, entityType string, entityID string, filter *dto.ReqGetAuditLog) dto.ResAuditLogList {
+	ctx, span := tracing.Start(ctx, "auditUsecase.GetEntityHistory")
+	defer span.End()
+
+	u.logger.InfoContext(ctx, "getting entity history", "entity_type", entityType, "entity_id", entityID)
+
+	if entityType == "" || entityID == "" {
+		u.logger.ErrorContext(ctx, "GetEntityHistory: entity is empty")
+		return u.auditSerializer.EntityToResponseList(nil, entitybase.BasePaginationResult{}, http.StatusBadRequest, message.GetResponseMessage(message.FailedList, auditEntityName), errorhelper.ComposeStacktrace(errors.New("entity is empty")))
+	}
+
+	if filter == nil {
+		filter = &dto.ReqGetAuditLog{}
+	}
+	filter.EntityType = &entityType
+	filter.EntityIDs = &entityID
+
+	return u.GetAuditLogsByFilter(ctx, filter)
+}
@@ -0,0 +1,18 @@
+package genericusecase
+
+import (
+	"context"
+
+	dtobase "github.com/alxhtp/monogo/pkg/dto/base"
+	"github.com/google/uuid"
+)
+
+// CrudUsecase holds the CRUD operations of an entity, taking its request DTOs and answering with envelopes of its
+// response DTO Res. Entity usecases delegate to it and convert the envelopes to their named ones, e.g. dto.ResUserSingle(res).
+type CrudUsecase[CreateReq, UpdateReq, FilterReq, Res any] interface {
+	Create(ctx context.Context, req *CreateReq) dtobase.BaseResSingle[Res]
+	GetByID(ctx context.Context, id uuid.UUID) dtobase.BaseResSingle[Res]
+	GetByFilter(ctx context.Context, filter *FilterReq) dtobase.BaseResList[Res]
+	Update(ctx context.Context, id uuid.UUID, req *UpdateReq) dtobase.BaseResSingle[Res]
+	Delete(ctx context.Context, id uuid.UUID) dtobase.BaseRes
+}
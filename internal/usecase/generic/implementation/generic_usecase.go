@@ -0,0 +1,306 @@
+package genericusecaseimplementation
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	entitybase "github.com/alxhtp/monogo/internal/entity/base"
+	genericrepository "github.com/alxhtp/monogo/internal/repository/generic"
+	genericserializer "github.com/alxhtp/monogo/internal/serializer/generic"
+	genericusecase "github.com/alxhtp/monogo/internal/usecase/generic"
+	dtobase "github.com/alxhtp/monogo/pkg/dto/base"
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
+	errorhelper "github.com/alxhtp/monogo/pkg/helper/error"
+	"github.com/alxhtp/monogo/pkg/message"
+	"github.com/alxhtp/monogo/pkg/tracing"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type crudUsecase[E, F, CreateReq, UpdateReq, FilterReq, Res any] struct {
+	entityName string
+	spanPrefix string
+	repository genericrepository.Repository[E, F]
+	serializer genericserializer.Serializer[E, F, CreateReq, UpdateReq, FilterReq, Res]
+	logger     *slog.Logger
+	validator  *validator.Validate
+}
+
+// NewCrudUsecase returns the CRUD usecase of the entity named entityName, e.g. "user", its spans are named
+// <entityName>Usecase.<operation> with entityName in camel case, e.g. blogPostUsecase.Create.
+// Create and Update requests are validated by their validate tags.
+func NewCrudUsecase[E, F, CreateReq, UpdateReq, FilterReq, Res any](
+	entityName string,
+	repository genericrepository.Repository[E, F],
+	serializer genericserializer.Serializer[E, F, CreateReq, UpdateReq, FilterReq, Res],
+) genericusecase.CrudUsecase[CreateReq, UpdateReq, FilterReq, Res] {
+	return &crudUsecase[E, F, CreateReq, UpdateReq, FilterReq, Res]{
+		entityName: entityName,
+		spanPrefix: spanName(entityName) + "Usecase.",
+		repository: repository,
+		serializer: serializer,
+		logger:     slog.Default().With("usecase", entityName),
+		validator:  validator.New(validator.WithRequiredStructEnabled()),
+	}
+}
+
+func (u *crudUsecase[E, F, CreateReq, UpdateReq, FilterReq, Res]) Create(ctx context.Context, req *CreateReq) dtobase.BaseResSingle[Res] {
+	ctx, span := tracing.Start(ctx, u.spanPrefix+"Create")
+	defer span.End()
+
+	u.logger.InfoContext(ctx, "creating "+u.entityName)
+	select {
+	case <-ctx.Done():
+		u.logger.ErrorContext(ctx, "Create: context done", "error", ctx.Err().Error())
+		return u.single(nil, http.StatusInternalServerError, message.GetResponseMessage(message.FailedCreated, u.entityName), ctx.Err())
+	default:
+	}
+
+	if req == nil {
+		u.logger.ErrorContext(ctx, "Create: request is nil")
+		return u.single(nil, http.StatusBadRequest, message.GetResponseMessage(message.FailedCreated, u.entityName), errors.New("request is nil"))
+	}
+
+	if err := u.validator.Struct(req); err != nil {
+		u.logger.ErrorContext(ctx, "Create: request validation failed", "error", err.Error())
+		return u.single(nil, http.StatusBadRequest, err.Error(), err)
+	}
+
+	entity, err := u.serializer.CreateDTOToEntity(*req)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "Create: error converting request to entity", "error", err.Error())
+		return u.single(nil, http.StatusInternalServerError, err.Error(), err)
+	}
+
+	output, err := u.repository.Create(ctx, &entity)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "Create: error creating "+u.entityName, "error", err.Error())
+		code := statusFromError(err)
+		err = u.responseError(err)
+		return u.single(nil, code, err.Error(), err)
+	}
+
+	u.logger.InfoContext(ctx, u.entityName+" created", "id", entityID(output))
+	return u.single(output, http.StatusCreated, message.GetResponseMessage(message.SuccessCreated, u.entityName), nil)
+}
+
+func (u *crudUsecase[E, F, CreateReq, UpdateReq, FilterReq, Res]) GetByID(ctx context.Context, id uuid.UUID) dtobase.BaseResSingle[Res] {
+	ctx, span := tracing.Start(ctx, u.spanPrefix+"GetByID")
+	defer span.End()
+
+	u.logger.InfoContext(ctx, "getting "+u.entityName+" by id", "id", id)
+	select {
+	case <-ctx.Done():
+		u.logger.ErrorContext(ctx, "GetByID: context done", "id", id, "error", ctx.Err().Error())
+		return u.single(nil, http.StatusInternalServerError, message.GetResponseMessage(message.FailedGetByID, u.entityName), ctx.Err())
+	default:
+	}
+
+	if id == uuid.Nil {
+		u.logger.ErrorContext(ctx, "GetByID: id is nil")
+		return u.single(nil, http.StatusBadRequest, message.GetResponseMessage(message.FailedGetByID, u.entityName), errors.New("id is nil"))
+	}
+
+	output, err := u.repository.GetByID(ctx, id)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "GetByID: error getting "+u.entityName+" by id", "id", id, "error", err.Error())
+		code := statusFromError(err)
+		err = u.responseError(err)
+		return u.single(nil, code, err.Error(), err)
+	}
+
+	u.logger.InfoContext(ctx, u.entityName+" got by id", "id", id)
+	return u.single(output, http.StatusOK, message.GetResponseMessage(message.SuccessGetByID, u.entityName), nil)
+}
+
+func (u *crudUsecase[E, F, CreateReq, UpdateReq, FilterReq, Res]) GetByFilter(ctx context.Context, filter *FilterReq) dtobase.BaseResList[Res] {
+	ctx, span := tracing.Start(ctx, u.spanPrefix+"GetByFilter")
+	defer span.End()
+
+	u.logger.InfoContext(ctx, "getting "+u.entityName+"s by filter", "filter", filter)
+	select {
+	case <-ctx.Done():
+		u.logger.ErrorContext(ctx, "GetByFilter: context done", "filter", filter, "error", ctx.Err().Error())
+		return u.list(nil, entitybase.BasePaginationResult{}, http.StatusInternalServerError, message.GetResponseMessage(message.FailedList, u.entityName), ctx.Err())
+	default:
+	}
+
+	if filter == nil {
+		u.logger.ErrorContext(ctx, "GetByFilter: filter is nil")
+		return u.list(nil, entitybase.BasePaginationResult{}, http.StatusBadRequest, message.GetResponseMessage(message.FailedList, u.entityName), errors.New("filter is nil"))
+	}
+
+	entityFilter, err := u.serializer.FilterDTOToEntity(*filter)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "GetByFilter: error converting filter to entity", "filter", filter, "error", err.Error())
+		return u.list(nil, entitybase.BasePaginationResult{}, http.StatusBadRequest, err.Error(), err)
+	}
+
+	output, paginationResult, err := u.repository.GetByFilter(ctx, &entityFilter)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "GetByFilter: error getting "+u.entityName+"s by filter", "filter", filter, "error", err.Error())
+		code := statusFromError(err)
+		err = u.responseError(err)
+		return u.list(nil, entitybase.BasePaginationResult{}, code, err.Error(), err)
+	}
+
+	u.logger.InfoContext(ctx, u.entityName+"s got by filter", "count", len(output))
+	return u.list(output, paginationResult, http.StatusOK, message.GetResponseMessage(message.SuccessList, u.entityName), nil)
+}
+
+func (u *crudUsecase[E, F, CreateReq, UpdateReq, FilterReq, Res]) Update(ctx context.Context, id uuid.UUID, req *UpdateReq) dtobase.BaseResSingle[Res] {
+	ctx, span := tracing.Start(ctx, u.spanPrefix+"Update")
+	defer span.End()
+
+	u.logger.InfoContext(ctx, "updating "+u.entityName, "id", id)
+	select {
+	case <-ctx.Done():
+		u.logger.ErrorContext(ctx, "Update: context done", "id", id, "error", ctx.Err().Error())
+		return u.single(nil, http.StatusInternalServerError, message.GetResponseMessage(message.FailedUpdated, u.entityName), ctx.Err())
+	default:
+	}
+
+	if id == uuid.Nil {
+		u.logger.ErrorContext(ctx, "Update: id is nil")
+		return u.single(nil, http.StatusBadRequest, message.GetResponseMessage(message.FailedUpdated, u.entityName), errors.New("id is nil"))
+	}
+
+	if req == nil {
+		u.logger.ErrorContext(ctx, "Update: request is nil")
+		return u.single(nil, http.StatusBadRequest, message.GetResponseMessage(message.FailedUpdated, u.entityName), errors.New("request is nil"))
+	}
+
+	if err := u.validator.Struct(req); err != nil {
+		u.logger.ErrorContext(ctx, "Update: request validation failed", "id", id, "error", err.Error())
+		return u.single(nil, http.StatusBadRequest, err.Error(), err)
+	}
+
+	updateMap, err := u.serializer.UpdateDTOToMap(*req)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "Update: error converting update to map", "id", id, "error", err.Error())
+		return u.single(nil, http.StatusInternalServerError, err.Error(), err)
+	}
+
+	output, err := u.repository.Update(ctx, id, updateMap)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "Update: error updating "+u.entityName, "id", id, "error", err.Error())
+		code := statusFromError(err)
+		err = u.responseError(err)
+		return u.single(nil, code, err.Error(), err)
+	}
+
+	u.logger.InfoContext(ctx, u.entityName+" updated", "id", id)
+	return u.single(output, http.StatusOK, message.GetResponseMessage(message.SuccessUpdated, u.entityName), nil)
+}
+
+func (u *crudUsecase[E, F, CreateReq, UpdateReq, FilterReq, Res]) Delete(ctx context.Context, id uuid.UUID) dtobase.BaseRes {
+	ctx, span := tracing.Start(ctx, u.spanPrefix+"Delete")
+	defer span.End()
+
+	u.logger.InfoContext(ctx, "deleting "+u.entityName, "id", id)
+	select {
+	case <-ctx.Done():
+		u.logger.ErrorContext(ctx, "Delete: context done", "id", id, "error", ctx.Err().Error())
+		return dtobase.BaseRes{Success: false, Code: http.StatusInternalServerError, Message: message.GetResponseMessage(message.FailedDeleted, u.entityName)}
+	default:
+	}
+
+	if id == uuid.Nil {
+		u.logger.ErrorContext(ctx, "Delete: id is nil")
+		return dtobase.BaseRes{Success: false, Code: http.StatusBadRequest, Message: message.GetResponseMessage(message.FailedDeleted, u.entityName)}
+	}
+
+	if err := u.repository.Delete(ctx, id); err != nil {
+		u.logger.ErrorContext(ctx, "Delete: error deleting "+u.entityName, "id", id, "error", err.Error())
+		return dtobase.BaseRes{Success: false, Code: statusFromError(err), Message: message.GetResponseMessage(message.FailedDeleted, u.entityName)}
+	}
+
+	u.logger.InfoContext(ctx, u.entityName+" deleted", "id", id)
+	return dtobase.BaseRes{Success: true, Code: http.StatusOK, Message: message.GetResponseMessage(message.SuccessDeleted, u.entityName)}
+}
+
+func (u *crudUsecase[E, F, CreateReq, UpdateReq, FilterReq, Res]) single(entity *E, code int, message string, err error) dtobase.BaseResSingle[Res] {
+	var data *Res
+	if entity != nil {
+		res := u.serializer.EntityToResponse(*entity)
+		data = &res
+	}
+
+	return dtobase.BaseResSingle[Res]{
+		BaseRes: dtobase.BaseRes{
+			Success:    code >= http.StatusOK && code < http.StatusMultipleChoices,
+			Code:       code,
+			Message:    message,
+			Stacktrace: errorhelper.ComposeStacktrace(err),
+		},
+		Data: data,
+	}
+}
+
+func (u *crudUsecase[E, F, CreateReq, UpdateReq, FilterReq, Res]) list(entities []E, pagination entitybase.BasePaginationResult, code int, message string, err error) dtobase.BaseResList[Res] {
+	responses := make([]Res, len(entities))
+	for i, entity := range entities {
+		responses[i] = u.serializer.EntityToResponse(entity)
+	}
+
+	return dtobase.BaseResList[Res]{
+		BaseResPagination: dtobase.BaseResPagination{
+			BaseRes: dtobase.BaseRes{
+				Success:    code >= http.StatusOK && code < http.StatusMultipleChoices,
+				Code:       code,
+				Message:    message,
+				Stacktrace: errorhelper.ComposeStacktrace(err),
+			},
+			Page: dtobase.BasePagination{
+				Offset:  pagination.Offset,
+				Limit:   pagination.Limit,
+				Count:   pagination.Count,
+				OrderBy: pagination.OrderBy,
+			},
+		},
+		Data: responses,
+	}
+}
+
+// statusFromError maps a repository error to the status of the response, a missing entity is a 404 and
+// a duplicate key a 409
+func statusFromError(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case databasehelper.IsUniqueViolation(err):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// responseError returns the error answered for a repository error, a duplicate key gets a fixed error
+// as the one of postgres names the constraint and the SQLSTATE, in its message and in the stacktrace
+func (u *crudUsecase[E, F, CreateReq, UpdateReq, FilterReq, Res]) responseError(err error) error {
+	if databasehelper.IsUniqueViolation(err) {
+		return errors.New(message.GetResponseMessage(message.FailedDuplicate, u.entityName))
+	}
+	return err
+}
+
+// spanName writes entityName in camel case, e.g. "blog post" as blogPost
+func spanName(entityName string) string {
+	words := strings.Fields(entityName)
+	for i := 1; i < len(words); i++ {
+		words[i] = strings.ToUpper(words[i][:1]) + words[i][1:]
+	}
+	return strings.Join(words, "")
+}
+
+// entityID returns the id of entity for the logs, entities are not logged whole as they may hold secrets
+func entityID(entity any) any {
+	if identified, ok := entity.(entitybase.Identified); ok {
+		return identified.GetID()
+	}
+	return nil
+}
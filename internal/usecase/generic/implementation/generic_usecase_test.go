@@ -0,0 +1,244 @@
+package genericusecaseimplementation_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	entitybase "github.com/alxhtp/monogo/internal/entity/base"
+	genericusecaseimplementation "github.com/alxhtp/monogo/internal/usecase/generic/implementation"
+	"github.com/alxhtp/monogo/pkg/dto"
+	dtobase "github.com/alxhtp/monogo/pkg/dto/base"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+type note struct {
+	ID   uuid.UUID
+	Text string
+}
+
+type noteFilter struct{}
+
+type reqNote struct {
+	Text string `validate:"required"`
+}
+
+type reqGetNote struct {
+	Invalid bool
+}
+
+// noteRepository answers every operation with err, or with the notes it holds
+type noteRepository struct {
+	notes []note
+	err   error
+}
+
+func (r *noteRepository) Create(_ context.Context, entity *note) (*note, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	entity.ID = uuid.New()
+	r.notes = append(r.notes, *entity)
+	return entity, nil
+}
+
+func (r *noteRepository) GetByID(_ context.Context, id uuid.UUID) (*note, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	for _, n := range r.notes {
+		if n.ID == id {
+			return &n, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *noteRepository) GetByFilter(context.Context, *noteFilter) ([]note, entitybase.BasePaginationResult, error) {
+	if r.err != nil {
+		return nil, entitybase.BasePaginationResult{}, r.err
+	}
+	return r.notes, entitybase.BasePaginationResult{Limit: 10, Count: len(r.notes), OrderBy: "created_at desc"}, nil
+}
+
+func (r *noteRepository) Update(ctx context.Context, id uuid.UUID, _ map[string]any) (*note, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *noteRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	_, err := r.GetByID(ctx, id)
+	return err
+}
+
+// noteSerializer answers with the user response DTO, so that its envelopes convert to the named user envelopes
+type noteSerializer struct{}
+
+func (noteSerializer) FilterDTOToEntity(filter reqGetNote) (noteFilter, error) {
+	if filter.Invalid {
+		return noteFilter{}, errors.New("invalid filter")
+	}
+	return noteFilter{}, nil
+}
+
+func (noteSerializer) UpdateDTOToMap(update reqNote) (map[string]any, error) {
+	return map[string]any{"text": update.Text}, nil
+}
+
+func (noteSerializer) CreateDTOToEntity(create reqNote) (note, error) {
+	return note{Text: create.Text}, nil
+}
+
+func (noteSerializer) EntityToResponse(entity note) dto.ResUser {
+	return dto.ResUser{ID: entity.ID, Name: entity.Text}
+}
+
+func TestRepositoryErrorsAnswerWithTheirStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "missing entity", err: gorm.ErrRecordNotFound, want: http.StatusNotFound},
+		{name: "wrapped missing entity", err: fmt.Errorf("get note: %w", gorm.ErrRecordNotFound), want: http.StatusNotFound},
+		{name: "duplicate key translated by gorm", err: gorm.ErrDuplicatedKey, want: http.StatusConflict},
+		{name: "unique violation", err: fmt.Errorf("create note: %w", &pgconn.PgError{Code: "23505"}), want: http.StatusConflict},
+		{name: "other postgres error", err: &pgconn.PgError{Code: "23503"}, want: http.StatusInternalServerError},
+		{name: "other error", err: errors.New("connection refused"), want: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			usecase := genericusecaseimplementation.NewCrudUsecase("note", &noteRepository{err: tt.err}, noteSerializer{})
+
+			if res := usecase.Create(ctx, &reqNote{Text: "hello"}); res.Code != tt.want || res.Success || res.Data != nil {
+				t.Errorf("create: %d %t, want %d", res.Code, res.Success, tt.want)
+			}
+			if res := usecase.GetByID(ctx, uuid.New()); res.Code != tt.want {
+				t.Errorf("get by id: %d, want %d", res.Code, tt.want)
+			}
+			if res := usecase.GetByFilter(ctx, &reqGetNote{}); res.Code != tt.want {
+				t.Errorf("get by filter: %d, want %d", res.Code, tt.want)
+			}
+			if res := usecase.Update(ctx, uuid.New(), &reqNote{Text: "hello"}); res.Code != tt.want {
+				t.Errorf("update: %d, want %d", res.Code, tt.want)
+			}
+			if res := usecase.Delete(ctx, uuid.New()); res.Code != tt.want {
+				t.Errorf("delete: %d, want %d", res.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestDuplicateKeysAnswerWithAFixedMessage(t *testing.T) {
+	ctx := context.Background()
+	err := &pgconn.PgError{Code: "23505", Message: `duplicate key value violates unique constraint "notes_tenant_id_text_key"`, ConstraintName: "notes_tenant_id_text_key"}
+	usecase := genericusecaseimplementation.NewCrudUsecase("note", &noteRepository{err: err}, noteSerializer{})
+
+	const want = "A unique field is already taken by another note"
+	for operation, res := range map[string]dtobase.BaseRes{
+		"create": usecase.Create(ctx, &reqNote{Text: "hello"}).BaseRes,
+		"update": usecase.Update(ctx, uuid.New(), &reqNote{Text: "hello"}).BaseRes,
+	} {
+		if res.Message != want {
+			t.Errorf("%s: %q, want %q", operation, res.Message, want)
+		}
+		// the stacktrace does not carry the postgres error either
+		if res.Stacktrace == nil || strings.Contains(*res.Stacktrace, "notes_tenant_id_text_key") || strings.Contains(*res.Stacktrace, "23505") {
+			t.Errorf("%s: stacktrace %v names the constraint", operation, res.Stacktrace)
+		}
+	}
+}
+
+func TestInvalidRequestsAreBadRequests(t *testing.T) {
+	ctx := context.Background()
+	repository := &noteRepository{}
+	usecase := genericusecaseimplementation.NewCrudUsecase("note", repository, noteSerializer{})
+	created := usecase.Create(ctx, &reqNote{Text: "hello"})
+	if created.Code != http.StatusCreated || created.Data == nil {
+		t.Fatalf("create: %d %s", created.Code, created.Message)
+	}
+	id := created.Data.ID
+
+	tests := []struct {
+		name string
+		code func() int
+	}{
+		{name: "nil create request", code: func() int { return usecase.Create(ctx, nil).Code }},
+		{name: "invalid create request", code: func() int { return usecase.Create(ctx, &reqNote{}).Code }},
+		{name: "nil id", code: func() int { return usecase.GetByID(ctx, uuid.Nil).Code }},
+		{name: "nil filter", code: func() int { return usecase.GetByFilter(ctx, nil).Code }},
+		{name: "filter the serializer rejects", code: func() int { return usecase.GetByFilter(ctx, &reqGetNote{Invalid: true}).Code }},
+		{name: "nil update id", code: func() int { return usecase.Update(ctx, uuid.Nil, &reqNote{Text: "hello"}).Code }},
+		{name: "nil update request", code: func() int { return usecase.Update(ctx, id, nil).Code }},
+		{name: "invalid update request", code: func() int { return usecase.Update(ctx, id, &reqNote{}).Code }},
+		{name: "nil delete id", code: func() int { return usecase.Delete(ctx, uuid.Nil).Code }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := tt.code(); code != http.StatusBadRequest {
+				t.Fatalf("got %d, want %d", code, http.StatusBadRequest)
+			}
+		})
+	}
+	if len(repository.notes) != 1 {
+		t.Fatalf("the repository holds %d notes, invalid requests must not reach it", len(repository.notes))
+	}
+}
+
+func TestEnvelopesConvertToTheNamedEnvelopes(t *testing.T) {
+	ctx := context.Background()
+	usecase := genericusecaseimplementation.NewCrudUsecase("note", &noteRepository{}, noteSerializer{})
+
+	single := usecase.Create(ctx, &reqNote{Text: "hello"})
+	named := dto.ResUserSingle(single)
+	if named.Data == nil || named.Data.Name != "hello" || !named.Success || named.Code != http.StatusCreated {
+		t.Fatalf("single = %+v", named)
+	}
+	assertSameJSON(t, single, named)
+	failed := usecase.GetByID(ctx, uuid.Nil)
+	if named := dto.ResUserSingle(failed); named.Data != nil || named.Success || named.Stacktrace == nil {
+		t.Fatalf("failed single = %+v", named)
+	}
+	assertSameJSON(t, failed, dto.ResUserSingle(failed))
+
+	list := usecase.GetByFilter(ctx, &reqGetNote{})
+	namedList := dto.ResUserList(list)
+	if len(namedList.Data) != 1 || namedList.Page.Count != 1 || namedList.Page.OrderBy != "created_at desc" {
+		t.Fatalf("list = %+v", namedList)
+	}
+	assertSameJSON(t, list, namedList)
+
+	// a failed list answers with an empty page rather than null
+	body, err := json.Marshal(dto.ResUserList(usecase.GetByFilter(ctx, nil)))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var failedList struct {
+		Data []dto.ResUser `json:"data"`
+	}
+	if err := json.Unmarshal(body, &failedList); err != nil || failedList.Data == nil {
+		t.Fatalf("failed list = %s", body)
+	}
+}
+
+func assertSameJSON(t *testing.T, generic, named any) {
+	t.Helper()
+
+	want, err := json.Marshal(generic)
+	if err != nil {
+		t.Fatalf("marshal %T: %v", generic, err)
+	}
+	got, err := json.Marshal(named)
+	if err != nil {
+		t.Fatalf("marshal %T: %v", named, err)
+	}
+	if string(got) != string(want) {
+		t.Fatalf("%T = %s, want %s", named, got, want)
+	}
+}
@@ -0,0 +1,143 @@
+package tenantusecaseimplementation
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/alxhtp/monogo/config"
+	tenantrepository "github.com/alxhtp/monogo/internal/repository/tenant"
+	tenantserializer "github.com/alxhtp/monogo/internal/serializer/tenant"
+	tenantusecase "github.com/alxhtp/monogo/internal/usecase/tenant"
+	"github.com/alxhtp/monogo/pkg/cache"
+	"github.com/alxhtp/monogo/pkg/dto"
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
+	errorhelper "github.com/alxhtp/monogo/pkg/helper/error"
+	"github.com/alxhtp/monogo/pkg/message"
+	"github.com/alxhtp/monogo/pkg/tracing"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultTenantCacheTTL = time.Minute
+	tenantCacheCapacity   = 1000
+	tenantCacheKeyPrefix  = "tenant:"
+)
+
+var (
+	tenantEntityName = "tenant"
+)
+
+type tenantUsecase struct {
+	tenantRepository tenantrepository.TenantRepository
+	tenantSerializer tenantserializer.TenantSerializer
+	// resolved caches the tenants found, unknown ids are looked up every time so a new tenant is usable right away
+	resolved  cache.Store
+	cacheTTL  time.Duration
+	logger    *slog.Logger
+	validator *validator.Validate
+}
+
+func NewTenantUsecase(
+	tenantRepository tenantrepository.TenantRepository,
+	tenantSerializer tenantserializer.TenantSerializer,
+	cfg config.TenantConfig,
+) tenantusecase.TenantUsecase {
+	cacheTTL, err := time.ParseDuration(cfg.TenantCacheTTL)
+	if err != nil || cacheTTL <= 0 {
+		cacheTTL = defaultTenantCacheTTL
+	}
+
+	return &tenantUsecase{
+		tenantRepository: tenantRepository,
+		tenantSerializer: tenantSerializer,
+		resolved:         cache.NewLRU(tenantCacheCapacity),
+		cacheTTL:         cacheTTL,
+		logger:           slog.Default().With("usecase", tenantEntityName),
+		validator:        validator.New(validator.WithRequiredStructEnabled()),
+	}
+}
+
+func (u *tenantUsecase) CreateTenant(ctx context.Context, req *dto.ReqCreateTenant) dto.ResTenantSingle {
+	ctx, span := tracing.Start(ctx, "tenantUsecase.CreateTenant")
+	defer span.End()
+
+	u.logger.InfoContext(ctx, "creating tenant")
+	select {
+	case <-ctx.Done():
+		u.logger.ErrorContext(ctx, "CreateTenant: context done", "error", ctx.Err().Error())
+		return u.tenantSerializer.EntityToResponseSingle(nil, http.StatusInternalServerError, message.GetResponseMessage(message.FailedCreated, tenantEntityName), errorhelper.ComposeStacktrace(ctx.Err()))
+	default:
+	}
+
+	if req == nil {
+		u.logger.ErrorContext(ctx, "CreateTenant: request is nil")
+		return u.tenantSerializer.EntityToResponseSingle(nil, http.StatusBadRequest, message.GetResponseMessage(message.FailedCreated, tenantEntityName), errorhelper.ComposeStacktrace(errors.New("request is nil")))
+	}
+
+	if err := u.validator.Struct(req); err != nil {
+		u.logger.ErrorContext(ctx, "CreateTenant: request validation failed", "error", err.Error())
+		return u.tenantSerializer.EntityToResponseSingle(nil, http.StatusBadRequest, err.Error(), errorhelper.ComposeStacktrace(err))
+	}
+
+	if err := databasehelper.ValidateTenantID(req.ID); err != nil {
+		u.logger.ErrorContext(ctx, "CreateTenant: invalid tenant id", "id", req.ID, "error", err.Error())
+		return u.tenantSerializer.EntityToResponseSingle(nil, http.StatusBadRequest, err.Error(), errorhelper.ComposeStacktrace(err))
+	}
+
+	output, created, err := u.tenantRepository.Provision(ctx, req.ID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "CreateTenant: error provisioning tenant", "id", req.ID, "error", err.Error())
+		return u.tenantSerializer.EntityToResponseSingle(nil, http.StatusInternalServerError, err.Error(), errorhelper.ComposeStacktrace(err))
+	}
+
+	if !created {
+		u.logger.InfoContext(ctx, "tenant upgraded", "id", output.ID, "migrations", output.Migrations)
+		return u.tenantSerializer.EntityToResponseSingle(output, http.StatusOK, message.GetResponseMessage(message.SuccessUpdated, tenantEntityName), nil)
+	}
+
+	u.logger.InfoContext(ctx, "tenant created", "id", output.ID, "migrations", output.Migrations)
+	return u.tenantSerializer.EntityToResponseSingle(output, http.StatusCreated, message.GetResponseMessage(message.SuccessCreated, tenantEntityName), nil)
+}
+
+func (u *tenantUsecase) GetTenants(ctx context.Context) dto.ResTenantList {
+	ctx, span := tracing.Start(ctx, "tenantUsecase.GetTenants")
+	defer span.End()
+
+	u.logger.InfoContext(ctx, "getting tenants")
+
+	output, err := u.tenantRepository.GetAll(ctx)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "GetTenants: error getting tenants", "error", err.Error())
+		return u.tenantSerializer.EntityToResponseList(nil, http.StatusInternalServerError, err.Error(), errorhelper.ComposeStacktrace(err))
+	}
+
+	u.logger.InfoContext(ctx, "tenants got", "count", len(output))
+	return u.tenantSerializer.EntityToResponseList(output, http.StatusOK, message.GetResponseMessage(message.SuccessList, tenantEntityName), nil)
+}
+
+func (u *tenantUsecase) ResolveTenant(ctx context.Context, id string) (found bool, err error) {
+	if databasehelper.ValidateTenantID(id) != nil {
+		return false, nil
+	}
+
+	key := tenantCacheKeyPrefix + id
+	if _, found, err := u.resolved.Get(ctx, key); err == nil && found {
+		return true, nil
+	}
+
+	if _, err := u.tenantRepository.GetByID(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if err := u.resolved.Set(ctx, key, []byte{1}, u.cacheTTL); err != nil {
+		u.logger.WarnContext(ctx, "ResolveTenant: error caching tenant", "id", id, "error", err.Error())
+	}
+	return true, nil
+}
@@ -0,0 +1,100 @@
+package tenantusecaseimplementation_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/alxhtp/monogo/config"
+	"github.com/alxhtp/monogo/internal/entity"
+	tenantserializer "github.com/alxhtp/monogo/internal/serializer/tenant/implementation"
+	tenantusecaseimplementation "github.com/alxhtp/monogo/internal/usecase/tenant/implementation"
+	"github.com/alxhtp/monogo/pkg/dto"
+	"gorm.io/gorm"
+)
+
+// tenantRepository provisions tenants in memory and counts the lookups
+type tenantRepository struct {
+	tenants map[string]*entity.Tenant
+	lookups int
+}
+
+func (r *tenantRepository) Provision(_ context.Context, id string) (*entity.Tenant, bool, error) {
+	tenant, found := r.tenants[id]
+	if !found {
+		tenant = &entity.Tenant{ID: id, Schema: "tenant_" + id}
+		r.tenants[id] = tenant
+	}
+	tenant.Migrations++
+	return tenant, !found, nil
+}
+
+func (r *tenantRepository) GetByID(_ context.Context, id string) (*entity.Tenant, error) {
+	r.lookups++
+	tenant, found := r.tenants[id]
+	if !found {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return tenant, nil
+}
+
+func (r *tenantRepository) GetAll(context.Context) ([]entity.Tenant, error) {
+	output := make([]entity.Tenant, 0, len(r.tenants))
+	for _, tenant := range r.tenants {
+		output = append(output, *tenant)
+	}
+	return output, nil
+}
+
+func TestCreateTenantProvisionsOnceThenUpgrades(t *testing.T) {
+	repo := &tenantRepository{tenants: map[string]*entity.Tenant{}}
+	usecase := tenantusecaseimplementation.NewTenantUsecase(repo, tenantserializer.NewTenantSerializer(), config.TenantConfig{})
+	ctx := context.Background()
+
+	if res := usecase.CreateTenant(ctx, &dto.ReqCreateTenant{ID: "acme"}); res.Code != http.StatusCreated {
+		t.Fatalf("create: code = %d, want 201: %s", res.Code, res.Message)
+	}
+	res := usecase.CreateTenant(ctx, &dto.ReqCreateTenant{ID: "acme"})
+	if res.Code != http.StatusOK {
+		t.Fatalf("create again: code = %d, want 200: %s", res.Code, res.Message)
+	}
+	if res.Data == nil || res.Data.ID != "acme" {
+		t.Fatalf("create again: data = %+v, want acme", res.Data)
+	}
+
+	for _, req := range []*dto.ReqCreateTenant{nil, {ID: ""}, {ID: "Acme"}, {ID: "acme; DROP SCHEMA monogo"}} {
+		if res := usecase.CreateTenant(ctx, req); res.Code != http.StatusBadRequest {
+			t.Errorf("create %+v: code = %d, want 400", req, res.Code)
+		}
+	}
+	if len(repo.tenants) != 1 {
+		t.Fatalf("provisioned %d tenants, want 1", len(repo.tenants))
+	}
+}
+
+func TestResolveTenantCachesFoundTenantsOnly(t *testing.T) {
+	repo := &tenantRepository{tenants: map[string]*entity.Tenant{}}
+	usecase := tenantusecaseimplementation.NewTenantUsecase(repo, tenantserializer.NewTenantSerializer(), config.TenantConfig{})
+	ctx := context.Background()
+
+	if found, err := usecase.ResolveTenant(ctx, "acme"); err != nil || found {
+		t.Fatalf("resolve before provisioning: got %t, %v, want not found", found, err)
+	}
+	// a tenant provisioned by another instance is found right away
+	repo.tenants["acme"] = &entity.Tenant{ID: "acme"}
+	for range 3 {
+		if found, err := usecase.ResolveTenant(ctx, "acme"); err != nil || !found {
+			t.Fatalf("resolve: got %t, %v, want found", found, err)
+		}
+	}
+	if repo.lookups != 2 {
+		t.Fatalf("looked up %d times, want 2", repo.lookups)
+	}
+
+	if found, _ := usecase.ResolveTenant(ctx, "../acme"); found {
+		t.Fatal("an invalid tenant id was resolved")
+	}
+	if repo.lookups != 2 {
+		t.Fatal("an invalid tenant id reached the repository")
+	}
+}
@@ -0,0 +1,15 @@
+package tenantusecase
+
+import (
+	"context"
+
+	"github.com/alxhtp/monogo/pkg/dto"
+)
+
+type TenantUsecase interface {
+	// CreateTenant provisions a tenant, an existing tenant is upgraded to the latest migrations
+	CreateTenant(ctx context.Context, req *dto.ReqCreateTenant) dto.ResTenantSingle
+	GetTenants(ctx context.Context) dto.ResTenantList
+	// ResolveTenant reports whether id is a provisioned tenant, found tenants are cached for TENANT_CACHE_TTL
+	ResolveTenant(ctx context.Context, id string) (found bool, err error)
+}
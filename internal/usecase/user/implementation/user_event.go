@@ -0,0 +1,93 @@
+package userusecaseimplementation
+
+import (
+	"context"
+	"slices"
+
+	"github.com/alxhtp/monogo/internal/entity"
+	"github.com/alxhtp/monogo/pkg/constant"
+	"github.com/alxhtp/monogo/pkg/event"
+	"github.com/alxhtp/monogo/pkg/metrics"
+	"github.com/google/uuid"
+)
+
+// createUser creates the user and records UserCreated in the same transaction
+func (u *userUsecase) createUser(ctx context.Context, user *entity.User) (output *entity.User, err error) {
+	err = u.transactionRepository.WithTransaction(ctx, func(ctx context.Context) error {
+		output, err = u.userRepository.Create(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		return u.outboxRepository.Enqueue(ctx, event.UserCreated{User: u.userSerializer.EntityToResponse(*output)})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	metrics.UsersCreatedTotal.Inc()
+	return output, nil
+}
+
+// updateUser updates the user and records UserUpdated, plus UserStatusChanged when the status moved,
+// in the same transaction
+func (u *userUsecase) updateUser(ctx context.Context, id uuid.UUID, updateMap map[string]any) (output *entity.User, err error) {
+	var banned bool
+	err = u.transactionRepository.WithTransaction(ctx, func(ctx context.Context) error {
+		before, err := u.userRepository.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		// collected before Update, the update hooks add bookkeeping columns into the map
+		changedFields := make([]string, 0, len(updateMap))
+		for field := range updateMap {
+			changedFields = append(changedFields, field)
+		}
+		slices.Sort(changedFields)
+
+		output, err = u.userRepository.Update(ctx, id, updateMap)
+		if err != nil {
+			return err
+		}
+
+		res := u.userSerializer.EntityToResponse(*output)
+		events := []event.Event{event.UserUpdated{User: res, ChangedFields: changedFields}}
+		if before.Status != output.Status {
+			events = append(events, event.UserStatusChanged{User: res, OldStatus: int(before.Status), NewStatus: int(output.Status)})
+			banned = output.Status == constant.UserStatusBanned
+		}
+
+		return u.outboxRepository.Enqueue(ctx, events...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if banned {
+		metrics.UsersBannedTotal.Inc()
+	}
+	return output, nil
+}
+
+// deleteUser deletes the user and records UserDeleted in the same transaction
+func (u *userUsecase) deleteUser(ctx context.Context, id uuid.UUID) error {
+	err := u.transactionRepository.WithTransaction(ctx, func(ctx context.Context) error {
+		before, err := u.userRepository.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := u.userRepository.Delete(ctx, id); err != nil {
+			return err
+		}
+
+		return u.outboxRepository.Enqueue(ctx, event.UserDeleted{User: u.userSerializer.EntityToResponse(*before)})
+	})
+	if err != nil {
+		return err
+	}
+
+	metrics.UsersDeletedTotal.Inc()
+	return nil
+}
@@ -0,0 +1,428 @@
+package userusecaseimplementation
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/alxhtp/monogo/internal/entity"
+	entitybase "github.com/alxhtp/monogo/internal/entity/base"
+	"github.com/alxhtp/monogo/pkg/dto"
+	dtobase "github.com/alxhtp/monogo/pkg/dto/base"
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
+	errorhelper "github.com/alxhtp/monogo/pkg/helper/error"
+	jobhelper "github.com/alxhtp/monogo/pkg/helper/job"
+	"github.com/alxhtp/monogo/pkg/message"
+	"github.com/alxhtp/monogo/pkg/tracing"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+const (
+	importJobKind       = "user-import"
+	importJobEntityName = "user import job"
+
+	importColumnName    = "name"
+	importColumnEmail   = "email"
+	importColumnSex     = "metadata.sex"
+	importColumnAddress = "metadata.address"
+	importColumnPhone   = "metadata.phone"
+)
+
+// errors of the rows failing on the database
+const (
+	importErrorEmailTaken = "email is already registered"
+	importErrorEmailCheck = "email could not be checked, retry the row"
+	importErrorCreate     = "user could not be created, retry the row"
+)
+
+// importColumnAliases maps accepted csv header names into ReqCreateUser fields
+var importColumnAliases = map[string]string{
+	"name":             importColumnName,
+	"email":            importColumnEmail,
+	"sex":              importColumnSex,
+	"metadata.sex":     importColumnSex,
+	"metadata_sex":     importColumnSex,
+	"address":          importColumnAddress,
+	"metadata.address": importColumnAddress,
+	"metadata_address": importColumnAddress,
+	"phone":            importColumnPhone,
+	"metadata.phone":   importColumnPhone,
+	"metadata_phone":   importColumnPhone,
+}
+
+var importErrorHeader = []string{"row", "name", "email", "sex", "address", "phone", "errors"}
+
+type importRecord struct {
+	row int
+	req dto.ReqCreateUser
+}
+
+func (u *userUsecase) ImportUsers(ctx context.Context, req *dto.ReqImportUser) dto.ResUserImport {
+	ctx, span := tracing.Start(ctx, "userUsecase.ImportUsers")
+	defer span.End()
+
+	u.logger.InfoContext(ctx, "importing users")
+	select {
+	case <-ctx.Done():
+		u.logger.ErrorContext(ctx, "ImportUsers: context done", "error", ctx.Err().Error())
+		return u.importResponse(nil, http.StatusInternalServerError, message.GetResponseMessage(message.FailedImport, userEntityName), errorhelper.ComposeStacktrace(ctx.Err()))
+	default:
+	}
+
+	if req == nil || req.File == nil {
+		u.logger.ErrorContext(ctx, "ImportUsers: request is empty")
+		return u.importResponse(nil, http.StatusBadRequest, message.GetResponseMessage(message.FailedImport, userEntityName), errorhelper.ComposeStacktrace(errors.New("request is empty")))
+	}
+
+	records, err := parseImportCSV(req.File, u.importCfg.ImportMaxRows)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "ImportUsers: error parsing csv", "error", err.Error())
+		return u.importResponse(nil, http.StatusBadRequest, err.Error(), errorhelper.ComposeStacktrace(err))
+	}
+
+	if u.importCfg.ImportAsyncThreshold > 0 && len(records) > u.importCfg.ImportAsyncThreshold {
+		job := u.importJobs.Create(importJobKind, contexthelper.GetTenant(ctx), len(records))
+		// the job outlives the request, it keeps the tenant, actor and request id of the caller for the audit trail
+		u.importJobs.Go(contexthelper.Detach(ctx), job.ID, func(ctx context.Context) error {
+			return u.runImportJob(ctx, job.ID, records, req.DryRun)
+		})
+
+		u.logger.InfoContext(ctx, "user import queued", "job_id", job.ID, "rows", len(records), "dry_run", req.DryRun)
+		return u.importResponse(&dto.ResUserImportReport{
+			DryRun: req.DryRun,
+			Total:  len(records),
+			Job:    importJobToResponse(job),
+		}, http.StatusAccepted, message.GetResponseMessage(message.SuccessQueued, userEntityName), nil)
+	}
+
+	report := dto.ResUserImportReport{
+		DryRun: req.DryRun,
+		Total:  len(records),
+		Rows:   make([]dto.ResUserImportRow, 0, len(records)),
+	}
+	seenEmails := make(map[string]int, len(records))
+	for i := range records {
+		row := u.importRecord(ctx, records[i], seenEmails, req.DryRun)
+		if row.Valid {
+			report.Succeeded++
+		} else {
+			report.Failed++
+		}
+		report.Rows = append(report.Rows, row)
+	}
+
+	u.logger.InfoContext(ctx, "users imported", "total", report.Total, "succeeded", report.Succeeded, "failed", report.Failed, "dry_run", req.DryRun)
+	switch {
+	case req.DryRun:
+		return u.importResponse(&report, http.StatusOK, message.GetResponseMessage(message.SuccessDryRun, userEntityName), nil)
+	case report.Failed == 0:
+		return u.importResponse(&report, http.StatusCreated, message.GetResponseMessage(message.SuccessImport, userEntityName), nil)
+	case report.Succeeded > 0:
+		return u.importResponse(&report, http.StatusMultiStatus, message.GetResponseMessage(message.SuccessImport, userEntityName), nil)
+	default:
+		return u.importResponse(&report, http.StatusUnprocessableEntity, message.GetResponseMessage(message.FailedImport, userEntityName), nil)
+	}
+}
+
+func (u *userUsecase) GetImportJob(ctx context.Context, id uuid.UUID) dto.ResUserImportJobSingle {
+	ctx, span := tracing.Start(ctx, "userUsecase.GetImportJob")
+	defer span.End()
+
+	u.logger.InfoContext(ctx, "getting user import job", "id", id)
+
+	// jobs of other tenants are reported as not found
+	job, ok := u.importJobs.Get(id)
+	if !ok || job.Tenant != contexthelper.GetTenant(ctx) {
+		u.logger.ErrorContext(ctx, "GetImportJob: job not found", "id", id)
+		return dto.ResUserImportJobSingle{
+			BaseRes: dtobase.BaseRes{Success: false, Code: http.StatusNotFound, Message: message.GetResponseMessage(message.FailedGetByID, importJobEntityName)},
+		}
+	}
+
+	return dto.ResUserImportJobSingle{
+		BaseRes: dtobase.BaseRes{Success: true, Code: http.StatusOK, Message: message.GetResponseMessage(message.SuccessGetByID, importJobEntityName)},
+		Data:    importJobToResponse(job),
+	}
+}
+
+func (u *userUsecase) GetImportJobErrors(ctx context.Context, id uuid.UUID) ([]byte, dtobase.BaseRes) {
+	ctx, span := tracing.Start(ctx, "userUsecase.GetImportJobErrors")
+	defer span.End()
+
+	u.logger.InfoContext(ctx, "getting user import job errors", "id", id)
+
+	// jobs of other tenants are reported as not found
+	job, ok := u.importJobs.Get(id)
+	if !ok || job.Tenant != contexthelper.GetTenant(ctx) {
+		u.logger.ErrorContext(ctx, "GetImportJobErrors: job not found", "id", id)
+		return nil, dtobase.BaseRes{Success: false, Code: http.StatusNotFound, Message: message.GetResponseMessage(message.FailedGetByID, importJobEntityName)}
+	}
+
+	if job.FinishedAt == nil {
+		u.logger.ErrorContext(ctx, "GetImportJobErrors: job is not finished", "id", id, "status", job.Status)
+		return nil, dtobase.BaseRes{Success: false, Code: http.StatusConflict, Message: fmt.Sprintf("%s is %s", importJobEntityName, job.Status)}
+	}
+
+	return job.Result, dtobase.BaseRes{Success: true, Code: http.StatusOK, Message: message.GetResponseMessage(message.SuccessGetByID, importJobEntityName)}
+}
+
+// runImportJob imports records, it stops between two rows once ctx is cancelled, see jobhelper.Store.Go
+func (u *userUsecase) runImportJob(ctx context.Context, jobID uuid.UUID, records []importRecord, dryRun bool) error {
+	u.importJobs.Update(jobID, func(job *jobhelper.Job) {
+		job.Status = jobhelper.StatusRunning
+	})
+
+	var (
+		buf        bytes.Buffer
+		writer     = csv.NewWriter(&buf)
+		seenEmails = make(map[string]int, len(records))
+	)
+	_ = writer.Write(importErrorHeader)
+
+	for i := range records {
+		if err := ctx.Err(); err != nil {
+			u.logger.WarnContext(ctx, "user import job interrupted", "job_id", jobID, "processed", i, "total", len(records))
+			return err
+		}
+
+		row := u.importRecord(ctx, records[i], seenEmails, dryRun)
+		if !row.Valid {
+			_ = writer.Write(importErrorRecord(records[i], row))
+		}
+
+		u.importJobs.Update(jobID, func(job *jobhelper.Job) {
+			job.Processed++
+			if row.Valid {
+				job.Succeeded++
+			} else {
+				job.Failed++
+			}
+		})
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		u.logger.ErrorContext(ctx, "runImportJob: error writing error csv", "job_id", jobID, "error", err.Error())
+		return errors.New("error report could not be written")
+	}
+
+	u.importJobs.Update(jobID, func(job *jobhelper.Job) {
+		job.Result = buf.Bytes()
+	})
+	u.logger.InfoContext(ctx, "user import job finished", "job_id", jobID, "dry_run", dryRun)
+	return nil
+}
+
+// StopImportJobs waits for the running import jobs until ctx is done, the jobs left are then interrupted
+func (u *userUsecase) StopImportJobs(ctx context.Context) error {
+	return u.importJobs.Stop(ctx)
+}
+
+// RunningImportJobs returns the ids of the running import jobs
+func (u *userUsecase) RunningImportJobs() []string {
+	return u.importJobs.Running()
+}
+
+// importRecord validates a single csv record and creates the user unless dryRun is set
+func (u *userUsecase) importRecord(ctx context.Context, record importRecord, seenEmails map[string]int, dryRun bool) dto.ResUserImportRow {
+	row := dto.ResUserImportRow{Row: record.row, Email: record.req.Email}
+
+	if err := u.validator.Struct(record.req); err != nil {
+		var validationErrors validator.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			for _, fieldErr := range validationErrors {
+				row.Errors = append(row.Errors, fieldErr.Error())
+			}
+		} else {
+			row.Errors = append(row.Errors, err.Error())
+		}
+	}
+
+	email := strings.ToLower(record.req.Email)
+	if email != "" {
+		if firstRow, ok := seenEmails[email]; ok {
+			row.Errors = append(row.Errors, fmt.Sprintf("email is duplicated in row %d", firstRow))
+		} else {
+			seenEmails[email] = record.row
+		}
+	}
+
+	if len(row.Errors) == 0 {
+		exists, err := u.emailExists(ctx, record.req.Email)
+		if err != nil {
+			u.logger.ErrorContext(ctx, "ImportUsers: error checking email", "row", record.row, "error", err.Error())
+			row.Errors = append(row.Errors, importErrorEmailCheck)
+		} else if exists {
+			row.Errors = append(row.Errors, importErrorEmailTaken)
+		}
+	}
+
+	if len(row.Errors) > 0 || dryRun {
+		row.Valid = len(row.Errors) == 0
+		return row
+	}
+
+	user, err := u.userSerializer.CreateDTOToEntity(record.req)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "ImportUsers: error converting row to entity", "row", record.row, "error", err.Error())
+		row.Errors = append(row.Errors, importErrorCreate)
+		return row
+	}
+
+	output, err := u.createUser(ctx, &user)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "ImportUsers: error creating user", "row", record.row, "error", err.Error())
+		// database errors are logged, the report names the constraints of the users table otherwise
+		if databasehelper.IsUniqueViolation(err) {
+			row.Errors = append(row.Errors, importErrorEmailTaken)
+		} else {
+			row.Errors = append(row.Errors, importErrorCreate)
+		}
+		return row
+	}
+
+	row.Valid = true
+	row.ID = &output.ID
+	return row
+}
+
+func (u *userUsecase) emailExists(ctx context.Context, email string) (bool, error) {
+	var (
+		limit       = 1
+		withDeleted = true
+	)
+
+	// soft deleted rows still hold the unique email constraint
+	users, _, err := u.userRepository.GetByFilter(ctx, &entity.UserFilter{
+		Email: &email,
+		PaginationFilter: entitybase.BasePaginationFilter{
+			Limit:       &limit,
+			WithDeleted: &withDeleted,
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return len(users) > 0, nil
+}
+
+func (u *userUsecase) importResponse(report *dto.ResUserImportReport, code int, msg string, stacktrace *string) dto.ResUserImport {
+	return dto.ResUserImport{
+		BaseRes: dtobase.BaseRes{
+			Success:    code >= http.StatusOK && code < http.StatusMultipleChoices,
+			Code:       code,
+			Message:    msg,
+			Stacktrace: stacktrace,
+		},
+		Data: report,
+	}
+}
+
+// parseImportCSV reads the csv header and maps every row into ReqCreateUser, the csv is read row by row and
+// refused once it has more than maxRows rows, 0 for no limit
+func parseImportCSV(file io.Reader, maxRows int) ([]importRecord, error) {
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("csv is empty")
+		}
+		return nil, fmt.Errorf("read csv header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		field, ok := importColumnAliases[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown csv column %q", header[i])
+		}
+		if _, duplicated := columns[field]; duplicated {
+			return nil, fmt.Errorf("csv column %q is mapped more than once", header[i])
+		}
+		columns[field] = i
+	}
+
+	for _, required := range []string{importColumnName, importColumnEmail} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("csv column %q is required", required)
+		}
+	}
+
+	records := make([]importRecord, 0)
+	for row := 2; ; row++ {
+		values, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read csv row %d: %w", row, err)
+		}
+		if maxRows > 0 && len(records) == maxRows {
+			return nil, fmt.Errorf("csv has more than %d rows", maxRows)
+		}
+
+		value := func(field string) string {
+			if i, ok := columns[field]; ok && i < len(values) {
+				return strings.TrimSpace(values[i])
+			}
+			return ""
+		}
+
+		records = append(records, importRecord{
+			row: row,
+			req: dto.ReqCreateUser{
+				Name:  value(importColumnName),
+				Email: value(importColumnEmail),
+				Metadata: dto.UserMetadata{
+					Sex:     value(importColumnSex),
+					Address: value(importColumnAddress),
+					Phone:   value(importColumnPhone),
+				},
+			},
+		})
+	}
+
+	if len(records) == 0 {
+		return nil, errors.New("csv has no rows")
+	}
+
+	return records, nil
+}
+
+func importErrorRecord(record importRecord, row dto.ResUserImportRow) []string {
+	return []string{
+		strconv.Itoa(record.row),
+		record.req.Name,
+		record.req.Email,
+		record.req.Metadata.Sex,
+		record.req.Metadata.Address,
+		record.req.Metadata.Phone,
+		strings.Join(row.Errors, "; "),
+	}
+}
+
+func importJobToResponse(job jobhelper.Job) *dto.ResUserImportJob {
+	return &dto.ResUserImportJob{
+		ID:         job.ID,
+		Status:     string(job.Status),
+		Total:      job.Total,
+		Processed:  job.Processed,
+		Succeeded:  job.Succeeded,
+		Failed:     job.Failed,
+		Error:      job.Error,
+		CreatedAt:  job.CreatedAt,
+		FinishedAt: job.FinishedAt,
+	}
+}
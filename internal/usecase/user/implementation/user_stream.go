@@ -0,0 +1,219 @@
+package userusecaseimplementation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/alxhtp/monogo/internal/entity"
+	"github.com/alxhtp/monogo/pkg/constant"
+	"github.com/alxhtp/monogo/pkg/dto"
+	dtobase "github.com/alxhtp/monogo/pkg/dto/base"
+	"github.com/alxhtp/monogo/pkg/event"
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
+	"github.com/alxhtp/monogo/pkg/message"
+	"github.com/alxhtp/monogo/pkg/tracing"
+	"gorm.io/gorm"
+)
+
+const (
+	userEventEntityName = "user event stream"
+	userEventReplayPage = 100
+)
+
+// userEventPayload is the union of the user event payloads
+type userEventPayload struct {
+	User          dto.ResUser `json:"user"`
+	ChangedFields []string    `json:"changed_fields"`
+	OldStatus     *int        `json:"old_status"`
+	NewStatus     *int        `json:"new_status"`
+}
+
+func (u *userUsecase) StreamUserEvents(ctx context.Context, req *dto.ReqGetUserEvent) (<-chan dto.ResUserEvent, dtobase.BaseRes) {
+	ctx, span := tracing.Start(ctx, "userUsecase.StreamUserEvents")
+	defer span.End()
+
+	u.logger.InfoContext(ctx, "streaming user events", "req", req)
+
+	if req == nil {
+		u.logger.ErrorContext(ctx, "StreamUserEvents: request is nil")
+		return nil, dtobase.BaseRes{Success: false, Code: http.StatusBadRequest, Message: message.GetResponseMessage(message.FailedGetByID, userEventEntityName)}
+	}
+
+	if u.eventSubscriber == nil {
+		u.logger.ErrorContext(ctx, "StreamUserEvents: event subscriber is not configured")
+		return nil, dtobase.BaseRes{Success: false, Code: http.StatusServiceUnavailable, Message: message.GetResponseMessage(message.FailedGetByID, userEventEntityName)}
+	}
+
+	userFilter, err := u.userSerializer.FilterDTOToEntity(req.Filter())
+	if err != nil {
+		u.logger.ErrorContext(ctx, "StreamUserEvents: error converting filter to entity", "req", req, "error", err.Error())
+		return nil, dtobase.BaseRes{Success: false, Code: http.StatusBadRequest, Message: err.Error()}
+	}
+
+	var lastEventID int64
+	if req.LastEventID != nil {
+		lastEventID = *req.LastEventID
+	}
+
+	// subscribe before replaying, so nothing published in between is missed
+	messages, unsubscribe := u.eventSubscriber.Subscribe()
+
+	replay, err := u.loadUserEventReplay(ctx, lastEventID)
+	if err != nil {
+		unsubscribe()
+		u.logger.ErrorContext(ctx, "StreamUserEvents: error loading replay", "last_event_id", lastEventID, "error", err.Error())
+		return nil, dtobase.BaseRes{Success: false, Code: http.StatusInternalServerError, Message: err.Error()}
+	}
+
+	events := make(chan dto.ResUserEvent)
+	go func() {
+		defer close(events)
+		defer unsubscribe()
+
+		// message ids are per tenant, events of other tenants are never sent
+		tenant := contexthelper.GetTenant(ctx)
+		send := func(msg event.Message) bool {
+			// live messages are newer than lastEventID even with a lower id, retried messages are published late
+			if msg.Tenant != tenant || msg.AggregateType != event.UserAggregateType || msg.ID == lastEventID {
+				return true
+			}
+
+			evt, ok := u.toUserEvent(ctx, msg, &userFilter)
+			if !ok {
+				return true
+			}
+
+			select {
+			case <-ctx.Done():
+				return false
+			case events <- evt:
+				return true
+			}
+		}
+
+		// only the replayed ids are kept, live messages published while replaying may repeat them,
+		// the set is bounded by the replay limit and does not grow with the life of the stream
+		replayed := make(map[int64]struct{}, len(replay))
+		for _, msg := range replay {
+			if !send(msg) {
+				return
+			}
+			replayed[msg.ID] = struct{}{}
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				if _, ok := replayed[msg.ID]; ok {
+					continue
+				}
+				if !send(msg) {
+					return
+				}
+			}
+		}
+	}()
+
+	return events, dtobase.BaseRes{Success: true, Code: http.StatusOK, Message: message.GetResponseMessage(message.SuccessGetByID, userEventEntityName)}
+}
+
+// loadUserEventReplay returns user events published after lastEventID in publish order, bounded by the replay limit.
+// Ids are not in publish order, a retried message is published after messages with higher ids, so the replay starts
+// when lastEventID was claimed for publishing: events published since then are replayed, some may have been streamed already.
+func (u *userUsecase) loadUserEventReplay(ctx context.Context, lastEventID int64) ([]event.Message, error) {
+	if lastEventID <= 0 {
+		return nil, nil
+	}
+
+	last, err := u.outboxRepository.GetByID(ctx, lastEventID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	since := last.CreatedAt
+	if last.ClaimedAt != nil {
+		since = *last.ClaimedAt
+	}
+
+	var (
+		output  []event.Message
+		afterID int64
+	)
+	for len(output) < u.eventsCfg.UserEventsReplayLimit {
+		limit := min(userEventReplayPage, u.eventsCfg.UserEventsReplayLimit-len(output))
+		messages, err := u.outboxRepository.GetPublishedSince(ctx, event.UserAggregateType, since, afterID, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, msg := range messages {
+			since, afterID = *msg.PublishedAt, msg.ID
+			if msg.ID == lastEventID {
+				continue
+			}
+
+			output = append(output, event.Message{
+				ID:            msg.ID,
+				Tenant:        contexthelper.GetTenant(ctx),
+				Type:          event.Type(msg.EventType),
+				AggregateType: msg.AggregateType,
+				AggregateID:   msg.AggregateID,
+				Payload:       json.RawMessage(msg.Payload.Item),
+				OccurredAt:    msg.CreatedAt,
+			})
+		}
+
+		if len(messages) < limit {
+			return output, nil
+		}
+	}
+
+	u.logger.WarnContext(ctx, "user event replay truncated", "last_event_id", lastEventID, "limit", u.eventsCfg.UserEventsReplayLimit)
+	return output, nil
+}
+
+func (u *userUsecase) toUserEvent(ctx context.Context, msg event.Message, filter *entity.UserFilter) (dto.ResUserEvent, bool) {
+	var payload userEventPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		u.logger.WarnContext(ctx, "invalid user event payload", "id", msg.ID, "error", err.Error())
+		return dto.ResUserEvent{}, false
+	}
+
+	user := entity.User{
+		Name:   payload.User.Name,
+		Email:  payload.User.Email,
+		Status: constant.UserStatus(payload.User.Status),
+		Metadata: databasehelper.GormJsonType[entity.UserMetadata]{
+			Item: entity.UserMetadata{
+				Sex:     payload.User.Metadata.Sex,
+				Address: payload.User.Metadata.Address,
+				Phone:   payload.User.Metadata.Phone,
+			},
+		},
+	}
+	user.ID = payload.User.ID
+
+	if !filter.Match(&user) {
+		return dto.ResUserEvent{}, false
+	}
+
+	return dto.ResUserEvent{
+		ID:            msg.ID,
+		Type:          string(msg.Type),
+		User:          payload.User,
+		ChangedFields: payload.ChangedFields,
+		OldStatus:     payload.OldStatus,
+		NewStatus:     payload.NewStatus,
+		OccurredAt:    msg.OccurredAt,
+	}, true
+}
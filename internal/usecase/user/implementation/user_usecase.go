@@ -5,15 +5,24 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"time"
 
+	"github.com/alxhtp/monogo/config"
+	"github.com/alxhtp/monogo/internal/entity"
 	entitybase "github.com/alxhtp/monogo/internal/entity/base"
+	outboxrepository "github.com/alxhtp/monogo/internal/repository/outbox"
+	transactionrepository "github.com/alxhtp/monogo/internal/repository/transaction"
 	userrepository "github.com/alxhtp/monogo/internal/repository/user"
 	userserializer "github.com/alxhtp/monogo/internal/serializer/user"
+	"github.com/alxhtp/monogo/internal/subscriber"
 	userusecase "github.com/alxhtp/monogo/internal/usecase/user"
 	"github.com/alxhtp/monogo/pkg/dto"
 	dtobase "github.com/alxhtp/monogo/pkg/dto/base"
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
 	errorhelper "github.com/alxhtp/monogo/pkg/helper/error"
+	jobhelper "github.com/alxhtp/monogo/pkg/helper/job"
 	"github.com/alxhtp/monogo/pkg/message"
+	"github.com/alxhtp/monogo/pkg/tracing"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 )
@@ -23,146 +32,190 @@ var (
 )
 
 type userUsecase struct {
-	userRepository userrepository.UserRepository
-	userSerializer userserializer.UserSerializer
-	logger         *slog.Logger
-	validator      *validator.Validate
+	userRepository        userrepository.UserRepository
+	outboxRepository      outboxrepository.OutboxRepository
+	transactionRepository transactionrepository.TransactionRepository
+	userSerializer        userserializer.UserSerializer
+	eventSubscriber       subscriber.Subscriber
+	logger                *slog.Logger
+	validator             *validator.Validate
+	importCfg             config.UserImportConfig
+	importJobs            *jobhelper.Store
+	eventsCfg             config.UserEventsConfig
 }
 
-func NewUserUsecase(userRepository userrepository.UserRepository, userSerializer userserializer.UserSerializer) userusecase.UserUsecase {
+func NewUserUsecase(
+	userRepository userrepository.UserRepository,
+	outboxRepository outboxrepository.OutboxRepository,
+	transactionRepository transactionrepository.TransactionRepository,
+	userSerializer userserializer.UserSerializer,
+	eventSubscriber subscriber.Subscriber,
+	importCfg config.UserImportConfig,
+	eventsCfg config.UserEventsConfig,
+) userusecase.UserUsecase {
+	jobRetention, err := time.ParseDuration(importCfg.ImportJobRetention)
+	if err != nil {
+		jobRetention = 24 * time.Hour
+	}
+
 	return &userUsecase{
-		userRepository: userRepository,
-		userSerializer: userSerializer,
-		logger:         slog.Default().With("usecase", userEntityName),
-		validator:      validator.New(validator.WithRequiredStructEnabled()),
+		userRepository:        userRepository,
+		outboxRepository:      outboxRepository,
+		transactionRepository: transactionRepository,
+		userSerializer:        userSerializer,
+		eventSubscriber:       eventSubscriber,
+		logger:                slog.Default().With("usecase", userEntityName),
+		validator:             validator.New(validator.WithRequiredStructEnabled()),
+		importCfg:             importCfg,
+		importJobs:            jobhelper.NewStore(jobRetention),
+		eventsCfg:             eventsCfg,
 	}
 }
 
 func (u *userUsecase) CreateUser(ctx context.Context, req *dto.ReqCreateUser) dto.ResUserSingle {
+	ctx, span := tracing.Start(ctx, "userUsecase.CreateUser")
+	defer span.End()
+
 	u.logger.InfoContext(ctx, "creating user")
 	select {
 	case <-ctx.Done():
-		u.logger.ErrorContext(ctx, "CreateUser: context done", "req", req, "error", ctx.Err().Error())
-		return u.userSerializer.EntityToResponseSingle(nil, http.StatusInternalServerError, message.GetResponseMessage(message.FailedCreated, userEntityName), errorhelper.ComposeStacktrace(ctx.Err()))
+		u.logger.ErrorContext(ctx, "CreateUser: context done", "error", ctx.Err().Error())
+		return u.single(nil, http.StatusInternalServerError, message.GetResponseMessage(message.FailedCreated, userEntityName), ctx.Err())
 	default:
 	}
 
 	if req == nil {
 		u.logger.ErrorContext(ctx, "CreateUser: request is nil")
-		return u.userSerializer.EntityToResponseSingle(nil, http.StatusBadRequest, message.GetResponseMessage(message.FailedCreated, userEntityName), errorhelper.ComposeStacktrace(errors.New("request is nil")))
+		return u.single(nil, http.StatusBadRequest, message.GetResponseMessage(message.FailedCreated, userEntityName), errors.New("request is nil"))
 	}
 
 	if err := u.validator.Struct(req); err != nil {
-		u.logger.ErrorContext(ctx, "CreateUser: request validation failed", "req", req, "error", err.Error())
-		return u.userSerializer.EntityToResponseSingle(nil, http.StatusBadRequest, err.Error(), errorhelper.ComposeStacktrace(err))
+		u.logger.ErrorContext(ctx, "CreateUser: request validation failed", "error", err.Error())
+		return u.single(nil, http.StatusBadRequest, err.Error(), err)
 	}
 
 	user, err := u.userSerializer.CreateDTOToEntity(*req)
 	if err != nil {
-		u.logger.ErrorContext(ctx, "CreateUser: error creating user", "req", req, "error", err.Error())
-		return u.userSerializer.EntityToResponseSingle(nil, http.StatusInternalServerError, err.Error(), errorhelper.ComposeStacktrace(err))
+		u.logger.ErrorContext(ctx, "CreateUser: error creating user", "error", err.Error())
+		return u.single(nil, http.StatusInternalServerError, err.Error(), err)
 	}
 
-	output, err := u.userRepository.Create(ctx, &user)
+	output, err := u.createUser(ctx, &user)
 	if err != nil {
-		u.logger.ErrorContext(ctx, "CreateUser: error creating user", "req", req, "error", err.Error())
-		return u.userSerializer.EntityToResponseSingle(nil, http.StatusInternalServerError, err.Error(), errorhelper.ComposeStacktrace(err))
+		u.logger.ErrorContext(ctx, "CreateUser: error creating user", "error", err.Error())
+		err = u.responseError(err)
+		return u.single(nil, http.StatusInternalServerError, err.Error(), err)
 	}
 
-	u.logger.InfoContext(ctx, "user created", "user", output)
-	return u.userSerializer.EntityToResponseSingle(output, http.StatusCreated, message.GetResponseMessage(message.SuccessCreated, userEntityName), nil)
+	u.logger.InfoContext(ctx, "user created", "id", output.ID)
+	return u.single(output, http.StatusCreated, message.GetResponseMessage(message.SuccessCreated, userEntityName), nil)
 }
 
 func (u *userUsecase) GetUserByID(ctx context.Context, id uuid.UUID) dto.ResUserSingle {
+	ctx, span := tracing.Start(ctx, "userUsecase.GetUserByID")
+	defer span.End()
+
 	u.logger.InfoContext(ctx, "getting user by id", "id", id)
 	select {
 	case <-ctx.Done():
 		u.logger.ErrorContext(ctx, "GetUserByID: context done", "id", id, "error", ctx.Err().Error())
-		return u.userSerializer.EntityToResponseSingle(nil, http.StatusInternalServerError, message.GetResponseMessage(message.FailedGetByID, userEntityName), errorhelper.ComposeStacktrace(ctx.Err()))
+		return u.single(nil, http.StatusInternalServerError, message.GetResponseMessage(message.FailedGetByID, userEntityName), ctx.Err())
 	default:
 	}
 
 	if id == uuid.Nil {
 		u.logger.ErrorContext(ctx, "GetUserByID: id is nil")
-		return u.userSerializer.EntityToResponseSingle(nil, http.StatusBadRequest, message.GetResponseMessage(message.FailedGetByID, userEntityName), errorhelper.ComposeStacktrace(errors.New("id is nil")))
+		return u.single(nil, http.StatusBadRequest, message.GetResponseMessage(message.FailedGetByID, userEntityName), errors.New("id is nil"))
 	}
 
 	output, err := u.userRepository.GetByID(ctx, id)
 	if err != nil {
 		u.logger.ErrorContext(ctx, "GetUserByID: error getting user by id", "id", id, "error", err.Error())
-		return u.userSerializer.EntityToResponseSingle(nil, http.StatusInternalServerError, err.Error(), errorhelper.ComposeStacktrace(err))
+		err = u.responseError(err)
+		return u.single(nil, http.StatusInternalServerError, err.Error(), err)
 	}
 
-	u.logger.InfoContext(ctx, "user got by id", "user", output)
-	return u.userSerializer.EntityToResponseSingle(output, http.StatusOK, message.GetResponseMessage(message.SuccessGetByID, userEntityName), nil)
+	u.logger.InfoContext(ctx, "user got by id", "id", id)
+	return u.single(output, http.StatusOK, message.GetResponseMessage(message.SuccessGetByID, userEntityName), nil)
 }
 
 func (u *userUsecase) GetUsersByFilter(ctx context.Context, filter *dto.ReqGetUser) dto.ResUserList {
+	ctx, span := tracing.Start(ctx, "userUsecase.GetUsersByFilter")
+	defer span.End()
+
 	u.logger.InfoContext(ctx, "getting users by filter", "filter", filter)
 	select {
 	case <-ctx.Done():
 		u.logger.ErrorContext(ctx, "GetUsersByFilter: context done", "filter", filter, "error", ctx.Err().Error())
-		return u.userSerializer.EntityToResponseList(nil, entitybase.BasePaginationResult{}, http.StatusInternalServerError, message.GetResponseMessage(message.FailedList, userEntityName), errorhelper.ComposeStacktrace(ctx.Err()))
+		return u.list(nil, entitybase.BasePaginationResult{}, http.StatusInternalServerError, message.GetResponseMessage(message.FailedList, userEntityName), ctx.Err())
 	default:
 	}
 
 	if filter == nil {
 		u.logger.ErrorContext(ctx, "GetUsersByFilter: filter is nil")
-		return u.userSerializer.EntityToResponseList(nil, entitybase.BasePaginationResult{}, http.StatusBadRequest, message.GetResponseMessage(message.FailedList, userEntityName), errorhelper.ComposeStacktrace(errors.New("filter is nil")))
+		return u.list(nil, entitybase.BasePaginationResult{}, http.StatusBadRequest, message.GetResponseMessage(message.FailedList, userEntityName), errors.New("filter is nil"))
 	}
 
 	userFilter, err := u.userSerializer.FilterDTOToEntity(*filter)
 	if err != nil {
 		u.logger.ErrorContext(ctx, "GetUsersByFilter: error converting filter to entity", "filter", filter, "error", err.Error())
-		return u.userSerializer.EntityToResponseList(nil, entitybase.BasePaginationResult{}, http.StatusInternalServerError, err.Error(), errorhelper.ComposeStacktrace(err))
+		return u.list(nil, entitybase.BasePaginationResult{}, http.StatusInternalServerError, err.Error(), err)
 	}
 
 	output, paginationResult, err := u.userRepository.GetByFilter(ctx, &userFilter)
 	if err != nil {
 		u.logger.ErrorContext(ctx, "GetUsersByFilter: error getting users by filter", "filter", filter, "error", err.Error())
-		return u.userSerializer.EntityToResponseList(nil, entitybase.BasePaginationResult{}, http.StatusInternalServerError, err.Error(), errorhelper.ComposeStacktrace(err))
+		err = u.responseError(err)
+		return u.list(nil, entitybase.BasePaginationResult{}, http.StatusInternalServerError, err.Error(), err)
 	}
 
-	u.logger.InfoContext(ctx, "users got by filter", "users", output)
-	return u.userSerializer.EntityToResponseList(output, paginationResult, http.StatusOK, message.GetResponseMessage(message.SuccessList, userEntityName), nil)
+	u.logger.InfoContext(ctx, "users got by filter", "count", len(output))
+	return u.list(output, paginationResult, http.StatusOK, message.GetResponseMessage(message.SuccessList, userEntityName), nil)
 }
 
 func (u *userUsecase) UpdateUser(ctx context.Context, id uuid.UUID, req *dto.ReqUpdateUser) dto.ResUserSingle {
-	u.logger.InfoContext(ctx, "updating user", "id", id, "req", req)
+	ctx, span := tracing.Start(ctx, "userUsecase.UpdateUser")
+	defer span.End()
+
+	u.logger.InfoContext(ctx, "updating user", "id", id)
 	select {
 	case <-ctx.Done():
-		u.logger.ErrorContext(ctx, "UpdateUser: context done", "id", id, "req", req, "error", ctx.Err().Error())
-		return u.userSerializer.EntityToResponseSingle(nil, http.StatusInternalServerError, message.GetResponseMessage(message.FailedUpdated, userEntityName), errorhelper.ComposeStacktrace(ctx.Err()))
+		u.logger.ErrorContext(ctx, "UpdateUser: context done", "id", id, "error", ctx.Err().Error())
+		return u.single(nil, http.StatusInternalServerError, message.GetResponseMessage(message.FailedUpdated, userEntityName), ctx.Err())
 	default:
 	}
 
 	if id == uuid.Nil {
 		u.logger.ErrorContext(ctx, "UpdateUser: id is nil")
-		return u.userSerializer.EntityToResponseSingle(nil, http.StatusBadRequest, message.GetResponseMessage(message.FailedUpdated, userEntityName), errorhelper.ComposeStacktrace(errors.New("id is nil")))
+		return u.single(nil, http.StatusBadRequest, message.GetResponseMessage(message.FailedUpdated, userEntityName), errors.New("id is nil"))
 	}
 
 	if req == nil {
 		u.logger.ErrorContext(ctx, "UpdateUser: request is nil")
-		return u.userSerializer.EntityToResponseSingle(nil, http.StatusBadRequest, message.GetResponseMessage(message.FailedUpdated, userEntityName), errorhelper.ComposeStacktrace(errors.New("request is nil")))
+		return u.single(nil, http.StatusBadRequest, message.GetResponseMessage(message.FailedUpdated, userEntityName), errors.New("request is nil"))
 	}
 
 	updateMap, err := u.userSerializer.UpdateDTOToMap(*req)
 	if err != nil {
-		u.logger.ErrorContext(ctx, "UpdateUser: error converting update to map", "req", req, "error", err.Error())
-		return u.userSerializer.EntityToResponseSingle(nil, http.StatusInternalServerError, err.Error(), errorhelper.ComposeStacktrace(err))
+		u.logger.ErrorContext(ctx, "UpdateUser: error converting update to map", "error", err.Error())
+		return u.single(nil, http.StatusInternalServerError, err.Error(), err)
 	}
 
-	output, err := u.userRepository.Update(ctx, id, updateMap)
+	output, err := u.updateUser(ctx, id, updateMap)
 	if err != nil {
-		u.logger.ErrorContext(ctx, "UpdateUser: error updating user", "id", id, "req", req, "error", err.Error())
-		return u.userSerializer.EntityToResponseSingle(nil, http.StatusInternalServerError, err.Error(), errorhelper.ComposeStacktrace(err))
+		u.logger.ErrorContext(ctx, "UpdateUser: error updating user", "id", id, "error", err.Error())
+		err = u.responseError(err)
+		return u.single(nil, http.StatusInternalServerError, err.Error(), err)
 	}
 
-	u.logger.InfoContext(ctx, "user updated", "user", output)
-	return u.userSerializer.EntityToResponseSingle(output, http.StatusOK, message.GetResponseMessage(message.SuccessUpdated, userEntityName), nil)
+	u.logger.InfoContext(ctx, "user updated", "id", id)
+	return u.single(output, http.StatusOK, message.GetResponseMessage(message.SuccessUpdated, userEntityName), nil)
 }
 
 func (u *userUsecase) DeleteUser(ctx context.Context, id uuid.UUID) dtobase.BaseRes {
+	ctx, span := tracing.Start(ctx, "userUsecase.DeleteUser")
+	defer span.End()
+
 	u.logger.InfoContext(ctx, "deleting user", "id", id)
 	select {
 	case <-ctx.Done():
@@ -176,7 +229,7 @@ func (u *userUsecase) DeleteUser(ctx context.Context, id uuid.UUID) dtobase.Base
 		return dtobase.BaseRes{Success: false, Code: http.StatusBadRequest, Message: message.GetResponseMessage(message.FailedDeleted, userEntityName)}
 	}
 
-	err := u.userRepository.Delete(ctx, id)
+	err := u.deleteUser(ctx, id)
 	if err != nil {
 		u.logger.ErrorContext(ctx, "DeleteUser: error deleting user", "id", id, "error", err.Error())
 		return dtobase.BaseRes{Success: false, Code: http.StatusInternalServerError, Message: message.GetResponseMessage(message.FailedDeleted, userEntityName)}
@@ -185,3 +238,55 @@ func (u *userUsecase) DeleteUser(ctx context.Context, id uuid.UUID) dtobase.Base
 	u.logger.InfoContext(ctx, "user deleted", "id", id)
 	return dtobase.BaseRes{Success: true, Code: http.StatusOK, Message: message.GetResponseMessage(message.SuccessDeleted, userEntityName)}
 }
+
+func (u *userUsecase) single(user *entity.User, code int, message string, err error) dto.ResUserSingle {
+	var data *dto.ResUser
+	if user != nil {
+		res := u.userSerializer.EntityToResponse(*user)
+		data = &res
+	}
+
+	return dto.ResUserSingle{
+		BaseRes: dtobase.BaseRes{
+			Success:    code >= http.StatusOK && code < http.StatusMultipleChoices,
+			Code:       code,
+			Message:    message,
+			Stacktrace: errorhelper.ComposeStacktrace(err),
+		},
+		Data: data,
+	}
+}
+
+func (u *userUsecase) list(users []entity.User, pagination entitybase.BasePaginationResult, code int, message string, err error) dto.ResUserList {
+	responses := make([]dto.ResUser, len(users))
+	for i, user := range users {
+		responses[i] = u.userSerializer.EntityToResponse(user)
+	}
+
+	return dto.ResUserList{
+		BaseResPagination: dtobase.BaseResPagination{
+			BaseRes: dtobase.BaseRes{
+				Success:    code >= http.StatusOK && code < http.StatusMultipleChoices,
+				Code:       code,
+				Message:    message,
+				Stacktrace: errorhelper.ComposeStacktrace(err),
+			},
+			Page: dtobase.BasePagination{
+				Offset:  pagination.Offset,
+				Limit:   pagination.Limit,
+				Count:   pagination.Count,
+				OrderBy: pagination.OrderBy,
+			},
+		},
+		Data: responses,
+	}
+}
+
+// responseError returns the error answered for a repository error, a duplicate key gets a fixed error
+// as the one of postgres names the constraint and the SQLSTATE, in its message and in the stacktrace
+func (u *userUsecase) responseError(err error) error {
+	if databasehelper.IsUniqueViolation(err) {
+		return errors.New(message.GetResponseMessage(message.FailedDuplicate, userEntityName))
+	}
+	return err
+}
@@ -0,0 +1,276 @@
+package userusecaseimplementation_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alxhtp/monogo/config"
+	outboxrepositorymemory "github.com/alxhtp/monogo/internal/repository/outbox/memory"
+	transactionrepositorymemory "github.com/alxhtp/monogo/internal/repository/transaction/memory"
+	userrepositorymemory "github.com/alxhtp/monogo/internal/repository/user/memory"
+	userserializerimplementation "github.com/alxhtp/monogo/internal/serializer/user/implementation"
+	userusecaseimplementation "github.com/alxhtp/monogo/internal/usecase/user/implementation"
+	"github.com/alxhtp/monogo/pkg/dto"
+	"github.com/alxhtp/monogo/pkg/event"
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestUserUsecaseOverMemoryRepositories(t *testing.T) {
+	ctx := context.Background()
+	outbox := outboxrepositorymemory.NewOutboxRepository()
+	usecase := userusecaseimplementation.NewUserUsecase(
+		userrepositorymemory.NewUserRepository(),
+		outbox,
+		transactionrepositorymemory.NewTransactionRepository(),
+		userserializerimplementation.NewUserSerializer(),
+		nil,
+		config.UserImportConfig{},
+		config.UserEventsConfig{},
+	)
+
+	req := &dto.ReqCreateUser{Name: "Alice", Email: "alice@example.com", Metadata: dto.UserMetadata{Sex: "female", Address: "1 Main St", Phone: "+14155550101"}}
+	created := usecase.CreateUser(ctx, req)
+	if created.Code != http.StatusCreated || created.Data == nil {
+		t.Fatalf("create: %d %s", created.Code, created.Message)
+	}
+
+	// repository errors answer 500, a duplicate key with a message that does not name the constraint
+	if res := usecase.CreateUser(ctx, req); res.Code != http.StatusInternalServerError || res.Message != "A unique field is already taken by another user" {
+		t.Fatalf("create with a duplicate email: %d %s", res.Code, res.Message)
+	}
+	if res := usecase.GetUserByID(ctx, uuid.New()); res.Code != http.StatusInternalServerError {
+		t.Fatalf("get a missing user: %d %s, want %d", res.Code, res.Message, http.StatusInternalServerError)
+	}
+
+	name := "ali"
+	list := usecase.GetUsersByFilter(ctx, &dto.ReqGetUser{Name: &name})
+	if list.Code != http.StatusOK || len(list.Data) != 1 || list.Data[0].ID != created.Data.ID {
+		t.Fatalf("get by filter: %d %s, %+v", list.Code, list.Message, list.Data)
+	}
+
+	if res := usecase.DeleteUser(ctx, created.Data.ID); res.Code != http.StatusOK {
+		t.Fatalf("delete: %d %s", res.Code, res.Message)
+	}
+
+	pending, err := outbox.GetPending(ctx, 10)
+	if err != nil {
+		t.Fatalf("get pending: %v", err)
+	}
+	if len(pending) != 1 || pending[0].EventType != string(event.UserCreatedType) {
+		t.Fatalf("pending events = %+v, want the head of the user, user.created", pending)
+	}
+}
+
+func TestUserUsecaseSpansAreNamedAfterItsMethods(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(provider) })
+
+	ctx := context.Background()
+	usecase := userusecaseimplementation.NewUserUsecase(
+		userrepositorymemory.NewUserRepository(),
+		outboxrepositorymemory.NewOutboxRepository(),
+		transactionrepositorymemory.NewTransactionRepository(),
+		userserializerimplementation.NewUserSerializer(),
+		nil,
+		config.UserImportConfig{},
+		config.UserEventsConfig{},
+	)
+
+	created := usecase.CreateUser(ctx, &dto.ReqCreateUser{Name: "Alice", Email: "alice@example.com", Metadata: dto.UserMetadata{Sex: "female", Address: "1 Main St", Phone: "+14155550101"}})
+	if created.Data == nil {
+		t.Fatalf("create: %d %s", created.Code, created.Message)
+	}
+	usecase.GetUserByID(ctx, created.Data.ID)
+	usecase.GetUsersByFilter(ctx, &dto.ReqGetUser{})
+	usecase.UpdateUser(ctx, created.Data.ID, &dto.ReqUpdateUser{})
+	usecase.DeleteUser(ctx, created.Data.ID)
+
+	var names []string
+	for _, span := range recorder.Ended() {
+		names = append(names, span.Name())
+	}
+	want := []string{"userUsecase.CreateUser", "userUsecase.GetUserByID", "userUsecase.GetUsersByFilter", "userUsecase.UpdateUser", "userUsecase.DeleteUser"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Fatalf("spans = %v, want %v", names, want)
+	}
+}
+
+// closedSubscriber has no live messages, streams end after their replay
+type closedSubscriber struct{}
+
+func (closedSubscriber) Name() string { return "closed" }
+
+func (closedSubscriber) Subscribe() (<-chan event.Message, func()) {
+	messages := make(chan event.Message)
+	close(messages)
+	return messages, func() {}
+}
+
+func (closedSubscriber) Run(ctx context.Context) { <-ctx.Done() }
+
+func TestStreamUserEventsReplaysLatePublishedEvents(t *testing.T) {
+	ctx := context.Background()
+	outbox := outboxrepositorymemory.NewOutboxRepository()
+	usecase := userusecaseimplementation.NewUserUsecase(
+		userrepositorymemory.NewUserRepository(),
+		outbox,
+		transactionrepositorymemory.NewTransactionRepository(),
+		userserializerimplementation.NewUserSerializer(),
+		closedSubscriber{},
+		config.UserImportConfig{},
+		config.UserEventsConfig{UserEventsReplayLimit: 100},
+	)
+
+	for _, email := range []string{"alice@example.com", "bob@example.com"} {
+		req := &dto.ReqCreateUser{Name: "User", Email: email, Metadata: dto.UserMetadata{Sex: "female", Address: "1 Main St", Phone: "+14155550101"}}
+		if res := usecase.CreateUser(ctx, req); res.Code != http.StatusCreated {
+			t.Fatalf("create %s: %d %s", email, res.Code, res.Message)
+		}
+	}
+
+	// event 1 fails and is published after event 2, a client streaming event 2 must still receive event 1
+	publish := func(id int64) {
+		if err := outbox.Claim(ctx, []int64{id}, time.Now()); err != nil {
+			t.Fatalf("claim %d: %v", id, err)
+		}
+		if err := outbox.MarkPublished(ctx, id); err != nil {
+			t.Fatalf("mark %d published: %v", id, err)
+		}
+	}
+	publish(2)
+	publish(1)
+
+	lastEventID := int64(2)
+	events, res := usecase.StreamUserEvents(ctx, &dto.ReqGetUserEvent{LastEventID: &lastEventID})
+	if !res.Success {
+		t.Fatalf("stream: %d %s", res.Code, res.Message)
+	}
+
+	var ids []int64
+	for evt := range events {
+		ids = append(ids, evt.ID)
+	}
+	if len(ids) != 1 || ids[0] != 1 {
+		t.Fatalf("replayed events = %v, want [1]", ids)
+	}
+}
+
+// liveSubscriber sends its messages then closes the stream
+type liveSubscriber []event.Message
+
+func (liveSubscriber) Name() string { return "live" }
+
+func (s liveSubscriber) Subscribe() (<-chan event.Message, func()) {
+	messages := make(chan event.Message, len(s))
+	for _, msg := range s {
+		messages <- msg
+	}
+	close(messages)
+	return messages, func() {}
+}
+
+func (liveSubscriber) Run(ctx context.Context) { <-ctx.Done() }
+
+func TestStreamUserEventsSkipsLiveMessagesAlreadyReplayed(t *testing.T) {
+	ctx := context.Background()
+	outbox := outboxrepositorymemory.NewOutboxRepository()
+	live := func(id int64) event.Message {
+		return event.Message{ID: id, Type: event.UserCreatedType, AggregateType: event.UserAggregateType, Payload: []byte(`{"user":{"name":"User"}}`)}
+	}
+	usecase := userusecaseimplementation.NewUserUsecase(
+		userrepositorymemory.NewUserRepository(),
+		outbox,
+		transactionrepositorymemory.NewTransactionRepository(),
+		userserializerimplementation.NewUserSerializer(),
+		liveSubscriber{live(2), live(3)},
+		config.UserImportConfig{},
+		config.UserEventsConfig{UserEventsReplayLimit: 100},
+	)
+
+	for _, email := range []string{"alice@example.com", "bob@example.com"} {
+		req := &dto.ReqCreateUser{Name: "User", Email: email, Metadata: dto.UserMetadata{Sex: "female", Address: "1 Main St", Phone: "+14155550101"}}
+		if res := usecase.CreateUser(ctx, req); res.Code != http.StatusCreated {
+			t.Fatalf("create %s: %d %s", email, res.Code, res.Message)
+		}
+	}
+	for _, id := range []int64{1, 2} {
+		if err := outbox.Claim(ctx, []int64{id}, time.Now()); err != nil {
+			t.Fatalf("claim %d: %v", id, err)
+		}
+		if err := outbox.MarkPublished(ctx, id); err != nil {
+			t.Fatalf("mark %d published: %v", id, err)
+		}
+	}
+
+	// event 2 is replayed and published live while replaying, it is sent once
+	lastEventID := int64(1)
+	events, res := usecase.StreamUserEvents(ctx, &dto.ReqGetUserEvent{LastEventID: &lastEventID})
+	if !res.Success {
+		t.Fatalf("stream: %d %s", res.Code, res.Message)
+	}
+
+	var ids []int64
+	for evt := range events {
+		ids = append(ids, evt.ID)
+	}
+	if len(ids) != 2 || ids[0] != 2 || ids[1] != 3 {
+		t.Fatalf("streamed events = %v, want [2 3]", ids)
+	}
+}
+
+func TestImportJobsRunAsTheirCaller(t *testing.T) {
+	outbox := outboxrepositorymemory.NewOutboxRepository()
+	usecase := userusecaseimplementation.NewUserUsecase(
+		userrepositorymemory.NewUserRepository(),
+		outbox,
+		transactionrepositorymemory.NewTransactionRepository(),
+		userserializerimplementation.NewUserSerializer(),
+		nil,
+		config.UserImportConfig{ImportMaxRows: 2, ImportAsyncThreshold: 1},
+		config.UserEventsConfig{},
+	)
+	ctx := contexthelper.WithActor(contexthelper.WithRequestID(context.Background(), "req-1"), "alice")
+
+	csv := "name,email,sex,address,phone\n" +
+		"Alice,alice@example.com,female,1 Main St,+14155550101\n" +
+		"Bob,bob@example.com,male,2 Main St,+14155550102\n"
+	res := usecase.ImportUsers(ctx, &dto.ReqImportUser{File: strings.NewReader(csv)})
+	if res.Code != http.StatusAccepted || res.Data == nil || res.Data.Job == nil {
+		t.Fatalf("import: %d %s", res.Code, res.Message)
+	}
+
+	if err := usecase.StopImportJobs(context.Background()); err != nil {
+		t.Fatalf("stop import jobs: %v", err)
+	}
+	job := usecase.GetImportJob(ctx, res.Data.Job.ID)
+	if job.Data == nil || job.Data.Status != "completed" || job.Data.Succeeded != 2 {
+		t.Fatalf("job = %+v", job.Data)
+	}
+
+	pending, err := outbox.GetPending(ctx, 10)
+	if err != nil {
+		t.Fatalf("get pending: %v", err)
+	}
+	for _, evt := range pending {
+		if evt.Actor != "alice" || evt.RequestID != "req-1" {
+			t.Fatalf("event recorded by %q in %q, want alice in req-1", evt.Actor, evt.RequestID)
+		}
+	}
+	if len(pending) != 2 {
+		t.Fatalf("got %d events, want 2", len(pending))
+	}
+
+	tooLong := csv + "Carol,carol@example.com,female,3 Main St,+14155550103\n"
+	if res := usecase.ImportUsers(ctx, &dto.ReqImportUser{File: strings.NewReader(tooLong)}); res.Code != http.StatusBadRequest || res.Message != "csv has more than 2 rows" {
+		t.Fatalf("import more than the max rows: %d %s", res.Code, res.Message)
+	}
+}
@@ -14,4 +14,14 @@ type UserUsecase interface {
 	GetUsersByFilter(ctx context.Context, filter *dto.ReqGetUser) dto.ResUserList
 	UpdateUser(ctx context.Context, id uuid.UUID, req *dto.ReqUpdateUser) dto.ResUserSingle
 	DeleteUser(ctx context.Context, id uuid.UUID) dtobase.BaseRes
+	ImportUsers(ctx context.Context, req *dto.ReqImportUser) dto.ResUserImport
+	GetImportJob(ctx context.Context, id uuid.UUID) dto.ResUserImportJobSingle
+	GetImportJobErrors(ctx context.Context, id uuid.UUID) ([]byte, dtobase.BaseRes)
+	// StopImportJobs waits for the running import jobs until ctx is done, the jobs left are then interrupted.
+	// Import jobs are kept in process memory, they are only known to the instance running them.
+	StopImportJobs(ctx context.Context) error
+	RunningImportJobs() []string
+	// StreamUserEvents returns user events matching the filter, replayed from req.LastEventID when set.
+	// The channel is closed when ctx is done or the subscription ends, the client should then resume.
+	StreamUserEvents(ctx context.Context, req *dto.ReqGetUserEvent) (<-chan dto.ResUserEvent, dtobase.BaseRes)
 }
@@ -0,0 +1,203 @@
+package webhookusecaseimplementation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/alxhtp/monogo/config"
+	entitybase "github.com/alxhtp/monogo/internal/entity/base"
+	webhookrepository "github.com/alxhtp/monogo/internal/repository/webhook"
+	webhookserializer "github.com/alxhtp/monogo/internal/serializer/webhook"
+	genericusecase "github.com/alxhtp/monogo/internal/usecase/generic"
+	genericusecaseimplementation "github.com/alxhtp/monogo/internal/usecase/generic/implementation"
+	webhookusecase "github.com/alxhtp/monogo/internal/usecase/webhook"
+	"github.com/alxhtp/monogo/pkg/constant"
+	"github.com/alxhtp/monogo/pkg/dto"
+	dtobase "github.com/alxhtp/monogo/pkg/dto/base"
+	errorhelper "github.com/alxhtp/monogo/pkg/helper/error"
+	"github.com/alxhtp/monogo/pkg/message"
+	"github.com/alxhtp/monogo/pkg/tracing"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+var (
+	webhookEntityName  = "webhook"
+	deliveryEntityName = "webhook delivery"
+)
+
+type webhookUsecase struct {
+	webhookRepository  webhookrepository.WebhookRepository
+	deliveryRepository webhookrepository.WebhookDeliveryRepository
+	webhookSerializer  webhookserializer.WebhookSerializer
+	logger             *slog.Logger
+	validator          *validator.Validate
+	crudUsecase        genericusecase.CrudUsecase[dto.ReqCreateWebhook, dto.ReqUpdateWebhook, dto.ReqGetWebhook, dto.ResWebhook]
+	maxRedeliveries    int
+}
+
+func NewWebhookUsecase(
+	webhookRepository webhookrepository.WebhookRepository,
+	deliveryRepository webhookrepository.WebhookDeliveryRepository,
+	webhookSerializer webhookserializer.WebhookSerializer,
+	cfg config.WebhookConfig,
+) webhookusecase.WebhookUsecase {
+	return &webhookUsecase{
+		webhookRepository:  webhookRepository,
+		deliveryRepository: deliveryRepository,
+		webhookSerializer:  webhookSerializer,
+		logger:             slog.Default().With("usecase", webhookEntityName),
+		validator:          validator.New(validator.WithRequiredStructEnabled()),
+		crudUsecase:        genericusecaseimplementation.NewCrudUsecase(webhookEntityName, webhookRepository, webhookSerializer),
+		maxRedeliveries:    cfg.WebhookMaxRedeliveries,
+	}
+}
+
+func (u *webhookUsecase) CreateWebhook(ctx context.Context, req *dto.ReqCreateWebhook) dto.ResWebhookSingle {
+	ctx, span := tracing.Start(ctx, "webhookUsecase.CreateWebhook")
+	defer span.End()
+
+	u.logger.InfoContext(ctx, "creating webhook")
+	select {
+	case <-ctx.Done():
+		u.logger.ErrorContext(ctx, "CreateWebhook: context done", "error", ctx.Err().Error())
+		return u.webhookSerializer.EntityToResponseSingle(nil, http.StatusInternalServerError, message.GetResponseMessage(message.FailedCreated, webhookEntityName), errorhelper.ComposeStacktrace(ctx.Err()))
+	default:
+	}
+
+	if req == nil {
+		u.logger.ErrorContext(ctx, "CreateWebhook: request is nil")
+		return u.webhookSerializer.EntityToResponseSingle(nil, http.StatusBadRequest, message.GetResponseMessage(message.FailedCreated, webhookEntityName), errorhelper.ComposeStacktrace(errors.New("request is nil")))
+	}
+
+	if err := u.validator.Struct(req); err != nil {
+		u.logger.ErrorContext(ctx, "CreateWebhook: request validation failed", "error", err.Error())
+		return u.webhookSerializer.EntityToResponseSingle(nil, http.StatusBadRequest, err.Error(), errorhelper.ComposeStacktrace(err))
+	}
+
+	webhook, err := u.webhookSerializer.CreateDTOToEntity(*req)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "CreateWebhook: error converting request to entity", "error", err.Error())
+		return u.webhookSerializer.EntityToResponseSingle(nil, http.StatusInternalServerError, err.Error(), errorhelper.ComposeStacktrace(err))
+	}
+
+	output, err := u.webhookRepository.Create(ctx, &webhook)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "CreateWebhook: error creating webhook", "error", err.Error())
+		return u.webhookSerializer.EntityToResponseSingle(nil, http.StatusInternalServerError, err.Error(), errorhelper.ComposeStacktrace(err))
+	}
+
+	u.logger.InfoContext(ctx, "webhook created", "id", output.ID)
+	res := u.webhookSerializer.EntityToResponseSingle(output, http.StatusCreated, message.GetResponseMessage(message.SuccessCreated, webhookEntityName), nil)
+	// the secret is only shown once, clients need it to verify signatures
+	res.Data.Secret = output.Secret
+	return res
+}
+
+func (u *webhookUsecase) GetWebhookByID(ctx context.Context, id uuid.UUID) dto.ResWebhookSingle {
+	return dto.ResWebhookSingle(u.crudUsecase.GetByID(ctx, id))
+}
+
+func (u *webhookUsecase) GetWebhooksByFilter(ctx context.Context, filter *dto.ReqGetWebhook) dto.ResWebhookList {
+	return dto.ResWebhookList(u.crudUsecase.GetByFilter(ctx, filter))
+}
+
+func (u *webhookUsecase) UpdateWebhook(ctx context.Context, id uuid.UUID, req *dto.ReqUpdateWebhook) dto.ResWebhookSingle {
+	return dto.ResWebhookSingle(u.crudUsecase.Update(ctx, id, req))
+}
+
+func (u *webhookUsecase) DeleteWebhook(ctx context.Context, id uuid.UUID) dtobase.BaseRes {
+	return u.crudUsecase.Delete(ctx, id)
+}
+
+func (u *webhookUsecase) GetDeliveriesByFilter(ctx context.Context, webhookID uuid.UUID, filter *dto.ReqGetWebhookDelivery) dto.ResWebhookDeliveryList {
+	ctx, span := tracing.Start(ctx, "webhookUsecase.GetDeliveriesByFilter")
+	defer span.End()
+
+	u.logger.InfoContext(ctx, "getting webhook deliveries by filter", "webhook_id", webhookID, "filter", filter)
+	select {
+	case <-ctx.Done():
+		u.logger.ErrorContext(ctx, "GetDeliveriesByFilter: context done", "webhook_id", webhookID, "error", ctx.Err().Error())
+		return u.webhookSerializer.DeliveryEntityToResponseList(nil, entitybase.BasePaginationResult{}, http.StatusInternalServerError, message.GetResponseMessage(message.FailedList, deliveryEntityName), errorhelper.ComposeStacktrace(ctx.Err()))
+	default:
+	}
+
+	if webhookID == uuid.Nil {
+		u.logger.ErrorContext(ctx, "GetDeliveriesByFilter: webhook id is nil")
+		return u.webhookSerializer.DeliveryEntityToResponseList(nil, entitybase.BasePaginationResult{}, http.StatusBadRequest, message.GetResponseMessage(message.FailedList, deliveryEntityName), errorhelper.ComposeStacktrace(errors.New("webhook id is nil")))
+	}
+
+	if filter == nil {
+		filter = &dto.ReqGetWebhookDelivery{}
+	}
+
+	deliveryFilter, err := u.webhookSerializer.DeliveryFilterDTOToEntity(*filter)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "GetDeliveriesByFilter: error converting filter to entity", "filter", filter, "error", err.Error())
+		return u.webhookSerializer.DeliveryEntityToResponseList(nil, entitybase.BasePaginationResult{}, http.StatusBadRequest, err.Error(), errorhelper.ComposeStacktrace(err))
+	}
+	deliveryFilter.WebhookID = &webhookID
+
+	output, paginationResult, err := u.deliveryRepository.GetByFilter(ctx, &deliveryFilter)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "GetDeliveriesByFilter: error getting deliveries by filter", "webhook_id", webhookID, "error", err.Error())
+		return u.webhookSerializer.DeliveryEntityToResponseList(nil, entitybase.BasePaginationResult{}, http.StatusInternalServerError, err.Error(), errorhelper.ComposeStacktrace(err))
+	}
+
+	u.logger.InfoContext(ctx, "webhook deliveries got by filter", "webhook_id", webhookID, "count", len(output))
+	return u.webhookSerializer.DeliveryEntityToResponseList(output, paginationResult, http.StatusOK, message.GetResponseMessage(message.SuccessList, deliveryEntityName), nil)
+}
+
+func (u *webhookUsecase) Redeliver(ctx context.Context, webhookID uuid.UUID, deliveryID uuid.UUID) dto.ResWebhookDeliverySingle {
+	ctx, span := tracing.Start(ctx, "webhookUsecase.Redeliver")
+	defer span.End()
+
+	u.logger.InfoContext(ctx, "redelivering webhook delivery", "webhook_id", webhookID, "delivery_id", deliveryID)
+	select {
+	case <-ctx.Done():
+		u.logger.ErrorContext(ctx, "Redeliver: context done", "delivery_id", deliveryID, "error", ctx.Err().Error())
+		return u.webhookSerializer.DeliveryEntityToResponseSingle(nil, http.StatusInternalServerError, message.GetResponseMessage(message.FailedUpdated, deliveryEntityName), errorhelper.ComposeStacktrace(ctx.Err()))
+	default:
+	}
+
+	if webhookID == uuid.Nil || deliveryID == uuid.Nil {
+		u.logger.ErrorContext(ctx, "Redeliver: id is nil")
+		return u.webhookSerializer.DeliveryEntityToResponseSingle(nil, http.StatusBadRequest, message.GetResponseMessage(message.FailedUpdated, deliveryEntityName), errorhelper.ComposeStacktrace(errors.New("id is nil")))
+	}
+
+	delivery, err := u.deliveryRepository.GetByID(ctx, deliveryID)
+	if err != nil {
+		u.logger.ErrorContext(ctx, "Redeliver: error getting delivery by id", "delivery_id", deliveryID, "error", err.Error())
+		return u.webhookSerializer.DeliveryEntityToResponseSingle(nil, http.StatusInternalServerError, err.Error(), errorhelper.ComposeStacktrace(err))
+	}
+
+	if delivery.WebhookID != webhookID {
+		u.logger.ErrorContext(ctx, "Redeliver: delivery does not belong to webhook", "webhook_id", webhookID, "delivery_id", deliveryID)
+		return u.webhookSerializer.DeliveryEntityToResponseSingle(nil, http.StatusNotFound, message.GetResponseMessage(message.FailedGetByID, deliveryEntityName), errorhelper.ComposeStacktrace(errors.New("delivery does not belong to webhook")))
+	}
+
+	// every redelivery grants a new round of attempts, so redeliveries are bounded
+	if delivery.Redeliveries >= u.maxRedeliveries {
+		err := fmt.Errorf("delivery was redelivered %d times, maximum is %d", delivery.Redeliveries, u.maxRedeliveries)
+		u.logger.ErrorContext(ctx, "Redeliver: too many redeliveries", "delivery_id", deliveryID, "redeliveries", delivery.Redeliveries)
+		return u.webhookSerializer.DeliveryEntityToResponseSingle(nil, http.StatusConflict, err.Error(), errorhelper.ComposeStacktrace(err))
+	}
+
+	output, err := u.deliveryRepository.Update(ctx, deliveryID, map[string]any{
+		"status":          constant.WebhookDeliveryStatusPending,
+		"attempts":        0,
+		"redeliveries":    delivery.Redeliveries + 1,
+		"next_attempt_at": time.Now(),
+	})
+	if err != nil {
+		u.logger.ErrorContext(ctx, "Redeliver: error updating delivery", "delivery_id", deliveryID, "error", err.Error())
+		return u.webhookSerializer.DeliveryEntityToResponseSingle(nil, http.StatusInternalServerError, err.Error(), errorhelper.ComposeStacktrace(err))
+	}
+
+	u.logger.InfoContext(ctx, "webhook delivery queued for redelivery", "delivery_id", deliveryID)
+	return u.webhookSerializer.DeliveryEntityToResponseSingle(output, http.StatusAccepted, message.GetResponseMessage(message.SuccessRedeliver, deliveryEntityName), nil)
+}
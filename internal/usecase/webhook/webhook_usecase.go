@@ -0,0 +1,20 @@
+package webhookusecase
+
+import (
+	"context"
+
+	"github.com/alxhtp/monogo/pkg/dto"
+	dtobase "github.com/alxhtp/monogo/pkg/dto/base"
+	"github.com/google/uuid"
+)
+
+type WebhookUsecase interface {
+	CreateWebhook(ctx context.Context, req *dto.ReqCreateWebhook) dto.ResWebhookSingle
+	GetWebhookByID(ctx context.Context, id uuid.UUID) dto.ResWebhookSingle
+	GetWebhooksByFilter(ctx context.Context, filter *dto.ReqGetWebhook) dto.ResWebhookList
+	UpdateWebhook(ctx context.Context, id uuid.UUID, req *dto.ReqUpdateWebhook) dto.ResWebhookSingle
+	DeleteWebhook(ctx context.Context, id uuid.UUID) dtobase.BaseRes
+	GetDeliveriesByFilter(ctx context.Context, webhookID uuid.UUID, filter *dto.ReqGetWebhookDelivery) dto.ResWebhookDeliveryList
+	// Redeliver schedules a delivery for an immediate new attempt, whatever its status
+	Redeliver(ctx context.Context, webhookID uuid.UUID, deliveryID uuid.UUID) dto.ResWebhookDeliverySingle
+}
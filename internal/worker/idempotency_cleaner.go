@@ -0,0 +1,81 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/alxhtp/monogo/config"
+	idempotencyrepository "github.com/alxhtp/monogo/internal/repository/idempotency"
+	tenantrepository "github.com/alxhtp/monogo/internal/repository/tenant"
+)
+
+const (
+	defaultIdempotencyCleanupInterval = time.Hour
+	idempotencyCleanupBatchSize       = 1000
+)
+
+// IdempotencyCleaner deletes expired idempotency keys, expired keys are already ignored when a request is received
+type IdempotencyCleaner struct {
+	idempotencyRepository idempotencyrepository.IdempotencyRepository
+	tenantRepository      tenantrepository.TenantRepository
+	interval              time.Duration
+	logger                *slog.Logger
+}
+
+func NewIdempotencyCleaner(
+	idempotencyRepository idempotencyrepository.IdempotencyRepository,
+	tenantRepository tenantrepository.TenantRepository,
+	cfg *config.IdempotencyConfig,
+) *IdempotencyCleaner {
+	interval, err := time.ParseDuration(cfg.IdempotencyCleanupInterval)
+	if err != nil || interval <= 0 {
+		interval = defaultIdempotencyCleanupInterval
+	}
+
+	return &IdempotencyCleaner{
+		idempotencyRepository: idempotencyRepository,
+		tenantRepository:      tenantRepository,
+		interval:              interval,
+		logger:                slog.Default().With("worker", "idempotency-cleaner"),
+	}
+}
+
+// Run deletes expired keys every interval until ctx is cancelled
+func (w *IdempotencyCleaner) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		total, err := eachTenant(ctx, w.tenantRepository, w.deleteExpired)
+		if err != nil && ctx.Err() == nil {
+			w.logger.ErrorContext(ctx, "delete expired idempotency keys failed", "error", err.Error())
+		}
+
+		if total > 0 {
+			w.logger.InfoContext(ctx, "expired idempotency keys deleted", "count", total)
+		}
+	}
+}
+
+// deleteExpired deletes the expired keys in batches, it returns how many were deleted
+func (w *IdempotencyCleaner) deleteExpired(ctx context.Context) (int, error) {
+	var total int
+	for ctx.Err() == nil {
+		deleted, err := w.idempotencyRepository.DeleteExpired(ctx, idempotencyCleanupBatchSize)
+		if err != nil {
+			return total, err
+		}
+		total += int(deleted)
+		if deleted < idempotencyCleanupBatchSize {
+			break
+		}
+	}
+	return total, ctx.Err()
+}
@@ -0,0 +1,199 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/alxhtp/monogo/config"
+	"github.com/alxhtp/monogo/internal/entity"
+	"github.com/alxhtp/monogo/internal/publisher"
+	outboxrepository "github.com/alxhtp/monogo/internal/repository/outbox"
+	tenantrepository "github.com/alxhtp/monogo/internal/repository/tenant"
+	transactionrepository "github.com/alxhtp/monogo/internal/repository/transaction"
+	"github.com/alxhtp/monogo/pkg/event"
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+)
+
+const (
+	defaultOutboxPollInterval = time.Second
+	defaultOutboxMaxBackoff   = 5 * time.Minute
+	defaultOutboxBatchSize    = 100
+	defaultOutboxClaimTimeout = time.Minute
+)
+
+// OutboxRelay publishes outbox messages. Relays across instances claim batches one at a time and publish them
+// outside of any transaction, messages of the same aggregate are published in order and retried with exponential backoff.
+// A claimed message whose result is not recorded, e.g. the relay stopped, is published again once its claim expires.
+type OutboxRelay struct {
+	outboxRepository      outboxrepository.OutboxRepository
+	transactionRepository transactionrepository.TransactionRepository
+	publisher             publisher.Publisher
+	tenantRepository      tenantrepository.TenantRepository
+	pollInterval          time.Duration
+	maxBackoff            time.Duration
+	claimTimeout          time.Duration
+	batchSize             int
+	logger                *slog.Logger
+}
+
+func NewOutboxRelay(
+	outboxRepository outboxrepository.OutboxRepository,
+	transactionRepository transactionrepository.TransactionRepository,
+	publisher publisher.Publisher,
+	tenantRepository tenantrepository.TenantRepository,
+	cfg *config.OutboxConfig,
+) *OutboxRelay {
+	pollInterval, err := time.ParseDuration(cfg.OutboxPollInterval)
+	if err != nil || pollInterval <= 0 {
+		pollInterval = defaultOutboxPollInterval
+	}
+
+	maxBackoff, err := time.ParseDuration(cfg.OutboxMaxBackoff)
+	if err != nil || maxBackoff <= 0 {
+		maxBackoff = defaultOutboxMaxBackoff
+	}
+
+	claimTimeout, err := time.ParseDuration(cfg.OutboxClaimTimeout)
+	if err != nil || claimTimeout <= 0 {
+		claimTimeout = defaultOutboxClaimTimeout
+	}
+
+	batchSize := cfg.OutboxBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultOutboxBatchSize
+	}
+
+	return &OutboxRelay{
+		outboxRepository:      outboxRepository,
+		transactionRepository: transactionRepository,
+		publisher:             publisher,
+		tenantRepository:      tenantRepository,
+		pollInterval:          pollInterval,
+		maxBackoff:            maxBackoff,
+		claimTimeout:          claimTimeout,
+		batchSize:             batchSize,
+		logger:                slog.Default().With("worker", "outbox-relay", "publisher", publisher.Name()),
+	}
+}
+
+// Run relays messages until ctx is cancelled, the outbox of every tenant when tenantRepository is set
+func (r *OutboxRelay) Run(ctx context.Context) {
+	r.logger.InfoContext(ctx, "outbox relay started", "poll_interval", r.pollInterval.String())
+	defer r.logger.InfoContext(ctx, "outbox relay stopped")
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		published, err := eachTenant(ctx, r.tenantRepository, r.relayBatch)
+		if err != nil && ctx.Err() == nil {
+			r.logger.ErrorContext(ctx, "relay batch failed", "error", err.Error())
+		}
+
+		// drain without waiting while the outbox has due messages
+		if published > 0 {
+			timer.Reset(0)
+		} else {
+			timer.Reset(r.pollInterval)
+		}
+	}
+}
+
+func (r *OutboxRelay) relayBatch(ctx context.Context) (published int, err error) {
+	messages, err := r.claimBatch(ctx)
+	if err != nil || len(messages) == 0 {
+		return 0, err
+	}
+
+	// a slow publisher must not hold a transaction nor the relay lock, messages are published after the claim commits
+	results := make([]error, 0, len(messages))
+	for i := range messages {
+		if ctx.Err() != nil {
+			break
+		}
+		results = append(results, r.publisher.Publish(ctx, toEventMessage(ctx, messages[i])))
+	}
+
+	// the results of a stopping relay are still recorded, unrecorded messages are published again once their claim expires
+	err = r.transactionRepository.WithTransaction(context.WithoutCancel(ctx), func(ctx context.Context) error {
+		for i, publishErr := range results {
+			msg := messages[i]
+			if publishErr != nil {
+				nextAttemptAt := time.Now().Add(r.backoff(msg.Attempts))
+				r.logger.WarnContext(ctx, "publish failed", "id", msg.ID, "type", msg.EventType, "attempts", msg.Attempts+1, "next_attempt_at", nextAttemptAt, "error", publishErr.Error())
+				if err := r.outboxRepository.MarkFailed(ctx, msg.ID, publishErr.Error(), nextAttemptAt); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := r.outboxRepository.MarkPublished(ctx, msg.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, publishErr := range results {
+		if publishErr == nil {
+			published++
+		}
+	}
+	return published, nil
+}
+
+// claimBatch takes the due messages for claimTimeout under the relay lock, nil when another relay holds the lock
+func (r *OutboxRelay) claimBatch(ctx context.Context) (messages []entity.OutboxMessage, err error) {
+	err = r.transactionRepository.WithTransaction(ctx, func(ctx context.Context) error {
+		acquired, err := r.outboxRepository.AcquireRelayLock(ctx)
+		if err != nil || !acquired {
+			return err
+		}
+
+		if messages, err = r.outboxRepository.GetPending(ctx, r.batchSize); err != nil || len(messages) == 0 {
+			return err
+		}
+
+		ids := make([]int64, len(messages))
+		for i := range messages {
+			ids[i] = messages[i].ID
+		}
+		return r.outboxRepository.Claim(ctx, ids, time.Now().Add(r.claimTimeout))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+func (r *OutboxRelay) backoff(attempts int) time.Duration {
+	backoff := r.pollInterval
+	for i := 0; i < attempts && backoff < r.maxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, r.maxBackoff)
+}
+
+func toEventMessage(ctx context.Context, msg entity.OutboxMessage) event.Message {
+	return event.Message{
+		ID:            msg.ID,
+		Tenant:        contexthelper.GetTenant(ctx),
+		Type:          event.Type(msg.EventType),
+		AggregateType: msg.AggregateType,
+		AggregateID:   msg.AggregateID,
+		Payload:       json.RawMessage(msg.Payload.Item),
+		OccurredAt:    msg.CreatedAt,
+	}
+}
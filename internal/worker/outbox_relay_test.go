@@ -0,0 +1,104 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alxhtp/monogo/config"
+	outboxrepositorymemory "github.com/alxhtp/monogo/internal/repository/outbox/memory"
+	"github.com/alxhtp/monogo/internal/worker"
+	"github.com/alxhtp/monogo/pkg/dto"
+	"github.com/alxhtp/monogo/pkg/event"
+	"github.com/google/uuid"
+)
+
+type inTransactionKey struct{}
+
+// transactionRepository marks the context of its transactions
+type transactionRepository struct{}
+
+func (transactionRepository) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(context.WithValue(ctx, inTransactionKey{}, true))
+}
+
+// publisher fails the messages of failing aggregates and records where it was called
+type publisher struct {
+	failing       map[string]bool
+	published     []int64
+	inTransaction bool
+}
+
+func (p *publisher) Name() string {
+	return "test"
+}
+
+func (p *publisher) Publish(ctx context.Context, msg event.Message) error {
+	if ctx.Value(inTransactionKey{}) != nil {
+		p.inTransaction = true
+	}
+	if p.failing[msg.AggregateID] {
+		return errors.New("broker is down")
+	}
+	p.published = append(p.published, msg.ID)
+	return nil
+}
+
+func TestOutboxRelayPublishesOutsideOfTransactions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	outbox := outboxrepositorymemory.NewOutboxRepository()
+	healthy, failing := uuid.New(), uuid.New()
+	if err := outbox.Enqueue(ctx,
+		event.UserCreated{User: dto.ResUser{ID: healthy}},
+		event.UserCreated{User: dto.ResUser{ID: failing}},
+		event.UserDeleted{User: dto.ResUser{ID: failing}},
+	); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	pub := &publisher{failing: map[string]bool{failing.String(): true}}
+	relay := worker.NewOutboxRelay(outbox, transactionRepository{}, pub, nil, &config.OutboxConfig{
+		OutboxPollInterval: "10ms",
+		OutboxMaxBackoff:   "1h",
+		OutboxClaimTimeout: "1h",
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		relay.Run(ctx)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		failed, err := outbox.GetByID(ctx, 2)
+		if err != nil {
+			t.Fatalf("get failed message: %v", err)
+		}
+		if failed.Attempts > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the failing message was not recorded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	published, err := outbox.GetByID(context.Background(), 1)
+	if err != nil || published.PublishedAt == nil {
+		t.Fatalf("message 1 = %+v, %v, want it published", published, err)
+	}
+	if blocked, err := outbox.GetByID(context.Background(), 3); err != nil || blocked.PublishedAt != nil || blocked.Attempts != 0 {
+		t.Fatalf("message 3 = %+v, %v, want it waiting behind message 2 of its aggregate", blocked, err)
+	}
+	if pub.inTransaction {
+		t.Fatal("messages were published inside a transaction")
+	}
+	if len(pub.published) != 1 || pub.published[0] != 1 {
+		t.Fatalf("published = %v, want [1]", pub.published)
+	}
+}
@@ -0,0 +1,41 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	tenantrepository "github.com/alxhtp/monogo/internal/repository/tenant"
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+)
+
+// eachTenant runs fn once per tenant with a context bound to that tenant, or once with ctx when tenantRepository
+// is nil because multi-tenancy is disabled. A failing tenant does not stop the others, the counts are summed.
+func eachTenant(ctx context.Context, tenantRepository tenantrepository.TenantRepository, fn func(ctx context.Context) (int, error)) (int, error) {
+	if tenantRepository == nil {
+		return fn(ctx)
+	}
+
+	tenants, err := tenantRepository.GetAll(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("get tenants: %w", err)
+	}
+
+	var (
+		total int
+		errs  []error
+	)
+	for _, tenant := range tenants {
+		if ctx.Err() != nil {
+			return total, ctx.Err()
+		}
+
+		count, err := fn(contexthelper.WithTenant(ctx, tenant.ID))
+		total += count
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", tenant.ID, err))
+		}
+	}
+
+	return total, errors.Join(errs...)
+}
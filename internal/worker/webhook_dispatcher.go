@@ -0,0 +1,289 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/alxhtp/monogo/config"
+	"github.com/alxhtp/monogo/internal/entity"
+	tenantrepository "github.com/alxhtp/monogo/internal/repository/tenant"
+	transactionrepository "github.com/alxhtp/monogo/internal/repository/transaction"
+	webhookrepository "github.com/alxhtp/monogo/internal/repository/webhook"
+	"github.com/alxhtp/monogo/pkg/constant"
+	webhookhelper "github.com/alxhtp/monogo/pkg/helper/webhook"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultWebhookMaxAttempts  = 8
+	defaultWebhookTimeout      = 10 * time.Second
+	defaultWebhookPollInterval = time.Second
+	defaultWebhookBatchSize    = 50
+	defaultWebhookBaseBackoff  = 10 * time.Second
+	defaultWebhookMaxBackoff   = time.Hour
+
+	// webhookResponseLimit caps the stored response body of a delivery attempt
+	webhookResponseLimit = 2048
+)
+
+// WebhookDispatcher sends queued webhook deliveries. Failed deliveries are retried with
+// exponential backoff and become dead after the configured number of attempts.
+type WebhookDispatcher struct {
+	webhookRepository     webhookrepository.WebhookRepository
+	deliveryRepository    webhookrepository.WebhookDeliveryRepository
+	transactionRepository transactionrepository.TransactionRepository
+	tenantRepository      tenantrepository.TenantRepository
+	client                *http.Client
+	maxAttempts           int
+	pollInterval          time.Duration
+	batchSize             int
+	baseBackoff           time.Duration
+	maxBackoff            time.Duration
+	logger                *slog.Logger
+}
+
+func NewWebhookDispatcher(
+	webhookRepository webhookrepository.WebhookRepository,
+	deliveryRepository webhookrepository.WebhookDeliveryRepository,
+	transactionRepository transactionrepository.TransactionRepository,
+	tenantRepository tenantrepository.TenantRepository,
+	cfg *config.WebhookConfig,
+) *WebhookDispatcher {
+	timeout, err := time.ParseDuration(cfg.WebhookTimeout)
+	if err != nil || timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+
+	pollInterval, err := time.ParseDuration(cfg.WebhookPollInterval)
+	if err != nil || pollInterval <= 0 {
+		pollInterval = defaultWebhookPollInterval
+	}
+
+	baseBackoff, err := time.ParseDuration(cfg.WebhookBaseBackoff)
+	if err != nil || baseBackoff <= 0 {
+		baseBackoff = defaultWebhookBaseBackoff
+	}
+
+	maxBackoff, err := time.ParseDuration(cfg.WebhookMaxBackoff)
+	if err != nil || maxBackoff <= 0 {
+		maxBackoff = defaultWebhookMaxBackoff
+	}
+
+	maxAttempts := cfg.WebhookMaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultWebhookMaxAttempts
+	}
+
+	batchSize := cfg.WebhookBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultWebhookBatchSize
+	}
+
+	return &WebhookDispatcher{
+		webhookRepository:     webhookRepository,
+		deliveryRepository:    deliveryRepository,
+		transactionRepository: transactionRepository,
+		tenantRepository:      tenantRepository,
+		client:                &http.Client{Timeout: timeout, Transport: webhookhelper.NewTransport(cfg.WebhookAllowPrivateNetworks)},
+		maxAttempts:           maxAttempts,
+		pollInterval:          pollInterval,
+		batchSize:             batchSize,
+		baseBackoff:           baseBackoff,
+		maxBackoff:            maxBackoff,
+		logger:                slog.Default().With("worker", "webhook-dispatcher"),
+	}
+}
+
+// Run dispatches deliveries until ctx is cancelled, those of every tenant when tenantRepository is set
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	d.logger.InfoContext(ctx, "webhook dispatcher started", "poll_interval", d.pollInterval.String())
+	defer d.logger.InfoContext(ctx, "webhook dispatcher stopped")
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		claimed, err := eachTenant(ctx, d.tenantRepository, d.dispatchBatch)
+		if err != nil && ctx.Err() == nil {
+			d.logger.ErrorContext(ctx, "dispatch batch failed", "error", err.Error())
+		}
+
+		// drain without waiting while deliveries are due
+		if claimed > 0 {
+			timer.Reset(0)
+		} else {
+			timer.Reset(d.pollInterval)
+		}
+	}
+}
+
+func (d *WebhookDispatcher) dispatchBatch(ctx context.Context) (int, error) {
+	deliveries, err := d.claim(ctx)
+	if err != nil || len(deliveries) == 0 {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for i := range deliveries {
+		wg.Add(1)
+		go func(delivery entity.WebhookDelivery) {
+			defer wg.Done()
+			d.dispatch(ctx, delivery)
+		}(deliveries[i])
+	}
+	wg.Wait()
+
+	return len(deliveries), nil
+}
+
+// claim leases due deliveries by pushing their next attempt past the request timeout,
+// so other instances skip them while they are in flight and pick them up again on a crash
+func (d *WebhookDispatcher) claim(ctx context.Context) (deliveries []entity.WebhookDelivery, err error) {
+	err = d.transactionRepository.WithTransaction(ctx, func(ctx context.Context) error {
+		deliveries, err = d.deliveryRepository.GetDue(ctx, d.batchSize)
+		if err != nil {
+			return err
+		}
+
+		leaseUntil := time.Now().Add(2 * d.client.Timeout)
+		for i := range deliveries {
+			if _, err := d.deliveryRepository.Update(ctx, deliveries[i].ID, map[string]any{"next_attempt_at": leaseUntil}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return deliveries, err
+}
+
+func (d *WebhookDispatcher) dispatch(ctx context.Context, delivery entity.WebhookDelivery) {
+	logger := d.logger.With("delivery_id", delivery.ID, "webhook_id", delivery.WebhookID, "event_id", delivery.EventID)
+
+	webhook, err := d.webhookRepository.GetByID(ctx, delivery.WebhookID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		if ctx.Err() != nil {
+			return
+		}
+
+		// the webhook may still exist, the delivery is retried without counting an attempt
+		nextAttemptAt := time.Now().Add(d.backoff(delivery.Attempts + 1))
+		logger.WarnContext(ctx, "get webhook failed", "next_attempt_at", nextAttemptAt, "error", err.Error())
+		d.update(ctx, logger, delivery.ID, map[string]any{
+			"last_error":      fmt.Sprintf("get webhook: %s", err.Error()),
+			"next_attempt_at": nextAttemptAt,
+		})
+		return
+	}
+	if err != nil || !webhook.Active {
+		// deleted or disabled webhooks never receive pending deliveries
+		reason := "webhook is inactive"
+		if err != nil {
+			reason = "webhook is deleted"
+		}
+		logger.WarnContext(ctx, "webhook delivery dropped", "reason", reason)
+		d.update(ctx, logger, delivery.ID, map[string]any{
+			"status":     constant.WebhookDeliveryStatusDead,
+			"last_error": reason,
+		})
+		return
+	}
+
+	statusCode, response, err := d.send(ctx, webhook, delivery)
+	if err != nil && ctx.Err() != nil {
+		// interrupted by shutdown, the delivery is retried once its lease expires
+		return
+	}
+
+	attempts := delivery.Attempts + 1
+	updateMap := map[string]any{
+		"attempts":         attempts,
+		"last_status_code": statusCode,
+		"last_response":    response,
+		"last_error":       "",
+	}
+
+	switch {
+	case err == nil:
+		updateMap["status"] = constant.WebhookDeliveryStatusSucceeded
+		updateMap["delivered_at"] = time.Now()
+		logger.InfoContext(ctx, "webhook delivered", "attempts", attempts, "status_code", *statusCode)
+	case attempts >= d.maxAttempts:
+		updateMap["status"] = constant.WebhookDeliveryStatusDead
+		updateMap["last_error"] = err.Error()
+		logger.ErrorContext(ctx, "webhook delivery dead", "attempts", attempts, "error", err.Error())
+	default:
+		nextAttemptAt := time.Now().Add(d.backoff(attempts))
+		updateMap["status"] = constant.WebhookDeliveryStatusFailed
+		updateMap["last_error"] = err.Error()
+		updateMap["next_attempt_at"] = nextAttemptAt
+		logger.WarnContext(ctx, "webhook delivery failed", "attempts", attempts, "next_attempt_at", nextAttemptAt, "error", err.Error())
+	}
+
+	d.update(ctx, logger, delivery.ID, updateMap)
+}
+
+func (d *WebhookDispatcher) send(ctx context.Context, webhook *entity.Webhook, delivery entity.WebhookDelivery) (statusCode *int, response string, err error) {
+	body := []byte(delivery.Payload.Item)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, "", fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhookhelper.HeaderDeliveryID, delivery.ID.String())
+	req.Header.Set(webhookhelper.HeaderEventID, strconv.FormatInt(delivery.EventID, 10))
+	req.Header.Set(webhookhelper.HeaderEventType, delivery.EventType)
+	req.Header.Set(webhookhelper.HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(webhookhelper.HeaderSignature, webhookhelper.Sign(webhook.Secret, timestamp, body))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("post webhook: %w", err)
+	}
+	defer res.Body.Close()
+
+	raw, _ := io.ReadAll(io.LimitReader(res.Body, webhookResponseLimit))
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 1<<16))
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return &res.StatusCode, string(raw), fmt.Errorf("post webhook: unexpected status %d", res.StatusCode)
+	}
+
+	return &res.StatusCode, string(raw), nil
+}
+
+func (d *WebhookDispatcher) update(ctx context.Context, logger *slog.Logger, id uuid.UUID, updateMap map[string]any) {
+	// record the attempt even when shutting down, the lease would otherwise resend it
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), d.client.Timeout)
+	defer cancel()
+
+	if _, err := d.deliveryRepository.Update(ctx, id, updateMap); err != nil {
+		logger.ErrorContext(ctx, "update webhook delivery failed", "error", err.Error())
+	}
+}
+
+func (d *WebhookDispatcher) backoff(attempts int) time.Duration {
+	backoff := d.baseBackoff
+	for i := 1; i < attempts && backoff < d.maxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, d.maxBackoff)
+}
@@ -0,0 +1,319 @@
+package worker_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alxhtp/monogo/config"
+	"github.com/alxhtp/monogo/internal/entity"
+	entitybase "github.com/alxhtp/monogo/internal/entity/base"
+	"github.com/alxhtp/monogo/internal/worker"
+	"github.com/alxhtp/monogo/pkg/constant"
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
+	webhookhelper "github.com/alxhtp/monogo/pkg/helper/webhook"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// webhookRepository holds webhooks by id, only GetByID is used by the dispatcher
+type webhookRepository map[uuid.UUID]*entity.Webhook
+
+func (r webhookRepository) Create(context.Context, *entity.Webhook) (*entity.Webhook, error) {
+	return nil, nil
+}
+
+func (r webhookRepository) GetByID(_ context.Context, id uuid.UUID) (*entity.Webhook, error) {
+	if webhook, ok := r[id]; ok {
+		return webhook, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r webhookRepository) GetByFilter(context.Context, *entity.WebhookFilter) ([]entity.Webhook, entitybase.BasePaginationResult, error) {
+	return nil, entitybase.BasePaginationResult{}, nil
+}
+
+func (r webhookRepository) Update(context.Context, uuid.UUID, map[string]any) (*entity.Webhook, error) {
+	return nil, nil
+}
+
+func (r webhookRepository) Delete(context.Context, uuid.UUID) error {
+	return nil
+}
+
+func (r webhookRepository) GetSubscribed(context.Context, string) ([]entity.Webhook, error) {
+	return nil, nil
+}
+
+// deliveryUpdate is an update of a delivery, recorded with the time it was made
+type deliveryUpdate struct {
+	at            time.Time
+	values        map[string]any
+	inTransaction bool
+}
+
+// deliveryRepository keeps deliveries in memory and records their updates
+type deliveryRepository struct {
+	mu         sync.Mutex
+	deliveries map[uuid.UUID]*entity.WebhookDelivery
+	updates    []deliveryUpdate
+}
+
+func newDeliveryRepository(deliveries ...entity.WebhookDelivery) *deliveryRepository {
+	r := &deliveryRepository{deliveries: make(map[uuid.UUID]*entity.WebhookDelivery)}
+	for i := range deliveries {
+		r.deliveries[deliveries[i].ID] = &deliveries[i]
+	}
+	return r
+}
+
+func (r *deliveryRepository) CreateMany(context.Context, []entity.WebhookDelivery) error {
+	return nil
+}
+
+func (r *deliveryRepository) GetByID(_ context.Context, id uuid.UUID) (*entity.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delivery, ok := r.deliveries[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	out := *delivery
+	return &out, nil
+}
+
+func (r *deliveryRepository) GetByFilter(context.Context, *entity.WebhookDeliveryFilter) ([]entity.WebhookDelivery, entitybase.BasePaginationResult, error) {
+	return nil, entitybase.BasePaginationResult{}, nil
+}
+
+func (r *deliveryRepository) GetDue(_ context.Context, limit int) (output []entity.WebhookDelivery, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, delivery := range r.deliveries {
+		due := delivery.Status == constant.WebhookDeliveryStatusPending || delivery.Status == constant.WebhookDeliveryStatusFailed
+		if due && !delivery.NextAttemptAt.After(time.Now()) && len(output) < limit {
+			output = append(output, *delivery)
+		}
+	}
+	return output, nil
+}
+
+func (r *deliveryRepository) Update(ctx context.Context, id uuid.UUID, updateMap map[string]any) (*entity.WebhookDelivery, error) {
+	r.mu.Lock()
+	delivery := r.deliveries[id]
+	r.updates = append(r.updates, deliveryUpdate{at: time.Now(), values: updateMap, inTransaction: ctx.Value(inTransactionKey{}) != nil})
+	for column, value := range updateMap {
+		switch column {
+		case "status":
+			delivery.Status = value.(constant.WebhookDeliveryStatus)
+		case "attempts":
+			delivery.Attempts = value.(int)
+		case "next_attempt_at":
+			delivery.NextAttemptAt = value.(time.Time)
+		case "last_error":
+			delivery.LastError = value.(string)
+		case "last_status_code":
+			delivery.LastStatusCode = value.(*int)
+		}
+	}
+	r.mu.Unlock()
+
+	return r.GetByID(ctx, id)
+}
+
+// attemptUpdates returns the updates recording an attempt
+func (r *deliveryRepository) attemptUpdates() (out []deliveryUpdate) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, update := range r.updates {
+		if _, ok := update.values["attempts"]; ok {
+			out = append(out, update)
+		}
+	}
+	return out
+}
+
+func newDelivery(webhookID uuid.UUID) entity.WebhookDelivery {
+	return entity.WebhookDelivery{
+		ID:        uuid.New(),
+		WebhookID: webhookID,
+		EventID:   7,
+		EventType: "user.created",
+		Payload:   databasehelper.GormJsonType[json.RawMessage]{Item: json.RawMessage(`{"id":7}`)},
+		Status:    constant.WebhookDeliveryStatusPending,
+	}
+}
+
+// runDispatcher runs the dispatcher until done reports true
+func runDispatcher(t *testing.T, dispatcher *worker.WebhookDispatcher, done func() bool) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		dispatcher.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-stopped
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !done() {
+		if time.Now().After(deadline) {
+			t.Fatal("the dispatcher did not finish in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func deliveryStatus(t *testing.T, deliveries *deliveryRepository, id uuid.UUID) constant.WebhookDeliveryStatus {
+	t.Helper()
+
+	delivery, err := deliveries.GetByID(context.Background(), id)
+	if err != nil {
+		t.Fatalf("get delivery: %v", err)
+	}
+	return delivery.Status
+}
+
+func TestWebhookDispatcherDeliversSignedEventsUnderALease(t *testing.T) {
+	webhook := &entity.Webhook{Base: entitybase.Base{ID: uuid.New()}, Secret: "secret", Active: true}
+
+	var verified atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body json.RawMessage
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(webhookhelper.HeaderTimestamp), 10, 64)
+		verified.Store(webhookhelper.Verify(webhook.Secret, timestamp, body, r.Header.Get(webhookhelper.HeaderSignature), time.Minute))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	webhook.URL = server.URL
+
+	delivery := newDelivery(webhook.ID)
+	deliveries := newDeliveryRepository(delivery)
+	dispatcher := worker.NewWebhookDispatcher(webhookRepository{webhook.ID: webhook}, deliveries, transactionRepository{}, nil, &config.WebhookConfig{
+		WebhookTimeout:              "1s",
+		WebhookPollInterval:         "5ms",
+		WebhookAllowPrivateNetworks: true,
+	})
+	runDispatcher(t, dispatcher, func() bool {
+		return deliveryStatus(t, deliveries, delivery.ID) == constant.WebhookDeliveryStatusSucceeded
+	})
+
+	if !verified.Load() {
+		t.Fatal("the receiver could not verify the signature")
+	}
+
+	// the delivery is leased inside the claiming transaction for twice the request timeout, then recorded outside of it
+	deliveries.mu.Lock()
+	defer deliveries.mu.Unlock()
+	if len(deliveries.updates) != 2 {
+		t.Fatalf("updates = %+v, want the lease and the attempt", deliveries.updates)
+	}
+	lease, attempt := deliveries.updates[0], deliveries.updates[1]
+	leaseUntil, _ := lease.values["next_attempt_at"].(time.Time)
+	if !lease.inTransaction || leaseUntil.Sub(lease.at) < time.Second || leaseUntil.Sub(lease.at) > 2*time.Second {
+		t.Fatalf("lease = %+v, want next_attempt_at 2s ahead inside the transaction", lease)
+	}
+	if attempt.inTransaction || attempt.values["attempts"] != 1 || *attempt.values["last_status_code"].(*int) != http.StatusNoContent {
+		t.Fatalf("attempt = %+v", attempt)
+	}
+}
+
+func TestWebhookDispatcherRetriesWithBackoffUntilDead(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	webhook := &entity.Webhook{Base: entitybase.Base{ID: uuid.New()}, URL: server.URL, Secret: "secret", Active: true}
+	delivery := newDelivery(webhook.ID)
+	deliveries := newDeliveryRepository(delivery)
+	dispatcher := worker.NewWebhookDispatcher(webhookRepository{webhook.ID: webhook}, deliveries, transactionRepository{}, nil, &config.WebhookConfig{
+		WebhookMaxAttempts:          4,
+		WebhookTimeout:              "1s",
+		WebhookPollInterval:         "5ms",
+		WebhookBaseBackoff:          "20ms",
+		WebhookMaxBackoff:           "50ms",
+		WebhookAllowPrivateNetworks: true,
+	})
+	runDispatcher(t, dispatcher, func() bool {
+		return deliveryStatus(t, deliveries, delivery.ID) == constant.WebhookDeliveryStatusDead
+	})
+
+	// the backoff doubles from the base up to the max, the last attempt makes the delivery dead
+	backoffs := []time.Duration{20 * time.Millisecond, 40 * time.Millisecond, 50 * time.Millisecond}
+	attempts := deliveries.attemptUpdates()
+	if len(attempts) != 4 {
+		t.Fatalf("attempts = %d, want 4", len(attempts))
+	}
+	for i, attempt := range attempts {
+		if attempt.values["attempts"] != i+1 || *attempt.values["last_status_code"].(*int) != http.StatusInternalServerError {
+			t.Fatalf("attempt %d = %+v", i+1, attempt.values)
+		}
+		if i == len(attempts)-1 {
+			if attempt.values["status"] != constant.WebhookDeliveryStatusDead || attempt.values["next_attempt_at"] != nil {
+				t.Fatalf("last attempt = %+v, want the delivery dead", attempt.values)
+			}
+			continue
+		}
+		nextAttemptAt, _ := attempt.values["next_attempt_at"].(time.Time)
+		backoff := nextAttemptAt.Sub(attempt.at)
+		if attempt.values["status"] != constant.WebhookDeliveryStatusFailed || backoff > backoffs[i] || backoff < backoffs[i]-10*time.Millisecond {
+			t.Fatalf("attempt %d = %+v, backoff %s, want %s", i+1, attempt.values, backoff, backoffs[i])
+		}
+	}
+}
+
+func TestWebhookDispatcherDropsDeliveriesOfDeletedWebhooks(t *testing.T) {
+	delivery := newDelivery(uuid.New())
+	deliveries := newDeliveryRepository(delivery)
+	dispatcher := worker.NewWebhookDispatcher(webhookRepository{}, deliveries, transactionRepository{}, nil, &config.WebhookConfig{
+		WebhookPollInterval: "5ms",
+	})
+	runDispatcher(t, dispatcher, func() bool {
+		return deliveryStatus(t, deliveries, delivery.ID) == constant.WebhookDeliveryStatusDead
+	})
+
+	dropped, _ := deliveries.GetByID(context.Background(), delivery.ID)
+	if dropped.Attempts != 0 || dropped.LastError != "webhook is deleted" {
+		t.Fatalf("delivery = %+v, want it dropped without an attempt", dropped)
+	}
+}
+
+func TestWebhookDispatcherRefusesPrivateNetworks(t *testing.T) {
+	var received atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		received.Store(true)
+	}))
+	defer server.Close()
+
+	webhook := &entity.Webhook{Base: entitybase.Base{ID: uuid.New()}, URL: server.URL, Secret: "secret", Active: true}
+	delivery := newDelivery(webhook.ID)
+	deliveries := newDeliveryRepository(delivery)
+	dispatcher := worker.NewWebhookDispatcher(webhookRepository{webhook.ID: webhook}, deliveries, transactionRepository{}, nil, &config.WebhookConfig{
+		WebhookPollInterval: "5ms",
+	})
+	runDispatcher(t, dispatcher, func() bool {
+		return len(deliveries.attemptUpdates()) > 0
+	})
+
+	failed, _ := deliveries.GetByID(context.Background(), delivery.ID)
+	if received.Load() || failed.Status != constant.WebhookDeliveryStatusFailed || !strings.Contains(failed.LastError, webhookhelper.ErrForbiddenAddress.Error()) {
+		t.Fatalf("delivery = %+v, want it failed without reaching the loopback receiver", failed)
+	}
+}
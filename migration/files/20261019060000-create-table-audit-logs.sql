@@ -0,0 +1,20 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS "monogo"."audit_logs" (
+    "id" bigserial PRIMARY KEY,
+    "actor" VARCHAR(255) NOT NULL,
+    "request_id" VARCHAR(255) NULL,
+    "entity_type" VARCHAR(255) NOT NULL,
+    "entity_id" VARCHAR(255) NOT NULL,
+    "operation" VARCHAR(32) NOT NULL,
+    "before" JSONB NULL,
+    "after" JSONB NULL,
+    "changes" JSONB NULL,
+    "created_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS "audit_logs_entity_idx" ON "monogo"."audit_logs" ("entity_type", "entity_id", "created_at");
+CREATE INDEX IF NOT EXISTS "audit_logs_actor_idx" ON "monogo"."audit_logs" ("actor", "created_at");
+CREATE INDEX IF NOT EXISTS "audit_logs_request_id_idx" ON "monogo"."audit_logs" ("request_id");
+
+-- +migrate Down
+DROP TABLE IF EXISTS "monogo"."audit_logs";
@@ -0,0 +1,20 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS "monogo"."outbox" (
+    "id" bigserial PRIMARY KEY,
+    "aggregate_type" VARCHAR(255) NOT NULL,
+    "aggregate_id" VARCHAR(255) NOT NULL,
+    "event_type" VARCHAR(255) NOT NULL,
+    "payload" JSONB NOT NULL,
+    "actor" VARCHAR(255) NULL,
+    "request_id" VARCHAR(255) NULL,
+    "attempts" int NOT NULL DEFAULT 0,
+    "last_error" TEXT NULL,
+    "next_attempt_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "published_at" timestamptz NULL,
+    "created_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS "outbox_pending_idx" ON "monogo"."outbox" ("aggregate_type", "aggregate_id", "id") WHERE "published_at" IS NULL;
+
+-- +migrate Down
+DROP TABLE IF EXISTS "monogo"."outbox";
@@ -0,0 +1,37 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS "monogo"."webhooks" (
+    "id" uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "name" VARCHAR(255) NOT NULL,
+    "url" TEXT NOT NULL,
+    "events" JSONB NOT NULL DEFAULT '[]',
+    "secret" VARCHAR(255) NOT NULL,
+    "active" boolean NOT NULL DEFAULT true,
+    "created_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "deleted_at" timestamptz NULL
+);
+
+CREATE TABLE IF NOT EXISTS "monogo"."webhook_deliveries" (
+    "id" uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    "webhook_id" uuid NOT NULL REFERENCES "monogo"."webhooks" ("id"),
+    "event_id" bigint NOT NULL,
+    "event_type" VARCHAR(255) NOT NULL,
+    "payload" JSONB NOT NULL,
+    "status" VARCHAR(32) NOT NULL,
+    "attempts" int NOT NULL DEFAULT 0,
+    "next_attempt_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "last_status_code" int NULL,
+    "last_error" TEXT NULL,
+    "last_response" TEXT NULL,
+    "delivered_at" timestamptz NULL,
+    "created_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE ("webhook_id", "event_id")
+);
+
+CREATE INDEX IF NOT EXISTS "webhook_deliveries_due_idx" ON "monogo"."webhook_deliveries" ("next_attempt_at") WHERE "status" IN ('pending', 'failed');
+CREATE INDEX IF NOT EXISTS "webhook_deliveries_webhook_idx" ON "monogo"."webhook_deliveries" ("webhook_id", "created_at");
+
+-- +migrate Down
+DROP TABLE IF EXISTS "monogo"."webhook_deliveries";
+DROP TABLE IF EXISTS "monogo"."webhooks";
@@ -0,0 +1,21 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS "monogo"."idempotency_keys" (
+    "caller" VARCHAR(255) NOT NULL,
+    "key" VARCHAR(255) NOT NULL,
+    "method" VARCHAR(16) NOT NULL,
+    "path" TEXT NOT NULL,
+    "request_hash" VARCHAR(64) NOT NULL,
+    "status_code" int NULL,
+    "content_type" VARCHAR(255) NULL,
+    "response_body" bytea NULL,
+    "locked_until" timestamptz NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "completed_at" timestamptz NULL,
+    "created_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("caller", "key")
+);
+
+CREATE INDEX IF NOT EXISTS "idempotency_keys_expires_at_idx" ON "monogo"."idempotency_keys" ("expires_at");
+
+-- +migrate Down
+DROP TABLE IF EXISTS "monogo"."idempotency_keys";
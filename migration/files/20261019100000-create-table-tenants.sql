@@ -0,0 +1,8 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS "monogo"."tenants" (
+    "id" VARCHAR(48) PRIMARY KEY,
+    "created_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS "monogo"."tenants";
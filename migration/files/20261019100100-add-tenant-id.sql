@@ -0,0 +1,23 @@
+-- +migrate Up
+-- tenant_id is empty for rows that belong to no tenant, the single-tenant and the schema-per-tenant modes
+-- keep working unchanged. The unique emails per tenant and the row level security policies of the row level mode
+-- are only applied by that mode, see migration/files/row.
+ALTER TABLE "monogo"."users" ADD COLUMN IF NOT EXISTS "tenant_id" VARCHAR(48) NOT NULL DEFAULT COALESCE(current_setting('monogo.tenant_id', true), '');
+ALTER TABLE "monogo"."webhooks" ADD COLUMN IF NOT EXISTS "tenant_id" VARCHAR(48) NOT NULL DEFAULT COALESCE(current_setting('monogo.tenant_id', true), '');
+ALTER TABLE "monogo"."webhook_deliveries" ADD COLUMN IF NOT EXISTS "tenant_id" VARCHAR(48) NOT NULL DEFAULT COALESCE(current_setting('monogo.tenant_id', true), '');
+ALTER TABLE "monogo"."outbox" ADD COLUMN IF NOT EXISTS "tenant_id" VARCHAR(48) NOT NULL DEFAULT COALESCE(current_setting('monogo.tenant_id', true), '');
+ALTER TABLE "monogo"."idempotency_keys" ADD COLUMN IF NOT EXISTS "tenant_id" VARCHAR(48) NOT NULL DEFAULT COALESCE(current_setting('monogo.tenant_id', true), '');
+ALTER TABLE "monogo"."audit_logs" ADD COLUMN IF NOT EXISTS "tenant_id" VARCHAR(48) NOT NULL DEFAULT COALESCE(current_setting('monogo.tenant_id', true), '');
+
+ALTER TABLE "monogo"."idempotency_keys" DROP CONSTRAINT IF EXISTS "idempotency_keys_pkey";
+ALTER TABLE "monogo"."idempotency_keys" ADD PRIMARY KEY ("tenant_id", "caller", "key");
+
+-- +migrate Down
+ALTER TABLE "monogo"."idempotency_keys" DROP CONSTRAINT IF EXISTS "idempotency_keys_pkey";
+ALTER TABLE "monogo"."idempotency_keys" ADD PRIMARY KEY ("caller", "key");
+ALTER TABLE "monogo"."audit_logs" DROP COLUMN IF EXISTS "tenant_id";
+ALTER TABLE "monogo"."idempotency_keys" DROP COLUMN IF EXISTS "tenant_id";
+ALTER TABLE "monogo"."outbox" DROP COLUMN IF EXISTS "tenant_id";
+ALTER TABLE "monogo"."webhook_deliveries" DROP COLUMN IF EXISTS "tenant_id";
+ALTER TABLE "monogo"."webhooks" DROP COLUMN IF EXISTS "tenant_id";
+ALTER TABLE "monogo"."users" DROP COLUMN IF EXISTS "tenant_id";
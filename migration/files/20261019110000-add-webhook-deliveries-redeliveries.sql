@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE "monogo"."webhook_deliveries" ADD COLUMN IF NOT EXISTS "redeliveries" int NOT NULL DEFAULT 0;
+
+-- +migrate Down
+ALTER TABLE "monogo"."webhook_deliveries" DROP COLUMN IF EXISTS "redeliveries";
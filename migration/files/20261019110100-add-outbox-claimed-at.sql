@@ -0,0 +1,8 @@
+-- +migrate Up
+ALTER TABLE "monogo"."outbox" ADD COLUMN IF NOT EXISTS "claimed_at" timestamptz NULL;
+
+CREATE INDEX IF NOT EXISTS "outbox_published_idx" ON "monogo"."outbox" ("aggregate_type", "published_at", "id") WHERE "published_at" IS NOT NULL;
+
+-- +migrate Down
+DROP INDEX IF EXISTS "monogo"."outbox_published_idx";
+ALTER TABLE "monogo"."outbox" DROP COLUMN IF EXISTS "claimed_at";
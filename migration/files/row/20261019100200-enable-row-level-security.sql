@@ -0,0 +1,71 @@
+-- +migrate Up
+-- Applied when the row level tenancy mode is enabled, on top of the tenant_id columns of the shared schema.
+-- Emails are unique per tenant, row level security is forced so it also applies
+-- to the owner of the tables, it never applies to superusers.
+ALTER TABLE "monogo"."users" DROP CONSTRAINT IF EXISTS "users_email_key";
+ALTER TABLE "monogo"."users" ADD CONSTRAINT "users_tenant_id_email_key" UNIQUE ("tenant_id", "email");
+CREATE INDEX IF NOT EXISTS "webhooks_tenant_id_idx" ON "monogo"."webhooks" ("tenant_id");
+CREATE INDEX IF NOT EXISTS "outbox_tenant_id_idx" ON "monogo"."outbox" ("tenant_id") WHERE "published_at" IS NULL;
+CREATE INDEX IF NOT EXISTS "audit_logs_tenant_id_idx" ON "monogo"."audit_logs" ("tenant_id", "entity_type", "entity_id");
+
+ALTER TABLE "monogo"."users" ENABLE ROW LEVEL SECURITY;
+ALTER TABLE "monogo"."users" FORCE ROW LEVEL SECURITY;
+CREATE POLICY "users_tenant_isolation" ON "monogo"."users"
+    USING ("tenant_id" = COALESCE(current_setting('monogo.tenant_id', true), ''))
+    WITH CHECK ("tenant_id" = COALESCE(current_setting('monogo.tenant_id', true), ''));
+
+ALTER TABLE "monogo"."webhooks" ENABLE ROW LEVEL SECURITY;
+ALTER TABLE "monogo"."webhooks" FORCE ROW LEVEL SECURITY;
+CREATE POLICY "webhooks_tenant_isolation" ON "monogo"."webhooks"
+    USING ("tenant_id" = COALESCE(current_setting('monogo.tenant_id', true), ''))
+    WITH CHECK ("tenant_id" = COALESCE(current_setting('monogo.tenant_id', true), ''));
+
+ALTER TABLE "monogo"."webhook_deliveries" ENABLE ROW LEVEL SECURITY;
+ALTER TABLE "monogo"."webhook_deliveries" FORCE ROW LEVEL SECURITY;
+CREATE POLICY "webhook_deliveries_tenant_isolation" ON "monogo"."webhook_deliveries"
+    USING ("tenant_id" = COALESCE(current_setting('monogo.tenant_id', true), ''))
+    WITH CHECK ("tenant_id" = COALESCE(current_setting('monogo.tenant_id', true), ''));
+
+ALTER TABLE "monogo"."outbox" ENABLE ROW LEVEL SECURITY;
+ALTER TABLE "monogo"."outbox" FORCE ROW LEVEL SECURITY;
+CREATE POLICY "outbox_tenant_isolation" ON "monogo"."outbox"
+    USING ("tenant_id" = COALESCE(current_setting('monogo.tenant_id', true), ''))
+    WITH CHECK ("tenant_id" = COALESCE(current_setting('monogo.tenant_id', true), ''));
+
+ALTER TABLE "monogo"."idempotency_keys" ENABLE ROW LEVEL SECURITY;
+ALTER TABLE "monogo"."idempotency_keys" FORCE ROW LEVEL SECURITY;
+CREATE POLICY "idempotency_keys_tenant_isolation" ON "monogo"."idempotency_keys"
+    USING ("tenant_id" = COALESCE(current_setting('monogo.tenant_id', true), ''))
+    WITH CHECK ("tenant_id" = COALESCE(current_setting('monogo.tenant_id', true), ''));
+
+ALTER TABLE "monogo"."audit_logs" ENABLE ROW LEVEL SECURITY;
+ALTER TABLE "monogo"."audit_logs" FORCE ROW LEVEL SECURITY;
+CREATE POLICY "audit_logs_tenant_isolation" ON "monogo"."audit_logs"
+    USING ("tenant_id" = COALESCE(current_setting('monogo.tenant_id', true), ''))
+    WITH CHECK ("tenant_id" = COALESCE(current_setting('monogo.tenant_id', true), ''));
+
+-- +migrate Down
+DROP POLICY IF EXISTS "audit_logs_tenant_isolation" ON "monogo"."audit_logs";
+ALTER TABLE "monogo"."audit_logs" NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE "monogo"."audit_logs" DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS "idempotency_keys_tenant_isolation" ON "monogo"."idempotency_keys";
+ALTER TABLE "monogo"."idempotency_keys" NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE "monogo"."idempotency_keys" DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS "outbox_tenant_isolation" ON "monogo"."outbox";
+ALTER TABLE "monogo"."outbox" NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE "monogo"."outbox" DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS "webhook_deliveries_tenant_isolation" ON "monogo"."webhook_deliveries";
+ALTER TABLE "monogo"."webhook_deliveries" NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE "monogo"."webhook_deliveries" DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS "webhooks_tenant_isolation" ON "monogo"."webhooks";
+ALTER TABLE "monogo"."webhooks" NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE "monogo"."webhooks" DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS "users_tenant_isolation" ON "monogo"."users";
+ALTER TABLE "monogo"."users" NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE "monogo"."users" DISABLE ROW LEVEL SECURITY;
+
+DROP INDEX IF EXISTS "monogo"."audit_logs_tenant_id_idx";
+DROP INDEX IF EXISTS "monogo"."outbox_tenant_id_idx";
+DROP INDEX IF EXISTS "monogo"."webhooks_tenant_id_idx";
+ALTER TABLE "monogo"."users" DROP CONSTRAINT IF EXISTS "users_tenant_id_email_key";
+ALTER TABLE "monogo"."users" ADD CONSTRAINT "users_email_key" UNIQUE ("email");
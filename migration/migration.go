@@ -0,0 +1,393 @@
+// Package migration embeds the SQL migrations of the service and applies them, to the shared schema
+// with the migrate command or on startup, to the schema of a new tenant, and the row level security
+// of the shared schema once the row level tenancy mode is enabled.
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+const (
+	// TableName is the table holding the applied migrations
+	TableName = "migrations"
+	// RowTableName is the table holding the applied migrations of the row level tenancy mode
+	RowTableName = "row_migrations"
+	// SharedSchema is the schema the migration files are written for
+	SharedSchema = "monogo"
+	// Dir is the directory of the migration files in the repository, new migrations are created there
+	Dir = "migration/files"
+	// RowDir is the directory of the migration files of the row level tenancy mode in the repository
+	RowDir = Dir + "/row"
+
+	dialect = "postgres"
+	// tableSchema holds the migrations table of the shared schema, where the sql-migrate CLI kept it
+	tableSchema = "public"
+
+	lockQuery   = "SELECT pg_advisory_lock(hashtextextended($1, 0))"
+	unlockQuery = "SELECT pg_advisory_unlock(hashtextextended($1, 0))"
+	lockPrefix  = "monogo:migration:"
+
+	idTimeLayout = "20060102150405"
+)
+
+// ErrSchemaBehind is returned when the database misses migrations embedded into the binary
+var ErrSchemaBehind = errors.New("database schema is behind")
+
+var nameSanitizer = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Status is an embedded migration and when it was applied, nil while pending
+type Status struct {
+	ID        string
+	AppliedAt *time.Time
+}
+
+//go:embed files/*.sql
+var files embed.FS
+
+// rowFiles are only applied to the shared schema in the row level tenancy mode
+//
+//go:embed files/row/*.sql
+var rowFiles embed.FS
+
+// Source returns the embedded migrations
+func Source() migrate.MigrationSource {
+	return migrate.EmbedFileSystemMigrationSource{FileSystem: files, Root: "files"}
+}
+
+// RowSource returns the embedded migrations of the row level tenancy mode
+func RowSource() migrate.MigrationSource {
+	return migrate.EmbedFileSystemMigrationSource{FileSystem: rowFiles, Root: "files/row"}
+}
+
+// SchemaSource returns the embedded migrations written into schema instead of the shared schema
+func SchemaSource(schema string) (migrate.MigrationSource, error) {
+	return rewriteSource(Source(), schema)
+}
+
+// rewriteSource returns the migrations of source written into schema instead of the shared schema
+func rewriteSource(source migrate.MigrationSource, schema string) (migrate.MigrationSource, error) {
+	migrations, err := source.FindMigrations()
+	if err != nil {
+		return nil, fmt.Errorf("find migrations: %w", err)
+	}
+
+	shared := quoteIdentifier(SharedSchema)
+	target := quoteIdentifier(schema)
+	out := make([]*migrate.Migration, len(migrations))
+	for i, m := range migrations {
+		out[i] = &migrate.Migration{
+			Id:                     m.Id,
+			Up:                     replaceAll(m.Up, shared, target),
+			Down:                   replaceAll(m.Down, shared, target),
+			DisableTransactionUp:   m.DisableTransactionUp,
+			DisableTransactionDown: m.DisableTransactionDown,
+		}
+	}
+	return migrate.MemoryMigrationSource{Migrations: out}, nil
+}
+
+// Up applies at most limit pending migrations to the shared schema, every one when limit is 0.
+// It returns the number of migrations applied.
+func Up(ctx context.Context, db *sql.DB, limit int) (applied int, err error) {
+	err = withLock(ctx, db, SharedSchema, func() error {
+		applied, err = sharedSet().ExecMaxContext(ctx, db, dialect, Source(), migrate.Up, limit)
+		return err
+	})
+	if err != nil {
+		return applied, fmt.Errorf("migrate up: %w", err)
+	}
+	return applied, nil
+}
+
+// Down rolls back at most limit migrations of the shared schema, the latest first, every one when limit is 0.
+// It returns the number of migrations rolled back.
+func Down(ctx context.Context, db *sql.DB, limit int) (rolledBack int, err error) {
+	err = withLock(ctx, db, SharedSchema, func() error {
+		rolledBack, err = sharedSet().ExecMaxContext(ctx, db, dialect, Source(), migrate.Down, limit)
+		return err
+	})
+	if err != nil {
+		return rolledBack, fmt.Errorf("migrate down: %w", err)
+	}
+	return rolledBack, nil
+}
+
+// Redo rolls back the latest migration of the shared schema and applies it again
+func Redo(ctx context.Context, db *sql.DB) error {
+	err := withLock(ctx, db, SharedSchema, func() error {
+		return redo(ctx, db, sharedSet(), Source())
+	})
+	if err != nil {
+		return fmt.Errorf("migrate redo: %w", err)
+	}
+	return nil
+}
+
+// DownSchema rolls back at most limit migrations of the schema of a tenant, the latest first, every one when limit is 0.
+// It returns the number of migrations rolled back.
+func DownSchema(ctx context.Context, db *sql.DB, schema string, limit int) (rolledBack int, err error) {
+	source, err := SchemaSource(schema)
+	if err != nil {
+		return 0, err
+	}
+
+	err = withLock(ctx, db, schema, func() error {
+		rolledBack, err = schemaSet(schema).ExecMaxContext(ctx, db, dialect, source, migrate.Down, limit)
+		return err
+	})
+	if err != nil {
+		return rolledBack, fmt.Errorf("migrate schema %s down: %w", schema, err)
+	}
+	return rolledBack, nil
+}
+
+// RedoSchema rolls back the latest migration of the schema of a tenant and applies it again
+func RedoSchema(ctx context.Context, db *sql.DB, schema string) error {
+	source, err := SchemaSource(schema)
+	if err != nil {
+		return err
+	}
+
+	err = withLock(ctx, db, schema, func() error {
+		return redo(ctx, db, schemaSet(schema), source)
+	})
+	if err != nil {
+		return fmt.Errorf("migrate schema %s redo: %w", schema, err)
+	}
+	return nil
+}
+
+// redo rolls back the latest migration of set and applies it again, the caller holds the lock of the schema
+func redo(ctx context.Context, db *sql.DB, set migrate.MigrationSet, source migrate.MigrationSource) error {
+	rolledBack, err := set.ExecMaxContext(ctx, db, dialect, source, migrate.Down, 1)
+	if err != nil {
+		return err
+	}
+	if rolledBack == 0 {
+		return errors.New("no migration to redo")
+	}
+	_, err = set.ExecMaxContext(ctx, db, dialect, source, migrate.Up, 1)
+	return err
+}
+
+// GetStatus returns every embedded migration with when it was applied to the shared schema.
+// It only reads, so it works for roles that cannot create the migrations table.
+func GetStatus(ctx context.Context, db *sql.DB) ([]Status, error) {
+	return getStatus(ctx, db, tableSchema)
+}
+
+// GetSchemaStatus returns every embedded migration with when it was applied to the schema of a tenant
+func GetSchemaStatus(ctx context.Context, db *sql.DB, schema string) ([]Status, error) {
+	return getStatus(ctx, db, schema)
+}
+
+// getStatus reads the migrations table of tableSchema
+func getStatus(ctx context.Context, db *sql.DB, tableSchema string) ([]Status, error) {
+	migrations, err := Source().FindMigrations()
+	if err != nil {
+		return nil, fmt.Errorf("find migrations: %w", err)
+	}
+
+	table := quoteIdentifier(tableSchema) + "." + quoteIdentifier(TableName)
+	appliedAt := map[string]time.Time{}
+	var exists bool
+	if err := db.QueryRowContext(ctx, "SELECT to_regclass($1) IS NOT NULL", table).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("migration status: %w", err)
+	}
+	if exists {
+		rows, err := db.QueryContext(ctx, "SELECT id, applied_at FROM "+table)
+		if err != nil {
+			return nil, fmt.Errorf("migration status: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				id string
+				at time.Time
+			)
+			if err := rows.Scan(&id, &at); err != nil {
+				return nil, fmt.Errorf("migration status: %w", err)
+			}
+			appliedAt[id] = at
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("migration status: %w", err)
+		}
+	}
+
+	output := make([]Status, len(migrations))
+	for i, m := range migrations {
+		output[i] = Status{ID: m.Id}
+		if at, ok := appliedAt[m.Id]; ok {
+			output[i].AppliedAt = &at
+		}
+	}
+	return output, nil
+}
+
+// Check returns ErrSchemaBehind when embedded migrations are not applied to the shared schema.
+// Migrations applied by a newer binary are ignored, so an older instance keeps running during a rollout.
+func Check(ctx context.Context, db *sql.DB) error {
+	statuses, err := GetStatus(ctx, db)
+	if err != nil {
+		return err
+	}
+	return checkStatus(statuses)
+}
+
+// CheckSchema returns ErrSchemaBehind when embedded migrations are not applied to the schema of a tenant
+func CheckSchema(ctx context.Context, db *sql.DB, schema string) error {
+	statuses, err := GetSchemaStatus(ctx, db, schema)
+	if err != nil {
+		return err
+	}
+	if err := checkStatus(statuses); err != nil {
+		return fmt.Errorf("schema %s: %w", schema, err)
+	}
+	return nil
+}
+
+func checkStatus(statuses []Status) error {
+	var pending []string
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending = append(pending, status.ID)
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: %d pending migrations, the latest is %s", ErrSchemaBehind, len(pending), pending[len(pending)-1])
+	}
+	return nil
+}
+
+// FileName returns the file name of a migration named after name created at now, e.g. 20250922163836-create-table-users.sql
+func FileName(name string, now time.Time) (string, error) {
+	name = strings.Trim(nameSanitizer.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if name == "" {
+		return "", errors.New("migration name is required")
+	}
+	return now.UTC().Format(idTimeLayout) + "-" + name + ".sql", nil
+}
+
+// Create writes an empty migration named after name into dir and returns its path
+func Create(dir, name string, now time.Time) (string, error) {
+	fileName, err := FileName(name, now)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, fileName)
+	content := "-- +migrate Up\n\n-- +migrate Down\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return "", fmt.Errorf("create migration: %w", err)
+	}
+	return path, nil
+}
+
+// UpSchema creates schema and applies the pending migrations into it, the applied migrations are recorded
+// in the migrations table of schema. It returns the number of migrations applied and whether schema was created,
+// by this call only when several run concurrently.
+func UpSchema(ctx context.Context, db *sql.DB, schema string) (applied int, created bool, err error) {
+	source, err := SchemaSource(schema)
+	if err != nil {
+		return 0, false, err
+	}
+
+	err = withLock(ctx, db, schema, func() error {
+		// the schema is only created while holding its lock, whoever finds it missing creates it
+		var exists bool
+		if err := db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = $1)", schema).Scan(&exists); err != nil {
+			return fmt.Errorf("find schema %s: %w", schema, err)
+		}
+		if !exists {
+			if _, err := db.ExecContext(ctx, "CREATE SCHEMA IF NOT EXISTS "+quoteIdentifier(schema)); err != nil {
+				return fmt.Errorf("create schema %s: %w", schema, err)
+			}
+			created = true
+		}
+
+		applied, err = schemaSet(schema).ExecContext(ctx, db, dialect, source, migrate.Up)
+		return err
+	})
+	if err != nil {
+		return applied, created, fmt.Errorf("migrate schema %s: %w", schema, err)
+	}
+	return applied, created, nil
+}
+
+// UpRow applies the pending migrations of the row level tenancy mode to schema, the shared schema
+// or a copy of it. They make emails unique per tenant and force the row level security policies,
+// so they are never applied in the single-tenant and schema-per-tenant modes.
+// It returns the number of migrations applied.
+func UpRow(ctx context.Context, db *sql.DB, schema string) (applied int, err error) {
+	source := RowSource()
+	set := migrate.MigrationSet{TableName: RowTableName, SchemaName: tableSchema, IgnoreUnknown: true}
+	if schema != SharedSchema {
+		if source, err = rewriteSource(source, schema); err != nil {
+			return 0, err
+		}
+		set.SchemaName = schema
+	}
+
+	err = withLock(ctx, db, "row:"+schema, func() error {
+		applied, err = set.ExecContext(ctx, db, dialect, source, migrate.Up)
+		return err
+	})
+	if err != nil {
+		return applied, fmt.Errorf("migrate row level security of %s: %w", schema, err)
+	}
+	return applied, nil
+}
+
+// sharedSet records the migrations of the shared schema. Migrations unknown to this binary were applied
+// by a newer one and are ignored.
+func sharedSet() migrate.MigrationSet {
+	return migrate.MigrationSet{TableName: TableName, SchemaName: tableSchema, IgnoreUnknown: true}
+}
+
+// schemaSet records the migrations of the schema of a tenant in a migrations table of that schema
+func schemaSet(schema string) migrate.MigrationSet {
+	return migrate.MigrationSet{TableName: TableName, SchemaName: schema}
+}
+
+// withLock runs fn holding the advisory lock of schema, so a single instance migrates it at a time.
+// The lock is held by a connection of its own, released when fn returns or the connection dies.
+func withLock(ctx context.Context, db *sql.DB, schema string, fn func() error) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("lock: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, lockQuery, lockPrefix+schema); err != nil {
+		return fmt.Errorf("lock: %w", err)
+	}
+	defer func() {
+		_, _ = conn.ExecContext(context.WithoutCancel(ctx), unlockQuery, lockPrefix+schema)
+	}()
+
+	return fn()
+}
+
+func replaceAll(statements []string, old, new string) []string {
+	out := make([]string, len(statements))
+	for i, statement := range statements {
+		out[i] = strings.ReplaceAll(statement, old, new)
+	}
+	return out
+}
+
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,261 @@
+package migration_test
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alxhtp/monogo/migration"
+	"github.com/alxhtp/monogo/migration/migrationtest"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+func TestFileName(t *testing.T) {
+	now := time.Date(2026, 10, 19, 10, 1, 2, 0, time.FixedZone("WIB", 7*60*60))
+
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "add-users-phone", want: "20261019030102-add-users-phone.sql"},
+		{name: "Add Users Phone", want: "20261019030102-add-users-phone.sql"},
+		{name: "  create_table:Blog Posts!! ", want: "20261019030102-create-table-blog-posts.sql"},
+		{name: "--", wantErr: true},
+		{name: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := migration.FileName(tt.name, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %q, want an error", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("got %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateWritesAnEmptyMigration(t *testing.T) {
+	dir := t.TempDir()
+
+	path, err := migration.Create(dir, "add users phone", time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if path != filepath.Join(dir, "20261019100000-add-users-phone.sql") {
+		t.Fatalf("path = %s", path)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(content) != "-- +migrate Up\n\n-- +migrate Down\n" {
+		t.Fatalf("content = %q", content)
+	}
+}
+
+func TestEmbeddedMigrationsAreWrittenForTheSharedSchema(t *testing.T) {
+	for name, source := range map[string]migrate.MigrationSource{"shared": migration.Source(), "row": migration.RowSource()} {
+		t.Run(name, func(t *testing.T) {
+			migrations, err := source.FindMigrations()
+			if err != nil {
+				t.Fatalf("find migrations: %v", err)
+			}
+			if len(migrations) == 0 {
+				t.Fatal("no migration is embedded")
+			}
+			for _, m := range migrations {
+				if len(m.Up) == 0 || len(m.Down) == 0 {
+					t.Errorf("%s: every migration can be applied and rolled back", m.Id)
+				}
+				if !strings.Contains(strings.Join(m.Up, "\n"), `"`+migration.SharedSchema+`"`) {
+					t.Errorf("%s: tables are qualified with the shared schema, tenant schemas are written from it", m.Id)
+				}
+			}
+		})
+	}
+}
+
+func TestSchemaSourceWritesMigrationsIntoSchema(t *testing.T) {
+	source, err := migration.SchemaSource("tenant_acme")
+	if err != nil {
+		t.Fatalf("schema source: %v", err)
+	}
+	migrations, err := source.FindMigrations()
+	if err != nil {
+		t.Fatalf("find migrations: %v", err)
+	}
+	shared, _ := migration.Source().FindMigrations()
+	if len(migrations) != len(shared) {
+		t.Fatalf("got %d migrations, want %d", len(migrations), len(shared))
+	}
+
+	for _, m := range migrations {
+		statements := strings.Join(append(m.Up, m.Down...), "\n")
+		if strings.Contains(statements, `"`+migration.SharedSchema+`"`) {
+			t.Errorf("%s: the shared schema is still referenced", m.Id)
+		}
+	}
+	if !strings.Contains(strings.Join(migrations[len(migrations)-1].Up, "\n"), `"tenant_acme"`) {
+		t.Error("the latest migration is not written into the tenant schema")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	migrations, err := migration.Source().FindMigrations()
+	if err != nil {
+		t.Fatalf("find migrations: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		applied int
+		exists  bool
+		wantErr error
+	}{
+		{name: "every migration applied", applied: len(migrations), exists: true},
+		{name: "latest migration pending", applied: len(migrations) - 1, exists: true, wantErr: migration.ErrSchemaBehind},
+		{name: "never migrated", exists: false, wantErr: migration.ErrSchemaBehind},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("sqlmock: %v", err)
+			}
+			defer db.Close()
+
+			mock.ExpectQuery(regexp.QuoteMeta("SELECT to_regclass($1) IS NOT NULL")).
+				WithArgs(`"public"."migrations"`).
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(tt.exists))
+			if tt.exists {
+				rows := sqlmock.NewRows([]string{"id", "applied_at"})
+				for _, m := range migrations[:tt.applied] {
+					rows.AddRow(m.Id, time.Now())
+				}
+				// a migration applied by a newer binary is ignored
+				rows.AddRow("29991231000000-from-a-newer-binary.sql", time.Now())
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, applied_at FROM "public"."migrations"`)).WillReturnRows(rows)
+			}
+
+			err = migration.Check(context.Background(), db)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestCheckSchemaReadsTheMigrationsOfTheSchema(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT to_regclass($1) IS NOT NULL")).
+		WithArgs(`"tenant_acme"."migrations"`).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	err = migration.CheckSchema(context.Background(), db, "tenant_acme")
+	if !errors.Is(err, migration.ErrSchemaBehind) || !strings.Contains(err.Error(), "tenant_acme") {
+		t.Fatalf("got %v, want ErrSchemaBehind naming the schema", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUpSchemaCreatesTheSchemaOnce(t *testing.T) {
+	db := migrationtest.OpenSchema(t)
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("db: %v", err)
+	}
+	ctx := context.Background()
+
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	schema := "test_" + hex.EncodeToString(suffix)
+	t.Cleanup(func() { _, _ = sqlDB.ExecContext(ctx, `DROP SCHEMA IF EXISTS "`+schema+`" CASCADE`) })
+
+	applied, created, err := migration.UpSchema(ctx, sqlDB, schema)
+	if err != nil || !created || applied == 0 {
+		t.Fatalf("first run: applied %d, created %t, %v", applied, created, err)
+	}
+	applied, created, err = migration.UpSchema(ctx, sqlDB, schema)
+	if err != nil || created || applied != 0 {
+		t.Fatalf("second run: applied %d, created %t, %v, want a no-op", applied, created, err)
+	}
+	if err := migration.CheckSchema(ctx, sqlDB, schema); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+}
+
+func TestDownSchemaRollsBackTheSchema(t *testing.T) {
+	db := migrationtest.OpenSchema(t)
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("db: %v", err)
+	}
+	ctx := context.Background()
+
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	schema := "test_" + hex.EncodeToString(suffix)
+	t.Cleanup(func() { _, _ = sqlDB.ExecContext(ctx, `DROP SCHEMA IF EXISTS "`+schema+`" CASCADE`) })
+
+	if _, _, err := migration.UpSchema(ctx, sqlDB, schema); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	if err := migration.RedoSchema(ctx, sqlDB, schema); err != nil {
+		t.Fatalf("redo: %v", err)
+	}
+	if rolledBack, err := migration.DownSchema(ctx, sqlDB, schema, 1); err != nil || rolledBack != 1 {
+		t.Fatalf("down: rolled back %d, %v, want 1", rolledBack, err)
+	}
+	if err := migration.CheckSchema(ctx, sqlDB, schema); !errors.Is(err, migration.ErrSchemaBehind) {
+		t.Fatalf("check after down: %v, want ErrSchemaBehind", err)
+	}
+	if applied, _, err := migration.UpSchema(ctx, sqlDB, schema); err != nil || applied != 1 {
+		t.Fatalf("up again: applied %d, %v, want 1", applied, err)
+	}
+}
+
+func TestUpRowAppliesOnce(t *testing.T) {
+	db := migrationtest.OpenSchema(t)
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("db: %v", err)
+	}
+	ctx := context.Background()
+
+	var schema string
+	if err := sqlDB.QueryRowContext(ctx, "SELECT current_schema()").Scan(&schema); err != nil {
+		t.Fatalf("current schema: %v", err)
+	}
+
+	rows, _ := migration.RowSource().FindMigrations()
+	if applied, err := migration.UpRow(ctx, sqlDB, schema); err != nil || applied != len(rows) {
+		t.Fatalf("first run: applied %d, %v, want %d", applied, err, len(rows))
+	}
+	if applied, err := migration.UpRow(ctx, sqlDB, schema); err != nil || applied != 0 {
+		t.Fatalf("second run: applied %d, %v, want a no-op", applied, err)
+	}
+}
@@ -0,0 +1,52 @@
+// Package migrationtest gives tests a migrated postgres schema of their own.
+package migrationtest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"testing"
+
+	"github.com/alxhtp/monogo/migration"
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
+	"gorm.io/gorm"
+)
+
+// DSNEnv names a postgres database the tests may create schemas in
+const DSNEnv = "TEST_DATABASE_DSN"
+
+// OpenSchema migrates a throwaway schema, dropped once t ends, and returns a connection to it.
+// The connection can be put in the row level tenancy mode with databasehelper.EnableTenancy.
+// t is skipped when DSNEnv is not set.
+func OpenSchema(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv(DSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", DSNEnv)
+	}
+	ctx := context.Background()
+
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	schema := "test_" + hex.EncodeToString(suffix)
+
+	// every connection of the pool resolves unqualified table names to the throwaway schema
+	db, err := databasehelper.OpenGormDSN(dsn, schema+",public")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { _ = databasehelper.CloseGormDB(db) })
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	if _, _, err := migration.UpSchema(ctx, sqlDB, schema); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	t.Cleanup(func() { _, _ = sqlDB.ExecContext(ctx, `DROP SCHEMA "`+schema+`" CASCADE`) })
+
+	return db
+}
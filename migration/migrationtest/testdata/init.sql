@@ -0,0 +1,4 @@
+-- The test role is neither superuser nor BYPASSRLS, so the row level tenancy tests run against it.
+-- It owns its database, it may create the throwaway schemas and the migrations tables of public.
+CREATE ROLE monogo_test LOGIN PASSWORD 'monogo_test';
+CREATE DATABASE monogo_test OWNER monogo_test;
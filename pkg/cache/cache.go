@@ -0,0 +1,16 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Store is a byte cache shared by the cached repositories. The in-process LRU is the default,
+// an external cache such as Redis implements Store to share entries and invalidations across instances.
+type Store interface {
+	// Get returns the value of key and whether it was found
+	Get(ctx context.Context, key string) (value []byte, found bool, err error)
+	// Set stores value for ttl, a zero ttl keeps it until it is evicted or deleted
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
@@ -0,0 +1,11 @@
+package constant
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusSucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryStatusFailed    WebhookDeliveryStatus = "failed"
+	// WebhookDeliveryStatusDead is set once a delivery ran out of attempts, only a manual redelivery retries it
+	WebhookDeliveryStatusDead WebhookDeliveryStatus = "dead"
+)
@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"time"
+
+	dtobase "github.com/alxhtp/monogo/pkg/dto/base"
+)
+
+type ReqGetAuditLog struct {
+	EntityType *string `query:"entity-type"`
+	EntityIDs  *string `query:"entity-ids"` // comma separated string of entity ids
+	Actor      *string `query:"actor"`
+	RequestID  *string `query:"request-id"`
+	Operation  *string `query:"operation"`
+	dtobase.BaseReqQueryPagination
+}
+
+type AuditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+type ResAuditLog struct {
+	ID         int64                  `json:"id"`
+	Actor      string                 `json:"actor"`
+	RequestID  string                 `json:"request_id"`
+	EntityType string                 `json:"entity_type"`
+	EntityID   string                 `json:"entity_id"`
+	Operation  string                 `json:"operation"`
+	Before     map[string]any         `json:"before"`
+	After      map[string]any         `json:"after"`
+	Changes    map[string]AuditChange `json:"changes"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+type ResAuditLogList struct {
+	dtobase.BaseResPagination
+	Data []ResAuditLog `json:"data"`
+}
@@ -36,3 +36,15 @@ type BaseResTime struct {
 	UpdatedAt *time.Time `json:"updated_at"`
 	DeletedAt *time.Time `json:"deleted_at"`
 }
+
+// BaseResSingle envelope struct of a single entity of any kind, its fields match the named envelopes, e.g. dto.ResUserSingle(res)
+type BaseResSingle[T any] struct {
+	BaseRes
+	Data *T `json:"data"`
+}
+
+// BaseResList envelope struct of a page of entities of any kind, its fields match the named envelopes, e.g. dto.ResUserList(res)
+type BaseResList[T any] struct {
+	BaseResPagination
+	Data []T `json:"data"`
+}
@@ -0,0 +1,29 @@
+package dto
+
+import (
+	"time"
+
+	dtobase "github.com/alxhtp/monogo/pkg/dto/base"
+)
+
+type ReqCreateTenant struct {
+	// ID is part of the schema name, lowercase letters, digits and underscores starting with a letter
+	ID string `json:"id" validate:"required,max=48"`
+}
+
+type ResTenant struct {
+	ID         string     `json:"id"`
+	Schema     string     `json:"schema"`
+	Migrations int        `json:"migrations"`
+	MigratedAt *time.Time `json:"migrated_at"`
+}
+
+type ResTenantSingle struct {
+	dtobase.BaseRes
+	Data *ResTenant `json:"data"`
+}
+
+type ResTenantList struct {
+	dtobase.BaseRes
+	Data []ResTenant `json:"data"`
+}
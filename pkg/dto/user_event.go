@@ -0,0 +1,37 @@
+package dto
+
+import "time"
+
+type ReqGetUserEvent struct {
+	IDs         *string `query:"ids"` // comma separated string of uuids
+	Name        *string `query:"name"`
+	Email       *string `query:"email"`
+	Status      *int    `query:"status"`
+	Sex         *string `query:"sex"`
+	Address     *string `query:"address"`
+	Phone       *string `query:"phone"`
+	LastEventID *int64  `query:"last-event-id"` // the Last-Event-ID header takes precedence
+}
+
+// Filter returns the user filter of the stream request
+func (r *ReqGetUserEvent) Filter() ReqGetUser {
+	return ReqGetUser{
+		IDs:     r.IDs,
+		Name:    r.Name,
+		Email:   r.Email,
+		Status:  r.Status,
+		Sex:     r.Sex,
+		Address: r.Address,
+		Phone:   r.Phone,
+	}
+}
+
+type ResUserEvent struct {
+	ID            int64     `json:"id"`
+	Type          string    `json:"type"`
+	User          ResUser   `json:"user"`
+	ChangedFields []string  `json:"changed_fields,omitempty"`
+	OldStatus     *int      `json:"old_status,omitempty"`
+	NewStatus     *int      `json:"new_status,omitempty"`
+	OccurredAt    time.Time `json:"occurred_at"`
+}
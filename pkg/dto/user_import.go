@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"io"
+	"time"
+
+	dtobase "github.com/alxhtp/monogo/pkg/dto/base"
+	"github.com/google/uuid"
+)
+
+type ReqImportUser struct {
+	DryRun bool `query:"dry_run" form:"dry_run"`
+	// File is the csv, read row by row
+	File io.Reader `json:"-" form:"-"`
+}
+
+type ResUserImportRow struct {
+	Row    int        `json:"row"`
+	Email  string     `json:"email"`
+	Valid  bool       `json:"valid"`
+	ID     *uuid.UUID `json:"id,omitempty"`
+	Errors []string   `json:"errors,omitempty"`
+}
+
+type ResUserImportReport struct {
+	DryRun    bool               `json:"dry_run"`
+	Total     int                `json:"total"`
+	Succeeded int                `json:"succeeded"`
+	Failed    int                `json:"failed"`
+	Rows      []ResUserImportRow `json:"rows,omitempty"`
+	Job       *ResUserImportJob  `json:"job,omitempty"`
+}
+
+type ResUserImportJob struct {
+	ID         uuid.UUID  `json:"id"`
+	Status     string     `json:"status"`
+	Total      int        `json:"total"`
+	Processed  int        `json:"processed"`
+	Succeeded  int        `json:"succeeded"`
+	Failed     int        `json:"failed"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+type ResUserImport struct {
+	dtobase.BaseRes
+	Data *ResUserImportReport `json:"data"`
+}
+
+type ResUserImportJobSingle struct {
+	dtobase.BaseRes
+	Data *ResUserImportJob `json:"data"`
+}
@@ -0,0 +1,79 @@
+package dtov2
+
+import (
+	"github.com/alxhtp/monogo/pkg/constant"
+	"github.com/alxhtp/monogo/pkg/dto"
+	dtobase "github.com/alxhtp/monogo/pkg/dto/base"
+	"github.com/google/uuid"
+)
+
+// User status labels, v2 exposes the status by name instead of its numeric value
+const (
+	UserStatusInactive = "inactive"
+	UserStatusActive   = "active"
+	UserStatusBanned   = "banned"
+)
+
+var userStatusByLabel = map[string]constant.UserStatus{
+	UserStatusInactive: constant.UserStatusInactive,
+	UserStatusActive:   constant.UserStatusActive,
+	UserStatusBanned:   constant.UserStatusBanned,
+}
+
+// UserStatusLabel returns the label of status, or an empty string for an unknown status
+func UserStatusLabel(status int) string {
+	for label, value := range userStatusByLabel {
+		if int(value) == status {
+			return label
+		}
+	}
+	return ""
+}
+
+// ParseUserStatus returns the numeric status of label
+func ParseUserStatus(label string) (int, bool) {
+	status, ok := userStatusByLabel[label]
+	return int(status), ok
+}
+
+type ReqCreateUser struct {
+	Name     string           `json:"name"`
+	Email    string           `json:"email"`
+	Metadata dto.UserMetadata `json:"metadata"`
+}
+
+type ReqUpdateUser struct {
+	Name     *string           `json:"name"`
+	Email    *string           `json:"email"`
+	Status   *string           `json:"status" enums:"inactive,active,banned"`
+	Metadata *dto.UserMetadata `json:"metadata"`
+}
+
+type ReqGetUser struct {
+	IDs     *string `query:"ids"` // comma separated string of uuids
+	Name    *string `query:"name"`
+	Email   *string `query:"email"`
+	Status  *string `query:"status"`
+	Sex     *string `query:"sex"`
+	Address *string `query:"address"`
+	Phone   *string `query:"phone"`
+	dtobase.BaseReqQueryPagination
+}
+
+type ResUser struct {
+	ID       uuid.UUID        `json:"id"`
+	Name     string           `json:"name"`
+	Email    string           `json:"email"`
+	Status   string           `json:"status" enums:"inactive,active,banned"`
+	Metadata dto.UserMetadata `json:"metadata"`
+}
+
+type ResUserSingle struct {
+	dtobase.BaseRes
+	Data *ResUser `json:"data"`
+}
+
+type ResUserList struct {
+	dtobase.BaseResPagination
+	Data []ResUser `json:"data"`
+}
@@ -0,0 +1,86 @@
+package dto
+
+import (
+	"time"
+
+	dtobase "github.com/alxhtp/monogo/pkg/dto/base"
+	"github.com/google/uuid"
+)
+
+type ReqCreateWebhook struct {
+	Name   string   `json:"name" validate:"required,max=255"`
+	URL    string   `json:"url" validate:"required,http_url"`
+	Events []string `json:"events" validate:"required,min=1,dive,oneof=* user.created user.updated user.status_changed user.deleted"`
+	Secret *string  `json:"secret" validate:"omitempty,min=16,max=255"` // generated when empty
+	Active *bool    `json:"active"`
+}
+
+type ReqUpdateWebhook struct {
+	Name   *string   `json:"name" validate:"omitempty,max=255"`
+	URL    *string   `json:"url" validate:"omitempty,http_url"`
+	Events *[]string `json:"events" validate:"omitempty,min=1,dive,oneof=* user.created user.updated user.status_changed user.deleted"`
+	Secret *string   `json:"secret" validate:"omitempty,min=16,max=255"`
+	Active *bool     `json:"active"`
+}
+
+type ReqGetWebhook struct {
+	IDs    *string `query:"ids"` // comma separated string of uuids
+	Name   *string `query:"name"`
+	Active *bool   `query:"active"`
+	Event  *string `query:"event"`
+	dtobase.BaseReqQueryPagination
+}
+
+type ResWebhook struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Active    bool      `json:"active"`
+	Secret    string    `json:"secret,omitempty"` // only returned when the webhook is created
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ResWebhookSingle struct {
+	dtobase.BaseRes
+	Data *ResWebhook `json:"data"`
+}
+
+type ResWebhookList struct {
+	dtobase.BaseResPagination
+	Data []ResWebhook `json:"data"`
+}
+
+type ReqGetWebhookDelivery struct {
+	Status    *string `query:"status"`
+	EventType *string `query:"event-type"`
+	dtobase.BaseReqQueryPagination
+}
+
+type ResWebhookDelivery struct {
+	ID             uuid.UUID  `json:"id"`
+	WebhookID      uuid.UUID  `json:"webhook_id"`
+	EventID        int64      `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	Redeliveries   int        `json:"redeliveries"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	LastStatusCode *int       `json:"last_status_code"`
+	LastError      string     `json:"last_error,omitempty"`
+	LastResponse   string     `json:"last_response,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+type ResWebhookDeliverySingle struct {
+	dtobase.BaseRes
+	Data *ResWebhookDelivery `json:"data"`
+}
+
+type ResWebhookDeliveryList struct {
+	dtobase.BaseResPagination
+	Data []ResWebhookDelivery `json:"data"`
+}
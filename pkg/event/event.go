@@ -0,0 +1,28 @@
+package event
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type Type string
+
+// Event is a domain event recorded into the outbox alongside the change it describes
+type Event interface {
+	EventType() Type
+	AggregateType() string
+	AggregateID() string
+}
+
+// Message is the envelope delivered to publishers.
+// ID is unique and increasing per aggregate, consumers use it to drop redeliveries.
+// Tenant is set with multi-tenancy enabled, IDs are only unique within a tenant.
+type Message struct {
+	ID            int64           `json:"id"`
+	Tenant        string          `json:"tenant,omitempty"`
+	Type          Type            `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+}
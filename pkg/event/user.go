@@ -0,0 +1,49 @@
+package event
+
+import (
+	"github.com/alxhtp/monogo/pkg/dto"
+)
+
+const (
+	UserCreatedType       Type = "user.created"
+	UserUpdatedType       Type = "user.updated"
+	UserStatusChangedType Type = "user.status_changed"
+	UserDeletedType       Type = "user.deleted"
+
+	UserAggregateType = "user"
+)
+
+type UserCreated struct {
+	User dto.ResUser `json:"user"`
+}
+
+func (e UserCreated) EventType() Type       { return UserCreatedType }
+func (e UserCreated) AggregateType() string { return UserAggregateType }
+func (e UserCreated) AggregateID() string   { return e.User.ID.String() }
+
+type UserUpdated struct {
+	User          dto.ResUser `json:"user"`
+	ChangedFields []string    `json:"changed_fields"`
+}
+
+func (e UserUpdated) EventType() Type       { return UserUpdatedType }
+func (e UserUpdated) AggregateType() string { return UserAggregateType }
+func (e UserUpdated) AggregateID() string   { return e.User.ID.String() }
+
+type UserStatusChanged struct {
+	User      dto.ResUser `json:"user"`
+	OldStatus int         `json:"old_status"`
+	NewStatus int         `json:"new_status"`
+}
+
+func (e UserStatusChanged) EventType() Type       { return UserStatusChangedType }
+func (e UserStatusChanged) AggregateType() string { return UserAggregateType }
+func (e UserStatusChanged) AggregateID() string   { return e.User.ID.String() }
+
+type UserDeleted struct {
+	User dto.ResUser `json:"user"`
+}
+
+func (e UserDeleted) EventType() Type       { return UserDeletedType }
+func (e UserDeleted) AggregateType() string { return UserAggregateType }
+func (e UserDeleted) AggregateID() string   { return e.User.ID.String() }
@@ -0,0 +1,78 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	databasehelper "github.com/alxhtp/monogo/pkg/helper/database"
+
+	"gorm.io/gorm"
+)
+
+// DatabaseChecker pings the database and reports the connection pool usage
+func DatabaseChecker(db *gorm.DB) Checker {
+	return CheckFunc("database", func(ctx context.Context) (any, error) {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+		if err := sqlDB.PingContext(ctx); err != nil {
+			return nil, fmt.Errorf("ping: %w", err)
+		}
+
+		stats := sqlDB.Stats()
+		return map[string]int{
+			"open_connections": stats.OpenConnections,
+			"in_use":           stats.InUse,
+			"idle":             stats.Idle,
+		}, nil
+	})
+}
+
+// MigrationChecker reads the latest applied sql-migrate migration from table.
+// When expected is set the check fails while the applied version sorts before it,
+// migration ids start with a timestamp so a prefix such as 20261019080000 is enough.
+func MigrationChecker(db *gorm.DB, table, expected string) Checker {
+	return CheckFunc("migrations", func(ctx context.Context) (any, error) {
+		var versions []string
+		// migrations are applied on the primary, the version of a lagging replica would be reported otherwise
+		if err := db.WithContext(databasehelper.WithPrimary(ctx)).Table(table).Order("id DESC").Limit(1).Pluck("id", &versions).Error; err != nil {
+			return nil, fmt.Errorf("read %s: %w", table, err)
+		}
+		if len(versions) == 0 {
+			return nil, errors.New("no migration applied")
+		}
+
+		details := map[string]string{"version": versions[0]}
+		if expected != "" {
+			details["expected"] = expected
+			if versions[0] < expected {
+				return details, fmt.Errorf("migration %s is behind expected %s", versions[0], expected)
+			}
+		}
+
+		return details, nil
+	})
+}
+
+// DiskSpaceChecker fails when the filesystem of path has less than minFreeBytes available
+func DiskSpaceChecker(path string, minFreeBytes uint64) Checker {
+	return CheckFunc("disk", func(ctx context.Context) (any, error) {
+		free, err := diskFree(path)
+		if err != nil {
+			return nil, fmt.Errorf("stat %s: %w", path, err)
+		}
+
+		details := map[string]any{
+			"path":           path,
+			"free_bytes":     free,
+			"min_free_bytes": minFreeBytes,
+		}
+		if free < minFreeBytes {
+			return details, fmt.Errorf("%d bytes free, below %d", free, minFreeBytes)
+		}
+
+		return details, nil
+	})
+}
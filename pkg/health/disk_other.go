@@ -0,0 +1,9 @@
+//go:build !linux && !darwin
+
+package health
+
+import "errors"
+
+func diskFree(string) (uint64, error) {
+	return 0, errors.New("disk space check is not supported on this platform")
+}
@@ -0,0 +1,176 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+
+	defaultCheckTimeout = 2 * time.Second
+)
+
+var ErrShuttingDown = errors.New("shutting down")
+
+// Checker is a single dependency check. Details are optional and are rendered next to the status.
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) (details any, err error)
+}
+
+// CheckResult is the outcome of one checker
+type CheckResult struct {
+	Status     Status `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+	Details    any    `json:"details,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Report is the outcome of a liveness or readiness probe
+type Report struct {
+	Status Status                 `json:"status"`
+	Error  string                 `json:"error,omitempty"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// Up reports whether the probe passed
+func (r Report) Up() bool {
+	return r.Status == StatusUp
+}
+
+// Registry runs the registered checkers for readiness probes.
+// Readiness turns down for good once SetShuttingDown is called, so load balancers stop routing
+// new requests while in-flight ones drain.
+type Registry struct {
+	mu           sync.RWMutex
+	checkers     []Checker
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+func NewRegistry(timeout time.Duration) *Registry {
+	if timeout <= 0 {
+		timeout = defaultCheckTimeout
+	}
+
+	return &Registry{timeout: timeout}
+}
+
+// Register adds checkers to the readiness probe
+func (r *Registry) Register(checkers ...Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checkers = append(r.checkers, checkers...)
+}
+
+// SetShuttingDown turns readiness down
+func (r *Registry) SetShuttingDown() {
+	r.shuttingDown.Store(true)
+}
+
+// Live reports whether the process is able to serve, dependencies are not checked
+// so a database outage does not get the process restarted
+func (r *Registry) Live() Report {
+	return Report{Status: StatusUp}
+}
+
+// Ready runs every checker concurrently, each bounded by the registry timeout
+func (r *Registry) Ready(ctx context.Context) Report {
+	if r.shuttingDown.Load() {
+		return Report{Status: StatusDown, Error: ErrShuttingDown.Error()}
+	}
+
+	r.mu.RLock()
+	checkers := append([]Checker(nil), r.checkers...)
+	r.mu.RUnlock()
+
+	results := make([]CheckResult, len(checkers))
+	var wg sync.WaitGroup
+	for i, checker := range checkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = r.run(ctx, checker)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp, Checks: make(map[string]CheckResult, len(checkers))}
+	for i, checker := range checkers {
+		report.Checks[checker.Name()] = results[i]
+		if results[i].Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+
+	return report
+}
+
+// run waits for the checker at most the registry timeout, also when the checker ignores ctx
+func (r *Registry) run(ctx context.Context, checker Checker) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	type outcome struct {
+		details any
+		err     error
+	}
+
+	start := time.Now()
+	done := make(chan outcome, 1)
+	go func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				done <- outcome{err: fmt.Errorf("panic: %v", rec)}
+			}
+		}()
+		details, err := checker.Check(ctx)
+		done <- outcome{details: details, err: err}
+	}()
+
+	var res outcome
+	select {
+	case res = <-done:
+	case <-ctx.Done():
+		res = outcome{err: fmt.Errorf("timed out after %s", r.timeout)}
+	}
+
+	result := CheckResult{
+		Status:     StatusUp,
+		DurationMs: time.Since(start).Milliseconds(),
+		Details:    res.details,
+	}
+	if res.err != nil {
+		result.Status = StatusDown
+		result.Error = res.err.Error()
+	}
+
+	return result
+}
+
+type checkFunc struct {
+	name  string
+	check func(ctx context.Context) (any, error)
+}
+
+// CheckFunc wraps a function as a custom Checker
+func CheckFunc(name string, check func(ctx context.Context) (details any, err error)) Checker {
+	return checkFunc{name: name, check: check}
+}
+
+func (c checkFunc) Name() string {
+	return c.name
+}
+
+func (c checkFunc) Check(ctx context.Context) (any, error) {
+	return c.check(ctx)
+}
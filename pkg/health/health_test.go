@@ -0,0 +1,79 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alxhtp/monogo/pkg/health"
+)
+
+func up(name string) health.Checker {
+	return health.CheckFunc(name, func(context.Context) (any, error) {
+		return map[string]int{"open_connections": 1}, nil
+	})
+}
+
+func TestReadyIsDownWhenACheckFails(t *testing.T) {
+	registry := health.NewRegistry(time.Second)
+	registry.Register(up("database"), health.CheckFunc("cache", func(context.Context) (any, error) {
+		return nil, errors.New("connection refused")
+	}))
+
+	report := registry.Ready(context.Background())
+	if report.Up() {
+		t.Fatalf("report = %+v, want down", report)
+	}
+	if check := report.Checks["database"]; check.Status != health.StatusUp || check.Details == nil {
+		t.Fatalf("database = %+v, want up with its details", check)
+	}
+	if check := report.Checks["cache"]; check.Status != health.StatusDown || check.Error != "connection refused" {
+		t.Fatalf("cache = %+v, want down with its error", check)
+	}
+	if !registry.Live().Up() {
+		t.Fatal("liveness depends on the dependencies")
+	}
+}
+
+func TestReadyBoundsChecksByTheTimeout(t *testing.T) {
+	registry := health.NewRegistry(20 * time.Millisecond)
+	release := make(chan struct{})
+	defer close(release)
+	registry.Register(
+		up("database"),
+		// a check ignoring its context still times out
+		health.CheckFunc("stuck", func(context.Context) (any, error) {
+			<-release
+			return nil, nil
+		}),
+		health.CheckFunc("panicking", func(context.Context) (any, error) {
+			panic("boom")
+		}),
+	)
+
+	start := time.Now()
+	report := registry.Ready(context.Background())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("ready took %s", elapsed)
+	}
+	if report.Up() || report.Checks["stuck"].Status != health.StatusDown || report.Checks["panicking"].Status != health.StatusDown {
+		t.Fatalf("report = %+v, want the stuck and panicking checks down", report)
+	}
+	if report.Checks["database"].Status != health.StatusUp {
+		t.Fatalf("database = %+v, want up", report.Checks["database"])
+	}
+}
+
+func TestReadyIsDownWhileShuttingDown(t *testing.T) {
+	registry := health.NewRegistry(time.Second)
+	registry.Register(up("database"))
+	if !registry.Ready(context.Background()).Up() {
+		t.Fatal("ready is down before shutting down")
+	}
+
+	registry.SetShuttingDown()
+	if report := registry.Ready(context.Background()); report.Up() || report.Error != health.ErrShuttingDown.Error() {
+		t.Fatalf("report = %+v, want down while shutting down", report)
+	}
+}
@@ -0,0 +1,126 @@
+package contexthelper
+
+import "context"
+
+type contextKey string
+
+// Context keys are exported so fiber handlers can store them with c.Locals,
+// fasthttp resolves ctx.Value lookups against the request user values.
+const (
+	KeyRequestID contextKey = "request_id"
+	KeyActor     contextKey = "actor"
+	// KeyPrincipal holds the verified identity of the caller, see GetPrincipal
+	KeyPrincipal contextKey = "principal"
+	// KeyClientIdentity holds the verified TLS client certificate of the caller
+	KeyClientIdentity contextKey = "client_identity"
+	// KeyTenant holds the tenant the request is made for when multi-tenancy is enabled
+	KeyTenant contextKey = "tenant"
+)
+
+const (
+	// ActorAnonymous is used when a request does not identify its caller
+	ActorAnonymous = "anonymous"
+	// ActorSystem is used for changes made outside of a request, e.g. background jobs
+	ActorSystem = "system"
+	// ActorCLI is used for changes made from the command line
+	ActorCLI = "cli"
+)
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, KeyRequestID, requestID)
+}
+
+func GetRequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	requestID, _ := ctx.Value(KeyRequestID).(string)
+	return requestID
+}
+
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, KeyActor, actor)
+}
+
+// GetActor returns the caller identity, defaults to ActorSystem outside of a request
+func GetActor(ctx context.Context) string {
+	if ctx == nil {
+		return ActorSystem
+	}
+
+	if actor, ok := ctx.Value(KeyActor).(string); ok && actor != "" {
+		return actor
+	}
+
+	return ActorSystem
+}
+
+func WithPrincipal(ctx context.Context, principal string) context.Context {
+	return context.WithValue(ctx, KeyPrincipal, principal)
+}
+
+// GetPrincipal returns the verified identity of the caller, a bearer token subject or a client certificate
+// common name, empty when the caller is not authenticated. Unlike GetActor it cannot be set by a request header.
+func GetPrincipal(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	principal, _ := ctx.Value(KeyPrincipal).(string)
+	return principal
+}
+
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, KeyTenant, tenant)
+}
+
+// GetTenant returns the tenant the context is bound to, empty when there is none
+func GetTenant(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	tenant, _ := ctx.Value(KeyTenant).(string)
+	return tenant
+}
+
+// Detach returns a background context holding the caller values of ctx: request id, actor, principal, client identity
+// and tenant. Work outliving a request runs under it, fasthttp recycles the request context once the handler returns.
+func Detach(ctx context.Context) context.Context {
+	detached := WithRequestID(context.Background(), GetRequestID(ctx))
+	if actor, ok := ctx.Value(KeyActor).(string); ok && actor != "" {
+		detached = WithActor(detached, actor)
+	}
+	if principal := GetPrincipal(ctx); principal != "" {
+		detached = WithPrincipal(detached, principal)
+	}
+	if identity := GetClientIdentity(ctx); identity != nil {
+		detached = WithClientIdentity(detached, identity)
+	}
+	return WithTenant(detached, GetTenant(ctx))
+}
+
+// ClientIdentity describes the client certificate presented over mutual TLS
+type ClientIdentity struct {
+	CommonName   string   `json:"common_name"`
+	Organization []string `json:"organization,omitempty"`
+	DNSNames     []string `json:"dns_names,omitempty"`
+	URIs         []string `json:"uris,omitempty"`
+	SerialNumber string   `json:"serial_number"`
+	Fingerprint  string   `json:"fingerprint"`
+}
+
+func WithClientIdentity(ctx context.Context, identity *ClientIdentity) context.Context {
+	return context.WithValue(ctx, KeyClientIdentity, identity)
+}
+
+// GetClientIdentity returns the caller client certificate, nil when none was presented
+func GetClientIdentity(ctx context.Context) *ClientIdentity {
+	if ctx == nil {
+		return nil
+	}
+
+	identity, _ := ctx.Value(KeyClientIdentity).(*ClientIdentity)
+	return identity
+}
@@ -0,0 +1,233 @@
+package databasehelper
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+	jsonconvert "github.com/alxhtp/monogo/pkg/jsonconvert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var AuditTableName = "audit_logs"
+
+const (
+	AuditOperationCreate = "create"
+	AuditOperationUpdate = "update"
+	AuditOperationDelete = "delete"
+
+	auditRedactedValue = "[redacted]"
+
+	auditSnapshotKey = "audit:snapshot"
+	auditRecordedKey = "audit:recorded"
+)
+
+// AuditEntity overrides the entity type written into the audit log, defaults to the table name
+type AuditEntity interface {
+	AuditEntityType() string
+}
+
+// AuditRedactedEntity lists columns whose values must never be written into the audit log
+type AuditRedactedEntity interface {
+	AuditRedactedColumns() []string
+}
+
+// AuditChange holds a single field change
+type AuditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// SnapshotBeforeChange loads the rows matched by the update/delete statement,
+// so the after hook can compute the diff. Must be called from a before hook.
+func SnapshotBeforeChange(tx *gorm.DB) error {
+	where, ok := tx.Statement.Clauses[clause.Where{}.Name()].Expression.(clause.Where)
+	if !ok || len(where.Exprs) == 0 {
+		return nil
+	}
+
+	rows, err := loadAuditRows(tx, clause.Where{Exprs: where.Exprs})
+	if err != nil {
+		return fmt.Errorf("audit snapshot: %w", err)
+	}
+
+	tx.Statement.Settings.Store(auditSnapshotKey, rows)
+	return nil
+}
+
+// RecordCreation writes the audit log of a created row. Must be called from AfterCreate.
+func RecordCreation(tx *gorm.DB, id any) error {
+	rows, err := loadAuditRows(tx, clause.Where{Exprs: []clause.Expression{clause.Eq{Column: ColID, Value: id}}})
+	if err != nil {
+		return fmt.Errorf("audit creation: %w", err)
+	}
+
+	for entityID, after := range rows {
+		if err := writeAuditLog(tx, AuditOperationCreate, entityID, nil, after); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RecordUpdate writes the audit log of every row changed since SnapshotBeforeChange
+func RecordUpdate(tx *gorm.DB) error {
+	return recordChange(tx, AuditOperationUpdate)
+}
+
+// RecordDeletion writes the audit log of every row deleted since SnapshotBeforeChange
+func RecordDeletion(tx *gorm.DB) error {
+	return recordChange(tx, AuditOperationDelete)
+}
+
+func recordChange(tx *gorm.DB, operation string) error {
+	// hooks are called once per element when the statement holds a slice
+	if _, recorded := tx.Statement.Settings.LoadOrStore(auditRecordedKey, true); recorded {
+		return nil
+	}
+
+	value, ok := tx.Statement.Settings.Load(auditSnapshotKey)
+	if !ok {
+		return nil
+	}
+	beforeRows, _ := value.(map[string]map[string]any)
+	if len(beforeRows) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(beforeRows))
+	for id := range beforeRows {
+		ids = append(ids, id)
+	}
+
+	afterRows, err := loadAuditRows(tx, clause.Where{Exprs: []clause.Expression{clause.IN{Column: ColID, Values: toAnySlice(ids)}}})
+	if err != nil {
+		return fmt.Errorf("audit %s: %w", operation, err)
+	}
+
+	for id, before := range beforeRows {
+		after := afterRows[id]
+		if operation == AuditOperationUpdate && len(diffAuditRows(before, after)) == 0 {
+			continue
+		}
+		if err := writeAuditLog(tx, operation, id, before, after); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func loadAuditRows(tx *gorm.DB, where clause.Where) (map[string]map[string]any, error) {
+	var rows []map[string]any
+	if err := tx.Table(tx.Statement.Table).Clauses(where).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	var redacted []string
+	if entity, ok := tx.Statement.Model.(AuditRedactedEntity); ok {
+		redacted = entity.AuditRedactedColumns()
+	}
+
+	out := make(map[string]map[string]any, len(rows))
+	for _, row := range rows {
+		for column, value := range row {
+			row[column] = normalizeAuditValue(value)
+		}
+		for _, column := range redacted {
+			if _, ok := row[column]; ok {
+				row[column] = auditRedactedValue
+			}
+		}
+		out[fmt.Sprint(row[ColID])] = row
+	}
+
+	return out, nil
+}
+
+func writeAuditLog(tx *gorm.DB, operation, entityID string, before, after map[string]any) error {
+	ctx := tx.Statement.Context
+
+	entityType := tx.Statement.Table
+	if entity, ok := tx.Statement.Model.(AuditEntity); ok {
+		entityType = entity.AuditEntityType()
+	}
+
+	record := map[string]any{
+		"actor":       contexthelper.GetActor(ctx),
+		"request_id":  contexthelper.GetRequestID(ctx),
+		"entity_type": entityType,
+		"entity_id":   entityID,
+		"operation":   operation,
+		"before":      auditJSON(before),
+		"after":       auditJSON(after),
+		"changes":     auditJSON(diffAuditRows(before, after)),
+		ColCreatedAt:  time.Now(),
+	}
+
+	if err := tx.Table(AuditTableName).Create(record).Error; err != nil {
+		return fmt.Errorf("write audit log: %w", err)
+	}
+
+	return nil
+}
+
+// diffAuditRows returns changed columns, updated_at is ignored as it changes on every write
+func diffAuditRows(before, after map[string]any) map[string]AuditChange {
+	out := make(map[string]AuditChange)
+
+	for column, value := range after {
+		if column == ColUpdatedAt {
+			continue
+		}
+		if jsonconvert.Serialize(before[column]) != jsonconvert.Serialize(value) {
+			out[column] = AuditChange{Before: before[column], After: value}
+		}
+	}
+
+	for column, value := range before {
+		if _, ok := after[column]; !ok && column != ColUpdatedAt {
+			out[column] = AuditChange{Before: value, After: nil}
+		}
+	}
+
+	return out
+}
+
+// normalizeAuditValue keeps json columns as json instead of escaped strings
+func normalizeAuditValue(value any) any {
+	var raw []byte
+	switch v := value.(type) {
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return value
+	}
+
+	if len(raw) > 0 && (raw[0] == '{' || raw[0] == '[') && json.Valid(raw) {
+		return json.RawMessage(raw)
+	}
+
+	return string(raw)
+}
+
+func auditJSON[T any](value map[string]T) any {
+	if len(value) == 0 {
+		return nil
+	}
+
+	return jsonconvert.Serialize(value)
+}
+
+func toAnySlice[T any](items []T) []any {
+	out := make([]any, len(items))
+	for i := range items {
+		out[i] = items[i]
+	}
+	return out
+}
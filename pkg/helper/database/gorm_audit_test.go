@@ -0,0 +1,219 @@
+package databasehelper
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// auditNote is an audited model hooked the way entitybase.Base is, its secret column is redacted
+type auditNote struct {
+	ID        uuid.UUID `gorm:"column:id;primaryKey;type:uuid"`
+	Body      string    `gorm:"column:body"`
+	Secret    string    `gorm:"column:secret"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+}
+
+func (n *auditNote) TableName() string {
+	return "notes"
+}
+
+func (n *auditNote) AuditRedactedColumns() []string {
+	return []string{"secret"}
+}
+
+func (n *auditNote) AfterCreate(tx *gorm.DB) error {
+	return RecordCreation(tx, n.ID)
+}
+
+func (n *auditNote) BeforeUpdate(tx *gorm.DB) error {
+	return SnapshotBeforeChange(tx)
+}
+
+func (n *auditNote) AfterUpdate(tx *gorm.DB) error {
+	return RecordUpdate(tx)
+}
+
+// auditLogColumns are the columns of the audit log insert, gorm writes the columns of a map in sorted order
+var auditLogColumns = []string{"actor", "after", "before", "changes", "created_at", "entity_id", "entity_type", "operation", "request_id"}
+
+// capturedArg matches any value and keeps it
+type capturedArg struct {
+	value *driver.Value
+}
+
+func (a capturedArg) Match(v driver.Value) bool {
+	*a.value = v
+	return true
+}
+
+// expectAuditLog expects the insert of an audit log and returns its values by column once executed
+func expectAuditLog(mock sqlmock.Sqlmock) map[string]*driver.Value {
+	values := make(map[string]*driver.Value, len(auditLogColumns))
+	args := make([]driver.Value, len(auditLogColumns))
+	for i, column := range auditLogColumns {
+		values[column] = new(driver.Value)
+		args[i] = capturedArg{value: values[column]}
+	}
+	mock.ExpectExec(`INSERT INTO "audit_logs"`).WithArgs(args...).WillReturnResult(sqlmock.NewResult(0, 1))
+	return values
+}
+
+func noteRows(id uuid.UUID, body string) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "body", "secret", "updated_at"}).AddRow(id.String(), body, "hunter2", time.Now())
+}
+
+func auditContext() context.Context {
+	ctx := contexthelper.WithRequestID(context.Background(), "req-1")
+	return contexthelper.WithActor(ctx, "alice")
+}
+
+// assertAuditJSON compares a json column of the audit log with want
+func assertAuditJSON(t *testing.T, column string, got *driver.Value, want string) {
+	t.Helper()
+
+	if want == "" {
+		if *got != nil {
+			t.Fatalf("%s = %v, want null", column, *got)
+		}
+		return
+	}
+	raw, ok := (*got).(string)
+	if !ok {
+		t.Fatalf("%s = %#v, want json", column, *got)
+	}
+	var gotValue, wantValue any
+	if err := json.Unmarshal([]byte(raw), &gotValue); err != nil {
+		t.Fatalf("%s: %v", column, err)
+	}
+	if err := json.Unmarshal([]byte(want), &wantValue); err != nil {
+		t.Fatalf("want %s: %v", column, err)
+	}
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		t.Fatalf("%s = %s, want %s", column, raw, want)
+	}
+}
+
+func TestRecordCreationWritesTheCreatedRow(t *testing.T) {
+	db, mock := newMockDB(t)
+	id := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO "notes"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT \* FROM "notes"`).WithArgs(id).WillReturnRows(noteRows(id, "hello"))
+	log := expectAuditLog(mock)
+	mock.ExpectCommit()
+
+	if err := db.WithContext(auditContext()).Create(&auditNote{ID: id, Body: "hello", Secret: "hunter2"}).Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+
+	for column, want := range map[string]string{"actor": "alice", "request_id": "req-1", "entity_type": "notes", "entity_id": id.String(), "operation": AuditOperationCreate} {
+		if *log[column] != want {
+			t.Errorf("%s = %v, want %s", column, *log[column], want)
+		}
+	}
+	assertAuditJSON(t, "before", log["before"], "")
+	var after map[string]any
+	if err := json.Unmarshal([]byte((*log["after"]).(string)), &after); err != nil {
+		t.Fatalf("after: %v", err)
+	}
+	if after["body"] != "hello" || after["secret"] != auditRedactedValue {
+		t.Fatalf("after = %v, want the body and the redacted secret", after)
+	}
+}
+
+func TestRecordUpdateWritesTheChangedColumns(t *testing.T) {
+	db, mock := newMockDB(t)
+	id := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "notes" WHERE id = \$1`).WithArgs(id).WillReturnRows(noteRows(id, "old"))
+	mock.ExpectExec(`UPDATE "notes"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT \* FROM "notes"`).WithArgs(id.String()).WillReturnRows(noteRows(id, "new"))
+	log := expectAuditLog(mock)
+	mock.ExpectCommit()
+
+	err := db.WithContext(auditContext()).Model(&auditNote{}).Where("id = ?", id).Updates(map[string]any{"body": "new"}).Error
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+
+	if *log["operation"] != AuditOperationUpdate || *log["actor"] != "alice" || *log["entity_id"] != id.String() {
+		t.Fatalf("audit log = %v %v %v", *log["operation"], *log["actor"], *log["entity_id"])
+	}
+	assertAuditJSON(t, "changes", log["changes"], `{"body": {"before": "old", "after": "new"}}`)
+}
+
+func TestRecordUpdateSkipsUnchangedRows(t *testing.T) {
+	db, mock := newMockDB(t)
+	id := uuid.New()
+
+	// only updated_at changes, no audit log is written
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "notes" WHERE id = \$1`).WithArgs(id).WillReturnRows(noteRows(id, "same"))
+	mock.ExpectExec(`UPDATE "notes"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT \* FROM "notes"`).WithArgs(id.String()).WillReturnRows(noteRows(id, "same"))
+	mock.ExpectCommit()
+
+	err := db.WithContext(auditContext()).Model(&auditNote{}).Where("id = ?", id).Updates(map[string]any{"body": "same"}).Error
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiffAuditRows(t *testing.T) {
+	before := map[string]any{"body": "old", "views": 1, "gone": "x", ColUpdatedAt: "yesterday", "tags": json.RawMessage(`["a"]`)}
+	after := map[string]any{"body": "new", "views": 1, "added": true, ColUpdatedAt: "today", "tags": json.RawMessage(`["a"]`)}
+
+	want := map[string]AuditChange{
+		"body":  {Before: "old", After: "new"},
+		"gone":  {Before: "x", After: nil},
+		"added": {Before: nil, After: true},
+	}
+	if got := diffAuditRows(before, after); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if got := diffAuditRows(before, before); len(got) != 0 {
+		t.Fatalf("unchanged rows: got %v", got)
+	}
+}
+
+func TestNormalizeAuditValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  any
+	}{
+		{name: "json object bytes", value: []byte(`{"a":1}`), want: json.RawMessage(`{"a":1}`)},
+		{name: "json array string", value: `["a"]`, want: json.RawMessage(`["a"]`)},
+		{name: "text bytes", value: []byte("hello"), want: "hello"},
+		{name: "invalid json", value: "{not json", want: "{not json"},
+		{name: "number", value: int64(3), want: int64(3)},
+		{name: "nil", value: nil, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeAuditValue(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
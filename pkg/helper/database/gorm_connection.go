@@ -2,33 +2,69 @@ package databasehelper
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"log/slog"
 	"sync"
 	"time"
 
 	"github.com/alxhtp/monogo/config"
+	"github.com/alxhtp/monogo/pkg/metrics"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
 var (
-	dbOnceByKey = map[string]*sync.Once{}
-	dbByKey     = map[string]*gorm.DB{}
+	dbOnceByKey  = map[string]*sync.Once{}
+	dbByKey      = map[string]*gorm.DB{}
+	replicasByDB = map[*gorm.DB]*replicaPlugin{}
+	sessionsByDB = map[*gorm.DB]*tenantSession{}
 )
 
 const (
-	defaultConnName   = "default"
-	defaultSearchPath = "public"
+	defaultConnName = "default"
+	// SharedSchema holds the tables of the service, tenant schemas are created next to it
+	SharedSchema      = "monogo"
+	defaultSearchPath = SharedSchema + ",public"
+
+	defaultConnectBackoff    = time.Second
+	defaultConnectMaxBackoff = 30 * time.Second
 )
 
 func NewGormDB(ctx context.Context, cfg *config.DatabaseConfig) (*gorm.DB, error) {
-	// Compose key for singleton map. In future, these can be passed/derived.
-	connName := defaultConnName
-	searchPath := defaultSearchPath
+	return openGormForKey(ctx, defaultConnName, defaultSearchPath, cfg.DBName, true, func() (*gorm.DB, error) {
+		return openGormWithConfig(cfg, defaultSearchPath)
+	})
+}
+
+// OpenGormDSN opens a pool over dsn whose connections use searchPath, for tests and tools without a DatabaseConfig.
+// Unlike NewGormDB the pool is not shared, it can be put in the row level tenancy mode and is closed with CloseGormDB.
+func OpenGormDSN(dsn, searchPath string) (*gorm.DB, error) {
+	session := &tenantSession{}
+	pool, err := openSQLDB(withSearchPath(dsn, searchPath), session)
+	if err != nil {
+		return nil, err
+	}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: pool}), &gorm.Config{})
+	if err != nil {
+		return nil, errors.Join(err, pool.Close())
+	}
+	setSessionForDB(db, session)
+	return db, nil
+}
+
+// openGormForKey returns the connection cached under connName and searchPath, opening it once with open.
+// A cached connection is pinged first when ping is set and reopened when the ping fails.
+// A new connection is closed unless its ping succeeds, its pool stats are then reported as statsName.
+func openGormForKey(ctx context.Context, connName, searchPath, statsName string, ping bool, open func() (*gorm.DB, error)) (*gorm.DB, error) {
 	key := connName + "-" + searchPath
 
 	// Fast path: reuse if healthy
 	if cached := getDBForKey(key); cached != nil {
+		if !ping {
+			return cached, nil
+		}
 		if sqlDB, err := cached.DB(); err == nil {
 			if err := sqlDB.PingContext(ctx); err == nil {
 				return cached, nil
@@ -43,46 +79,150 @@ func NewGormDB(ctx context.Context, cfg *config.DatabaseConfig) (*gorm.DB, error
 	var openErr error
 	once.Do(func() {
 		var db *gorm.DB
-		db, openErr = openGormWithConfig(cfg)
+		db, openErr = open()
 		if openErr != nil {
 			return
 		}
 		// Validate connection
-		if sqlDB, err := db.DB(); err != nil {
-			openErr = err
-			return
-		} else if err := sqlDB.PingContext(ctx); err != nil {
-			openErr = err
+		sqlDB, err := db.DB()
+		if err == nil {
+			err = sqlDB.PingContext(ctx)
+		}
+		if err == nil {
+			err = metrics.RegisterDBStats(sqlDB, statsName)
+		}
+		if err != nil {
+			openErr = errors.Join(err, CloseGormDB(db))
 			return
 		}
 		setDBForKey(key, db)
 	})
 	if openErr != nil {
+		// allow the next call to open the connection again
+		resetOnceForKey(key)
 		return nil, openErr
 	}
 	db := getDBForKey(key)
+	if db == nil {
+		return nil, fmt.Errorf("connection %s is not open", key)
+	}
 	return db, nil
 }
 
-func openGormWithConfig(cfg *config.DatabaseConfig) (*gorm.DB, error) {
-	dsn := fmt.Sprintf(
-		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+// NewGormDBWithRetry calls NewGormDB until it succeeds, waiting with exponential backoff
+// between attempts. It gives up after DB_CONNECT_MAX_ATTEMPTS attempts, when set, or when ctx is cancelled.
+func NewGormDBWithRetry(ctx context.Context, cfg *config.DatabaseConfig) (*gorm.DB, error) {
+	backoff, err := time.ParseDuration(cfg.ConnectBackoff)
+	if err != nil || backoff <= 0 {
+		backoff = defaultConnectBackoff
+	}
+	maxBackoff, err := time.ParseDuration(cfg.ConnectMaxBackoff)
+	if err != nil || maxBackoff <= 0 {
+		maxBackoff = defaultConnectMaxBackoff
+	}
+
+	for attempt := 1; ; attempt++ {
+		db, err := NewGormDB(ctx, cfg)
+		if err == nil {
+			return db, nil
+		}
+		if cfg.ConnectMaxAttempts > 0 && attempt >= cfg.ConnectMaxAttempts {
+			return nil, fmt.Errorf("connect after %d attempts: %w", attempt, err)
+		}
+
+		slog.WarnContext(ctx, "database connection failed", "attempt", attempt, "error", err.Error(), "retry_in", backoff.String())
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("connect: %w", errors.Join(ctx.Err(), err))
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// CloseGormDB closes the connection pools of db, its replicas and its tenants and forgets them, so the next NewGormDB opens new ones
+func CloseGormDB(db *gorm.DB) error {
+	dbMu.Lock()
+	forgetDB(db)
+	replicas := replicasByDB[db]
+	delete(replicasByDB, db)
+	delete(sessionsByDB, db)
+	tenants := tenancyByDB[db]
+	delete(tenancyByDB, db)
+	var tenantPools []*gorm.DB
+	if tenants != nil {
+		tenantPools = tenants.pools.all()
+	}
+	dbMu.Unlock()
+
+	var errs []error
+	if replicas != nil {
+		errs = append(errs, replicas.close())
+	}
+	errs = append(errs, closeTenantPools(tenantPools))
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+	metrics.UnregisterDBStats(sqlDB)
+	return errors.Join(append(errs, sqlDB.Close())...)
+}
+
+// DSN returns the postgres connection string of cfg, unqualified table names resolve to the shared schema
+func DSN(cfg *config.DatabaseConfig) string {
+	return dsnWithSearchPath(cfg, defaultSearchPath)
+}
+
+func dsnWithSearchPath(cfg *config.DatabaseConfig, searchPath string) string {
+	return fmt.Sprintf(
+		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s search_path=%s",
 		cfg.DBHost,
 		cfg.DBPort,
 		cfg.DBUser,
 		cfg.DBPassword,
 		cfg.DBName,
 		cfg.DBSSLMode,
+		searchPath,
 	)
-	db, err := gorm.Open(postgres.New(postgres.Config{DSN: dsn, PreferSimpleProtocol: true}), &gorm.Config{})
+}
+
+// openGormWithConfig opens a pool whose connections use searchPath, the pool is closed when it fails
+func openGormWithConfig(cfg *config.DatabaseConfig, searchPath string) (*gorm.DB, error) {
+	session := &tenantSession{}
+	pool, err := openSQLDB(dsnWithSearchPath(cfg, searchPath), session)
 	if err != nil {
 		return nil, err
 	}
-	sqlDB, err := db.DB()
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: pool}), &gorm.Config{})
 	if err != nil {
-		return nil, err
+		return nil, errors.Join(err, pool.Close())
+	}
+	if err := db.Use(metricsPlugin{}); err != nil {
+		return nil, errors.Join(err, pool.Close())
+	}
+	if err := db.Use(tracingPlugin{}); err != nil {
+		return nil, errors.Join(err, pool.Close())
 	}
-	// Apply pool settings
+	configurePool(pool, cfg)
+
+	if len(cfg.ReplicaDSNs) > 0 {
+		replicas, err := newReplicaPlugin(cfg, session)
+		if err != nil {
+			return nil, errors.Join(err, pool.Close())
+		}
+		if err := db.Use(replicas); err != nil {
+			return nil, errors.Join(err, replicas.close(), pool.Close())
+		}
+		setReplicasForDB(db, replicas)
+	}
+	setSessionForDB(db, session)
+
+	return db, nil
+}
+
+// configurePool applies the pool settings of cfg, shared by the primary and the replicas
+func configurePool(sqlDB *sql.DB, cfg *config.DatabaseConfig) {
 	if cfg.MaxIdleConns > 0 {
 		sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
 	}
@@ -94,7 +234,6 @@ func openGormWithConfig(cfg *config.DatabaseConfig) (*gorm.DB, error) {
 			sqlDB.SetConnMaxLifetime(d)
 		}
 	}
-	return db, nil
 }
 
 // Storage and helpers for singleton db connections
@@ -111,6 +250,12 @@ func ensureOnceForKey(key string) *sync.Once {
 	return o
 }
 
+func resetOnceForKey(key string) {
+	dbMu.Lock()
+	defer dbMu.Unlock()
+	delete(dbOnceByKey, key)
+}
+
 func getDBForKey(key string) *gorm.DB {
 	dbMu.Lock()
 	defer dbMu.Unlock()
@@ -122,3 +267,31 @@ func setDBForKey(key string, db *gorm.DB) {
 	defer dbMu.Unlock()
 	dbByKey[key] = db
 }
+
+// forgetDB drops the cached connection db, dbMu must be held
+func forgetDB(db *gorm.DB) {
+	for key, cached := range dbByKey {
+		if cached == db {
+			delete(dbByKey, key)
+			delete(dbOnceByKey, key)
+		}
+	}
+}
+
+func setReplicasForDB(db *gorm.DB, replicas *replicaPlugin) {
+	dbMu.Lock()
+	defer dbMu.Unlock()
+	replicasByDB[db] = replicas
+}
+
+func setSessionForDB(db *gorm.DB, session *tenantSession) {
+	dbMu.Lock()
+	defer dbMu.Unlock()
+	sessionsByDB[db] = session
+}
+
+func sessionFor(db *gorm.DB) *tenantSession {
+	dbMu.Lock()
+	defer dbMu.Unlock()
+	return sessionsByDB[db]
+}
@@ -0,0 +1,92 @@
+package databasehelper
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alxhtp/monogo/pkg/metrics"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// newPingedDB returns a db over a mock monitoring pings, gorm does not ping it on open
+func newPingedDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+
+	sqlDB, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	return db, mock
+}
+
+// statsExported reports whether the pool stats of dbName are exported
+func statsExported(t *testing.T, dbName string) bool {
+	t.Helper()
+
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "db_name" && label.GetValue() == dbName {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func TestOpenGormForKeyClosesPoolsFailingThePing(t *testing.T) {
+	db, mock := newPingedDB(t)
+	mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+	mock.ExpectClose()
+
+	_, err := openGormForKey(context.Background(), "test", t.Name(), "monogo-ping", true, func() (*gorm.DB, error) {
+		return db, nil
+	})
+	if err == nil {
+		t.Fatal("opened a connection failing its ping")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+	if getDBForKey("test-"+t.Name()) != nil || statsExported(t, "monogo-ping") {
+		t.Fatal("the failed connection is cached or exported")
+	}
+}
+
+func TestOpenGormForKeyExportsStatsUntilClosed(t *testing.T) {
+	db, mock := newPingedDB(t)
+	mock.ExpectPing()
+	mock.ExpectClose()
+
+	opened, err := openGormForKey(context.Background(), "test", t.Name(), "monogo-open", true, func() (*gorm.DB, error) {
+		return db, nil
+	})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if !statsExported(t, "monogo-open") {
+		t.Fatal("the pool stats are not exported")
+	}
+
+	if err := CloseGormDB(opened); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+	if getDBForKey("test-"+t.Name()) != nil || statsExported(t, "monogo-open") {
+		t.Fatal("the closed connection is cached or exported")
+	}
+}
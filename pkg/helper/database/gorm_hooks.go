@@ -13,6 +13,7 @@ var (
 	ColUpdatedAt = "updated_at"
 	ColDeletedAt = "deleted_at"
 	ColID        = "id"
+	ColTenantID  = "tenant_id"
 )
 
 func PrepareCreation(tx *gorm.DB) {
@@ -21,6 +22,11 @@ func PrepareCreation(tx *gorm.DB) {
 	tx.Statement.SetColumn(ColID, uuid.New())
 	tx.Statement.SetColumn(ColCreatedAt, now)
 	tx.Statement.SetColumn(ColUpdatedAt, now)
+
+	// in the row level mode rows belong to the tenant of the context, the policies reject any other tenant
+	if tenant := rowTenant(tx); tenant != "" && tx.Statement.Schema != nil && tx.Statement.Schema.LookUpField(ColTenantID) != nil {
+		tx.Statement.SetColumn(ColTenantID, tenant)
+	}
 }
 
 func PrepareUpdate(tx *gorm.DB) {
@@ -0,0 +1,69 @@
+package databasehelper
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/alxhtp/monogo/pkg/metrics"
+	"gorm.io/gorm"
+)
+
+const metricsStartKey = "metrics:start"
+
+// metricsPlugin records the duration of every GORM statement into metrics.DBQueryDuration
+type metricsPlugin struct{}
+
+func (metricsPlugin) Name() string {
+	return "monogo:metrics"
+}
+
+func (p metricsPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+
+	type register func(name string, fn func(*gorm.DB)) error
+	operations := []struct {
+		name          string
+		before, after register
+	}{
+		{"create", callbacks.Create().Before("*").Register, callbacks.Create().After("*").Register},
+		{"query", callbacks.Query().Before("*").Register, callbacks.Query().After("*").Register},
+		{"update", callbacks.Update().Before("*").Register, callbacks.Update().After("*").Register},
+		{"delete", callbacks.Delete().Before("*").Register, callbacks.Delete().After("*").Register},
+		{"row", callbacks.Row().Before("*").Register, callbacks.Row().After("*").Register},
+		{"raw", callbacks.Raw().Before("*").Register, callbacks.Raw().After("*").Register},
+	}
+
+	for _, operation := range operations {
+		if err := operation.before("metrics:before_"+operation.name, p.before); err != nil {
+			return err
+		}
+		if err := operation.after("metrics:after_"+operation.name, p.after(operation.name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p metricsPlugin) before(db *gorm.DB) {
+	db.Statement.Settings.Store(metricsStartKey, time.Now())
+}
+
+func (p metricsPlugin) after(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.Statement.Settings.LoadAndDelete(metricsStartKey)
+		if !ok {
+			return
+		}
+		start, _ := value.(time.Time)
+
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+
+		metrics.DBQueryDuration.
+			WithLabelValues(statementOperation(db, operation), table, strconv.FormatBool(db.Error != nil)).
+			Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,346 @@
+package databasehelper
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alxhtp/monogo/config"
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+	"github.com/alxhtp/monogo/pkg/metrics"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+const (
+	defaultReplicaStickyWindow  = 5 * time.Second
+	defaultReplicaMaxLag        = 10 * time.Second
+	defaultReplicaCheckInterval = 5 * time.Second
+
+	// replicaLagQuery returns the replay delay of a standby, 0 when it replayed everything it received
+	// so an idle primary does not make it look lagging, and 0 on a server that is not a standby
+	replicaLagQuery = `SELECT COALESCE(CASE
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())
+	END, 0)`
+)
+
+type primaryContextKey struct{}
+
+// WithPrimary sends every read made with the returned context to the primary,
+// for reads that must see writes made by another caller or instance
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryContextKey{}, true)
+}
+
+func forcesPrimary(ctx context.Context) bool {
+	forced, _ := ctx.Value(primaryContextKey{}).(bool)
+	return forced
+}
+
+type replica struct {
+	name    string
+	db      *sql.DB
+	healthy atomic.Bool
+}
+
+// replicaPlugin routes reads outside of a transaction to a healthy replica through dbresolver, writes go to the primary.
+// A caller reads from the primary for stickyWindow after its last committed write, so it sees its own writes.
+// Replicas failing the health check or lagging more than maxLag are skipped until they catch up.
+type replicaPlugin struct {
+	replicas      []*replica
+	next          atomic.Uint64
+	stickyWindow  time.Duration
+	maxLag        time.Duration
+	checkInterval time.Duration
+
+	mu         sync.Mutex
+	lastWrites map[string]time.Time
+
+	running bool
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+func newReplicaPlugin(cfg *config.DatabaseConfig, session *tenantSession) (*replicaPlugin, error) {
+	p := &replicaPlugin{
+		stickyWindow:  parseDurationOr(cfg.ReplicaStickyWindow, defaultReplicaStickyWindow),
+		maxLag:        parseDurationOr(cfg.ReplicaMaxLag, defaultReplicaMaxLag),
+		checkInterval: parseDurationOr(cfg.ReplicaCheckInterval, defaultReplicaCheckInterval),
+		lastWrites:    make(map[string]time.Time),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+
+	for i, dsn := range cfg.ReplicaDSNs {
+		// replicas are opened without a ping, one that is down is skipped until the health check passes.
+		// They share the tenant session of the primary, so reads are bound to their tenant on either.
+		sqlDB, err := openSQLDB(withSearchPath(dsn, defaultSearchPath), session)
+		if err != nil {
+			p.close()
+			return nil, fmt.Errorf("open replica %d: %w", i, err)
+		}
+		configurePool(sqlDB, cfg)
+
+		name := "replica-" + strconv.Itoa(i)
+		if err := metrics.RegisterDBStats(sqlDB, cfg.DBName+"-"+name); err != nil {
+			return nil, errors.Join(err, sqlDB.Close(), p.close())
+		}
+		// assumed healthy until the first check, so a replica that is down at startup is reported
+		replica := &replica{name: name, db: sqlDB}
+		replica.healthy.Store(true)
+		p.replicas = append(p.replicas, replica)
+	}
+
+	return p, nil
+}
+
+func (p *replicaPlugin) Name() string {
+	return "monogo:replica"
+}
+
+func (p *replicaPlugin) Initialize(db *gorm.DB) error {
+	dialectors := make([]gorm.Dialector, len(p.replicas))
+	for i, replica := range p.replicas {
+		dialectors[i] = postgres.New(postgres.Config{Conn: replica.db, PreferSimpleProtocol: true})
+	}
+	if err := db.Use(dbresolver.Register(dbresolver.Config{Replicas: dialectors, Policy: p})); err != nil {
+		return err
+	}
+
+	// dbresolver runs before "*" as well, callbacks before "*" run in reverse registration order so route comes first
+	callbacks := db.Callback()
+	routes := []func(name string, fn func(*gorm.DB)) error{
+		callbacks.Query().Before("*").Register,
+		callbacks.Row().Before("*").Register,
+		callbacks.Raw().Before("*").Register,
+	}
+	for _, register := range routes {
+		if err := register("replica:route", p.route); err != nil {
+			return err
+		}
+	}
+
+	writes := []func(name string, fn func(*gorm.DB)) error{
+		callbacks.Create().After("*").Register,
+		callbacks.Update().After("*").Register,
+		callbacks.Delete().After("*").Register,
+		callbacks.Raw().After("*").Register,
+	}
+	for _, register := range writes {
+		if err := register("replica:record_write", p.recordWrite); err != nil {
+			return err
+		}
+	}
+
+	p.check(context.Background())
+	p.running = true
+	go p.run()
+
+	return nil
+}
+
+// Resolve implements dbresolver.Policy, picking the next healthy replica. route already sent the statement
+// to the primary when every replica is down, a replica failing in between is still picked.
+func (p *replicaPlugin) Resolve(pools []gorm.ConnPool) gorm.ConnPool {
+	start := p.next.Add(1)
+	for i := range uint64(len(p.replicas)) {
+		replica := p.replicas[(start+i)%uint64(len(p.replicas))]
+		if replica.healthy.Load() {
+			return replica.db
+		}
+	}
+
+	return pools[start%uint64(len(pools))]
+}
+
+// route sends the statement to the primary when ctx forces it, when the caller wrote recently or no replica is healthy
+func (p *replicaPlugin) route(db *gorm.DB) {
+	ctx := db.Statement.Context
+	if InTransaction(ctx) {
+		return
+	}
+
+	if forcesPrimary(ctx) || p.wroteRecently(stickyCaller(ctx)) || !p.anyHealthy() {
+		dbresolver.Write.ModifyStatement(db.Statement)
+	}
+}
+
+// recordWrite starts the sticky window of the caller once the write is committed
+func (p *replicaPlugin) recordWrite(db *gorm.DB) {
+	if db.Error != nil || !isWrite(db.Statement) {
+		return
+	}
+
+	ctx := db.Statement.Context
+	caller := stickyCaller(ctx)
+	if caller == "" {
+		return
+	}
+	AfterCommit(ctx, func() {
+		p.mu.Lock()
+		p.lastWrites[caller] = time.Now()
+		p.mu.Unlock()
+	})
+}
+
+func (p *replicaPlugin) wroteRecently(caller string) bool {
+	if caller == "" {
+		return false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	lastWrite, ok := p.lastWrites[caller]
+	return ok && time.Since(lastWrite) < p.stickyWindow
+}
+
+// stickyCaller identifies the caller of a sticky window by its verified principal within its tenant,
+// unauthenticated callers have none since anything else they send can be spoofed
+func stickyCaller(ctx context.Context) string {
+	principal := contexthelper.GetPrincipal(ctx)
+	if principal == "" {
+		return ""
+	}
+	return contexthelper.GetTenant(ctx) + "/" + principal
+}
+
+func (p *replicaPlugin) anyHealthy() bool {
+	for _, replica := range p.replicas {
+		if replica.healthy.Load() {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *replicaPlugin) run() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.check(context.Background())
+			p.forgetWrites()
+		}
+	}
+}
+
+// check measures the lag of every replica, a replica that cannot be queried counts as unhealthy
+func (p *replicaPlugin) check(ctx context.Context) {
+	for _, replica := range p.replicas {
+		ctx, cancel := context.WithTimeout(ctx, p.checkInterval)
+		var lagSeconds float64
+		err := replica.db.QueryRowContext(ctx, replicaLagQuery).Scan(&lagSeconds)
+		cancel()
+
+		lag := time.Duration(lagSeconds * float64(time.Second))
+		healthy := err == nil && lag <= p.maxLag
+		if err == nil {
+			metrics.DBReplicaLagSeconds.WithLabelValues(replica.name).Set(lagSeconds)
+		}
+		metrics.DBReplicaHealthy.WithLabelValues(replica.name).Set(boolToFloat(healthy))
+
+		if replica.healthy.Swap(healthy) == healthy {
+			continue
+		}
+		switch {
+		case healthy:
+			slog.Info("database replica is healthy", "replica", replica.name, "lag", lag.String())
+		case err != nil:
+			slog.Warn("database replica is unreachable", "replica", replica.name, "error", err.Error())
+		default:
+			slog.Warn("database replica is lagging", "replica", replica.name, "lag", lag.String(), "max_lag", p.maxLag.String())
+		}
+	}
+}
+
+// forgetWrites drops the callers whose sticky window ended
+func (p *replicaPlugin) forgetWrites() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for caller, lastWrite := range p.lastWrites {
+		if time.Since(lastWrite) >= p.stickyWindow {
+			delete(p.lastWrites, caller)
+		}
+	}
+}
+
+// close stops the health check and closes the replica connection pools
+func (p *replicaPlugin) close() error {
+	close(p.stop)
+	if p.running {
+		<-p.done
+	}
+
+	var errs []error
+	for _, replica := range p.replicas {
+		metrics.UnregisterDBStats(replica.db)
+		if err := replica.db.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close %s: %w", replica.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// isWrite reports whether the statement changed data, raw statements are writes unless they start with SELECT
+func isWrite(stmt *gorm.Statement) bool {
+	sql := strings.TrimSpace(stmt.SQL.String())
+	if sql == "" {
+		return true
+	}
+	return len(sql) < 6 || !strings.EqualFold(sql[:6], "select")
+}
+
+// withSearchPath sets the search_path of a URL or key/value DSN unless it already sets one
+func withSearchPath(dsn, searchPath string) string {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return dsn
+		}
+		query := u.Query()
+		if query.Has("search_path") {
+			return dsn
+		}
+		query.Set("search_path", searchPath)
+		u.RawQuery = query.Encode()
+		return u.String()
+	}
+
+	if strings.Contains(dsn, "search_path=") {
+		return dsn
+	}
+	return strings.TrimSpace(dsn + " search_path=" + searchPath)
+}
+
+func parseDurationOr(value string, fallback time.Duration) time.Duration {
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return fallback
+	}
+	return d
+}
+
+func boolToFloat(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,31 @@
+package databasehelper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+)
+
+func TestStickyCallerIgnoresUnverifiedActors(t *testing.T) {
+	plugin := &replicaPlugin{stickyWindow: time.Minute, lastWrites: make(map[string]time.Time)}
+
+	ctx := contexthelper.WithActor(context.Background(), "alice")
+	if caller := stickyCaller(ctx); caller != "" {
+		t.Fatalf("sticky caller of an unauthenticated actor = %q, want none", caller)
+	}
+
+	alice := contexthelper.WithPrincipal(ctx, "alice")
+	plugin.lastWrites[stickyCaller(alice)] = time.Now()
+
+	if !plugin.wroteRecently(stickyCaller(alice)) {
+		t.Error("the principal that wrote is not sticky")
+	}
+	if plugin.wroteRecently(stickyCaller(ctx)) {
+		t.Error("an actor naming the principal is sticky")
+	}
+	if plugin.wroteRecently(stickyCaller(contexthelper.WithTenant(alice, "acme"))) {
+		t.Error("the principal is sticky in another tenant")
+	}
+}
@@ -0,0 +1,242 @@
+package databasehelper
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/alxhtp/monogo/config"
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+	"gorm.io/gorm"
+)
+
+// Tenant modes of TENANT_MODE
+const (
+	TenantModeSchema = "schema"
+	TenantModeRow    = "row"
+)
+
+const (
+	tenantConnName     = "tenant"
+	tenantSchemaPrefix = "tenant_"
+
+	defaultTenantMaxOpenConns = 10
+	defaultTenantMaxIdleConns = 2
+	defaultTenantMaxPools     = 100
+)
+
+var (
+	// ErrTenantRequired is returned by queries made without a tenant in the schema-per-tenant mode
+	ErrTenantRequired = errors.New("tenant is required")
+	// ErrInvalidTenant is returned for tenant ids that cannot be used as a schema name
+	ErrInvalidTenant = errors.New("tenant id must start with a lowercase letter followed by at most 47 lowercase letters, digits or underscores")
+	// ErrCrossTenant is returned when a transaction is used with the context of another tenant
+	ErrCrossTenant = errors.New("transaction belongs to another tenant")
+
+	tenantIDPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,47}$`)
+
+	tenancyByDB = map[*gorm.DB]*tenancy{}
+)
+
+// tenancy holds the connection pools of the tenants of a shared db, each pool uses the tenant schema as search_path
+// so unqualified table names can only resolve to the tables of that tenant.
+type tenancy struct {
+	cfg   config.DatabaseConfig
+	pools *tenantPools
+}
+
+// tenantPools keeps the pools of the most recently used tenants, dbMu must be held
+type tenantPools struct {
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List // of *tenantPool, most recently used first
+}
+
+type tenantPool struct {
+	tenant string
+	db     *gorm.DB
+}
+
+func newTenantPools(capacity int) *tenantPools {
+	return &tenantPools{capacity: capacity, entries: map[string]*list.Element{}, order: list.New()}
+}
+
+// use marks db as the most recently used pool of tenant and returns the pools evicted to stay within capacity,
+// they are forgotten so the next use of their tenant opens a new pool
+func (p *tenantPools) use(tenant string, db *gorm.DB) []*gorm.DB {
+	if element, ok := p.entries[tenant]; ok {
+		element.Value.(*tenantPool).db = db
+		p.order.MoveToFront(element)
+	} else {
+		p.entries[tenant] = p.order.PushFront(&tenantPool{tenant: tenant, db: db})
+	}
+
+	var evicted []*gorm.DB
+	for p.order.Len() > p.capacity {
+		pool := p.order.Remove(p.order.Back()).(*tenantPool)
+		delete(p.entries, pool.tenant)
+		forgetDB(pool.db)
+		evicted = append(evicted, pool.db)
+	}
+	return evicted
+}
+
+// all returns every pool and forgets them
+func (p *tenantPools) all() []*gorm.DB {
+	pools := make([]*gorm.DB, 0, p.order.Len())
+	for element := p.order.Front(); element != nil; element = element.Next() {
+		pool := element.Value.(*tenantPool)
+		forgetDB(pool.db)
+		pools = append(pools, pool.db)
+	}
+	p.order.Init()
+	clear(p.entries)
+	return pools
+}
+
+// EnableTenancy isolates the tenants of db in the mode of cfg, see enableSchemaTenancy and enableRowTenancy.
+// The row level mode first applies its migrations to the shared schema, see migration.UpRow.
+func EnableTenancy(ctx context.Context, db *gorm.DB, dbCfg *config.DatabaseConfig, cfg *config.TenantConfig) error {
+	switch cfg.TenantMode {
+	case TenantModeSchema, "":
+		enableSchemaTenancy(db, dbCfg, cfg)
+		return nil
+	case TenantModeRow:
+		if err := migrateRowTenancy(ctx, db); err != nil {
+			return err
+		}
+		return enableRowTenancy(ctx, db)
+	default:
+		return fmt.Errorf("TENANT_MODE: unknown mode %q, expected schema or row", cfg.TenantMode)
+	}
+}
+
+// enableSchemaTenancy makes queries made through DBFromContext with db go to the schema of the tenant carried by the context.
+// Queries without a tenant fail with ErrTenantRequired. Tenant pools are opened on first use with the tenant pool sizes,
+// they read from the primary as replicas are only used by the shared pool. Beyond TENANT_MAX_POOLS the least recently
+// used pool is closed, a statement already running on it completes but the next ones fail.
+func enableSchemaTenancy(db *gorm.DB, dbCfg *config.DatabaseConfig, cfg *config.TenantConfig) {
+	tenantCfg := *dbCfg
+	tenantCfg.ReplicaDSNs = nil
+	tenantCfg.MaxOpenConns = cfg.TenantMaxOpenConns
+	if tenantCfg.MaxOpenConns <= 0 {
+		tenantCfg.MaxOpenConns = defaultTenantMaxOpenConns
+	}
+	tenantCfg.MaxIdleConns = cfg.TenantMaxIdleConns
+	if tenantCfg.MaxIdleConns <= 0 {
+		tenantCfg.MaxIdleConns = defaultTenantMaxIdleConns
+	}
+
+	maxPools := cfg.TenantMaxPools
+	if maxPools <= 0 {
+		maxPools = defaultTenantMaxPools
+	}
+
+	dbMu.Lock()
+	defer dbMu.Unlock()
+	tenancyByDB[db] = &tenancy{cfg: tenantCfg, pools: newTenantPools(maxPools)}
+}
+
+// TenancyEnabled reports whether db is in the schema-per-tenant mode
+func TenancyEnabled(db *gorm.DB) bool {
+	return tenancyFor(db) != nil
+}
+
+// ValidateTenantID reports whether id can be used as a tenant, the id is part of the schema name
+func ValidateTenantID(id string) error {
+	if !tenantIDPattern.MatchString(id) {
+		return ErrInvalidTenant
+	}
+	return nil
+}
+
+// TenantSchema returns the schema holding the tables of tenant
+func TenantSchema(tenant string) string {
+	return tenantSchemaPrefix + tenant
+}
+
+// TenantFromSchema returns the tenant owning schema, false when schema is not a tenant schema
+func TenantFromSchema(schema string) (string, bool) {
+	tenant, ok := strings.CutPrefix(schema, tenantSchemaPrefix)
+	return tenant, ok && ValidateTenantID(tenant) == nil
+}
+
+// TenantDB returns the connection pool of tenant in the schema-per-tenant mode, opened on first use, db otherwise.
+// It does not check the schema exists.
+func TenantDB(ctx context.Context, db *gorm.DB, tenant string) (*gorm.DB, error) {
+	t := tenancyFor(db)
+	if t == nil {
+		return db, nil
+	}
+	if err := ValidateTenantID(tenant); err != nil {
+		return nil, err
+	}
+
+	schema := TenantSchema(tenant)
+	searchPath := schema + ",public"
+	key := tenantConnName + "-" + searchPath
+	for {
+		pool, err := openGormForKey(ctx, tenantConnName, searchPath, t.cfg.DBName+"-"+schema, false, func() (*gorm.DB, error) {
+			return openGormWithConfig(&t.cfg, searchPath)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		dbMu.Lock()
+		// the pool was evicted by another tenant meanwhile, a new one is opened
+		if dbByKey[key] != pool {
+			dbMu.Unlock()
+			continue
+		}
+		evicted := t.pools.use(tenant, pool)
+		dbMu.Unlock()
+
+		if err := closeTenantPools(evicted); err != nil {
+			slog.WarnContext(ctx, "failed to close evicted tenant pool", "error", err.Error())
+		}
+		return pool, nil
+	}
+}
+
+// closeTenantPools closes pools along with their replicas and stats, dbMu must not be held
+func closeTenantPools(pools []*gorm.DB) error {
+	var errs []error
+	for _, pool := range pools {
+		errs = append(errs, CloseGormDB(pool))
+	}
+	return errors.Join(errs...)
+}
+
+// tenantDBFromContext returns the pool of the tenant carried by ctx, or db carrying the error when there is none
+func tenantDBFromContext(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if !TenancyEnabled(db) {
+		return db.WithContext(ctx)
+	}
+
+	tenant := contexthelper.GetTenant(ctx)
+	if tenant == "" {
+		return withError(db.WithContext(ctx), ErrTenantRequired)
+	}
+	pool, err := TenantDB(ctx, db, tenant)
+	if err != nil {
+		return withError(db.WithContext(ctx), err)
+	}
+	return pool.WithContext(ctx)
+}
+
+func tenancyFor(db *gorm.DB) *tenancy {
+	dbMu.Lock()
+	defer dbMu.Unlock()
+	return tenancyByDB[db]
+}
+
+// withError makes every statement run on conn fail with err instead of reaching the database
+func withError(conn *gorm.DB, err error) *gorm.DB {
+	_ = conn.AddError(err)
+	return conn
+}
@@ -0,0 +1,88 @@
+package databasehelper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/alxhtp/monogo/migration"
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+	"gorm.io/gorm"
+)
+
+// TenantSetting is the session variable the row level security policies compare tenant_id with,
+// a connection sets it to the tenant of the context of every statement before running it
+const TenantSetting = "monogo.tenant_id"
+
+const (
+	rowTenancyPluginName = "monogo:row_tenancy"
+
+	bypassRLSQuery = "SELECT rolsuper OR rolbypassrls FROM pg_roles WHERE rolname = current_user"
+)
+
+// ErrRowSecurityBypassed is returned when the row level mode is enabled for a role row level security does not apply to
+var ErrRowSecurityBypassed = errors.New("row level tenancy requires a database role that is neither superuser nor BYPASSRLS")
+
+// enableRowTenancy binds every statement of db to the tenant carried by its context. The policies of the tenant-owned tables only let a statement see and write the rows
+// of its tenant, a statement run without a tenant only sees the rows that belong to no tenant.
+func enableRowTenancy(ctx context.Context, db *gorm.DB) error {
+	session := sessionFor(db)
+	if session == nil {
+		return errors.New("row level tenancy requires a db opened by NewGormDB")
+	}
+
+	var bypassed bool
+	if err := db.WithContext(ctx).Raw(bypassRLSQuery).Scan(&bypassed).Error; err != nil {
+		return fmt.Errorf("check row level security: %w", err)
+	}
+	if bypassed {
+		return ErrRowSecurityBypassed
+	}
+
+	if err := db.Use(rowTenancyPlugin{}); err != nil {
+		return err
+	}
+	session.enabled.Store(true)
+	return nil
+}
+
+// migrateRowTenancy applies the migrations of the row level mode to the schema of db, the shared schema
+// first in its search path: the row level security policies and the unique emails per tenant
+func migrateRowTenancy(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	var schema string
+	if err := sqlDB.QueryRowContext(ctx, "SELECT current_schema()").Scan(&schema); err != nil {
+		return fmt.Errorf("current schema: %w", err)
+	}
+	_, err = migration.UpRow(ctx, sqlDB, schema)
+	return err
+}
+
+// rowTenancyEnabled reports whether db, or the db it was derived from, is in the row level mode
+func rowTenancyEnabled(db *gorm.DB) bool {
+	_, ok := db.Config.Plugins[rowTenancyPluginName]
+	return ok
+}
+
+// rowTenant returns the tenant owning the rows written by tx in the row level mode, empty otherwise
+func rowTenant(tx *gorm.DB) string {
+	if !rowTenancyEnabled(tx) {
+		return ""
+	}
+	return contexthelper.GetTenant(tx.Statement.Context)
+}
+
+// rowTenancyPlugin marks a db in the row level mode. The tenant itself is set by the connections of the pool,
+// see tenantConn, so Row, Rows and Raw().Scan are bound to their tenant like every other statement.
+type rowTenancyPlugin struct{}
+
+func (rowTenancyPlugin) Name() string {
+	return rowTenancyPluginName
+}
+
+func (rowTenancyPlugin) Initialize(*gorm.DB) error {
+	return nil
+}
@@ -0,0 +1,263 @@
+package databasehelper
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// tenantNote is a tenant-owned model, the entity package cannot be imported from here
+type tenantNote struct {
+	ID        uuid.UUID `gorm:"column:id;primaryKey;type:uuid"`
+	TenantID  string    `gorm:"column:tenant_id"`
+	Body      string    `gorm:"column:body"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+}
+
+func (n *tenantNote) TableName() string {
+	return "notes"
+}
+
+func (n *tenantNote) BeforeCreate(tx *gorm.DB) error {
+	PrepareCreation(tx)
+	return nil
+}
+
+var setTenantPattern = regexp.QuoteMeta(setSessionTenantQuery)
+
+// dsnConnector opens the connections of the sqlmock dsn, the way sql.Open would
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// newRowTenancyDB returns a db in the row level mode over a single mocked connection, so the tenant the connection
+// is bound to carries over from one statement to the next like on a pool of postgres connections
+func newRowTenancyDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+
+	dsn := "row_tenancy_" + t.Name()
+	mockDB, mock, err := sqlmock.NewWithDSN(dsn)
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	t.Cleanup(func() { _ = mockDB.Close() })
+
+	session := &tenantSession{}
+	sqlDB := sql.OpenDB(&tenantConnector{Connector: dsnConnector{dsn: dsn, driver: mockDB.Driver()}, session: session})
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB, PreferSimpleProtocol: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	setSessionForDB(db, session)
+	t.Cleanup(func() { _ = CloseGormDB(db) })
+
+	mock.ExpectQuery(regexp.QuoteMeta(bypassRLSQuery)).WillReturnRows(sqlmock.NewRows([]string{"bypassed"}).AddRow(false))
+	if err := enableRowTenancy(context.Background(), db); err != nil {
+		t.Fatalf("enable row tenancy: %v", err)
+	}
+	return db, mock
+}
+
+func TestEnableRowTenancyRejectsRolesBypassingRowSecurity(t *testing.T) {
+	db, mock := newMockDB(t)
+	session := &tenantSession{}
+	setSessionForDB(db, session)
+	t.Cleanup(func() { _ = CloseGormDB(db) })
+
+	mock.ExpectQuery(regexp.QuoteMeta(bypassRLSQuery)).WillReturnRows(sqlmock.NewRows([]string{"bypassed"}).AddRow(true))
+	if err := enableRowTenancy(context.Background(), db); !errors.Is(err, ErrRowSecurityBypassed) {
+		t.Fatalf("got %v, want ErrRowSecurityBypassed", err)
+	}
+	if rowTenancyEnabled(db) || session.enabled.Load() {
+		t.Fatal("row tenancy enabled for a role bypassing row level security")
+	}
+}
+
+func TestRowTenancyBindsReadsToTenantWithoutTransaction(t *testing.T) {
+	db, mock := newRowTenancyDB(t)
+	ctx := contexthelper.WithTenant(context.Background(), "acme")
+
+	// the connection is bound once, the next statements of the same tenant run right away
+	mock.ExpectExec(setTenantPattern).WithArgs("acme").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT \* FROM "notes"`).WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "body"}))
+	mock.ExpectQuery(`SELECT \* FROM "notes"`).WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "body"}))
+
+	for range 2 {
+		var notes []tenantNote
+		if err := DBFromContext(ctx, db).Find(&notes).Error; err != nil {
+			t.Fatalf("find: %v", err)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRowTenancyBindsRawScansAndRows(t *testing.T) {
+	db, mock := newRowTenancyDB(t)
+	acme := contexthelper.WithTenant(context.Background(), "acme")
+	globex := contexthelper.WithTenant(context.Background(), "globex")
+
+	mock.ExpectExec(setTenantPattern).WithArgs("acme").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM notes`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectExec(setTenantPattern).WithArgs("globex").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT body FROM notes`).WillReturnRows(sqlmock.NewRows([]string{"body"}).AddRow("theirs"))
+	mock.ExpectExec(setTenantPattern).WithArgs("acme").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT body FROM notes`).WillReturnRows(sqlmock.NewRows([]string{"body"}).AddRow("mine"))
+
+	var count int
+	if err := DBFromContext(acme, db).Raw("SELECT count(*) FROM notes").Scan(&count).Error; err != nil || count != 2 {
+		t.Fatalf("raw scan: got %d, %v", count, err)
+	}
+
+	var body string
+	if err := DBFromContext(globex, db).Raw("SELECT body FROM notes").Row().Scan(&body); err != nil || body != "theirs" {
+		t.Fatalf("row: got %q, %v", body, err)
+	}
+
+	rows, err := DBFromContext(acme, db).Raw("SELECT body FROM notes").Rows()
+	if err != nil {
+		t.Fatalf("rows: %v", err)
+	}
+	for rows.Next() {
+		if err := rows.Scan(&body); err != nil || body != "mine" {
+			t.Fatalf("rows: got %q, %v", body, err)
+		}
+	}
+	if err := rows.Close(); err != nil {
+		t.Fatalf("rows: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRowTenancyReadsWithoutTenantSeeNoTenant(t *testing.T) {
+	db, mock := newRowTenancyDB(t)
+
+	// the session variable is set even when empty, so the policies only let rows of no tenant through
+	mock.ExpectExec(setTenantPattern).WithArgs("").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT \* FROM "notes"`).WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "body"}))
+
+	var notes []tenantNote
+	if err := DBFromContext(context.Background(), db).Find(&notes).Error; err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRowTenancyCreateWritesRowOfTenant(t *testing.T) {
+	db, mock := newRowTenancyDB(t)
+	ctx := contexthelper.WithTenant(context.Background(), "acme")
+
+	mock.ExpectExec(setTenantPattern).WithArgs("acme").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO "notes"`).WithArgs(sqlmock.AnyArg(), "acme", "hello", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	note := &tenantNote{Body: "hello"}
+	if err := DBFromContext(ctx, db).Create(note).Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if note.TenantID != "acme" {
+		t.Fatalf("tenant_id = %q, want acme", note.TenantID)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRowTenancyTransactionIsBoundToItsTenant(t *testing.T) {
+	db, mock := newRowTenancyDB(t)
+	ctx := contexthelper.WithTenant(context.Background(), "acme")
+
+	// the tenant is set once before the transaction begins, the statements of another tenant never reach the database
+	mock.ExpectExec(setTenantPattern).WithArgs("acme").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "notes"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectRollback()
+
+	err := WithTransaction(ctx, db, func(ctx context.Context) error {
+		if err := DBFromContext(ctx, db).Model(&tenantNote{}).Where("id = ?", uuid.New()).Update("body", "mine").Error; err != nil {
+			return err
+		}
+
+		other := contexthelper.WithTenant(ctx, "globex")
+		return DBFromContext(other, db).Model(&tenantNote{}).Where("id = ?", uuid.New()).Update("body", "theirs").Error
+	})
+	if !errors.Is(err, ErrCrossTenant) {
+		t.Fatalf("got %v, want ErrCrossTenant", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRowTenancyIsOffUntilEnabled(t *testing.T) {
+	db, mock := newMockDB(t)
+
+	// a db of another mode never sets the tenant, its connections are not bound to any
+	mock.ExpectQuery(`SELECT \* FROM "notes"`).WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "body"}))
+
+	var notes []tenantNote
+	if err := db.WithContext(contexthelper.WithTenant(context.Background(), "acme")).Find(&notes).Error; err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+	if err := enableRowTenancy(context.Background(), db); err == nil {
+		t.Fatal("row tenancy enabled without a tenant session")
+	}
+}
+
+func TestSchemaModeDoesNotPopulateTenant(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	defer sqlDB.Close()
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB, PreferSimpleProtocol: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	ctx := contexthelper.WithTenant(context.Background(), "acme")
+
+	// tenant schemas hold rows of no tenant, a tenant_id would be rejected by their policies
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO "notes"`).WithArgs(sqlmock.AnyArg(), "", "hello", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := db.WithContext(ctx).Create(&tenantNote{Body: "hello"}).Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
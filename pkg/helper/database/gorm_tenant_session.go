@@ -0,0 +1,185 @@
+package databasehelper
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// setSessionTenantQuery sets TenantSetting for the session, it stays set until the connection runs for another tenant
+const setSessionTenantQuery = "SELECT set_config('" + TenantSetting + "', $1, false)"
+
+// tenantSession makes the connections of a pool set TenantSetting from the context of every statement
+// they run outside of a transaction, once the row level mode is enabled
+type tenantSession struct {
+	enabled atomic.Bool
+}
+
+// openSQLDB opens a pool over dsn whose connections follow the tenant of session
+func openSQLDB(dsn string, session *tenantSession) (*sql.DB, error) {
+	pgxCfg, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+	pgxCfg.DefaultQueryExecMode = pgx.QueryExecModeSimpleProtocol
+
+	return sql.OpenDB(&tenantConnector{Connector: stdlib.GetConnector(*pgxCfg), session: session}), nil
+}
+
+type tenantConnector struct {
+	driver.Connector
+	session *tenantSession
+}
+
+func (c *tenantConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &tenantConn{Conn: conn, session: c.session}, nil
+}
+
+// tenantConn sets the tenant of the context of a statement before running it, so Row, Rows and Raw().Scan are bound
+// to their tenant like every other statement, without a transaction of their own. The tenant is only set when it
+// changes, and never inside a transaction where a rollback would undo it behind the back of the connection.
+// Statements of a transaction run with the tenant set when it began.
+type tenantConn struct {
+	driver.Conn
+	session *tenantSession
+
+	tenant string
+	known  bool
+	inTx   bool
+}
+
+func (c *tenantConn) useTenant(ctx context.Context) error {
+	if !c.session.enabled.Load() || c.inTx {
+		return nil
+	}
+
+	tenant := contexthelper.GetTenant(ctx)
+	if c.known && c.tenant == tenant {
+		return nil
+	}
+
+	c.known = false
+	if err := c.exec(ctx, setSessionTenantQuery, tenant); err != nil {
+		return fmt.Errorf("set tenant: %w", err)
+	}
+	c.tenant, c.known = tenant, true
+	return nil
+}
+
+func (c *tenantConn) exec(ctx context.Context, query string, arg string) error {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return errors.New("driver does not support ExecContext")
+	}
+	_, err := execer.ExecContext(ctx, query, []driver.NamedValue{{Ordinal: 1, Value: arg}})
+	return err
+}
+
+func (c *tenantConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	if err := c.useTenant(ctx); err != nil {
+		return nil, err
+	}
+	return execer.ExecContext(ctx, query, args)
+}
+
+func (c *tenantConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	if err := c.useTenant(ctx); err != nil {
+		return nil, err
+	}
+	return queryer.QueryContext(ctx, query, args)
+}
+
+func (c *tenantConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if err := c.useTenant(ctx); err != nil {
+		return nil, err
+	}
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+func (c *tenantConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if err := c.useTenant(ctx); err != nil {
+		return nil, err
+	}
+
+	var (
+		tx  driver.Tx
+		err error
+	)
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		tx, err = beginner.BeginTx(ctx, opts)
+	} else {
+		// drivers without BeginTx, as database/sql does
+		tx, err = c.Conn.Begin()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.inTx = true
+	return &tenantTx{Tx: tx, conn: c}, nil
+}
+
+func (c *tenantConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *tenantConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+func (c *tenantConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *tenantConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+type tenantTx struct {
+	driver.Tx
+	conn *tenantConn
+}
+
+func (tx *tenantTx) Commit() error {
+	tx.conn.inTx = false
+	return tx.Tx.Commit()
+}
+
+func (tx *tenantTx) Rollback() error {
+	tx.conn.inTx = false
+	return tx.Tx.Rollback()
+}
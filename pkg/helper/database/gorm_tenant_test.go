@@ -0,0 +1,110 @@
+package databasehelper
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alxhtp/monogo/config"
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB, PreferSimpleProtocol: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	return db, mock
+}
+
+// newSchemaTenancyDB returns a shared db in the schema mode keeping at most maxPools tenant pools,
+// the pools of tenants are mocked and already open so TenantDB never reaches a database
+func newSchemaTenancyDB(t *testing.T, maxPools int, tenants ...string) (*gorm.DB, map[string]sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, _ := newMockDB(t)
+	enableSchemaTenancy(db, &config.DatabaseConfig{}, &config.TenantConfig{TenantMaxPools: maxPools})
+	t.Cleanup(func() { _ = CloseGormDB(db) })
+
+	mocks := make(map[string]sqlmock.Sqlmock, len(tenants))
+	for _, tenant := range tenants {
+		pool, mock := newMockDB(t)
+		setDBForKey(tenantConnName+"-"+TenantSchema(tenant)+",public", pool)
+		mocks[tenant] = mock
+	}
+	return db, mocks
+}
+
+func TestTenantDBEvictsLeastRecentlyUsedPool(t *testing.T) {
+	db, mocks := newSchemaTenancyDB(t, 2, "acme", "globex", "initech")
+	ctx := context.Background()
+
+	acme, err := TenantDB(ctx, db, "acme")
+	if err != nil {
+		t.Fatalf("acme: %v", err)
+	}
+	if _, err := TenantDB(ctx, db, "globex"); err != nil {
+		t.Fatalf("globex: %v", err)
+	}
+	// acme is used again, globex becomes the least recently used pool
+	if again, err := TenantDB(ctx, db, "acme"); err != nil || again != acme {
+		t.Fatalf("acme again: got %p, %v, want the open pool %p", again, err, acme)
+	}
+
+	mocks["globex"].ExpectClose()
+	if _, err := TenantDB(ctx, db, "initech"); err != nil {
+		t.Fatalf("initech: %v", err)
+	}
+
+	if err := mocks["globex"].ExpectationsWereMet(); err != nil {
+		t.Fatalf("evicted pool: %v", err)
+	}
+	if getDBForKey(tenantConnName+"-"+TenantSchema("globex")+",public") != nil {
+		t.Fatal("the evicted pool is still cached")
+	}
+	if getDBForKey(tenantConnName+"-"+TenantSchema("acme")+",public") != acme {
+		t.Fatal("the recently used pool was evicted")
+	}
+}
+
+func TestSchemaTenancyRequiresTenant(t *testing.T) {
+	db, _ := newSchemaTenancyDB(t, 0)
+
+	if err := DBFromContext(context.Background(), db).Exec("SELECT 1").Error; !errors.Is(err, ErrTenantRequired) {
+		t.Fatalf("got %v, want ErrTenantRequired", err)
+	}
+	if _, err := TenantDB(context.Background(), db, "Acme; DROP SCHEMA monogo"); !errors.Is(err, ErrInvalidTenant) {
+		t.Fatalf("got %v, want ErrInvalidTenant", err)
+	}
+}
+
+func TestSchemaTenancyTransactionIsBoundToItsTenant(t *testing.T) {
+	db, mocks := newSchemaTenancyDB(t, 0, "acme", "globex")
+	acme := contexthelper.WithTenant(context.Background(), "acme")
+
+	mocks["acme"].ExpectBegin()
+	mocks["acme"].ExpectRollback()
+	err := WithTransaction(acme, db, func(ctx context.Context) error {
+		return DBFromContext(contexthelper.WithTenant(ctx, "globex"), db).Exec("SELECT 1").Error
+	})
+	if !errors.Is(err, ErrCrossTenant) {
+		t.Fatalf("got %v, want ErrCrossTenant", err)
+	}
+	if err := mocks["acme"].ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+	if err := mocks["globex"].ExpectationsWereMet(); err != nil {
+		t.Fatalf("the other tenant was reached: %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package databasehelper
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/alxhtp/monogo/pkg/tracing"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const tracingSpanKey = "tracing:span"
+
+// tracingPlugin starts a client span for every GORM statement, as a child of the span in the statement context
+type tracingPlugin struct{}
+
+func (tracingPlugin) Name() string {
+	return "monogo:tracing"
+}
+
+func (p tracingPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+
+	type register func(name string, fn func(*gorm.DB)) error
+	operations := []struct {
+		name          string
+		before, after register
+	}{
+		{"create", callbacks.Create().Before("*").Register, callbacks.Create().After("*").Register},
+		{"query", callbacks.Query().Before("*").Register, callbacks.Query().After("*").Register},
+		{"update", callbacks.Update().Before("*").Register, callbacks.Update().After("*").Register},
+		{"delete", callbacks.Delete().Before("*").Register, callbacks.Delete().After("*").Register},
+		{"row", callbacks.Row().Before("*").Register, callbacks.Row().After("*").Register},
+		{"raw", callbacks.Raw().Before("*").Register, callbacks.Raw().After("*").Register},
+	}
+
+	for _, operation := range operations {
+		if err := operation.before("tracing:before_"+operation.name, p.before(operation.name)); err != nil {
+			return err
+		}
+		if err := operation.after("tracing:after_"+operation.name, p.after); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p tracingPlugin) before(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx, span := tracing.Start(db.Statement.Context, "gorm."+operation,
+			semconv.DBSystemPostgreSQL,
+		)
+		db.Statement.Context = ctx
+		db.Statement.Settings.Store(tracingSpanKey, span)
+	}
+}
+
+func (p tracingPlugin) after(db *gorm.DB) {
+	value, ok := db.Statement.Settings.LoadAndDelete(tracingSpanKey)
+	if !ok {
+		return
+	}
+	span, _ := value.(trace.Span)
+	defer span.End()
+
+	sql := db.Statement.SQL.String()
+	operation := statementOperation(db, "")
+	if operation != "" {
+		span.SetName("gorm." + operation)
+	}
+
+	span.SetAttributes(
+		semconv.DBQueryText(sql),
+		semconv.DBCollectionName(db.Statement.Table),
+		semconv.DBOperationName(strings.ToUpper(firstWord(sql))),
+	)
+
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
+
+// statementOperation returns "count" for count queries, such as the PaginateEntityQuery count, or fallback
+func statementOperation(db *gorm.DB, fallback string) string {
+	if strings.HasPrefix(strings.ToLower(db.Statement.SQL.String()), "select count(") {
+		return "count"
+	}
+	return fallback
+}
+
+func firstWord(sql string) string {
+	sql = strings.TrimSpace(sql)
+	if i := strings.IndexAny(sql, " \n\t"); i > 0 {
+		return sql[:i]
+	}
+	return sql
+}
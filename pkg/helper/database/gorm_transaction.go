@@ -0,0 +1,89 @@
+package databasehelper
+
+import (
+	"context"
+	"sync"
+
+	contexthelper "github.com/alxhtp/monogo/pkg/helper/context"
+	"gorm.io/gorm"
+)
+
+type txContextKey struct{}
+
+type txCommitHooksKey struct{}
+
+type txTenantKey struct{}
+
+// txCommitHooks are run once the outermost transaction is committed
+type txCommitHooks struct {
+	mu  sync.Mutex
+	fns []func()
+}
+
+// WithTransaction runs fn inside a transaction carried by the context passed to fn.
+// Nested calls reuse the outer transaction through a savepoint. The transaction is bound to the tenant of ctx.
+func WithTransaction(ctx context.Context, db *gorm.DB, fn func(ctx context.Context) error) error {
+	conn := DBFromContext(ctx, db)
+	if conn.Error != nil {
+		return conn.Error
+	}
+
+	hooks, nested := ctx.Value(txCommitHooksKey{}).(*txCommitHooks)
+	if !nested {
+		hooks = &txCommitHooks{}
+		ctx = context.WithValue(ctx, txCommitHooksKey{}, hooks)
+		ctx = context.WithValue(ctx, txTenantKey{}, contexthelper.GetTenant(ctx))
+	}
+
+	// in the row level mode the connection is bound to the tenant of ctx when the transaction begins
+	err := conn.Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txContextKey{}, tx))
+	})
+	if err != nil || nested {
+		return err
+	}
+
+	hooks.mu.Lock()
+	fns := hooks.fns
+	hooks.mu.Unlock()
+	for _, fn := range fns {
+		fn()
+	}
+
+	return nil
+}
+
+// DBFromContext returns the transaction stored in the context or db, bound to ctx.
+// In the schema-per-tenant mode db is replaced by the pool of the tenant of ctx, in the row level mode statements
+// are bound to the tenant of ctx by the row level security policies. The returned db fails every statement
+// when the transaction was started for another tenant, or in the schema-per-tenant mode when ctx carries no tenant.
+func DBFromContext(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txContextKey{}).(*gorm.DB); ok && tx != nil {
+		if tenant, _ := ctx.Value(txTenantKey{}).(string); tenant != contexthelper.GetTenant(ctx) {
+			return withError(tx.WithContext(ctx), ErrCrossTenant)
+		}
+		return tx.WithContext(ctx)
+	}
+
+	return tenantDBFromContext(ctx, db)
+}
+
+// InTransaction reports whether ctx carries a transaction of WithTransaction
+func InTransaction(ctx context.Context) bool {
+	tx, ok := ctx.Value(txContextKey{}).(*gorm.DB)
+	return ok && tx != nil
+}
+
+// AfterCommit runs fn once the transaction carried by ctx is committed, or right away outside a transaction.
+// fn is not run when the transaction is rolled back, it is run even if only a nested savepoint was rolled back.
+func AfterCommit(ctx context.Context, fn func()) {
+	hooks, ok := ctx.Value(txCommitHooksKey{}).(*txCommitHooks)
+	if !ok || !InTransaction(ctx) {
+		fn()
+		return
+	}
+
+	hooks.mu.Lock()
+	hooks.fns = append(hooks.fns, fn)
+	hooks.mu.Unlock()
+}
@@ -0,0 +1,17 @@
+package databasehelper
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// PgUniqueViolation is the SQLSTATE of a duplicate key
+const PgUniqueViolation = "23505"
+
+// IsUniqueViolation reports whether err is a duplicate key, as reported by postgres or translated by gorm
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.Is(err, gorm.ErrDuplicatedKey) || errors.As(err, &pgErr) && pgErr.Code == PgUniqueViolation
+}
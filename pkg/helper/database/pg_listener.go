@@ -0,0 +1,66 @@
+package databasehelper
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/alxhtp/monogo/config"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	listenMinBackoff = time.Second
+	listenMaxBackoff = 30 * time.Second
+)
+
+// Listen runs LISTEN on channel with a dedicated connection and calls handle with every
+// notification payload. The connection is reopened with backoff until ctx is cancelled,
+// onConnected is called after every (re)connection so callers can catch up on missed notifications.
+func Listen(ctx context.Context, cfg *config.DatabaseConfig, channel string, onConnected func(), handle func(payload string)) {
+	logger := slog.Default().With("listener", channel)
+	backoff := listenMinBackoff
+
+	for ctx.Err() == nil {
+		err := listen(ctx, cfg, channel, func() {
+			backoff = listenMinBackoff
+			logger.InfoContext(ctx, "listening")
+			if onConnected != nil {
+				onConnected()
+			}
+		}, handle)
+		if ctx.Err() != nil {
+			return
+		}
+
+		logger.WarnContext(ctx, "listen connection lost", "error", err.Error(), "retry_in", backoff.String())
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, listenMaxBackoff)
+	}
+}
+
+func listen(ctx context.Context, cfg *config.DatabaseConfig, channel string, onConnected func(), handle func(payload string)) error {
+	conn, err := pgx.Connect(ctx, DSN(cfg))
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+	onConnected()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("wait for notification: %w", err)
+		}
+		handle(notification.Payload)
+	}
+}
@@ -93,6 +93,26 @@ func Validation(message string, err error) *AppError {
 	return NewAppError(ErrValidation, message, http.StatusUnprocessableEntity, err)
 }
 
+// CodeFromStatus returns the error code matching an HTTP status carried by a usecase response
+func CodeFromStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return ErrBadRequest
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusConflict:
+		return ErrDuplicateEntry
+	case http.StatusUnprocessableEntity:
+		return ErrValidation
+	default:
+		return ErrInternalServer
+	}
+}
+
 // Wrap wraps an error with a message
 func Wrap(err error, message string) error {
 	return errors.Wrap(err, message)
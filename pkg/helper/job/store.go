@@ -0,0 +1,209 @@
+package jobhelper
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+)
+
+// Job is a snapshot of a background job progress
+type Job struct {
+	ID         uuid.UUID
+	Kind       string
+	Tenant     string
+	Status     Status
+	Total      int
+	Processed  int
+	Succeeded  int
+	Failed     int
+	Error      string
+	Result     []byte
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	FinishedAt *time.Time
+}
+
+// ErrInterrupted is the error of the jobs still running when the store is stopped
+var ErrInterrupted = errors.New("interrupted by a shutdown")
+
+// Store keeps background jobs in process memory, a job is only known to the instance running it and is lost
+// on restart. Finished jobs are pruned once they are older than retention.
+type Store struct {
+	mu        sync.RWMutex
+	jobs      map[uuid.UUID]*Job
+	retention time.Duration
+
+	// running holds the cancel functions of the jobs started by Go
+	running  map[uuid.UUID]context.CancelFunc
+	wg       sync.WaitGroup
+	stopping bool
+}
+
+func NewStore(retention time.Duration) *Store {
+	return &Store{
+		jobs:      make(map[uuid.UUID]*Job),
+		retention: retention,
+		running:   make(map[uuid.UUID]context.CancelFunc),
+	}
+}
+
+// Create registers a new pending job of tenant and returns its snapshot, tenant is empty without multi-tenancy
+func (s *Store) Create(kind, tenant string, total int) Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pruneLocked()
+
+	now := time.Now()
+	job := &Job{
+		ID:        uuid.New(),
+		Kind:      kind,
+		Tenant:    tenant,
+		Status:    StatusPending,
+		Total:     total,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.jobs[job.ID] = job
+
+	return *job
+}
+
+// Get returns a snapshot of the job
+func (s *Store) Get(id uuid.UUID) (Job, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+
+	return *job, true
+}
+
+// Update applies fn to the job under lock
+func (s *Store) Update(id uuid.UUID, fn func(job *Job)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return
+	}
+
+	fn(job)
+	job.UpdatedAt = time.Now()
+	if job.Status == StatusCompleted || job.Status == StatusFailed {
+		finishedAt := job.UpdatedAt
+		job.FinishedAt = &finishedAt
+	}
+}
+
+// Go runs the job id in a goroutine, ctx is detached from the request, see contexthelper.Detach.
+// The job fails with the error run returns, it completes otherwise. Once Stop is called jobs fail with ErrInterrupted.
+func (s *Store) Go(ctx context.Context, id uuid.UUID, run func(ctx context.Context) error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	s.mu.Lock()
+	if s.stopping {
+		s.mu.Unlock()
+		cancel()
+		s.finish(id, ErrInterrupted)
+		return
+	}
+	s.running[id] = cancel
+	s.wg.Add(1)
+	s.mu.Unlock()
+
+	go func() {
+		defer s.wg.Done()
+		defer cancel()
+
+		err := run(ctx)
+		if ctx.Err() != nil {
+			err = ErrInterrupted
+		}
+
+		s.mu.Lock()
+		delete(s.running, id)
+		s.mu.Unlock()
+		s.finish(id, err)
+	}()
+}
+
+// Stop waits for the running jobs until ctx is done, then cancels them, they fail with ErrInterrupted.
+// Jobs started after Stop fail right away.
+func (s *Store) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	s.stopping = true
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	for _, cancel := range s.running {
+		cancel()
+	}
+	s.mu.Unlock()
+	<-done
+	return nil
+}
+
+// Running returns the ids of the running jobs
+func (s *Store) Running() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := make([]string, 0, len(s.running))
+	for id := range s.running {
+		ids = append(ids, id.String())
+	}
+	return ids
+}
+
+func (s *Store) finish(id uuid.UUID, err error) {
+	s.Update(id, func(job *Job) {
+		if err != nil {
+			job.Status = StatusFailed
+			job.Error = err.Error()
+			return
+		}
+		job.Status = StatusCompleted
+	})
+}
+
+func (s *Store) pruneLocked() {
+	if s.retention <= 0 {
+		return
+	}
+
+	threshold := time.Now().Add(-s.retention)
+	for id, job := range s.jobs {
+		if job.FinishedAt != nil && job.FinishedAt.Before(threshold) {
+			delete(s.jobs, id)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package jobhelper_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	jobhelper "github.com/alxhtp/monogo/pkg/helper/job"
+)
+
+func TestGoCompletesOrFailsTheJob(t *testing.T) {
+	store := jobhelper.NewStore(time.Hour)
+	succeeded := store.Create("test", "", 1)
+	failed := store.Create("test", "", 1)
+
+	store.Go(context.Background(), succeeded.ID, func(ctx context.Context) error { return nil })
+	store.Go(context.Background(), failed.ID, func(ctx context.Context) error { return errors.New("report could not be written") })
+	if err := store.Stop(context.Background()); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+
+	if job, _ := store.Get(succeeded.ID); job.Status != jobhelper.StatusCompleted || job.FinishedAt == nil {
+		t.Fatalf("succeeded job = %+v", job)
+	}
+	if job, _ := store.Get(failed.ID); job.Status != jobhelper.StatusFailed || job.Error != "report could not be written" {
+		t.Fatalf("failed job = %+v", job)
+	}
+}
+
+func TestStopWaitsForRunningJobs(t *testing.T) {
+	store := jobhelper.NewStore(time.Hour)
+	job := store.Create("test", "", 1)
+
+	release := make(chan struct{})
+	store.Go(context.Background(), job.ID, func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+	if running := store.Running(); len(running) != 1 || running[0] != job.ID.String() {
+		t.Fatalf("running = %v", running)
+	}
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- store.Stop(context.Background()) }()
+	select {
+	case <-stopped:
+		t.Fatal("stop returned while the job was running")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-stopped; err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if got, _ := store.Get(job.ID); got.Status != jobhelper.StatusCompleted {
+		t.Fatalf("job = %+v, want it completed", got)
+	}
+}
+
+func TestStopInterruptsJobsOnceTheDrainTimeoutIsExceeded(t *testing.T) {
+	store := jobhelper.NewStore(time.Hour)
+	job := store.Create("test", "", 1)
+
+	store.Go(context.Background(), job.ID, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := store.Stop(ctx); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if got, _ := store.Get(job.ID); got.Status != jobhelper.StatusFailed || got.Error != jobhelper.ErrInterrupted.Error() {
+		t.Fatalf("job = %+v, want it interrupted", got)
+	}
+
+	late := store.Create("test", "", 1)
+	store.Go(context.Background(), late.ID, func(ctx context.Context) error {
+		t.Error("a job started after stop ran")
+		return nil
+	})
+	if got, _ := store.Get(late.ID); got.Status != jobhelper.StatusFailed {
+		t.Fatalf("late job = %+v, want it failed", got)
+	}
+}
@@ -0,0 +1,192 @@
+package tlshelper
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ClientAuthNone     = "none"
+	ClientAuthOptional = "optional"
+	ClientAuthRequire  = "require"
+)
+
+// Reloader serves the certificate and client CA bundle read from disk,
+// Run reloads them whenever one of the files is modified so certificates can be rotated without a restart
+type Reloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+	logger   *slog.Logger
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time
+}
+
+// NewReloader loads the key pair and, when caFile is set, the client CA bundle
+func NewReloader(certFile, keyFile, caFile string) (*Reloader, error) {
+	r := &Reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		caFile:   caFile,
+		logger:   slog.Default().With("component", "tls-reloader"),
+	}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Run checks the files every interval until ctx is cancelled, a failed reload keeps the previous certificates
+func (r *Reloader) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.modified() {
+				continue
+			}
+			if err := r.reload(); err != nil {
+				r.logger.ErrorContext(ctx, "reload certificates failed, keeping the previous ones", "error", err.Error())
+				continue
+			}
+			r.logger.InfoContext(ctx, "certificates reloaded", "cert_file", r.certFile)
+		}
+	}
+}
+
+// ServerConfig returns a TLS config reading the current certificates on every handshake.
+// Client certificates are verified against the CA bundle according to clientAuth.
+func (r *Reloader) ServerConfig(clientAuth tls.ClientAuthType, minVersion uint16) *tls.Config {
+	base := &tls.Config{
+		MinVersion: minVersion,
+		ClientAuth: clientAuth,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			return r.cert, nil
+		},
+	}
+
+	// the client CA pool can only be swapped through a per-connection config
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		cfg := base.Clone()
+		cfg.GetConfigForClient = nil
+		r.mu.RLock()
+		cfg.ClientCAs = r.clientCAs
+		r.mu.RUnlock()
+		return cfg, nil
+	}
+
+	return base
+}
+
+func (r *Reloader) reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("load key pair: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.caFile != "" {
+		bundle, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return fmt.Errorf("read client CA bundle: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(bundle) {
+			return errors.New("client CA bundle holds no certificate")
+		}
+	}
+
+	modTimes := make(map[string]time.Time, 3)
+	for _, file := range r.files() {
+		if info, err := os.Stat(file); err == nil {
+			modTimes[file] = info.ModTime()
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.modTimes = modTimes
+
+	return nil
+}
+
+func (r *Reloader) modified() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			// the file is being replaced, retry on the next tick
+			continue
+		}
+		if !info.ModTime().Equal(r.modTimes[file]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Reloader) files() []string {
+	files := []string{r.certFile, r.keyFile}
+	if r.caFile != "" {
+		files = append(files, r.caFile)
+	}
+	return files
+}
+
+// ParseClientAuth maps none, optional and require to the client certificate policy,
+// optional and require verify the presented certificate against the client CA bundle
+func ParseClientAuth(value string) (tls.ClientAuthType, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case ClientAuthNone, "":
+		return tls.NoClientCert, nil
+	case ClientAuthOptional:
+		return tls.VerifyClientCertIfGiven, nil
+	case ClientAuthRequire:
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return tls.NoClientCert, fmt.Errorf("unknown client auth %q", value)
+	}
+}
+
+// ParseVersion maps 1.2 and 1.3 to the TLS version
+func ParseVersion(value string) (uint16, error) {
+	switch strings.TrimSpace(value) {
+	case "1.2", "":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported TLS version %q", value)
+	}
+}
+
+// Fingerprint returns the hex encoded SHA-256 of the DER certificate
+func Fingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
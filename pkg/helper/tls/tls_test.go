@@ -0,0 +1,150 @@
+package tlshelper_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tlshelper "github.com/alxhtp/monogo/pkg/helper/tls"
+)
+
+// writeKeyPair writes a self-signed certificate of commonName and its key, modified at modTime
+func writeKeyPair(t *testing.T, certFile, keyFile, commonName string, modTime time.Time) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+
+	writePEM(t, certFile, "CERTIFICATE", der, modTime)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER, modTime)
+}
+
+func writePEM(t *testing.T, file, blockType string, der []byte, modTime time.Time) {
+	t.Helper()
+
+	if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write %s: %v", file, err)
+	}
+	if err := os.Chtimes(file, modTime, modTime); err != nil {
+		t.Fatalf("touch %s: %v", file, err)
+	}
+}
+
+// servedCommonName returns the common name of the certificate served on addr
+func servedCommonName(t *testing.T, addr string) string {
+	t.Helper()
+
+	conn, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates[0].Subject.CommonName
+}
+
+func TestReloaderServesRotatedCertificates(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	rotatedAt := time.Now().Add(-time.Minute)
+	writeKeyPair(t, certFile, keyFile, "first", rotatedAt)
+
+	reloader, err := tlshelper.NewReloader(certFile, keyFile, "")
+	if err != nil {
+		t.Fatalf("new reloader: %v", err)
+	}
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", reloader.ServerConfig(tls.NoClientCert, tls.VersionTLS12))
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				_ = conn.(*tls.Conn).Handshake()
+			}(conn)
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reloader.Run(ctx, 5*time.Millisecond)
+
+	addr := listener.Addr().String()
+	if got := servedCommonName(t, addr); got != "first" {
+		t.Fatalf("served %q, want first", got)
+	}
+
+	waitFor := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for servedCommonName(t, addr) != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("the %s certificate is not served", want)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	writeKeyPair(t, certFile, keyFile, "second", rotatedAt.Add(time.Second))
+	waitFor("second")
+
+	// a broken certificate is not loaded, the previous one is kept until a valid one is written
+	if err := os.WriteFile(certFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if got := servedCommonName(t, addr); got != "second" {
+		t.Fatalf("served %q after a broken rotation, want second", got)
+	}
+	writeKeyPair(t, certFile, keyFile, "third", rotatedAt.Add(2*time.Second))
+	waitFor("third")
+}
+
+func TestNewReloaderRejectsInvalidFiles(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeKeyPair(t, certFile, keyFile, "server", time.Now())
+	invalid := filepath.Join(dir, "invalid.pem")
+	if err := os.WriteFile(invalid, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if _, err := tlshelper.NewReloader(invalid, keyFile, ""); err == nil {
+		t.Error("loaded an invalid certificate")
+	}
+	if _, err := tlshelper.NewReloader(certFile, keyFile, invalid); err == nil {
+		t.Error("loaded a client CA bundle holding no certificate")
+	}
+}
@@ -0,0 +1,51 @@
+package webhookhelper
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress is returned when a webhook resolves to an address of a private network
+var ErrForbiddenAddress = errors.New("webhook address is not public")
+
+// sharedAddressSpace is the carrier grade NAT range, it is not covered by netip.Addr.IsPrivate
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// CheckAddress rejects loopback, private, link-local, multicast and unspecified addresses
+func CheckAddress(addr netip.Addr) error {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() || sharedAddressSpace.Contains(addr) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addr)
+	}
+	return nil
+}
+
+// dialControl checks the resolved address of every connection, so redirects and DNS rebinding are covered too
+func dialControl(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, address)
+	}
+	return CheckAddress(addrPort.Addr())
+}
+
+// NewTransport returns the transport of webhook deliveries, it only connects to public addresses
+// unless allowPrivateNetworks is set. Proxies are not used, they would connect on behalf of the guard.
+func NewTransport(allowPrivateNetworks bool) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if allowPrivateNetworks {
+		return transport
+	}
+
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: dialControl}
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
@@ -0,0 +1,66 @@
+package webhookhelper_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	webhookhelper "github.com/alxhtp/monogo/pkg/helper/webhook"
+)
+
+func TestCheckAddress(t *testing.T) {
+	tests := []struct {
+		addr      string
+		forbidden bool
+	}{
+		{addr: "93.184.216.34"},
+		{addr: "2606:2800:220:1:248:1893:25c8:1946"},
+		{addr: "127.0.0.1", forbidden: true},
+		{addr: "::1", forbidden: true},
+		{addr: "10.1.2.3", forbidden: true},
+		{addr: "172.16.0.1", forbidden: true},
+		{addr: "192.168.1.1", forbidden: true},
+		{addr: "169.254.169.254", forbidden: true},
+		{addr: "fe80::1", forbidden: true},
+		{addr: "fd00::1", forbidden: true},
+		{addr: "100.64.0.1", forbidden: true},
+		{addr: "0.0.0.0", forbidden: true},
+		{addr: "224.0.0.1", forbidden: true},
+		{addr: "::ffff:127.0.0.1", forbidden: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			err := webhookhelper.CheckAddress(netip.MustParseAddr(tt.addr))
+			if tt.forbidden != errors.Is(err, webhookhelper.ErrForbiddenAddress) {
+				t.Fatalf("got %v, want forbidden %t", err, tt.forbidden)
+			}
+		})
+	}
+}
+
+func TestTransportRefusesPrivateNetworks(t *testing.T) {
+	var received int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		received++
+	}))
+	defer server.Close()
+
+	_, err := (&http.Client{Transport: webhookhelper.NewTransport(false)}).Get(server.URL)
+	if !errors.Is(err, webhookhelper.ErrForbiddenAddress) {
+		t.Fatalf("got %v, want ErrForbiddenAddress", err)
+	}
+	if received != 0 {
+		t.Fatal("the loopback server was reached")
+	}
+
+	res, err := (&http.Client{Transport: webhookhelper.NewTransport(true)}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("allowed private networks: %v", err)
+	}
+	res.Body.Close()
+	if received != 1 {
+		t.Fatal("the loopback server was not reached")
+	}
+}
@@ -0,0 +1,53 @@
+package webhookhelper_test
+
+import (
+	"testing"
+	"time"
+
+	webhookhelper "github.com/alxhtp/monogo/pkg/helper/webhook"
+)
+
+func TestSign(t *testing.T) {
+	const want = "sha256=3dd1b9aef568d75f6790a84bd2e5dfa1f44409eef3cbdbd3f10b837376100c11"
+	if got := webhookhelper.Sign("secret", 1700000000, []byte(`{"id":1}`)); got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	now := time.Now().Unix()
+	signature := webhookhelper.Sign("secret", now, body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		body      []byte
+		signature string
+		tolerance time.Duration
+		want      bool
+	}{
+		{name: "valid", secret: "secret", timestamp: now, body: body, signature: signature, tolerance: time.Minute, want: true},
+		{name: "no tolerance", secret: "secret", timestamp: now, body: body, signature: signature, want: true},
+		{name: "another secret", secret: "other", timestamp: now, body: body, signature: signature, tolerance: time.Minute},
+		{name: "tampered body", secret: "secret", timestamp: now, body: []byte(`{"id":2}`), signature: signature, tolerance: time.Minute},
+		{name: "another timestamp", secret: "secret", timestamp: now - 1, body: body, signature: signature, tolerance: time.Minute},
+		{name: "missing prefix", secret: "secret", timestamp: now, body: body, signature: signature[len("sha256="):], tolerance: time.Minute},
+		{
+			name: "replayed", secret: "secret", timestamp: now - 600, body: body,
+			signature: webhookhelper.Sign("secret", now-600, body), tolerance: 5 * time.Minute,
+		},
+		{
+			name: "from the future", secret: "secret", timestamp: now + 600, body: body,
+			signature: webhookhelper.Sign("secret", now+600, body), tolerance: 5 * time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := webhookhelper.Verify(tt.secret, tt.timestamp, tt.body, tt.signature, tt.tolerance); got != tt.want {
+				t.Fatalf("got %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
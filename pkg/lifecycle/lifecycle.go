@@ -0,0 +1,192 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const (
+	defaultDrainTimeout = 30 * time.Second
+
+	// waitLogInterval is how often a stop hook that has not returned yet is reported
+	waitLogInterval = 5 * time.Second
+
+	// stopGracePeriod bounds the hooks stopped after the drain timeout was exceeded
+	stopGracePeriod = time.Second
+)
+
+// Hook is a component started and stopped by the Manager.
+// OnStart must not block, long running work belongs in goroutines stopped by OnStop.
+// Pending optionally describes what the component is still waiting for while it stops.
+type Hook struct {
+	Name    string
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+	Pending func() []string
+}
+
+func (h Hook) pending() []string {
+	if h.Pending == nil {
+		return nil
+	}
+	return h.Pending()
+}
+
+// Manager starts hooks in the order they were appended and stops them in reverse order,
+// so components are stopped before the dependencies they use.
+type Manager struct {
+	hooks        []Hook
+	started      []Hook
+	drainTimeout time.Duration
+	failed       chan error
+	logger       *slog.Logger
+}
+
+func NewManager(drainTimeout time.Duration) *Manager {
+	if drainTimeout <= 0 {
+		drainTimeout = defaultDrainTimeout
+	}
+
+	return &Manager{
+		drainTimeout: drainTimeout,
+		failed:       make(chan error, 1),
+		logger:       slog.Default().With("component", "lifecycle"),
+	}
+}
+
+// Append registers hooks, a hook must be appended after the hooks it depends on
+func (m *Manager) Append(hooks ...Hook) {
+	m.hooks = append(m.hooks, hooks...)
+}
+
+// Run starts every hook, waits until ctx is cancelled or a component fails, see Fail, and stops them within the drain timeout.
+// When a hook fails to start or a component fails the hooks already started are stopped and the error is returned.
+func (m *Manager) Run(ctx context.Context) error {
+	if err := m.Start(ctx); err != nil {
+		return errors.Join(err, m.stop(context.WithoutCancel(ctx)))
+	}
+
+	select {
+	case <-ctx.Done():
+		m.logger.InfoContext(ctx, "shutting down", "drain_timeout", m.drainTimeout.String())
+		return m.stop(context.WithoutCancel(ctx))
+	case err := <-m.failed:
+		m.logger.ErrorContext(ctx, "component failed, shutting down", "drain_timeout", m.drainTimeout.String(), "error", err.Error())
+		return errors.Join(err, m.stop(context.WithoutCancel(ctx)))
+	}
+}
+
+// Fail reports that a started component stopped on its own, e.g. a listener failed, Run then stops every hook.
+// Only the first failure is kept.
+func (m *Manager) Fail(name string, err error) {
+	select {
+	case m.failed <- fmt.Errorf("%s: %w", name, err):
+	default:
+	}
+}
+
+// Start calls OnStart of every hook in order, it stops at the first error
+func (m *Manager) Start(ctx context.Context) error {
+	for _, hook := range m.hooks {
+		if hook.OnStart != nil {
+			if err := hook.OnStart(ctx); err != nil {
+				return fmt.Errorf("start %s: %w", hook.Name, err)
+			}
+		}
+		m.started = append(m.started, hook)
+		m.logger.InfoContext(ctx, "started", "hook", hook.Name)
+	}
+
+	return nil
+}
+
+// stop calls OnStop of the started hooks in reverse order. Every hook is stopped, also once the
+// drain timeout is exceeded, so resources are released even when a previous hook blocked.
+func (m *Manager) stop(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, m.drainTimeout)
+	defer cancel()
+
+	var errs []error
+	for i := len(m.started) - 1; i >= 0; i-- {
+		hook := m.started[i]
+		if hook.OnStop == nil {
+			continue
+		}
+
+		start := time.Now()
+		if err := m.stopHook(ctx, hook); err != nil {
+			m.logger.ErrorContext(ctx, "stop failed", "hook", hook.Name, "duration", time.Since(start).String(), "error", err.Error())
+			errs = append(errs, fmt.Errorf("stop %s: %w", hook.Name, err))
+			continue
+		}
+		m.logger.InfoContext(ctx, "stopped", "hook", hook.Name, "duration", time.Since(start).String())
+	}
+	m.started = nil
+
+	return errors.Join(errs...)
+}
+
+// stopHook waits for OnStop and reports periodically what it is blocked on
+func (m *Manager) stopHook(ctx context.Context, hook Hook) error {
+	if ctx.Err() != nil {
+		// a previous hook used up the drain timeout, give this one a short grace period
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.WithoutCancel(ctx), stopGracePeriod)
+		defer cancel()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- hook.OnStop(ctx)
+	}()
+
+	ticker := time.NewTicker(waitLogInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case err := <-done:
+			return err
+		case <-ticker.C:
+			m.logger.WarnContext(ctx, "waiting for hook to stop", "hook", hook.Name, "pending", hook.pending())
+		case <-ctx.Done():
+			m.logger.ErrorContext(ctx, "drain timeout exceeded, not waiting any longer", "hook", hook.Name, "pending", hook.pending())
+			return ctx.Err()
+		}
+	}
+}
+
+// Worker returns a hook running run in a goroutine until it is stopped.
+// The worker context is detached from the start context, so workers keep running
+// while the components stopped before them drain.
+func Worker(name string, run func(ctx context.Context)) Hook {
+	var (
+		cancel context.CancelFunc
+		done   = make(chan struct{})
+	)
+
+	return Hook{
+		Name: name,
+		OnStart: func(ctx context.Context) error {
+			var workerCtx context.Context
+			workerCtx, cancel = context.WithCancel(context.WithoutCancel(ctx))
+			go func() {
+				defer close(done)
+				run(workerCtx)
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+}
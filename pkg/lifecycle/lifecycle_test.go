@@ -0,0 +1,47 @@
+package lifecycle_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alxhtp/monogo/pkg/lifecycle"
+)
+
+func TestManagerRunStopsOnFailure(t *testing.T) {
+	manager := lifecycle.NewManager(time.Second)
+	errListen := errors.New("listener closed")
+
+	var stopped []string
+	manager.Append(
+		lifecycle.Hook{
+			Name:   "database",
+			OnStop: func(ctx context.Context) error { stopped = append(stopped, "database"); return nil },
+		},
+		lifecycle.Hook{
+			Name: "http-server",
+			OnStart: func(ctx context.Context) error {
+				go manager.Fail("http-server", errListen)
+				return nil
+			},
+			OnStop: func(ctx context.Context) error { stopped = append(stopped, "http-server"); return nil },
+		},
+	)
+
+	done := make(chan error, 1)
+	go func() { done <- manager.Run(context.Background()) }()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, errListen) {
+			t.Fatalf("Run() = %v, want %v", err, errListen)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after a component failed")
+	}
+
+	if len(stopped) != 2 || stopped[0] != "http-server" || stopped[1] != "database" {
+		t.Fatalf("stopped = %v, want the hooks stopped in reverse order", stopped)
+	}
+}
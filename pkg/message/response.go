@@ -5,18 +5,24 @@ import "fmt"
 type ResponseMessage string
 
 const (
-	SuccessCreated ResponseMessage = "Successfully created a"
-	SuccessUpdated ResponseMessage = "Successfully updated a"
-	SuccessDeleted ResponseMessage = "Successfully deleted a"
-	SuccessList    ResponseMessage = "Successfully got a list of"
-	SuccessGetByID ResponseMessage = "Successfully got a"
-	FailedCreated  ResponseMessage = "Failed to create a"
-	FailedUpdated  ResponseMessage = "Failed to update a"
-	FailedDeleted  ResponseMessage = "Failed to delete a"
-	FailedList     ResponseMessage = "Failed to get a list of"
-	FailedUpdate   ResponseMessage = "Failed to update a"
-	FailedDelete   ResponseMessage = "Failed to delete a"
-	FailedGetByID  ResponseMessage = "Failed to get a"
+	SuccessCreated   ResponseMessage = "Successfully created a"
+	SuccessUpdated   ResponseMessage = "Successfully updated a"
+	SuccessDeleted   ResponseMessage = "Successfully deleted a"
+	SuccessList      ResponseMessage = "Successfully got a list of"
+	SuccessGetByID   ResponseMessage = "Successfully got a"
+	FailedCreated    ResponseMessage = "Failed to create a"
+	FailedUpdated    ResponseMessage = "Failed to update a"
+	FailedDeleted    ResponseMessage = "Failed to delete a"
+	FailedList       ResponseMessage = "Failed to get a list of"
+	FailedUpdate     ResponseMessage = "Failed to update a"
+	FailedDelete     ResponseMessage = "Failed to delete a"
+	FailedGetByID    ResponseMessage = "Failed to get a"
+	SuccessImport    ResponseMessage = "Successfully imported a list of"
+	SuccessDryRun    ResponseMessage = "Successfully validated a list of"
+	SuccessQueued    ResponseMessage = "Successfully queued an import of"
+	FailedImport     ResponseMessage = "Failed to import a list of"
+	SuccessRedeliver ResponseMessage = "Successfully queued a redelivery of"
+	FailedDuplicate  ResponseMessage = "A unique field is already taken by another"
 )
 
 func GetResponseMessage(message ResponseMessage, entity string) string {
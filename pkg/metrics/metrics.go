@@ -0,0 +1,156 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "monogo"
+
+// Results of CacheRequestsTotal
+const (
+	CacheHit  = "hit"
+	CacheMiss = "miss"
+)
+
+// Registry holds every application metric, it is exported by Handler
+var Registry = prometheus.NewRegistry()
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+var (
+	HTTPRequestsTotal = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Number of HTTP requests by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = promauto.With(Registry).NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by method, route template and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	HTTPDeprecatedRequestsTotal = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "deprecated_requests_total",
+		Help:      "Number of requests served by deprecated API versions or endpoints, by version, method and route template.",
+	}, []string{"version", "method", "route"})
+
+	DBQueryDuration = promauto.With(Registry).NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "GORM statement latency by operation and table.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"operation", "table", "error"})
+
+	DBReplicaLagSeconds = promauto.With(Registry).NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "replica_lag_seconds",
+		Help:      "Replay lag of each read replica at its last health check.",
+	}, []string{"replica"})
+
+	DBReplicaHealthy = promauto.With(Registry).NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "replica_healthy",
+		Help:      "Whether each read replica receives reads, 0 while it is unreachable or lagging.",
+	}, []string{"replica"})
+
+	CacheRequestsTotal = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "requests_total",
+		Help:      "Number of cache lookups by cache and result, hit or miss.",
+	}, []string{"cache", "result"})
+
+	UsersCreatedTotal = promauto.With(Registry).NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "users",
+		Name:      "created_total",
+		Help:      "Number of users created.",
+	})
+
+	UsersBannedTotal = promauto.With(Registry).NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "users",
+		Name:      "banned_total",
+		Help:      "Number of users moved to the banned status.",
+	})
+
+	UsersDeletedTotal = promauto.With(Registry).NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "users",
+		Name:      "deleted_total",
+		Help:      "Number of users deleted.",
+	})
+)
+
+// dbStats holds the pool statistics collector registered under each database name
+var dbStats = struct {
+	sync.Mutex
+	byName map[string]dbStatsCollector
+}{byName: map[string]dbStatsCollector{}}
+
+type dbStatsCollector struct {
+	db        *sql.DB
+	collector prometheus.Collector
+}
+
+// RegisterDBStats exports the connection pool statistics of db as dbName. A pool registered earlier under dbName,
+// e.g. one that was reopened, is replaced so the gauges follow the open pool.
+func RegisterDBStats(db *sql.DB, dbName string) error {
+	dbStats.Lock()
+	defer dbStats.Unlock()
+
+	if registered, ok := dbStats.byName[dbName]; ok {
+		if registered.db == db {
+			return nil
+		}
+		Registry.Unregister(registered.collector)
+		delete(dbStats.byName, dbName)
+	}
+
+	collector := collectors.NewDBStatsCollector(db, dbName)
+	if err := Registry.Register(collector); err != nil {
+		return err
+	}
+	dbStats.byName[dbName] = dbStatsCollector{db: db, collector: collector}
+
+	return nil
+}
+
+// UnregisterDBStats stops exporting the statistics of db, call it when the pool is closed
+func UnregisterDBStats(db *sql.DB) {
+	dbStats.Lock()
+	defer dbStats.Unlock()
+
+	for name, registered := range dbStats.byName {
+		if registered.db == db {
+			Registry.Unregister(registered.collector)
+			delete(dbStats.byName, name)
+		}
+	}
+}
+
+// Handler serves the registry in the prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
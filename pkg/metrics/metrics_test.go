@@ -0,0 +1,67 @@
+package metrics_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alxhtp/monogo/pkg/metrics"
+)
+
+func newPool(t *testing.T, maxOpenConns int) *sql.DB {
+	t.Helper()
+
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	db.SetMaxOpenConns(maxOpenConns)
+	return db
+}
+
+// maxOpenConns returns the exported max open connections of dbName, false when it is not exported
+func maxOpenConns(t *testing.T, dbName string) (float64, bool) {
+	t.Helper()
+
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != "go_sql_max_open_connections" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "db_name" && label.GetValue() == dbName {
+					return metric.GetGauge().GetValue(), true
+				}
+			}
+		}
+	}
+	return 0, false
+}
+
+func TestRegisterDBStatsFollowsTheOpenPool(t *testing.T) {
+	first, reopened := newPool(t, 1), newPool(t, 2)
+
+	for _, db := range []*sql.DB{first, first, reopened} {
+		if err := metrics.RegisterDBStats(db, "monogo-test"); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	if got, ok := maxOpenConns(t, "monogo-test"); !ok || got != 2 {
+		t.Fatalf("max open connections = %v, %t, want the reopened pool", got, ok)
+	}
+
+	// the first pool is no longer exported, unregistering it leaves the reopened one
+	metrics.UnregisterDBStats(first)
+	if _, ok := maxOpenConns(t, "monogo-test"); !ok {
+		t.Fatal("the reopened pool is not exported")
+	}
+	metrics.UnregisterDBStats(reopened)
+	if _, ok := maxOpenConns(t, "monogo-test"); ok {
+		t.Fatal("a closed pool is still exported")
+	}
+}